@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -8,14 +9,19 @@ import (
 	"time"
 
 	"frappuccino/internal/handler"
+	"frappuccino/internal/jobs"
 	"frappuccino/internal/repositories"
 	"frappuccino/internal/router"
 	"frappuccino/internal/service"
+	"frappuccino/models"
 	"frappuccino/pkg/database"
 	"frappuccino/pkg/envconfig"
+	"frappuccino/pkg/events"
 	"frappuccino/pkg/flags"
 	"frappuccino/pkg/logger"
+	"frappuccino/pkg/payments"
 	"frappuccino/pkg/shutdownsetup"
+	"frappuccino/pkg/webhook"
 )
 
 func main() {
@@ -95,14 +101,111 @@ func main() {
 	orderRepo := repositories.NewOrderRepository(appLogger, db)
 	menuRepo := repositories.NewMenuRepository(appLogger, db)
 	inventoryRepo := repositories.NewInventoryRepository(appLogger, db)
+	lotRepo := repositories.NewLotRepository(appLogger, db)
+	reservationRepo := repositories.NewReservationRepository(appLogger, db)
 	aggregationRepo := repositories.NewAggregationRepository(db, appLogger)
+	alertRepo := repositories.NewAlertRepository(appLogger, db)
+	supplierRepo := repositories.NewSupplierRepository(appLogger, db)
+	purchaseOrderRepo := repositories.NewPurchaseOrderRepository(appLogger, db)
+	stocktakeRepo := repositories.NewStocktakeRepository(appLogger, db)
+	priceRepo := repositories.NewPriceRepository(appLogger, db)
+	stationRepo := repositories.NewStationRepository(appLogger, db)
+	pricingRuleRepo := repositories.NewPricingRuleRepository(appLogger, db)
+	taxRateRepo := repositories.NewTaxRateRepository(appLogger, db)
+	paymentRepo := repositories.NewPaymentRepository(appLogger, db)
+	drawerRepo := repositories.NewDrawerRepository(appLogger, db)
+	customerRepo := repositories.NewCustomerRepository(appLogger, db)
+	loyaltyRepo := repositories.NewLoyaltyRepository(appLogger, db)
+
+	webhookConfig := webhook.Config{
+		URLs:   envconfig.GetList("ALERT_WEBHOOK_URLS"),
+		Secret: envconfig.GetEnv("ALERT_WEBHOOK_SECRET", ""),
+	}
+	if attempts, err := strconv.Atoi(envconfig.GetEnv("ALERT_WEBHOOK_MAX_ATTEMPTS", "3")); err == nil {
+		webhookConfig.MaxAttempts = attempts
+	}
+	if len(webhookConfig.URLs) > 0 && webhookConfig.Secret == "" {
+		appLogger.Warn("ALERT_WEBHOOK_SECRET is not set, alert webhooks will be unsigned")
+	}
+	alertSender := webhook.NewSender(webhookConfig)
+
+	// Menu schedules are evaluated in the shop's timezone
+	shopLocation, err := time.LoadLocation(envconfig.GetEnv("SHOP_TIMEZONE", "UTC"))
+	if err != nil {
+		appLogger.Warn("Invalid SHOP_TIMEZONE, using UTC", "error", err)
+		shopLocation = time.UTC
+	}
+
+	// Menu prices either include tax or have it added on top
+	taxMode := envconfig.GetEnv("TAX_PRICING_MODE", models.TaxExclusive)
+	if taxMode != models.TaxExclusive && taxMode != models.TaxInclusive {
+		appLogger.Warn("Invalid TAX_PRICING_MODE, using exclusive", "value", taxMode)
+		taxMode = models.TaxExclusive
+	}
+
+	// Card payments go through the configured gateway; only the local fake is built in
+	var paymentGateway payments.Gateway
+	switch gatewayName := envconfig.GetEnv("PAYMENT_GATEWAY", "fake"); gatewayName {
+	case "fake":
+		paymentGateway = payments.NewFakeGateway()
+	default:
+		appLogger.Warn("Unknown PAYMENT_GATEWAY, using fake", "value", gatewayName)
+		paymentGateway = payments.NewFakeGateway()
+	}
 
 	// Initialize services with logger
 	// TODO: Services updated for PostgreSQL transition
-	orderService := service.NewOrderService(orderRepo, menuRepo, inventoryRepo, appLogger)
-	menuService := service.NewMenuService(inventoryRepo, menuRepo, orderRepo, appLogger)
-	inventoryService := service.NewInventoryService(inventoryRepo, orderRepo, menuRepo, appLogger)
-	aggregationService := service.NewAggregationService(aggregationRepo, appLogger)
+	menuService := service.NewMenuService(inventoryRepo, menuRepo, orderRepo, priceRepo, stationRepo, shopLocation, appLogger)
+	alertService := service.NewAlertService(alertRepo, inventoryRepo, menuService, alertSender, appLogger)
+	orderEvents := events.NewBus(64)
+	orderService := service.NewOrderService(orderRepo, menuRepo, inventoryRepo, reservationRepo, pricingRuleRepo, customerRepo, taxRateRepo, paymentRepo, loyaltyRepo, alertService, orderEvents, shopLocation, taxMode == models.TaxInclusive, appLogger)
+	inventoryService := service.NewInventoryService(inventoryRepo, lotRepo, orderRepo, menuRepo, alertService, appLogger)
+	aggregationService := service.NewAggregationService(aggregationRepo, menuRepo, inventoryRepo, shopLocation, appLogger)
+	supplierService := service.NewSupplierService(supplierRepo, inventoryRepo, appLogger)
+	purchaseOrderService := service.NewPurchaseOrderService(purchaseOrderRepo, supplierRepo, inventoryRepo, alertService, appLogger)
+	stocktakeService := service.NewStocktakeService(stocktakeRepo, alertService, appLogger)
+	pricingRuleService := service.NewPricingRuleService(pricingRuleRepo, menuRepo, appLogger)
+	taxService := service.NewTaxService(taxRateRepo, appLogger)
+	paymentService := service.NewPaymentService(paymentRepo, orderRepo, loyaltyRepo, paymentGateway, appLogger)
+	drawerService := service.NewDrawerService(drawerRepo, appLogger)
+	customerService := service.NewCustomerService(customerRepo, orderRepo, appLogger)
+	loyaltyService := service.NewLoyaltyService(loyaltyRepo, customerRepo, appLogger)
+	kdsService := service.NewKDSService(orderRepo, menuRepo, orderEvents, appLogger)
+
+	// Background jobs stop when main returns after shutdown
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	defer stopJobs()
+
+	expiryInterval, err := time.ParseDuration(envconfig.GetEnv("LOT_EXPIRY_CHECK_INTERVAL", "1h"))
+	if err != nil {
+		appLogger.Warn("Invalid LOT_EXPIRY_CHECK_INTERVAL, using 1h", "error", err)
+		expiryInterval = time.Hour
+	}
+	priceChangeInterval, err := time.ParseDuration(envconfig.GetEnv("PRICE_CHANGE_CHECK_INTERVAL", "1m"))
+	if err != nil {
+		appLogger.Warn("Invalid PRICE_CHANGE_CHECK_INTERVAL, using 1m", "error", err)
+		priceChangeInterval = time.Minute
+	}
+	alertRedeliveryInterval, err := time.ParseDuration(envconfig.GetEnv("ALERT_REDELIVERY_INTERVAL", "5m"))
+	if err != nil {
+		appLogger.Warn("Invalid ALERT_REDELIVERY_INTERVAL, using 5m", "error", err)
+		alertRedeliveryInterval = 5 * time.Minute
+	}
+	if db != nil {
+		jobs.RunPeriodically(jobsCtx, "expired-lot-write-off", expiryInterval, appLogger, func() error {
+			// Write-offs re-check stock levels, which syncs the menu items using the written-off ingredients
+			_, err := inventoryService.WriteOffExpiredLots()
+			return err
+		})
+		jobs.RunPeriodically(jobsCtx, "scheduled-price-changes", priceChangeInterval, appLogger, func() error {
+			_, err := menuService.ApplyScheduledPriceChanges()
+			return err
+		})
+		jobs.RunPeriodically(jobsCtx, "alert-redelivery", alertRedeliveryInterval, appLogger, func() error {
+			_, err := alertService.RedeliverAlerts()
+			return err
+		})
+	}
 
 	// Initialize handlers with logger
 	// TODO: Handlers updated for PostgreSQL transition
@@ -110,9 +213,19 @@ func main() {
 	menuHandler := handler.NewMenuHandler(menuService, appLogger)
 	inventoryHandler := handler.NewInventoryHandler(inventoryService, appLogger)
 	aggregationHandler := handler.NewAggregationHandler(aggregationService, appLogger)
+	supplierHandler := handler.NewSupplierHandler(supplierService, appLogger)
+	purchaseOrderHandler := handler.NewPurchaseOrderHandler(purchaseOrderService, appLogger)
+	stocktakeHandler := handler.NewStocktakeHandler(stocktakeService, appLogger)
+	pricingRuleHandler := handler.NewPricingRuleHandler(pricingRuleService, appLogger)
+	taxRateHandler := handler.NewTaxRateHandler(taxService, appLogger)
+	paymentHandler := handler.NewPaymentHandler(paymentService, appLogger)
+	drawerHandler := handler.NewDrawerHandler(drawerService, appLogger)
+	customerHandler := handler.NewCustomerHandler(customerService, appLogger)
+	loyaltyHandler := handler.NewLoyaltyHandler(loyaltyService, appLogger)
+	kdsHandler := handler.NewKDSHandler(kdsService, appLogger)
 
 	// TODO: Router updated for PostgreSQL transition
-	mux := router.NewRouter(orderHandler, menuHandler, inventoryHandler, aggregationHandler)
+	mux := router.NewRouter(orderHandler, menuHandler, inventoryHandler, aggregationHandler, supplierHandler, purchaseOrderHandler, stocktakeHandler, pricingRuleHandler, taxRateHandler, paymentHandler, drawerHandler, customerHandler, loyaltyHandler, kdsHandler)
 
 	handler := appLogger.HTTPMiddleware(mux)
 
@@ -130,6 +243,8 @@ func main() {
 		WriteTimeout: 15 * time.Second,
 		IdleTimeout:  60 * time.Second,
 	}
+	// Closing the bus ends open event streams so shutdown doesn't wait on them
+	server.RegisterOnShutdown(orderEvents.Close)
 
 	serverErrors := make(chan error, 1)
 
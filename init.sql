@@ -11,13 +11,28 @@ CREATE TYPE unit_type AS ENUM ('grams', 'ml', 'pieces', 'kg', 'liters');
 
 CREATE TYPE transaction_type AS ENUM ('purchase', 'usage', 'waste', 'adjustment', 'return');
 
+CREATE TYPE purchase_order_status AS ENUM ('draft', 'sent', 'received', 'cancelled');
+
+CREATE TYPE stocktake_status AS ENUM ('open', 'approved', 'cancelled');
+
+CREATE TYPE station AS ENUM ('bar', 'kitchen', 'pastry_case');
+
+CREATE TYPE prep_status AS ENUM ('queued', 'in_progress', 'done');
+
 CREATE TABLE inventory (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     name VARCHAR(255) NOT NULL UNIQUE,
     quantity DECIMAL(10,3) NOT NULL DEFAULT 0 CHECK (quantity >= 0),
     unit unit_type NOT NULL,
     min_threshold DECIMAL(10,3) NOT NULL DEFAULT 0 CHECK (min_threshold >= 0),
-    cost_per_unit DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (cost_per_unit >= 0),
+    cost_per_unit DECIMAL(12,4) NOT NULL DEFAULT 0 CHECK (cost_per_unit >= 0), -- Per unit of stock, e.g. per g
+    shelf_life_days INTEGER NOT NULL DEFAULT 0 CHECK (shelf_life_days >= 0),
+    allergens TEXT[] NOT NULL DEFAULT '{}',
+    -- Nutrition per unit of the item's unit, menu item nutrition is derived from it
+    kcal_per_unit DECIMAL(10,4) NOT NULL DEFAULT 0 CHECK (kcal_per_unit >= 0),
+    sugar_g_per_unit DECIMAL(10,4) NOT NULL DEFAULT 0 CHECK (sugar_g_per_unit >= 0),
+    fat_g_per_unit DECIMAL(10,4) NOT NULL DEFAULT 0 CHECK (fat_g_per_unit >= 0),
+    caffeine_mg_per_unit DECIMAL(10,4) NOT NULL DEFAULT 0 CHECK (caffeine_mg_per_unit >= 0),
     last_updated TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
@@ -28,14 +43,34 @@ CREATE TABLE menu_items (
     category VARCHAR(100) NOT NULL,
     price DECIMAL(10,2) NOT NULL CHECK (price >= 0),
     available BOOLEAN NOT NULL DEFAULT true,
+    out_of_stock BOOLEAN NOT NULL DEFAULT false, -- available was switched off because an ingredient ran out
+    available_from DATE, -- Seasonal items are only sold between these dates, in the shop's timezone
+    available_until DATE,
     metadata JSONB DEFAULT '{}',
     tags TEXT[] DEFAULT '{}',
-    allergens TEXT[] DEFAULT '{}',
+    -- Allergens are derived from the recipe's ingredients; these correct them by hand
+    allergens_added TEXT[] NOT NULL DEFAULT '{}',
+    allergens_removed TEXT[] NOT NULL DEFAULT '{}',
+    nutrition_overrides JSONB NOT NULL DEFAULT '{}', -- Per-serving values set by hand, e.g. {"kcal": 120}
     available_sizes item_size[] DEFAULT ARRAY['medium']::item_size[],
+    station station, -- Overrides the category's station
     created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
+-- Station each menu category's items are made at; categories without a route go to the kitchen
+CREATE TABLE category_stations (
+    category VARCHAR(100) PRIMARY KEY,
+    station station NOT NULL
+);
+
+INSERT INTO category_stations (category, station) VALUES
+    ('coffee', 'bar'),
+    ('tea', 'bar'),
+    ('drink', 'bar'),
+    ('pastry', 'pastry_case'),
+    ('sandwich', 'kitchen');
+
 CREATE TABLE menu_item_ingredients (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     menu_item_id UUID NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
@@ -45,11 +80,67 @@ CREATE TABLE menu_item_ingredients (
     UNIQUE(menu_item_id, ingredient_id)
 );
 
+-- Ingredients a customization adds to a menu item, applied when an order item's customizations set the
+-- modifier's name, e.g. {"whipped_cream": true} or {"extra_shot": 2}
+CREATE TABLE menu_item_modifiers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    menu_item_id UUID NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    ingredient_id UUID NOT NULL REFERENCES inventory(id) ON DELETE RESTRICT,
+    quantity DECIMAL(10,3) NOT NULL CHECK (quantity > 0),
+    unit unit_type NOT NULL,
+    UNIQUE(menu_item_id, name, ingredient_id)
+);
+
+-- Daily windows an item is sold in; an item without windows is sold all day
+CREATE TABLE menu_item_schedules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    menu_item_id UUID NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    weekdays TEXT[] NOT NULL DEFAULT '{}' CHECK (weekdays <@ ARRAY['sun', 'mon', 'tue', 'wed', 'thu', 'fri', 'sat']),
+    start_time TIME NOT NULL,
+    end_time TIME NOT NULL,
+    CHECK (start_time < end_time)
+);
+
+-- Components of bundle menu items; a slot with a single option and no category is fixed
+CREATE TABLE menu_bundle_slots (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    bundle_id UUID NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1 CHECK (quantity > 0),
+    category VARCHAR(100), -- Any item of this category can fill the slot
+    UNIQUE(bundle_id, name)
+);
+
+CREATE TABLE menu_bundle_slot_options (
+    slot_id UUID NOT NULL REFERENCES menu_bundle_slots(id) ON DELETE CASCADE,
+    menu_item_id UUID NOT NULL REFERENCES menu_items(id) ON DELETE RESTRICT,
+    PRIMARY KEY (slot_id, menu_item_id)
+);
+
+CREATE TABLE customers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    phone VARCHAR(50),
+    email VARCHAR(255),
+    preferences JSONB NOT NULL DEFAULT '{}',
+    allergens TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
 CREATE TABLE orders (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     customer_name VARCHAR(255) NOT NULL,
+    customer_id UUID REFERENCES customers(id) ON DELETE SET NULL, -- NULL for walk-ins
     special_instructions JSONB DEFAULT '{}',
     status order_status NOT NULL DEFAULT 'pending',
+    order_type VARCHAR(20) NOT NULL DEFAULT 'dine_in' CHECK (order_type IN ('dine_in', 'takeaway')),
+    subtotal DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (subtotal >= 0), -- Item prices after discounts
+    discount_amount DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (discount_amount >= 0),
+    tax_amount DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (tax_amount >= 0),
+    tax_inclusive BOOLEAN NOT NULL DEFAULT false, -- Whether the subtotal already contains the tax
     total_amount DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (total_amount >= 0),
     created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
@@ -61,7 +152,151 @@ CREATE TABLE order_items (
     menu_item_id UUID NOT NULL REFERENCES menu_items(id) ON DELETE RESTRICT,
     quantity INTEGER NOT NULL CHECK (quantity > 0),
     price_at_time DECIMAL(10,2) NOT NULL CHECK (price_at_time >= 0),
-    customizations JSONB DEFAULT '{}'
+    customizations JSONB DEFAULT '{}',
+    bundle_id UUID REFERENCES menu_items(id) ON DELETE RESTRICT, -- Set on components of an ordered bundle
+    bundle_line INTEGER CHECK (bundle_line > 0), -- Requested order line the bundle came from
+    discount_amount DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (discount_amount >= 0), -- Share of the order's discounts
+    -- Items are routed to a station when ordered and tracked there on the order's station ticket
+    station station NOT NULL DEFAULT 'kitchen',
+    prep_status prep_status NOT NULL DEFAULT 'queued',
+    prep_started_at TIMESTAMPTZ,
+    prep_done_at TIMESTAMPTZ
+);
+
+-- Discounts for new orders; rules with a promo code only apply when an order names it
+CREATE TABLE pricing_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    rule_type VARCHAR(20) NOT NULL CHECK (rule_type IN ('percentage', 'fixed', 'buy_x_get_y')),
+    value DECIMAL(10,2) NOT NULL CHECK (value >= 0),
+    buy_quantity INTEGER CHECK (buy_quantity > 0),
+    get_quantity INTEGER CHECK (get_quantity > 0),
+    menu_item_ids UUID[] NOT NULL DEFAULT '{}',
+    category VARCHAR(100),
+    schedule JSONB, -- Windows and dates the rule applies in, e.g. happy hour
+    promo_code VARCHAR(50) UNIQUE,
+    max_uses INTEGER CHECK (max_uses > 0),
+    uses INTEGER NOT NULL DEFAULT 0 CHECK (uses >= 0),
+    expires_at TIMESTAMPTZ,
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (rule_type != 'buy_x_get_y' OR (buy_quantity IS NOT NULL AND get_quantity IS NOT NULL)),
+    CHECK (max_uses IS NULL OR uses <= max_uses)
+);
+
+-- Points per currency unit spent, or a stamp per item towards a free one
+CREATE TABLE loyalty_rules (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    rule_type VARCHAR(20) NOT NULL CHECK (rule_type IN ('points', 'stamps')),
+    category VARCHAR(100), -- Items that count, NULL for all
+    points_per_unit DECIMAL(10,2) CHECK (points_per_unit > 0),
+    point_value DECIMAL(10,4) CHECK (point_value > 0),
+    stamps_required INTEGER CHECK (stamps_required > 1),
+    max_reward_value DECIMAL(10,2) CHECK (max_reward_value > 0),
+    active BOOLEAN NOT NULL DEFAULT true,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (rule_type != 'points' OR (points_per_unit IS NOT NULL AND point_value IS NOT NULL)),
+    CHECK (rule_type != 'stamps' OR stamps_required IS NOT NULL)
+);
+
+CREATE TABLE order_discounts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    rule_id UUID REFERENCES pricing_rules(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL, -- Kept so the discount still reads after the rule is deleted
+    promo_code VARCHAR(50),
+    amount DECIMAL(10,2) NOT NULL CHECK (amount >= 0),
+    loyalty_rule_id UUID REFERENCES loyalty_rules(id) ON DELETE RESTRICT, -- Set on loyalty redemptions
+    loyalty_points INTEGER CHECK (loyalty_points > 0) -- Points or stamps spent
+);
+
+-- Tax by menu category and order type; NULL matches any, the most specific rate applies
+CREATE TABLE tax_rates (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL,
+    rate DECIMAL(6,3) NOT NULL CHECK (rate >= 0 AND rate <= 100),
+    category VARCHAR(100),
+    order_type VARCHAR(20) CHECK (order_type IN ('dine_in', 'takeaway')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE order_taxes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    tax_rate_id UUID REFERENCES tax_rates(id) ON DELETE SET NULL,
+    name VARCHAR(255) NOT NULL,
+    rate DECIMAL(6,3) NOT NULL,
+    net_amount DECIMAL(10,2) NOT NULL,
+    tax_amount DECIMAL(10,2) NOT NULL CHECK (tax_amount >= 0)
+);
+
+-- Money taken for orders; amount counts towards the order total, the tip is on top
+CREATE TABLE payments (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE RESTRICT,
+    tender VARCHAR(20) NOT NULL CHECK (tender IN ('cash', 'card', 'voucher')),
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    tip DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (tip >= 0),
+    tendered DECIMAL(10,2) CHECK (tendered >= 0), -- Cash handed over
+    change_given DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (change_given >= 0),
+    guest VARCHAR(100),
+    reference VARCHAR(255), -- Gateway transaction or voucher code
+    refunded_amount DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (refunded_amount >= 0 AND refunded_amount <= amount),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE refunds (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    payment_id UUID NOT NULL REFERENCES payments(id) ON DELETE RESTRICT,
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE RESTRICT,
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    reason TEXT NOT NULL,
+    reference VARCHAR(255), -- Gateway refund reference
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE drawer_sessions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'closed')),
+    opened_by VARCHAR(255) NOT NULL,
+    opening_float DECIMAL(10,2) NOT NULL CHECK (opening_float >= 0),
+    opened_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    closed_by VARCHAR(255),
+    counted_cash DECIMAL(10,2) CHECK (counted_cash >= 0),
+    closed_at TIMESTAMPTZ
+);
+
+CREATE TABLE drawer_movements (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_id UUID NOT NULL REFERENCES drawer_sessions(id) ON DELETE RESTRICT,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('paid_in', 'paid_out')),
+    amount DECIMAL(10,2) NOT NULL CHECK (amount > 0),
+    reason TEXT NOT NULL,
+    created_by VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Every change to a customer's loyalty balance; the balance under a rule is the sum of its points
+CREATE TABLE loyalty_ledger (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    customer_id UUID NOT NULL REFERENCES customers(id) ON DELETE CASCADE,
+    rule_id UUID NOT NULL REFERENCES loyalty_rules(id) ON DELETE RESTRICT,
+    order_id UUID REFERENCES orders(id) ON DELETE SET NULL,
+    type VARCHAR(20) NOT NULL CHECK (type IN ('earn', 'redeem', 'reversal')),
+    points INTEGER NOT NULL CHECK (points != 0),
+    reason TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- The report is written once when its session closes; a trigger rejects any later change
+CREATE TABLE z_reports (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    session_id UUID NOT NULL UNIQUE REFERENCES drawer_sessions(id) ON DELETE RESTRICT,
+    report JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 
 CREATE TABLE order_status_history (
@@ -84,6 +319,19 @@ CREATE TABLE price_history (
     reason TEXT
 );
 
+-- Price changes planned ahead; a background job applies them once effective_at has passed
+CREATE TABLE scheduled_price_changes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    menu_item_id UUID NOT NULL REFERENCES menu_items(id) ON DELETE CASCADE,
+    new_price DECIMAL(10,2) NOT NULL CHECK (new_price >= 0),
+    effective_at TIMESTAMPTZ NOT NULL,
+    changed_by VARCHAR(255) NOT NULL DEFAULT 'system',
+    reason TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'applied', 'cancelled')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    applied_at TIMESTAMPTZ
+);
+
 CREATE TABLE inventory_transactions (
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     ingredient_id UUID NOT NULL REFERENCES inventory(id) ON DELETE CASCADE,
@@ -94,21 +342,162 @@ CREATE TABLE inventory_transactions (
     transaction_date TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
     reference_type VARCHAR(50),
     reference_id UUID,
+    unit_cost DECIMAL(12,4) NOT NULL DEFAULT 0 CHECK (unit_cost >= 0),
     notes TEXT
 );
 
+-- Stock on hand per receipt; inventory.quantity is always the sum of its lots
+CREATE TABLE inventory_lots (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ingredient_id UUID NOT NULL REFERENCES inventory(id) ON DELETE CASCADE,
+    quantity DECIMAL(10,3) NOT NULL CHECK (quantity >= 0),
+    initial_quantity DECIMAL(10,3) NOT NULL CHECK (initial_quantity >= 0),
+    unit_cost DECIMAL(12,4) NOT NULL DEFAULT 0 CHECK (unit_cost >= 0),
+    received_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMPTZ,
+    reference_type VARCHAR(50),
+    reference_id UUID
+);
+
+-- Stock promised to open orders; consumed when the order moves on, released when it is cancelled
+CREATE TABLE inventory_reservations (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    order_id UUID NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    ingredient_id UUID NOT NULL REFERENCES inventory(id) ON DELETE CASCADE,
+    quantity DECIMAL(10,3) NOT NULL CHECK (quantity > 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(order_id, ingredient_id)
+);
+
+CREATE TABLE inventory_alerts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    ingredient_id UUID NOT NULL REFERENCES inventory(id) ON DELETE CASCADE,
+    alert_type VARCHAR(50) NOT NULL DEFAULT 'low_stock',
+    status VARCHAR(20) NOT NULL DEFAULT 'open' CHECK (status IN ('open', 'resolved')),
+    quantity DECIMAL(10,3) NOT NULL,
+    min_threshold DECIMAL(10,3) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    resolved_at TIMESTAMPTZ,
+    delivery_attempts INTEGER NOT NULL DEFAULT 0,
+    delivered_at TIMESTAMPTZ, -- Once every receiver has accepted the alert
+    last_error TEXT
+);
+
+-- Webhook delivery of an alert to each receiver, so redelivery skips those that already accepted it
+CREATE TABLE alert_deliveries (
+    alert_id UUID NOT NULL REFERENCES inventory_alerts(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    delivered_at TIMESTAMPTZ,
+    last_error TEXT,
+    PRIMARY KEY (alert_id, url)
+);
+
+CREATE TABLE suppliers (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    name VARCHAR(255) NOT NULL UNIQUE,
+    contact_name VARCHAR(255),
+    email VARCHAR(255),
+    phone VARCHAR(50),
+    lead_time_days INTEGER NOT NULL DEFAULT 1 CHECK (lead_time_days >= 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Items a supplier sells; pack_size is expressed in the inventory unit of the ingredient
+CREATE TABLE supplier_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    supplier_id UUID NOT NULL REFERENCES suppliers(id) ON DELETE CASCADE,
+    ingredient_id UUID NOT NULL REFERENCES inventory(id) ON DELETE CASCADE,
+    pack_size DECIMAL(10,3) NOT NULL CHECK (pack_size > 0),
+    pack_price DECIMAL(10,2) NOT NULL CHECK (pack_price >= 0),
+    UNIQUE(supplier_id, ingredient_id)
+);
+
+CREATE TABLE purchase_orders (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    supplier_id UUID NOT NULL REFERENCES suppliers(id) ON DELETE RESTRICT,
+    status purchase_order_status NOT NULL DEFAULT 'draft',
+    total_amount DECIMAL(10,2) NOT NULL DEFAULT 0 CHECK (total_amount >= 0),
+    notes TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    sent_at TIMESTAMPTZ,
+    received_at TIMESTAMPTZ
+);
+
+CREATE TABLE purchase_order_items (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    purchase_order_id UUID NOT NULL REFERENCES purchase_orders(id) ON DELETE CASCADE,
+    ingredient_id UUID NOT NULL REFERENCES inventory(id) ON DELETE RESTRICT,
+    packs INTEGER NOT NULL CHECK (packs > 0),
+    pack_size DECIMAL(10,3) NOT NULL CHECK (pack_size > 0),
+    pack_price DECIMAL(10,2) NOT NULL CHECK (pack_price >= 0),
+    UNIQUE(purchase_order_id, ingredient_id)
+);
+
+CREATE TABLE stocktakes (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    status stocktake_status NOT NULL DEFAULT 'open',
+    notes TEXT,
+    approved_by VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    approved_at TIMESTAMPTZ,
+    cancelled_at TIMESTAMPTZ
+);
+
+-- System quantity and cost are captured when the count is submitted, so later movements don't skew the variance
+CREATE TABLE stocktake_counts (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    stocktake_id UUID NOT NULL REFERENCES stocktakes(id) ON DELETE CASCADE,
+    ingredient_id UUID NOT NULL REFERENCES inventory(id) ON DELETE CASCADE,
+    counted_quantity DECIMAL(10,3) NOT NULL CHECK (counted_quantity >= 0),
+    system_quantity DECIMAL(10,3) NOT NULL,
+    unit_cost DECIMAL(12,4) NOT NULL DEFAULT 0,
+    counted_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(stocktake_id, ingredient_id)
+);
+
 -- INDEXES
 CREATE INDEX idx_orders_customer_name ON orders(customer_name);
+CREATE INDEX idx_orders_customer_id ON orders(customer_id, created_at);
 CREATE INDEX idx_orders_status ON orders(status);
 CREATE INDEX idx_orders_created_at ON orders(created_at);
 CREATE INDEX idx_orders_updated_at ON orders(updated_at);
 
 CREATE INDEX idx_order_items_order_id ON order_items(order_id);
 CREATE INDEX idx_order_items_menu_item_id ON order_items(menu_item_id);
+CREATE INDEX idx_order_items_bundle_id ON order_items(bundle_id);
+CREATE INDEX idx_order_items_station_open ON order_items(station) WHERE prep_status != 'done';
 
 CREATE INDEX idx_menu_items_category ON menu_items(category);
 CREATE INDEX idx_menu_items_available ON menu_items(available);
 CREATE INDEX idx_menu_items_price ON menu_items(price);
+CREATE INDEX idx_menu_item_schedules_item ON menu_item_schedules(menu_item_id);
+CREATE INDEX idx_order_discounts_order ON order_discounts(order_id);
+CREATE INDEX idx_order_discounts_rule ON order_discounts(rule_id);
+CREATE UNIQUE INDEX idx_tax_rates_scope ON tax_rates(COALESCE(category, ''), COALESCE(order_type, ''));
+CREATE INDEX idx_order_taxes_order ON order_taxes(order_id);
+CREATE INDEX idx_payments_order ON payments(order_id);
+CREATE INDEX idx_payments_created_at ON payments(created_at);
+CREATE INDEX idx_refunds_payment ON refunds(payment_id);
+CREATE INDEX idx_refunds_order ON refunds(order_id);
+-- A phone number or email address identifies one customer
+CREATE UNIQUE INDEX idx_customers_phone ON customers(phone) WHERE phone IS NOT NULL;
+CREATE UNIQUE INDEX idx_customers_email ON customers(LOWER(email)) WHERE email IS NOT NULL;
+CREATE INDEX idx_customers_name_trgm ON customers USING gin(name gin_trgm_ops);
+
+-- Only one drawer session can be open at a time
+CREATE UNIQUE INDEX idx_drawer_sessions_open ON drawer_sessions(status) WHERE status = 'open';
+CREATE INDEX idx_drawer_sessions_opened_at ON drawer_sessions(opened_at);
+CREATE INDEX idx_drawer_movements_session ON drawer_movements(session_id);
+CREATE INDEX idx_refunds_created_at ON refunds(created_at);
+-- An order earns once per rule, so accrual can be retried safely
+CREATE UNIQUE INDEX idx_loyalty_ledger_earn ON loyalty_ledger(order_id, rule_id) WHERE type = 'earn';
+CREATE INDEX idx_loyalty_ledger_customer ON loyalty_ledger(customer_id, rule_id, created_at);
+CREATE INDEX idx_loyalty_ledger_order ON loyalty_ledger(order_id);
+CREATE INDEX idx_scheduled_price_changes_due ON scheduled_price_changes(status, effective_at);
 
 CREATE INDEX idx_inventory_name ON inventory(name);
 CREATE INDEX idx_inventory_quantity ON inventory(quantity);
@@ -121,9 +510,28 @@ CREATE INDEX idx_orders_customer_trgm ON orders USING gin(customer_name gin_trgm
 CREATE INDEX idx_order_status_history_order_changed ON order_status_history(order_id, changed_at);
 CREATE INDEX idx_price_history_item_changed ON price_history(menu_item_id, changed_at);
 CREATE INDEX idx_inventory_transactions_ingredient_date ON inventory_transactions(ingredient_id, transaction_date);
+CREATE INDEX idx_inventory_transactions_reference ON inventory_transactions(reference_type, reference_id);
+
+CREATE INDEX idx_inventory_lots_ingredient_received ON inventory_lots(ingredient_id, received_at) WHERE quantity > 0;
+CREATE INDEX idx_inventory_lots_expires ON inventory_lots(expires_at) WHERE quantity > 0;
+CREATE INDEX idx_inventory_reservations_ingredient ON inventory_reservations(ingredient_id);
+
+-- At most one open alert per ingredient and type, until it is restocked
+CREATE UNIQUE INDEX idx_inventory_alerts_open ON inventory_alerts(ingredient_id, alert_type) WHERE status = 'open';
+CREATE INDEX idx_inventory_alerts_status_created ON inventory_alerts(status, created_at);
+
+CREATE INDEX idx_supplier_items_ingredient ON supplier_items(ingredient_id);
+CREATE INDEX idx_purchase_orders_status ON purchase_orders(status);
+CREATE INDEX idx_purchase_orders_supplier ON purchase_orders(supplier_id);
+CREATE INDEX idx_purchase_order_items_order ON purchase_order_items(purchase_order_id);
+
+-- Only one stocktake can be counted at a time
+CREATE UNIQUE INDEX idx_stocktakes_open ON stocktakes(status) WHERE status = 'open';
+CREATE INDEX idx_stocktakes_approved_at ON stocktakes(approved_at) WHERE status = 'approved';
+CREATE INDEX idx_stocktake_counts_ingredient ON stocktake_counts(ingredient_id);
 
 CREATE INDEX idx_menu_items_tags ON menu_items USING gin(tags);
-CREATE INDEX idx_menu_items_allergens ON menu_items USING gin(allergens);
+CREATE INDEX idx_inventory_allergens ON inventory USING gin(allergens);
 CREATE INDEX idx_menu_items_metadata ON menu_items USING gin(metadata);
 CREATE INDEX idx_orders_special_instructions ON orders USING gin(special_instructions);
 CREATE INDEX idx_order_items_customizations ON order_items USING gin(customizations);
@@ -145,6 +553,35 @@ CREATE TRIGGER update_orders_updated_at BEFORE UPDATE ON orders
 CREATE TRIGGER update_menu_items_updated_at BEFORE UPDATE ON menu_items
     FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
 
+CREATE TRIGGER update_suppliers_updated_at BEFORE UPDATE ON suppliers
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_purchase_orders_updated_at BEFORE UPDATE ON purchase_orders
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_stocktakes_updated_at BEFORE UPDATE ON stocktakes
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_tax_rates_updated_at BEFORE UPDATE ON tax_rates
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_customers_updated_at BEFORE UPDATE ON customers
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+CREATE TRIGGER update_loyalty_rules_updated_at BEFORE UPDATE ON loyalty_rules
+    FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- Z-reports are immutable once written
+CREATE OR REPLACE FUNCTION prevent_z_report_change()
+RETURNS TRIGGER AS $$
+BEGIN
+    RAISE EXCEPTION 'z-report % cannot be changed', OLD.id;
+END;
+$$ language 'plpgsql';
+
+CREATE TRIGGER protect_z_reports BEFORE UPDATE OR DELETE ON z_reports
+    FOR EACH ROW EXECUTE FUNCTION prevent_z_report_change();
+
 -- Function to track order status changes
 CREATE OR REPLACE FUNCTION track_order_status_change()
 RETURNS TRIGGER AS $$
@@ -166,8 +603,11 @@ CREATE OR REPLACE FUNCTION track_price_change()
 RETURNS TRIGGER AS $$
 BEGIN
     IF OLD.price != NEW.price THEN
-        INSERT INTO price_history (menu_item_id, old_price, new_price, reason)
-        VALUES (NEW.id, OLD.price, NEW.price, 'Price updated');
+        -- The application names the actor and reason for the current transaction with set_config
+        INSERT INTO price_history (menu_item_id, old_price, new_price, changed_by, reason)
+        VALUES (NEW.id, OLD.price, NEW.price,
+                COALESCE(NULLIF(current_setting('app.price_changed_by', true), ''), 'system'),
+                COALESCE(NULLIF(current_setting('app.price_change_reason', true), ''), 'Price updated'));
     END IF;
     RETURN NEW;
 END;
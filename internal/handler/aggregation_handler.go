@@ -49,6 +49,26 @@ func (h *AggregationHandler) GetTotalSales(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if wantsCSV(r) {
+		rows := make([][]string, 0, len(report.ItemSales))
+		for _, sale := range report.ItemSales {
+			rows = append(rows, []string{
+				sale.ProductID,
+				sale.ProductName,
+				strconv.Itoa(sale.QuantitySold),
+				formatMoney(sale.Discount),
+				formatMoney(sale.TotalValue),
+			})
+		}
+		header := []string{"product_id", "product_name", "quantity_sold", "discount", "total_value"}
+		if err := writeCSVResponse(w, exportFilename("total-sales", time.Time{}, time.Now()), header, rows); err != nil {
+			h.logger.Error("Failed to write total sales CSV", "error", err)
+		}
+		reqCtx.StatusCode = http.StatusOK
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
 	writeJSONResponse(w, http.StatusOK, report)
 	reqCtx.StatusCode = http.StatusOK
 	h.logger.LogResponse(reqCtx)
@@ -73,6 +93,27 @@ func (h *AggregationHandler) GetPopularItems(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	if wantsCSV(r) {
+		rows := make([][]string, 0, len(report))
+		for i, item := range report {
+			rows = append(rows, []string{
+				strconv.Itoa(i + 1),
+				item.ID,
+				item.Name,
+				string(item.Category),
+				formatMoney(item.Price),
+				strconv.Itoa(item.SalesCount),
+			})
+		}
+		header := []string{"rank", "product_id", "name", "category", "price", "sales_count"}
+		if err := writeCSVResponse(w, exportFilename("popular-items", time.Time{}, time.Now()), header, rows); err != nil {
+			h.logger.Error("Failed to write popular items CSV", "error", err)
+		}
+		reqCtx.StatusCode = http.StatusOK
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
 	writeJSONResponse(w, http.StatusOK, report)
 	reqCtx.StatusCode = http.StatusOK
 	h.logger.LogResponse(reqCtx)
@@ -188,7 +229,323 @@ func (h *AggregationHandler) GetOrderedItemsByPeriod(w http.ResponseWriter, r *h
 		return
 	}
 
+	if wantsCSV(r) {
+		rows := make([][]string, 0, len(result.OrderedItems))
+		for _, entry := range result.OrderedItems {
+			for key, count := range entry {
+				rows = append(rows, []string{key, strconv.Itoa(count)})
+			}
+		}
+		from, to := periodDateRange(period, month, year)
+		header := []string{result.Period, "order_count"}
+		if err := writeCSVResponse(w, exportFilename("ordered-items", from, to), header, rows); err != nil {
+			h.logger.Error("Failed to write ordered items CSV", "error", err)
+		}
+		reqCtx.StatusCode = http.StatusOK
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
 	writeJSONResponse(w, http.StatusOK, result)
 	reqCtx.StatusCode = http.StatusOK
 	h.logger.LogResponse(reqCtx)
 }
+
+// GetMarginReport handles GET /api/v1/reports/margins
+func (h *AggregationHandler) GetMarginReport(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	target := service.DefaultTargetMarginPercent
+	if targetStr := r.URL.Query().Get("target"); targetStr != "" {
+		parsed, err := strconv.ParseFloat(targetStr, 64)
+		if err != nil {
+			h.logger.Warn("Invalid target parameter", "value", targetStr, "error", err)
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid target parameter")
+			reqCtx.StatusCode = http.StatusBadRequest
+			h.logger.LogResponse(reqCtx)
+			return
+		}
+		target = parsed
+	}
+
+	report, err := h.aggregationService.GetMarginReport(target)
+	if err != nil {
+		h.logger.Error("Failed to get margin report", "error", err)
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "target margin") {
+			statusCode = http.StatusBadRequest
+		}
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := make([][]string, 0, len(report.Items))
+		for _, item := range report.Items {
+			rows = append(rows, []string{
+				item.ProductID,
+				item.ProductName,
+				string(item.Category),
+				formatMoney(item.Price),
+				formatMoney(item.IngredientCost),
+				formatMoney(item.GrossMargin),
+				formatMoney(item.MarginPercent),
+				strconv.FormatBool(item.BelowTarget),
+			})
+		}
+		header := []string{"product_id", "product_name", "category", "price", "ingredient_cost", "gross_margin", "margin_percent", "below_target"}
+		if err := writeCSVResponse(w, exportFilename("margins", time.Now(), time.Now()), header, rows); err != nil {
+			h.logger.Error("Failed to write margin report CSV", "error", err)
+		}
+		reqCtx.StatusCode = http.StatusOK
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, report)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetProfitReport handles GET /api/v1/reports/profit?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *AggregationHandler) GetProfitReport(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	from, err := parseReportDate(r.URL.Query().Get("from"))
+	if err != nil {
+		h.logger.Warn("Invalid from parameter", "value", r.URL.Query().Get("from"), "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid from parameter (expected YYYY-MM-DD)")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	to, err := parseReportDate(r.URL.Query().Get("to"))
+	if err != nil {
+		h.logger.Warn("Invalid to parameter", "value", r.URL.Query().Get("to"), "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid to parameter (expected YYYY-MM-DD)")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	// 'to' is inclusive, the service expects an exclusive upper bound
+	var toExclusive *time.Time
+	if to != nil {
+		next := to.AddDate(0, 0, 1)
+		toExclusive = &next
+	}
+
+	report, err := h.aggregationService.GetProfitReport(from, toExclusive)
+	if err != nil {
+		h.logger.Error("Failed to get profit report", "error", err)
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "invalid date range") {
+			statusCode = http.StatusBadRequest
+		}
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := make([][]string, 0, len(report.Categories)+len(report.Items))
+		for _, category := range report.Categories {
+			rows = append(rows, profitCSVRow("category", category))
+		}
+		for _, item := range report.Items {
+			rows = append(rows, profitCSVRow("item", item))
+		}
+
+		fileFrom, fileTo := time.Time{}, time.Now()
+		if from != nil {
+			fileFrom = *from
+		}
+		if to != nil {
+			fileTo = *to
+		}
+
+		header := []string{"level", "product_id", "name", "category", "quantity_sold", "revenue", "cogs", "gross_profit", "margin_percent"}
+		if err := writeCSVResponse(w, exportFilename("profit", fileFrom, fileTo), header, rows); err != nil {
+			h.logger.Error("Failed to write profit report CSV", "error", err)
+		}
+		reqCtx.StatusCode = http.StatusOK
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, report)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+func profitCSVRow(level string, line service.ProfitLine) []string {
+	return []string{
+		level,
+		line.ProductID,
+		line.Name,
+		line.Category,
+		strconv.Itoa(line.QuantitySold),
+		formatMoney(line.Revenue),
+		formatMoney(line.COGS),
+		formatMoney(line.GrossProfit),
+		formatMoney(line.MarginPercent),
+	}
+}
+
+func (h *AggregationHandler) GetServiceTimesReport(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	from, err := parseReportDate(r.URL.Query().Get("from"))
+	if err != nil {
+		h.logger.Warn("Invalid from parameter", "value", r.URL.Query().Get("from"), "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid from parameter (expected YYYY-MM-DD)")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	to, err := parseReportDate(r.URL.Query().Get("to"))
+	if err != nil {
+		h.logger.Warn("Invalid to parameter", "value", r.URL.Query().Get("to"), "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid to parameter (expected YYYY-MM-DD)")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	// 'to' is inclusive, the service expects an exclusive upper bound
+	var toExclusive *time.Time
+	if to != nil {
+		next := to.AddDate(0, 0, 1)
+		toExclusive = &next
+	}
+
+	report, err := h.aggregationService.GetServiceTimesReport(from, toExclusive)
+	if err != nil {
+		h.logger.Error("Failed to get service times report", "error", err)
+		statusCode := http.StatusInternalServerError
+		if strings.Contains(err.Error(), "invalid date range") {
+			statusCode = http.StatusBadRequest
+		}
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := serviceTimesCSVRows("overall", "all", report.Overall)
+		for _, group := range report.ByHour {
+			rows = append(rows, serviceTimesCSVRows("hour", group.Key, group.ServiceTimes)...)
+		}
+		for _, group := range report.ByWeekday {
+			rows = append(rows, serviceTimesCSVRows("weekday", group.Key, group.ServiceTimes)...)
+		}
+		for _, group := range report.ByItemMix {
+			rows = append(rows, serviceTimesCSVRows("item_mix", group.Key, group.ServiceTimes)...)
+		}
+
+		fileFrom, fileTo := time.Time{}, time.Now()
+		if from != nil {
+			fileFrom = *from
+		}
+		if to != nil {
+			fileTo = *to
+		}
+
+		header := []string{"dimension", "key", "stage", "orders", "avg_seconds", "p50_seconds", "p90_seconds"}
+		if err := writeCSVResponse(w, exportFilename("service-times", fileFrom, fileTo), header, rows); err != nil {
+			h.logger.Error("Failed to write service times report CSV", "error", err)
+		}
+		reqCtx.StatusCode = http.StatusOK
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, report)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// serviceTimesCSVRows flattens a group's stage times into one row per stage
+func serviceTimesCSVRows(dimension, key string, times service.ServiceTimes) [][]string {
+	stages := []struct {
+		name string
+		time service.StageTime
+	}{
+		{"pending_to_preparing", times.PendingToPreparing},
+		{"preparing_to_ready", times.PreparingToReady},
+		{"ready_to_closed", times.ReadyToClosed},
+		{"pending_to_ready", times.PendingToReady},
+		{"pending_to_closed", times.PendingToClosed},
+	}
+
+	rows := make([][]string, 0, len(stages))
+	for _, stage := range stages {
+		rows = append(rows, []string{
+			dimension,
+			key,
+			stage.name,
+			strconv.Itoa(stage.time.Orders),
+			strconv.FormatFloat(stage.time.AvgSeconds, 'f', 0, 64),
+			strconv.FormatFloat(stage.time.P50Seconds, 'f', 0, 64),
+			strconv.FormatFloat(stage.time.P90Seconds, 'f', 0, 64),
+		})
+	}
+	return rows
+}
+
+// parseReportDate parses an optional YYYY-MM-DD query value; empty means unbounded
+func parseReportDate(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	return &date, nil
+}
+
+// periodDateRange resolves the calendar range covered by an orderedItemsByPeriod request
+func periodDateRange(period, month, year string) (time.Time, time.Time) {
+	now := time.Now()
+
+	if period == "day" {
+		parsedMonth, err := time.Parse("January", month)
+		if err != nil {
+			return time.Time{}, now
+		}
+		from := time.Date(now.Year(), parsedMonth.Month(), 1, 0, 0, 0, 0, now.Location())
+		return from, from.AddDate(0, 1, -1)
+	}
+
+	yearNum := now.Year()
+	if parsed, err := strconv.Atoi(year); err == nil {
+		yearNum = parsed
+	}
+	from := time.Date(yearNum, time.January, 1, 0, 0, 0, 0, now.Location())
+	return from, from.AddDate(1, 0, -1)
+}
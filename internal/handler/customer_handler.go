@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"frappuccino/internal/service"
+	"frappuccino/pkg/logger"
+)
+
+type CustomerHandler struct {
+	customerService service.CustomerServiceInterface
+	logger          *logger.Logger
+}
+
+func NewCustomerHandler(customerService service.CustomerServiceInterface, logger *logger.Logger) *CustomerHandler {
+	return &CustomerHandler{
+		customerService: customerService,
+		logger:          logger.WithComponent("customer_handler"),
+	}
+}
+
+// GetCustomers handles GET /api/v1/customers?q=
+func (h *CustomerHandler) GetCustomers(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	customers, err := h.customerService.GetCustomers(r.URL.Query().Get("q"))
+	if err != nil {
+		h.logger.Error("Failed to get customers", "error", err)
+		statusCode := customerErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, customers)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetCustomer handles GET /api/v1/customers/{id}
+func (h *CustomerHandler) GetCustomer(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := customerIDFromPath(r)
+	customer, err := h.customerService.GetCustomer(id)
+	if err != nil {
+		h.logger.Warn("Failed to get customer", "id", id, "error", err)
+		statusCode := customerErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, customer)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetCustomerOrders handles GET /api/v1/customers/{id}/orders
+func (h *CustomerHandler) GetCustomerOrders(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := customerIDFromPath(r)
+	orders, err := h.customerService.GetCustomerOrders(id)
+	if err != nil {
+		h.logger.Warn("Failed to get customer orders", "id", id, "error", err)
+		statusCode := customerErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, orders)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// CreateCustomer handles POST /api/v1/customers
+func (h *CustomerHandler) CreateCustomer(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	var req service.CustomerRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for create customer", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	customer, err := h.customerService.CreateCustomer(req)
+	if err != nil {
+		h.logger.Warn("Failed to create customer", "error", err)
+		statusCode := customerErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, customer)
+	reqCtx.StatusCode = http.StatusCreated
+	h.logger.LogResponse(reqCtx)
+}
+
+// UpdateCustomer handles PUT /api/v1/customers/{id}
+func (h *CustomerHandler) UpdateCustomer(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := customerIDFromPath(r)
+
+	var req service.CustomerRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for update customer", "id", id, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	customer, err := h.customerService.UpdateCustomer(id, req)
+	if err != nil {
+		h.logger.Warn("Failed to update customer", "id", id, "error", err)
+		statusCode := customerErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, customer)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// DeleteCustomer handles DELETE /api/v1/customers/{id}
+func (h *CustomerHandler) DeleteCustomer(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := customerIDFromPath(r)
+	if err := h.customerService.DeleteCustomer(id); err != nil {
+		h.logger.Warn("Failed to delete customer", "id", id, "error", err)
+		statusCode := customerErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusNoContent, nil)
+	reqCtx.StatusCode = http.StatusNoContent
+	h.logger.LogResponse(reqCtx)
+}
+
+// MergeCustomers handles POST /api/v1/customers/{id}/merge
+func (h *CustomerHandler) MergeCustomers(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := customerIDFromPath(r)
+
+	var req service.MergeCustomersRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for merge customers", "id", id, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	customer, err := h.customerService.MergeCustomers(id, req)
+	if err != nil {
+		h.logger.Warn("Failed to merge customers", "id", id, "source_id", req.SourceID, "error", err)
+		statusCode := customerErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, customer)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// customerIDFromPath extracts the ID from /api/v1/customers/{id}[/orders|loyalty|merge]
+func customerIDFromPath(r *http.Request) string {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/v1/customers/"))
+	if len(parts) > 0 {
+		return parts[0]
+	}
+	return ""
+}
+
+// customerErrorStatus maps customer service errors to HTTP status codes
+func customerErrorStatus(err error) int {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "with id") && strings.Contains(message, "not found"):
+		return http.StatusNotFound
+	case strings.Contains(message, "already exists"):
+		return http.StatusConflict
+	case strings.Contains(message, "failed to"):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
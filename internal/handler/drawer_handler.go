@@ -0,0 +1,194 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"frappuccino/internal/service"
+	"frappuccino/pkg/logger"
+)
+
+type DrawerHandler struct {
+	drawerService service.DrawerServiceInterface
+	logger        *logger.Logger
+}
+
+func NewDrawerHandler(drawerService service.DrawerServiceInterface, logger *logger.Logger) *DrawerHandler {
+	return &DrawerHandler{
+		drawerService: drawerService,
+		logger:        logger.WithComponent("drawer_handler"),
+	}
+}
+
+// GetDrawerSessions handles GET /api/v1/drawer-sessions?status=
+func (h *DrawerHandler) GetDrawerSessions(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	sessions, err := h.drawerService.GetDrawerSessions(r.URL.Query().Get("status"))
+	if err != nil {
+		h.logger.Warn("Failed to get drawer sessions", "error", err)
+		statusCode := drawerErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, sessions)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetDrawerSession handles GET /api/v1/drawer-sessions/{id} and GET /api/v1/drawer-sessions/{id}/z-report
+func (h *DrawerHandler) GetDrawerSession(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := drawerSessionIDFromPath(r)
+
+	var result interface{}
+	var err error
+	if strings.HasSuffix(r.URL.Path, "/z-report") {
+		result, err = h.drawerService.GetZReport(id)
+	} else {
+		result, err = h.drawerService.GetDrawerSession(id)
+	}
+	if err != nil {
+		h.logger.Warn("Failed to get drawer session", "id", id, "path", r.URL.Path, "error", err)
+		statusCode := drawerErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, result)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// OpenDrawerSession handles POST /api/v1/drawer-sessions
+func (h *DrawerHandler) OpenDrawerSession(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	var req service.OpenDrawerRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for open drawer session", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	session, err := h.drawerService.OpenDrawerSession(req)
+	if err != nil {
+		h.logger.Warn("Failed to open drawer session", "error", err)
+		statusCode := drawerErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, session)
+	reqCtx.StatusCode = http.StatusCreated
+	h.logger.LogResponse(reqCtx)
+}
+
+// DrawerSessionAction handles POST /api/v1/drawer-sessions/{id}/movements and /close
+func (h *DrawerHandler) DrawerSessionAction(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := drawerSessionIDFromPath(r)
+
+	var result interface{}
+	var err error
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/movements"):
+		var req service.DrawerMovementRequest
+		if err := parseRequestBody(r, &req); err != nil {
+			h.logger.Warn("Invalid request body for drawer movement", "id", id, "error", err)
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			reqCtx.StatusCode = http.StatusBadRequest
+			h.logger.LogResponse(reqCtx)
+			return
+		}
+		result, err = h.drawerService.AddDrawerMovement(id, req)
+	case strings.HasSuffix(r.URL.Path, "/close"):
+		var req service.CloseDrawerRequest
+		if err := parseRequestBody(r, &req); err != nil {
+			h.logger.Warn("Invalid request body for close drawer session", "id", id, "error", err)
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			reqCtx.StatusCode = http.StatusBadRequest
+			h.logger.LogResponse(reqCtx)
+			return
+		}
+		result, err = h.drawerService.CloseDrawerSession(id, req)
+	default:
+		writeErrorResponse(w, http.StatusNotFound, "Unknown drawer session action")
+		reqCtx.StatusCode = http.StatusNotFound
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	if err != nil {
+		h.logger.Warn("Drawer session action failed", "id", id, "path", r.URL.Path, "error", err)
+		statusCode := drawerErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, result)
+	reqCtx.StatusCode = http.StatusCreated
+	h.logger.LogResponse(reqCtx)
+}
+
+// drawerSessionIDFromPath extracts the ID from /api/v1/drawer-sessions/{id}[/action]
+func drawerSessionIDFromPath(r *http.Request) string {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/v1/drawer-sessions/"))
+	if len(parts) > 0 {
+		return parts[0]
+	}
+	return ""
+}
+
+// drawerErrorStatus maps drawer service errors to HTTP status codes
+func drawerErrorStatus(err error) int {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "with id") && strings.Contains(message, "not found"):
+		return http.StatusNotFound
+	case strings.Contains(message, "drawer session is"):
+		return http.StatusConflict
+	case strings.Contains(message, "failed to"):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// exportFormatCSV is the ?format= value that selects CSV output
+	exportFormatCSV = "csv"
+	// csvContentType is the media type used for CSV responses and Accept negotiation
+	csvContentType = "text/csv"
+	// exportDateLayout is the date format used inside export filenames
+	exportDateLayout = "2006-01-02"
+	// csvFlushEvery controls how many rows are buffered before flushing to the client
+	csvFlushEvery = 100
+)
+
+// wantsCSV reports whether the client asked for CSV via ?format=csv or an Accept: text/csv header.
+// An explicit ?format= always wins over the Accept header.
+func wantsCSV(r *http.Request) bool {
+	if format := r.URL.Query().Get("format"); format != "" {
+		return strings.EqualFold(format, exportFormatCSV)
+	}
+
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType := strings.TrimSpace(strings.Split(accept, ";")[0])
+		if strings.EqualFold(mediaType, csvContentType) {
+			return true
+		}
+	}
+	return false
+}
+
+// exportFilename builds "<report>_<from>_<to>.csv"; a zero from is rendered as "all"
+func exportFilename(report string, from, to time.Time) string {
+	fromPart := "all"
+	if !from.IsZero() {
+		fromPart = from.Format(exportDateLayout)
+	}
+	return fmt.Sprintf("%s_%s_%s.csv", report, fromPart, to.Format(exportDateLayout))
+}
+
+// csvStream writes CSV rows through a csv.Writer straight to the response, flushing periodically
+type csvStream struct {
+	writer  *csv.Writer
+	flusher http.Flusher
+	pending int
+}
+
+// newCSVStream sets CSV headers, writes the header row and returns a stream for the data rows
+func newCSVStream(w http.ResponseWriter, filename string, header []string) (*csvStream, error) {
+	w.Header().Set("Content-Type", csvContentType+"; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	w.WriteHeader(http.StatusOK)
+
+	stream := &csvStream{writer: csv.NewWriter(w)}
+	if flusher, ok := w.(http.Flusher); ok {
+		stream.flusher = flusher
+	}
+
+	if err := stream.Write(header); err != nil {
+		return nil, err
+	}
+	return stream, nil
+}
+
+// Write appends a single record, flushing to the client every csvFlushEvery rows
+func (s *csvStream) Write(record []string) error {
+	if err := s.writer.Write(record); err != nil {
+		return fmt.Errorf("failed to write csv record: %v", err)
+	}
+	s.pending++
+	if s.pending >= csvFlushEvery {
+		return s.Flush()
+	}
+	return nil
+}
+
+// Flush pushes buffered rows to the client
+func (s *csvStream) Flush() error {
+	s.writer.Flush()
+	s.pending = 0
+	if err := s.writer.Error(); err != nil {
+		return fmt.Errorf("failed to flush csv output: %v", err)
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+	return nil
+}
+
+// writeCSVResponse writes a table already built in memory as a CSV attachment. Reports small enough to
+// build whole use it; exports that page through their data, like leftovers, write to a csvStream as they go.
+func writeCSVResponse(w http.ResponseWriter, filename string, header []string, rows [][]string) error {
+	stream, err := newCSVStream(w, filename, header)
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		if err := stream.Write(row); err != nil {
+			return err
+		}
+	}
+	return stream.Flush()
+}
+
+// formatMoney renders monetary values with two decimals
+func formatMoney(value float64) string {
+	return strconv.FormatFloat(value, 'f', 2, 64)
+}
+
+// formatUnitCost renders per-unit costs with the schema's four decimals, as per g or ml costs are fractions of a cent
+func formatUnitCost(value float64) string {
+	return strconv.FormatFloat(value, 'f', 4, 64)
+}
+
+// formatQuantity renders inventory quantities with the schema's three decimals
+func formatQuantity(value float64) string {
+	return strconv.FormatFloat(value, 'f', 3, 64)
+}
@@ -217,6 +217,20 @@ func (h *InventoryHandler) GetLeftOvers(w http.ResponseWriter, r *http.Request)
 		PageSize: pageSize,
 	}
 
+	// CSV exports stream every page unless the client asked for a specific one
+	if wantsCSV(r) && pageStr == "" {
+		if err := h.streamLeftOversCSV(w, req); err != nil {
+			h.logger.Error("Failed to export inventory leftovers", "error", err)
+			writeErrorResponse(w, http.StatusInternalServerError, "Failed to export inventory leftovers")
+			reqCtx.StatusCode = http.StatusInternalServerError
+			h.logger.LogResponse(reqCtx)
+			return
+		}
+		reqCtx.StatusCode = http.StatusOK
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
 	response, err := h.inventoryService.GetLeftOvers(req)
 	if err != nil {
 		h.logger.Error("Failed to get inventory leftovers", "error", err)
@@ -226,11 +240,143 @@ func (h *InventoryHandler) GetLeftOvers(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if wantsCSV(r) {
+		stream, err := newCSVStream(w, exportFilename("leftovers", time.Now(), time.Now()), leftOversCSVHeader)
+		if err == nil {
+			err = writeLeftOversRows(stream, response.Data)
+		}
+		if err != nil {
+			h.logger.Error("Failed to write inventory leftovers CSV", "error", err)
+		}
+		reqCtx.StatusCode = http.StatusOK
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
 	writeJSONResponse(w, http.StatusOK, response)
 	reqCtx.StatusCode = http.StatusOK
 	h.logger.LogResponse(reqCtx)
 }
 
+// GetInventoryValuation handles GET /api/v1/reports/inventory-valuation
+func (h *InventoryHandler) GetInventoryValuation(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	valuation, err := h.inventoryService.GetInventoryValuation()
+	if err != nil {
+		h.logger.Error("Failed to get inventory valuation", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get inventory valuation")
+		reqCtx.StatusCode = http.StatusInternalServerError
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, valuation)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetLowStockAlerts handles GET /api/v1/inventory/alerts
+func (h *InventoryHandler) GetLowStockAlerts(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	alerts, err := h.inventoryService.GetLowStockAlerts()
+	if err != nil {
+		h.logger.Error("Failed to get inventory alerts", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get inventory alerts")
+		reqCtx.StatusCode = http.StatusInternalServerError
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, alerts)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// leftOversCSVHeader is the stable column order for leftovers exports
+var leftOversCSVHeader = []string{"ingredient_id", "name", "quantity", "unit", "price", "stock_value", "daily_usage", "days_of_cover", "min_threshold", "below_threshold"}
+
+// streamLeftOversCSV walks every leftovers page and streams it as CSV.
+// The first page is fetched before any headers are written so failures can still return JSON.
+func (h *InventoryHandler) streamLeftOversCSV(w http.ResponseWriter, req service.GetLeftOversRequest) error {
+	req.Page = 1
+	req.PageSize = 500
+
+	response, err := h.inventoryService.GetLeftOvers(req)
+	if err != nil {
+		return err
+	}
+
+	stream, err := newCSVStream(w, exportFilename("leftovers", time.Now(), time.Now()), leftOversCSVHeader)
+	if err != nil {
+		// Headers are already sent, nothing more can be reported to the client
+		h.logger.Error("Failed to start inventory leftovers CSV", "error", err)
+		return nil
+	}
+
+	for {
+		if err := writeLeftOversRows(stream, response.Data); err != nil {
+			h.logger.Error("Failed to write inventory leftovers CSV", "error", err)
+			return nil
+		}
+		if !response.HasNextPage {
+			break
+		}
+
+		req.Page++
+		response, err = h.inventoryService.GetLeftOvers(req)
+		if err != nil {
+			// Headers are already sent, so the export is simply truncated
+			h.logger.Error("Failed to fetch next leftovers page for export", "page", req.Page, "error", err)
+			return nil
+		}
+	}
+
+	if err := stream.Flush(); err != nil {
+		h.logger.Error("Failed to flush inventory leftovers CSV", "error", err)
+	}
+	return nil
+}
+
+// writeLeftOversRows appends leftover items to a CSV stream
+func writeLeftOversRows(stream *csvStream, items []service.LeftOverItem) error {
+	for _, item := range items {
+		daysOfCover := ""
+		if item.DaysOfCover != nil {
+			daysOfCover = strconv.FormatFloat(*item.DaysOfCover, 'f', 1, 64)
+		}
+		record := []string{
+			item.IngredientID,
+			item.Name,
+			formatQuantity(item.Quantity),
+			item.Unit,
+			formatUnitCost(item.Price),
+			formatMoney(item.StockValue),
+			formatQuantity(item.DailyUsage),
+			daysOfCover,
+			formatQuantity(item.MinThreshold),
+			strconv.FormatBool(item.BelowThreshold),
+		}
+		if err := stream.Write(record); err != nil {
+			return err
+		}
+	}
+	return stream.Flush()
+}
+
 // Private helper methods
 
 // writeJSONResponse - writes JSON response with given status code and data
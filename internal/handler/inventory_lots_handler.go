@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"frappuccino/internal/service"
+	"frappuccino/pkg/logger"
+)
+
+// GetLots handles GET /api/v1/inventory/{id}/lots
+func (h *InventoryHandler) GetLots(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := inventoryIDFromPath(r)
+	lots, err := h.inventoryService.GetLots(id)
+	if err != nil {
+		h.logger.Warn("Failed to get inventory lots", "id", id, "error", err)
+		statusCode := lotErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, lots)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// ReceiveLot handles POST /api/v1/inventory/{id}/lots
+func (h *InventoryHandler) ReceiveLot(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := inventoryIDFromPath(r)
+
+	var req service.ReceiveLotRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for receive lot", "id", id, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	lot, err := h.inventoryService.ReceiveLot(id, req)
+	if err != nil {
+		h.logger.Warn("Failed to receive inventory lot", "id", id, "error", err)
+		statusCode := lotErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, lot)
+	reqCtx.StatusCode = http.StatusCreated
+	h.logger.LogResponse(reqCtx)
+}
+
+// WriteOffExpiredLots handles POST /api/v1/inventory/lots/write-off-expired
+func (h *InventoryHandler) WriteOffExpiredLots(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	result, err := h.inventoryService.WriteOffExpiredLots()
+	if err != nil {
+		h.logger.Error("Failed to write off expired lots", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to write off expired lots")
+		reqCtx.StatusCode = http.StatusInternalServerError
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, result)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetExpiringLots handles GET /api/v1/reports/expiring?days=
+func (h *InventoryHandler) GetExpiringLots(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	days := service.DefaultExpiringWithinDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			h.logger.Warn("Invalid days parameter", "value", value)
+			writeErrorResponse(w, http.StatusBadRequest, "days must be a non-negative integer")
+			reqCtx.StatusCode = http.StatusBadRequest
+			h.logger.LogResponse(reqCtx)
+			return
+		}
+		days = parsed
+	}
+
+	report, err := h.inventoryService.GetExpiringLots(days)
+	if err != nil {
+		h.logger.Error("Failed to get expiring lots", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get expiring lots")
+		reqCtx.StatusCode = http.StatusInternalServerError
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := make([][]string, 0, len(report.Lots))
+		for _, lot := range report.Lots {
+			rows = append(rows, []string{
+				lot.ID,
+				lot.IngredientID,
+				lot.Name,
+				formatQuantity(lot.Quantity),
+				lot.Unit,
+				formatUnitCost(lot.UnitCost),
+				formatMoney(lot.Value),
+				lot.ExpiresAt.Format(time.RFC3339),
+				strconv.FormatBool(lot.Expired),
+			})
+		}
+		header := []string{"lot_id", "ingredient_id", "name", "quantity", "unit", "unit_cost", "value", "expires_at", "expired"}
+		if err := writeCSVResponse(w, exportFilename("expiring", report.GeneratedAt, report.GeneratedAt.AddDate(0, 0, days)), header, rows); err != nil {
+			h.logger.Error("Failed to write expiring lots CSV", "error", err)
+		}
+		reqCtx.StatusCode = http.StatusOK
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, report)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// inventoryIDFromPath extracts the ID from /api/v1/inventory/{id}[/lots]
+func inventoryIDFromPath(r *http.Request) string {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/v1/inventory/"))
+	if len(parts) > 0 {
+		return parts[0]
+	}
+	return ""
+}
+
+// lotErrorStatus maps lot service errors to HTTP status codes
+func lotErrorStatus(err error) int {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "with id") && strings.Contains(message, "not found"):
+		return http.StatusNotFound
+	case strings.Contains(message, "failed to"):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
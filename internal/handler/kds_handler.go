@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"frappuccino/internal/service"
+	"frappuccino/models"
+	"frappuccino/pkg/logger"
+)
+
+// kdsKeepAliveInterval keeps idle event streams open through proxies
+const kdsKeepAliveInterval = 15 * time.Second
+
+type KDSHandler struct {
+	kdsService service.KDSServiceInterface
+	logger     *logger.Logger
+}
+
+func NewKDSHandler(kdsService service.KDSServiceInterface, logger *logger.Logger) *KDSHandler {
+	return &KDSHandler{
+		kdsService: kdsService,
+		logger:     logger.WithComponent("kds_handler"),
+	}
+}
+
+// GetQueue handles GET /api/v1/kds/queue?station=bar
+func (h *KDSHandler) GetQueue(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	queue, err := h.kdsService.GetQueue(models.Station(r.URL.Query().Get("station")))
+	if err != nil {
+		if strings.Contains(err.Error(), "invalid station") {
+			h.logger.Warn("Invalid station for kitchen queue", "error", err)
+			writeErrorResponse(w, http.StatusBadRequest, err.Error())
+			reqCtx.StatusCode = http.StatusBadRequest
+			h.logger.LogResponse(reqCtx)
+			return
+		}
+		h.logger.Error("Failed to get kitchen queue", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get kitchen queue")
+		reqCtx.StatusCode = http.StatusInternalServerError
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, queue)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// Stream handles GET /api/v1/kds/stream, sending order events as server-sent events until the client
+// disconnects. A client that falls behind is disconnected and should reconnect and refetch the queue.
+func (h *KDSHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeErrorResponse(w, http.StatusInternalServerError, "Streaming is not supported")
+		reqCtx.StatusCode = http.StatusInternalServerError
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+	// The stream outlives the server's write timeout
+	if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil {
+		h.logger.Warn("Failed to clear write deadline for event stream", "error", err)
+	}
+
+	orderEvents, unsubscribe := h.kdsService.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	reqCtx.StatusCode = http.StatusOK
+	defer h.logger.LogResponse(reqCtx)
+
+	fmt.Fprint(w, "retry: 3000\n\n")
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(kdsKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+		case event, ok := <-orderEvents:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event.Data)
+			if err != nil {
+				h.logger.Error("Failed to encode order event", "event", event.Type, "error", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
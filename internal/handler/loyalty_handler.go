@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"frappuccino/internal/service"
+	"frappuccino/pkg/logger"
+)
+
+type LoyaltyHandler struct {
+	loyaltyService service.LoyaltyServiceInterface
+	logger         *logger.Logger
+}
+
+func NewLoyaltyHandler(loyaltyService service.LoyaltyServiceInterface, logger *logger.Logger) *LoyaltyHandler {
+	return &LoyaltyHandler{
+		loyaltyService: loyaltyService,
+		logger:         logger.WithComponent("loyalty_handler"),
+	}
+}
+
+// GetLoyaltyRules handles GET /api/v1/loyalty-rules
+func (h *LoyaltyHandler) GetLoyaltyRules(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	rules, err := h.loyaltyService.GetLoyaltyRules()
+	if err != nil {
+		h.logger.Error("Failed to get loyalty rules", "error", err)
+		statusCode := loyaltyErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, rules)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetLoyaltyRule handles GET /api/v1/loyalty-rules/{id}
+func (h *LoyaltyHandler) GetLoyaltyRule(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := extractIDFromPath(r)
+	rule, err := h.loyaltyService.GetLoyaltyRule(id)
+	if err != nil {
+		h.logger.Warn("Failed to get loyalty rule", "id", id, "error", err)
+		statusCode := loyaltyErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, rule)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// CreateLoyaltyRule handles POST /api/v1/loyalty-rules
+func (h *LoyaltyHandler) CreateLoyaltyRule(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	var req service.LoyaltyRuleRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for create loyalty rule", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	rule, err := h.loyaltyService.CreateLoyaltyRule(req)
+	if err != nil {
+		h.logger.Warn("Failed to create loyalty rule", "error", err)
+		statusCode := loyaltyErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, rule)
+	reqCtx.StatusCode = http.StatusCreated
+	h.logger.LogResponse(reqCtx)
+}
+
+// UpdateLoyaltyRule handles PUT /api/v1/loyalty-rules/{id}
+func (h *LoyaltyHandler) UpdateLoyaltyRule(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := extractIDFromPath(r)
+
+	var req service.LoyaltyRuleRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for update loyalty rule", "id", id, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	rule, err := h.loyaltyService.UpdateLoyaltyRule(id, req)
+	if err != nil {
+		h.logger.Warn("Failed to update loyalty rule", "id", id, "error", err)
+		statusCode := loyaltyErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, rule)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// DeleteLoyaltyRule handles DELETE /api/v1/loyalty-rules/{id}
+func (h *LoyaltyHandler) DeleteLoyaltyRule(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := extractIDFromPath(r)
+	if err := h.loyaltyService.DeleteLoyaltyRule(id); err != nil {
+		h.logger.Warn("Failed to delete loyalty rule", "id", id, "error", err)
+		statusCode := loyaltyErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusNoContent, nil)
+	reqCtx.StatusCode = http.StatusNoContent
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetCustomerLoyalty handles GET /api/v1/customers/{id}/loyalty
+func (h *LoyaltyHandler) GetCustomerLoyalty(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := customerIDFromPath(r)
+	loyalty, err := h.loyaltyService.GetCustomerLoyalty(id)
+	if err != nil {
+		h.logger.Warn("Failed to get customer loyalty", "id", id, "error", err)
+		statusCode := loyaltyErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, loyalty)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetLiabilityReport handles GET /api/v1/reports/loyalty-liability
+func (h *LoyaltyHandler) GetLiabilityReport(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	report, err := h.loyaltyService.GetLiabilityReport()
+	if err != nil {
+		h.logger.Error("Failed to get loyalty liability report", "error", err)
+		statusCode := loyaltyErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, report)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// loyaltyErrorStatus maps loyalty service errors to HTTP status codes
+func loyaltyErrorStatus(err error) int {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "with id") && strings.Contains(message, "not found"):
+		return http.StatusNotFound
+	case strings.Contains(message, "deactivate it instead"):
+		return http.StatusConflict
+	case strings.Contains(message, "failed to"):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
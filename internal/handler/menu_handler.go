@@ -10,6 +10,7 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -29,7 +30,7 @@ func NewMenuHandler(menuService service.MenuServiceInterface, logger *logger.Log
 	}
 }
 
-// GetAllMenuItems handles GET /api/v1/menu
+// GetAllMenuItems handles GET /api/v1/menu?available_only=true&at=RFC3339
 func (h *MenuHandler) GetAllMenuItems(w http.ResponseWriter, r *http.Request) {
 	reqCtx := &logger.RequestContext{
 		Method:     r.Method,
@@ -39,7 +40,32 @@ func (h *MenuHandler) GetAllMenuItems(w http.ResponseWriter, r *http.Request) {
 	}
 	h.logger.LogRequest(reqCtx)
 
-	items, err := h.menuService.GetAllMenuItems()
+	var filter service.MenuFilter
+	if value := r.URL.Query().Get("available_only"); value != "" {
+		availableOnly, err := strconv.ParseBool(value)
+		if err != nil {
+			h.logger.Warn("Invalid available_only parameter", "value", value)
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid available_only parameter (expected true or false)")
+			reqCtx.StatusCode = http.StatusBadRequest
+			h.logger.LogResponse(reqCtx)
+			return
+		}
+		filter.AvailableOnly = availableOnly
+	}
+
+	if value := r.URL.Query().Get("at"); value != "" {
+		at, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			h.logger.Warn("Invalid at parameter", "value", value)
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid at parameter (expected RFC 3339, e.g. 2024-10-01T08:30:00+02:00)")
+			reqCtx.StatusCode = http.StatusBadRequest
+			h.logger.LogResponse(reqCtx)
+			return
+		}
+		filter.At = &at
+	}
+
+	items, err := h.menuService.GetAllMenuItems(filter)
 	if err != nil {
 		h.logger.Error("Failed to get all menu items")
 		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch menu items")
@@ -164,8 +190,12 @@ func (h *MenuHandler) DeleteMenuItem(w http.ResponseWriter, r *http.Request) {
 
 	if err := h.menuService.DeleteMenuItem(id); err != nil {
 		h.logger.Warn("Failed to delete menu item", "id", id, "error", err)
-		writeErrorResponse(w, http.StatusNotFound, err.Error())
-		reqCtx.StatusCode = http.StatusNotFound
+		statusCode := http.StatusNotFound
+		if strings.Contains(err.Error(), "is used in") {
+			statusCode = http.StatusConflict
+		}
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
 		h.logger.LogResponse(reqCtx)
 		return
 	}
@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"frappuccino/internal/service"
+	"frappuccino/pkg/logger"
+)
+
+// GetPriceHistory handles GET /api/v1/menu/{id}/price-history
+func (h *MenuHandler) GetPriceHistory(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id, _ := menuPricePath(r)
+	history, err := h.menuService.GetPriceHistory(id)
+	if err != nil {
+		h.logger.Warn("Failed to get price history", "id", id, "error", err)
+		statusCode := priceErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, history)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetScheduledPriceChanges handles GET /api/v1/menu/{id}/price-changes
+func (h *MenuHandler) GetScheduledPriceChanges(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id, _ := menuPricePath(r)
+	changes, err := h.menuService.GetScheduledPriceChanges(id)
+	if err != nil {
+		h.logger.Warn("Failed to get scheduled price changes", "id", id, "error", err)
+		statusCode := priceErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, changes)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// SchedulePriceChange handles POST /api/v1/menu/{id}/price-changes
+func (h *MenuHandler) SchedulePriceChange(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id, _ := menuPricePath(r)
+
+	var req service.SchedulePriceChangeRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for price change", "id", id, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	change, err := h.menuService.SchedulePriceChange(id, req)
+	if err != nil {
+		h.logger.Warn("Failed to schedule price change", "id", id, "error", err)
+		statusCode := priceErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, change)
+	reqCtx.StatusCode = http.StatusCreated
+	h.logger.LogResponse(reqCtx)
+}
+
+// CancelPriceChange handles DELETE /api/v1/menu/{id}/price-changes/{changeId}
+func (h *MenuHandler) CancelPriceChange(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id, changeID := menuPricePath(r)
+	if err := h.menuService.CancelPriceChange(id, changeID); err != nil {
+		h.logger.Warn("Failed to cancel price change", "id", id, "change_id", changeID, "error", err)
+		statusCode := priceErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusNoContent, nil)
+	reqCtx.StatusCode = http.StatusNoContent
+	h.logger.LogResponse(reqCtx)
+}
+
+// menuPricePath extracts the menu item and price change IDs from /api/v1/menu/{id}/price-changes[/{changeId}]
+func menuPricePath(r *http.Request) (string, string) {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/v1/menu/"))
+	var id, changeID string
+	if len(parts) > 0 {
+		id = parts[0]
+	}
+	if len(parts) > 2 {
+		changeID = parts[2]
+	}
+	return id, changeID
+}
+
+// priceErrorStatus maps price service errors to HTTP status codes
+func priceErrorStatus(err error) int {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "not found"):
+		return http.StatusNotFound
+	case strings.Contains(message, "cannot cancel"):
+		return http.StatusConflict
+	case strings.Contains(message, "failed to"):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
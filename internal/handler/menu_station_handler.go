@@ -0,0 +1,74 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"frappuccino/internal/service"
+	"frappuccino/models"
+	"frappuccino/pkg/logger"
+)
+
+// GetStationRoutes handles GET /api/v1/station-routes
+func (h *MenuHandler) GetStationRoutes(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	routes, err := h.menuService.GetStationRoutes()
+	if err != nil {
+		h.logger.Error("Failed to get station routes", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to get station routes")
+		reqCtx.StatusCode = http.StatusInternalServerError
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, routes)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// SetStationRoute handles PUT /api/v1/station-routes/{category}
+func (h *MenuHandler) SetStationRoute(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	category := models.MenuCategory(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/station-routes/"), "/"))
+
+	var req service.SetStationRouteRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for station route", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	route, err := h.menuService.SetStationRoute(category, req)
+	if err != nil {
+		h.logger.Warn("Failed to set station route", "category", category, "error", err)
+		statusCode := http.StatusBadRequest
+		if !strings.Contains(err.Error(), "invalid") {
+			statusCode = http.StatusInternalServerError
+		}
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, route)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
@@ -10,6 +10,7 @@ package handler
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -56,12 +57,33 @@ func (h *OrderHandler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	order, err := h.orderService.CreateOrder(createReq)
 	if err != nil {
 		h.logger.Warn("Failed to create order", "error", err)
+
+		// Allergen conflicts carry the warnings so staff can confirm them with allergen_override
+		var conflict *service.AllergenConflictError
+		if errors.As(err, &conflict) {
+			h.writeJSONResponse(w, http.StatusConflict, map[string]interface{}{
+				"error":              conflict.Error(),
+				"declared_allergens": conflict.Declared,
+				"allergen_warnings":  conflict.Warnings,
+			})
+			reqCtx.StatusCode = http.StatusConflict
+			h.logger.LogResponse(reqCtx)
+			return
+		}
+
 		statusCode := http.StatusBadRequest
 
-		if strings.Contains(err.Error(), "not found in menu") {
+		if strings.Contains(err.Error(), "insufficient loyalty balance") {
+			statusCode = http.StatusConflict
+		} else if strings.Contains(err.Error(), "promo code") || strings.Contains(err.Error(), "customer with id") ||
+			strings.Contains(err.Error(), "loyalty") {
+			statusCode = http.StatusUnprocessableEntity
+		} else if strings.Contains(err.Error(), "not found in menu") {
 			statusCode = http.StatusNotFound
 		} else if strings.Contains(err.Error(), "insufficient inventory") {
 			statusCode = http.StatusConflict
+		} else if strings.Contains(err.Error(), "not on sale") {
+			statusCode = http.StatusUnprocessableEntity
 		} else if strings.Contains(err.Error(), "foreign key") || strings.Contains(err.Error(), "violates") {
 			statusCode = http.StatusUnprocessableEntity
 		}
@@ -165,13 +187,33 @@ func (h *OrderHandler) UpdateOrder(w http.ResponseWriter, r *http.Request) {
 	err := h.orderService.UpdateOrder(id, updateReq)
 	if err != nil {
 		h.logger.Warn("Failed to update order", "id", id, "error", err)
+
+		var conflict *service.AllergenConflictError
+		if errors.As(err, &conflict) {
+			h.writeJSONResponse(w, http.StatusConflict, map[string]interface{}{
+				"error":              conflict.Error(),
+				"declared_allergens": conflict.Declared,
+				"allergen_warnings":  conflict.Warnings,
+			})
+			reqCtx.StatusCode = http.StatusConflict
+			h.logger.LogResponse(reqCtx)
+			return
+		}
+
 		statusCode := http.StatusBadRequest
 
-		if strings.Contains(err.Error(), "not found") {
+		if strings.Contains(err.Error(), "promo code") || strings.Contains(err.Error(), "customer with id") ||
+			strings.Contains(err.Error(), "loyalty rule with id") {
+			statusCode = http.StatusUnprocessableEntity
+		} else if strings.Contains(err.Error(), "not found") {
 			statusCode = http.StatusNotFound
 		} else if strings.Contains(err.Error(), "insufficient inventory") {
 			statusCode = http.StatusConflict
-		} else if strings.Contains(err.Error(), "cannot update closed order") {
+		} else if strings.Contains(err.Error(), "cannot update") || strings.Contains(err.Error(), "cannot move") ||
+			strings.Contains(err.Error(), "cannot change") {
+			statusCode = http.StatusConflict
+		} else if strings.Contains(err.Error(), "cannot close") || strings.Contains(err.Error(), "cannot cancel") ||
+			strings.Contains(err.Error(), "cannot reduce") {
 			statusCode = http.StatusConflict
 		} else if strings.Contains(err.Error(), "foreign key") || strings.Contains(err.Error(), "violates") {
 			statusCode = http.StatusUnprocessableEntity
@@ -211,10 +253,14 @@ func (h *OrderHandler) DeleteOrder(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		h.logger.Warn("Failed to delete order", "id", id, "error", err)
 		statusCode := http.StatusNotFound
+		message := "Order not found"
 		if strings.Contains(err.Error(), "foreign key") || strings.Contains(err.Error(), "violates") {
 			statusCode = http.StatusConflict
+		} else if strings.Contains(err.Error(), "cannot delete") {
+			statusCode = http.StatusConflict
+			message = err.Error()
 		}
-		h.writeErrorResponse(w, statusCode, "Order not found")
+		h.writeErrorResponse(w, statusCode, message)
 		reqCtx.StatusCode = statusCode
 		h.logger.LogResponse(reqCtx)
 		return
@@ -248,7 +294,8 @@ func (h *OrderHandler) CloseOrder(w http.ResponseWriter, r *http.Request) {
 	if err != nil {
 		h.logger.Warn("Failed to close order", "id", id, "error", err)
 		statusCode := http.StatusNotFound
-		if strings.Contains(err.Error(), "already closed") {
+		if strings.Contains(err.Error(), "already closed") || strings.Contains(err.Error(), "cannot close") ||
+			strings.Contains(err.Error(), "insufficient inventory") {
 			statusCode = http.StatusConflict
 		}
 		h.writeErrorResponse(w, statusCode, err.Error())
@@ -262,6 +309,97 @@ func (h *OrderHandler) CloseOrder(w http.ResponseWriter, r *http.Request) {
 	h.logger.LogResponse(reqCtx)
 }
 
+// GetOrderTickets handles GET /api/v1/orders/{id}/tickets
+func (h *OrderHandler) GetOrderTickets(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := h.extractIDFromPath(r)
+	if err := h.validateOrderID(id); err != nil {
+		h.logger.Warn("Invalid order ID", "id", id, "error", err)
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid order ID")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	tickets, err := h.orderService.GetOrderTickets(id)
+	if err != nil {
+		h.logger.Warn("Failed to get order tickets", "id", id, "error", err)
+		h.writeErrorResponse(w, http.StatusNotFound, "Order not found")
+		reqCtx.StatusCode = http.StatusNotFound
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, tickets)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// UpdateItemStatus handles PUT /api/v1/orders/{id}/items/{itemId}/status
+func (h *OrderHandler) UpdateItemStatus(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := h.extractIDFromPath(r)
+	if err := h.validateOrderID(id); err != nil {
+		h.logger.Warn("Invalid order ID", "id", id, "error", err)
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid order ID")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	// /api/v1/orders/{id}/items/{itemId}/status
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/orders/"), "/"), "/")
+	if len(parts) != 4 || parts[1] != "items" || parts[2] == "" {
+		h.writeErrorResponse(w, http.StatusNotFound, "Order item not found")
+		reqCtx.StatusCode = http.StatusNotFound
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+	itemID := parts[2]
+
+	var req service.UpdateItemStatusRequest
+	if err := h.parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for item status", "error", err)
+		h.writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	tickets, err := h.orderService.UpdateItemStatus(id, itemID, req.Status)
+	if err != nil {
+		h.logger.Warn("Failed to update order item status", "id", id, "item_id", itemID, "error", err)
+		statusCode := http.StatusBadRequest
+		if strings.Contains(err.Error(), "not found") {
+			statusCode = http.StatusNotFound
+		} else if strings.Contains(err.Error(), "cannot update") {
+			statusCode = http.StatusConflict
+		}
+		h.writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	h.writeJSONResponse(w, http.StatusOK, tickets)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
 // GetNumberOfOrderedItems handles GET /api/v1/orders/numberOfOrderedItems
 func (h *OrderHandler) GetNumberOfOrderedItems(w http.ResponseWriter, r *http.Request) {
 	reqCtx := &logger.RequestContext{
@@ -332,7 +470,9 @@ func (h *OrderHandler) BatchProcessOrders(w http.ResponseWriter, r *http.Request
 		h.logger.Error("Failed to batch process orders", "error", err)
 		statusCode := http.StatusInternalServerError
 
-		if strings.Contains(err.Error(), "validation failed") {
+		if strings.Contains(err.Error(), "declared allergens") {
+			statusCode = http.StatusConflict
+		} else if strings.Contains(err.Error(), "validation failed") {
 			statusCode = http.StatusBadRequest
 		} else if strings.Contains(err.Error(), "not found") {
 			statusCode = http.StatusNotFound
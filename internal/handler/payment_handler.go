@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"frappuccino/internal/service"
+	"frappuccino/pkg/logger"
+)
+
+type PaymentHandler struct {
+	paymentService service.PaymentServiceInterface
+	logger         *logger.Logger
+}
+
+func NewPaymentHandler(paymentService service.PaymentServiceInterface, logger *logger.Logger) *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: paymentService,
+		logger:         logger.WithComponent("payment_handler"),
+	}
+}
+
+// GetOrderPayments handles GET /api/v1/orders/{id}/payments[?split=N]
+func (h *PaymentHandler) GetOrderPayments(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	split := 0
+	if splitStr := r.URL.Query().Get("split"); splitStr != "" {
+		parsed, err := strconv.Atoi(splitStr)
+		if err != nil || parsed < 1 {
+			h.logger.Warn("Invalid split parameter", "value", splitStr, "error", err)
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid split parameter")
+			reqCtx.StatusCode = http.StatusBadRequest
+			h.logger.LogResponse(reqCtx)
+			return
+		}
+		split = parsed
+	}
+
+	orderID := paymentOrderIDFromPath(r)
+	summary, err := h.paymentService.GetOrderPayments(orderID, split)
+	if err != nil {
+		h.logger.Warn("Failed to get order payments", "order_id", orderID, "error", err)
+		statusCode := paymentErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, summary)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// AddPayment handles POST /api/v1/orders/{id}/payments
+func (h *PaymentHandler) AddPayment(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	var req service.PaymentRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for add payment", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	orderID := paymentOrderIDFromPath(r)
+	payment, err := h.paymentService.AddPayment(orderID, req)
+	if err != nil {
+		h.logger.Warn("Failed to add payment", "order_id", orderID, "error", err)
+		statusCode := paymentErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, payment)
+	reqCtx.StatusCode = http.StatusCreated
+	h.logger.LogResponse(reqCtx)
+}
+
+// RefundPayment handles POST /api/v1/orders/{id}/refunds
+func (h *PaymentHandler) RefundPayment(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	var req service.RefundRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for refund", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	orderID := paymentOrderIDFromPath(r)
+	refund, err := h.paymentService.RefundPayment(orderID, req)
+	if err != nil {
+		h.logger.Warn("Failed to refund payment", "order_id", orderID, "payment_id", req.PaymentID, "error", err)
+		statusCode := paymentErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, refund)
+	reqCtx.StatusCode = http.StatusCreated
+	h.logger.LogResponse(reqCtx)
+}
+
+// paymentOrderIDFromPath extracts the order ID from /api/v1/orders/{id}/payments and /refunds
+func paymentOrderIDFromPath(r *http.Request) string {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/v1/orders/"))
+	if len(parts) > 0 {
+		return parts[0]
+	}
+	return ""
+}
+
+// paymentErrorStatus maps payment service errors to HTTP status codes
+func paymentErrorStatus(err error) int {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "with id") && strings.Contains(message, "not found"):
+		return http.StatusNotFound
+	case strings.Contains(message, "declined"):
+		return http.StatusPaymentRequired
+	case strings.Contains(message, "cannot pay") || strings.Contains(message, "already fully paid") ||
+		strings.Contains(message, "exceeds"):
+		return http.StatusConflict
+	case strings.Contains(message, "failed to"):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
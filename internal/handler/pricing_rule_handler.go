@@ -0,0 +1,197 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"frappuccino/internal/service"
+	"frappuccino/models"
+	"frappuccino/pkg/logger"
+)
+
+type PricingRuleHandler struct {
+	pricingRuleService service.PricingRuleServiceInterface
+	logger             *logger.Logger
+}
+
+func NewPricingRuleHandler(pricingRuleService service.PricingRuleServiceInterface, logger *logger.Logger) *PricingRuleHandler {
+	return &PricingRuleHandler{
+		pricingRuleService: pricingRuleService,
+		logger:             logger.WithComponent("pricing_rule_handler"),
+	}
+}
+
+// GetPricingRules handles GET /api/v1/pricing-rules
+func (h *PricingRuleHandler) GetPricingRules(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	rules, err := h.pricingRuleService.GetAllPricingRules()
+	if err != nil {
+		h.logger.Error("Failed to get pricing rules", "error", err)
+		statusCode := pricingRuleErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, rules)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetPricingRule handles GET /api/v1/pricing-rules/{id}
+func (h *PricingRuleHandler) GetPricingRule(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := pricingRuleIDFromPath(r)
+	rule, err := h.pricingRuleService.GetPricingRule(id)
+	if err != nil {
+		h.logger.Warn("Failed to get pricing rule", "id", id, "error", err)
+		statusCode := pricingRuleErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, rule)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// CreatePricingRule handles POST /api/v1/pricing-rules
+func (h *PricingRuleHandler) CreatePricingRule(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	var req service.CreatePricingRuleRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for create pricing rule", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	rule, err := h.pricingRuleService.CreatePricingRule(req)
+	if err != nil {
+		h.logger.Warn("Failed to create pricing rule", "error", err)
+		statusCode := pricingRuleErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, rule)
+	reqCtx.StatusCode = http.StatusCreated
+	h.logger.LogResponse(reqCtx)
+}
+
+// PricingRuleAction handles POST /api/v1/pricing-rules/{id}/activate and /deactivate
+func (h *PricingRuleHandler) PricingRuleAction(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := pricingRuleIDFromPath(r)
+
+	var rule *models.PricingRule
+	var err error
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/activate"):
+		rule, err = h.pricingRuleService.SetPricingRuleActive(id, true)
+	case strings.HasSuffix(r.URL.Path, "/deactivate"):
+		rule, err = h.pricingRuleService.SetPricingRuleActive(id, false)
+	default:
+		writeErrorResponse(w, http.StatusNotFound, "Unknown pricing rule action")
+		reqCtx.StatusCode = http.StatusNotFound
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	if err != nil {
+		h.logger.Warn("Pricing rule action failed", "id", id, "path", r.URL.Path, "error", err)
+		statusCode := pricingRuleErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, rule)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// DeletePricingRule handles DELETE /api/v1/pricing-rules/{id}
+func (h *PricingRuleHandler) DeletePricingRule(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := pricingRuleIDFromPath(r)
+	if err := h.pricingRuleService.DeletePricingRule(id); err != nil {
+		h.logger.Warn("Failed to delete pricing rule", "id", id, "error", err)
+		statusCode := pricingRuleErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusNoContent, nil)
+	reqCtx.StatusCode = http.StatusNoContent
+	h.logger.LogResponse(reqCtx)
+}
+
+// pricingRuleIDFromPath extracts the ID from /api/v1/pricing-rules/{id}[/action]
+func pricingRuleIDFromPath(r *http.Request) string {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/v1/pricing-rules/"))
+	if len(parts) > 0 {
+		return parts[0]
+	}
+	return ""
+}
+
+// pricingRuleErrorStatus maps pricing rule service errors to HTTP status codes
+func pricingRuleErrorStatus(err error) int {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "with id") && strings.Contains(message, "not found"):
+		return http.StatusNotFound
+	case strings.Contains(message, "already exists"):
+		return http.StatusConflict
+	case strings.Contains(message, "failed to"):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,275 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"frappuccino/internal/service"
+	"frappuccino/models"
+	"frappuccino/pkg/logger"
+)
+
+type PurchaseOrderHandler struct {
+	purchaseOrderService service.PurchaseOrderServiceInterface
+	logger               *logger.Logger
+}
+
+func NewPurchaseOrderHandler(purchaseOrderService service.PurchaseOrderServiceInterface, logger *logger.Logger) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{
+		purchaseOrderService: purchaseOrderService,
+		logger:               logger.WithComponent("purchase_order_handler"),
+	}
+}
+
+// GetPurchaseOrders handles GET /api/v1/purchase-orders?status=
+func (h *PurchaseOrderHandler) GetPurchaseOrders(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	orders, err := h.purchaseOrderService.GetPurchaseOrders(r.URL.Query().Get("status"))
+	if err != nil {
+		h.logger.Warn("Failed to get purchase orders", "error", err)
+		statusCode := purchaseOrderErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, orders)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetPurchaseOrder handles GET /api/v1/purchase-orders/{id}
+func (h *PurchaseOrderHandler) GetPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := purchaseOrderIDFromPath(r)
+	order, err := h.purchaseOrderService.GetPurchaseOrder(id)
+	if err != nil {
+		h.logger.Warn("Failed to get purchase order", "id", id, "error", err)
+		statusCode := purchaseOrderErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, order)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// CreatePurchaseOrder handles POST /api/v1/purchase-orders
+func (h *PurchaseOrderHandler) CreatePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	var req service.CreatePurchaseOrderRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for create purchase order", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	order, err := h.purchaseOrderService.CreatePurchaseOrder(req)
+	if err != nil {
+		h.logger.Warn("Failed to create purchase order", "error", err)
+		statusCode := purchaseOrderErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, order)
+	reqCtx.StatusCode = http.StatusCreated
+	h.logger.LogResponse(reqCtx)
+}
+
+// DeletePurchaseOrder handles DELETE /api/v1/purchase-orders/{id}
+func (h *PurchaseOrderHandler) DeletePurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := purchaseOrderIDFromPath(r)
+	if err := h.purchaseOrderService.DeletePurchaseOrder(id); err != nil {
+		h.logger.Warn("Failed to delete purchase order", "id", id, "error", err)
+		statusCode := purchaseOrderErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusNoContent, nil)
+	reqCtx.StatusCode = http.StatusNoContent
+	h.logger.LogResponse(reqCtx)
+}
+
+// TransitionPurchaseOrder handles POST /api/v1/purchase-orders/{id}/send, /receive and /cancel
+func (h *PurchaseOrderHandler) TransitionPurchaseOrder(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := purchaseOrderIDFromPath(r)
+
+	var order *models.PurchaseOrder
+	var err error
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/send"):
+		order, err = h.purchaseOrderService.SendPurchaseOrder(id)
+	case strings.HasSuffix(r.URL.Path, "/receive"):
+		order, err = h.purchaseOrderService.ReceivePurchaseOrder(id)
+	case strings.HasSuffix(r.URL.Path, "/cancel"):
+		order, err = h.purchaseOrderService.CancelPurchaseOrder(id)
+	default:
+		writeErrorResponse(w, http.StatusNotFound, "Unknown purchase order action")
+		reqCtx.StatusCode = http.StatusNotFound
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	if err != nil {
+		h.logger.Warn("Failed to transition purchase order", "id", id, "path", r.URL.Path, "error", err)
+		statusCode := purchaseOrderErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, order)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetReorderProposal handles GET /api/v1/purchase-orders/reorder?cover_days=
+func (h *PurchaseOrderHandler) GetReorderProposal(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	coverDays, ok := h.parseCoverDays(w, r, reqCtx)
+	if !ok {
+		return
+	}
+
+	proposal, err := h.purchaseOrderService.GetReorderProposal(coverDays)
+	if err != nil {
+		h.logger.Error("Failed to get reorder proposal", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to calculate reorder proposal")
+		reqCtx.StatusCode = http.StatusInternalServerError
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, proposal)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GenerateDraftOrders handles POST /api/v1/purchase-orders/reorder?cover_days=
+func (h *PurchaseOrderHandler) GenerateDraftOrders(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	coverDays, ok := h.parseCoverDays(w, r, reqCtx)
+	if !ok {
+		return
+	}
+
+	orders, err := h.purchaseOrderService.GenerateDraftOrders(coverDays)
+	if err != nil {
+		h.logger.Error("Failed to generate draft purchase orders", "error", err, "created", len(orders))
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to generate purchase orders")
+		reqCtx.StatusCode = http.StatusInternalServerError
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, orders)
+	reqCtx.StatusCode = http.StatusCreated
+	h.logger.LogResponse(reqCtx)
+}
+
+func (h *PurchaseOrderHandler) parseCoverDays(w http.ResponseWriter, r *http.Request, reqCtx *logger.RequestContext) (int, bool) {
+	value := r.URL.Query().Get("cover_days")
+	if value == "" {
+		return service.DefaultReorderCoverDays, true
+	}
+
+	coverDays, err := strconv.Atoi(value)
+	if err != nil || coverDays <= 0 {
+		h.logger.Warn("Invalid cover_days parameter", "value", value)
+		writeErrorResponse(w, http.StatusBadRequest, "cover_days must be a positive integer")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return 0, false
+	}
+	return coverDays, true
+}
+
+// purchaseOrderIDFromPath extracts the ID from /api/v1/purchase-orders/{id}[/action]
+func purchaseOrderIDFromPath(r *http.Request) string {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/v1/purchase-orders/"))
+	if len(parts) > 0 {
+		return parts[0]
+	}
+	return ""
+}
+
+// purchaseOrderErrorStatus maps purchase order service errors to HTTP status codes
+func purchaseOrderErrorStatus(err error) int {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "with id") && strings.Contains(message, "not found"):
+		return http.StatusNotFound
+	case strings.Contains(message, "purchase order is"):
+		return http.StatusConflict
+	case strings.Contains(message, "failed to"):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,291 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"frappuccino/internal/service"
+	"frappuccino/models"
+	"frappuccino/pkg/logger"
+)
+
+type StocktakeHandler struct {
+	stocktakeService service.StocktakeServiceInterface
+	logger           *logger.Logger
+}
+
+func NewStocktakeHandler(stocktakeService service.StocktakeServiceInterface, logger *logger.Logger) *StocktakeHandler {
+	return &StocktakeHandler{
+		stocktakeService: stocktakeService,
+		logger:           logger.WithComponent("stocktake_handler"),
+	}
+}
+
+// GetStocktakes handles GET /api/v1/stocktakes?status=
+func (h *StocktakeHandler) GetStocktakes(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	stocktakes, err := h.stocktakeService.GetStocktakes(r.URL.Query().Get("status"))
+	if err != nil {
+		h.logger.Warn("Failed to get stocktakes", "error", err)
+		statusCode := stocktakeErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, stocktakes)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetStocktake handles GET /api/v1/stocktakes/{id}
+func (h *StocktakeHandler) GetStocktake(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := stocktakeIDFromPath(r)
+	stocktake, err := h.stocktakeService.GetStocktake(id)
+	if err != nil {
+		h.logger.Warn("Failed to get stocktake", "id", id, "error", err)
+		statusCode := stocktakeErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, stocktake)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// CreateStocktake handles POST /api/v1/stocktakes
+func (h *StocktakeHandler) CreateStocktake(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	var req service.CreateStocktakeRequest
+	if r.ContentLength != 0 {
+		if err := parseRequestBody(r, &req); err != nil {
+			h.logger.Warn("Invalid request body for create stocktake", "error", err)
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			reqCtx.StatusCode = http.StatusBadRequest
+			h.logger.LogResponse(reqCtx)
+			return
+		}
+	}
+
+	stocktake, err := h.stocktakeService.CreateStocktake(req)
+	if err != nil {
+		h.logger.Warn("Failed to create stocktake", "error", err)
+		statusCode := stocktakeErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, stocktake)
+	reqCtx.StatusCode = http.StatusCreated
+	h.logger.LogResponse(reqCtx)
+}
+
+// StocktakeAction handles POST /api/v1/stocktakes/{id}/counts, /approve and /cancel
+func (h *StocktakeHandler) StocktakeAction(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := stocktakeIDFromPath(r)
+
+	var stocktake *models.Stocktake
+	var err error
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/counts"):
+		var req service.SubmitCountsRequest
+		if err := parseRequestBody(r, &req); err != nil {
+			h.logger.Warn("Invalid request body for stocktake counts", "id", id, "error", err)
+			writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+			reqCtx.StatusCode = http.StatusBadRequest
+			h.logger.LogResponse(reqCtx)
+			return
+		}
+		stocktake, err = h.stocktakeService.SubmitCounts(id, req)
+	case strings.HasSuffix(r.URL.Path, "/approve"):
+		var req service.ApproveStocktakeRequest
+		if r.ContentLength != 0 {
+			if err := parseRequestBody(r, &req); err != nil {
+				h.logger.Warn("Invalid request body for stocktake approval", "id", id, "error", err)
+				writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+				reqCtx.StatusCode = http.StatusBadRequest
+				h.logger.LogResponse(reqCtx)
+				return
+			}
+		}
+		stocktake, err = h.stocktakeService.ApproveStocktake(id, req)
+	case strings.HasSuffix(r.URL.Path, "/cancel"):
+		stocktake, err = h.stocktakeService.CancelStocktake(id)
+	default:
+		writeErrorResponse(w, http.StatusNotFound, "Unknown stocktake action")
+		reqCtx.StatusCode = http.StatusNotFound
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	if err != nil {
+		h.logger.Warn("Stocktake action failed", "id", id, "path", r.URL.Path, "error", err)
+		statusCode := stocktakeErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, stocktake)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetVarianceReport handles GET /api/v1/reports/stocktake-variance?from=YYYY-MM-DD&to=YYYY-MM-DD
+func (h *StocktakeHandler) GetVarianceReport(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	from, err := parseReportDate(r.URL.Query().Get("from"))
+	if err != nil {
+		h.logger.Warn("Invalid from parameter", "value", r.URL.Query().Get("from"), "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid from parameter (expected YYYY-MM-DD)")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	to, err := parseReportDate(r.URL.Query().Get("to"))
+	if err != nil {
+		h.logger.Warn("Invalid to parameter", "value", r.URL.Query().Get("to"), "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid to parameter (expected YYYY-MM-DD)")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	// 'to' is inclusive, the service expects an exclusive upper bound
+	var toExclusive *time.Time
+	if to != nil {
+		next := to.AddDate(0, 0, 1)
+		toExclusive = &next
+	}
+
+	report, err := h.stocktakeService.GetVarianceReport(from, toExclusive)
+	if err != nil {
+		h.logger.Error("Failed to get stocktake variance report", "error", err)
+		statusCode := stocktakeErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	if wantsCSV(r) {
+		rows := make([][]string, 0, len(report.Sessions)+len(report.Items))
+		for _, session := range report.Sessions {
+			rows = append(rows, []string{
+				"session",
+				session.StocktakeID,
+				session.ApprovedAt.Format(time.RFC3339),
+				"",
+				"",
+				strconv.Itoa(session.ItemsCounted),
+				"",
+				formatMoney(session.VarianceValue),
+				formatMoney(session.ShrinkageValue),
+			})
+		}
+		for _, item := range report.Items {
+			rows = append(rows, []string{
+				"item",
+				item.IngredientID,
+				"",
+				item.Name,
+				item.Unit,
+				strconv.Itoa(item.Counts),
+				formatQuantity(item.Variance),
+				formatMoney(item.VarianceValue),
+				formatMoney(item.ShrinkageValue),
+			})
+		}
+
+		fileFrom, fileTo := time.Time{}, time.Now()
+		if from != nil {
+			fileFrom = *from
+		}
+		if to != nil {
+			fileTo = *to
+		}
+
+		header := []string{"level", "id", "approved_at", "name", "unit", "counts", "variance", "variance_value", "shrinkage_value"}
+		if err := writeCSVResponse(w, exportFilename("stocktake-variance", fileFrom, fileTo), header, rows); err != nil {
+			h.logger.Error("Failed to write stocktake variance CSV", "error", err)
+		}
+		reqCtx.StatusCode = http.StatusOK
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, report)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// stocktakeIDFromPath extracts the ID from /api/v1/stocktakes/{id}[/action]
+func stocktakeIDFromPath(r *http.Request) string {
+	parts := splitPath(strings.TrimPrefix(r.URL.Path, "/api/v1/stocktakes/"))
+	if len(parts) > 0 {
+		return parts[0]
+	}
+	return ""
+}
+
+// stocktakeErrorStatus maps stocktake service errors to HTTP status codes
+func stocktakeErrorStatus(err error) int {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "with id") && strings.Contains(message, "not found"):
+		return http.StatusNotFound
+	case strings.Contains(message, "stocktake is"), strings.Contains(message, "insufficient inventory"):
+		return http.StatusConflict
+	case strings.Contains(message, "failed to"):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,181 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"frappuccino/internal/service"
+	"frappuccino/pkg/logger"
+)
+
+type SupplierHandler struct {
+	supplierService service.SupplierServiceInterface
+	logger          *logger.Logger
+}
+
+func NewSupplierHandler(supplierService service.SupplierServiceInterface, logger *logger.Logger) *SupplierHandler {
+	return &SupplierHandler{
+		supplierService: supplierService,
+		logger:          logger.WithComponent("supplier_handler"),
+	}
+}
+
+// GetAllSuppliers handles GET /api/v1/suppliers
+func (h *SupplierHandler) GetAllSuppliers(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	suppliers, err := h.supplierService.GetAllSuppliers()
+	if err != nil {
+		h.logger.Error("Failed to get suppliers", "error", err)
+		writeErrorResponse(w, http.StatusInternalServerError, "Failed to fetch suppliers")
+		reqCtx.StatusCode = http.StatusInternalServerError
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, suppliers)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetSupplier handles GET /api/v1/suppliers/{id}
+func (h *SupplierHandler) GetSupplier(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := extractIDFromPath(r)
+	supplier, err := h.supplierService.GetSupplier(id)
+	if err != nil {
+		h.logger.Warn("Supplier not found", "id", id, "error", err)
+		writeErrorResponse(w, http.StatusNotFound, err.Error())
+		reqCtx.StatusCode = http.StatusNotFound
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, supplier)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// CreateSupplier handles POST /api/v1/suppliers
+func (h *SupplierHandler) CreateSupplier(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	var req service.SupplierRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for create supplier", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	supplier, err := h.supplierService.CreateSupplier(req)
+	if err != nil {
+		h.logger.Warn("Failed to create supplier", "error", err)
+		statusCode := supplierErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, supplier)
+	reqCtx.StatusCode = http.StatusCreated
+	h.logger.LogResponse(reqCtx)
+}
+
+// UpdateSupplier handles PUT /api/v1/suppliers/{id}
+func (h *SupplierHandler) UpdateSupplier(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := extractIDFromPath(r)
+
+	var req service.SupplierRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for update supplier", "id", id, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	supplier, err := h.supplierService.UpdateSupplier(id, req)
+	if err != nil {
+		h.logger.Warn("Failed to update supplier", "id", id, "error", err)
+		statusCode := supplierErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, supplier)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// DeleteSupplier handles DELETE /api/v1/suppliers/{id}
+func (h *SupplierHandler) DeleteSupplier(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := extractIDFromPath(r)
+	if err := h.supplierService.DeleteSupplier(id); err != nil {
+		h.logger.Warn("Failed to delete supplier", "id", id, "error", err)
+		statusCode := supplierErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusNoContent, nil)
+	reqCtx.StatusCode = http.StatusNoContent
+	h.logger.LogResponse(reqCtx)
+}
+
+// supplierErrorStatus maps supplier service errors to HTTP status codes
+func supplierErrorStatus(err error) int {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "supplier with id") && strings.Contains(message, "not found"):
+		return http.StatusNotFound
+	case strings.Contains(message, "already exists"), strings.Contains(message, "cannot be deleted"):
+		return http.StatusConflict
+	case strings.Contains(message, "failed to"):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"frappuccino/internal/service"
+	"frappuccino/pkg/logger"
+)
+
+type TaxRateHandler struct {
+	taxService service.TaxServiceInterface
+	logger     *logger.Logger
+}
+
+func NewTaxRateHandler(taxService service.TaxServiceInterface, logger *logger.Logger) *TaxRateHandler {
+	return &TaxRateHandler{
+		taxService: taxService,
+		logger:     logger.WithComponent("tax_rate_handler"),
+	}
+}
+
+// GetTaxRates handles GET /api/v1/tax-rates
+func (h *TaxRateHandler) GetTaxRates(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	rates, err := h.taxService.GetAllTaxRates()
+	if err != nil {
+		h.logger.Error("Failed to get tax rates", "error", err)
+		statusCode := taxRateErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, rates)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// GetTaxRate handles GET /api/v1/tax-rates/{id}
+func (h *TaxRateHandler) GetTaxRate(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := extractIDFromPath(r)
+	rate, err := h.taxService.GetTaxRate(id)
+	if err != nil {
+		h.logger.Warn("Failed to get tax rate", "id", id, "error", err)
+		statusCode := taxRateErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, rate)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// CreateTaxRate handles POST /api/v1/tax-rates
+func (h *TaxRateHandler) CreateTaxRate(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	var req service.TaxRateRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for create tax rate", "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	rate, err := h.taxService.CreateTaxRate(req)
+	if err != nil {
+		h.logger.Warn("Failed to create tax rate", "error", err)
+		statusCode := taxRateErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusCreated, rate)
+	reqCtx.StatusCode = http.StatusCreated
+	h.logger.LogResponse(reqCtx)
+}
+
+// UpdateTaxRate handles PUT /api/v1/tax-rates/{id}
+func (h *TaxRateHandler) UpdateTaxRate(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := extractIDFromPath(r)
+
+	var req service.TaxRateRequest
+	if err := parseRequestBody(r, &req); err != nil {
+		h.logger.Warn("Invalid request body for update tax rate", "id", id, "error", err)
+		writeErrorResponse(w, http.StatusBadRequest, "Invalid request body")
+		reqCtx.StatusCode = http.StatusBadRequest
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	rate, err := h.taxService.UpdateTaxRate(id, req)
+	if err != nil {
+		h.logger.Warn("Failed to update tax rate", "id", id, "error", err)
+		statusCode := taxRateErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusOK, rate)
+	reqCtx.StatusCode = http.StatusOK
+	h.logger.LogResponse(reqCtx)
+}
+
+// DeleteTaxRate handles DELETE /api/v1/tax-rates/{id}
+func (h *TaxRateHandler) DeleteTaxRate(w http.ResponseWriter, r *http.Request) {
+	reqCtx := &logger.RequestContext{
+		Method:     r.Method,
+		Path:       r.URL.Path,
+		RemoteAddr: r.RemoteAddr,
+		StartTime:  time.Now(),
+	}
+	h.logger.LogRequest(reqCtx)
+
+	id := extractIDFromPath(r)
+	if err := h.taxService.DeleteTaxRate(id); err != nil {
+		h.logger.Warn("Failed to delete tax rate", "id", id, "error", err)
+		statusCode := taxRateErrorStatus(err)
+		writeErrorResponse(w, statusCode, err.Error())
+		reqCtx.StatusCode = statusCode
+		h.logger.LogResponse(reqCtx)
+		return
+	}
+
+	writeJSONResponse(w, http.StatusNoContent, nil)
+	reqCtx.StatusCode = http.StatusNoContent
+	h.logger.LogResponse(reqCtx)
+}
+
+// taxRateErrorStatus maps tax service errors to HTTP status codes
+func taxRateErrorStatus(err error) int {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "with id") && strings.Contains(message, "not found"):
+		return http.StatusNotFound
+	case strings.Contains(message, "already exists"):
+		return http.StatusConflict
+	case strings.Contains(message, "failed to"):
+		return http.StatusInternalServerError
+	default:
+		return http.StatusBadRequest
+	}
+}
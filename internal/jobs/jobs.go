@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"frappuccino/pkg/logger"
+)
+
+// RunPeriodically calls fn every interval until ctx is cancelled.
+// The first run happens immediately; errors are logged and do not stop the job.
+func RunPeriodically(ctx context.Context, name string, interval time.Duration, log *logger.Logger, fn func() error) {
+	log = log.WithComponent("job")
+
+	if interval <= 0 {
+		log.Info("Background job disabled", "job", name)
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			start := time.Now()
+			if err := fn(); err != nil {
+				log.Error("Background job failed", "job", name, "error", err)
+			} else {
+				log.Debug("Background job finished", "job", name, "duration", time.Since(start))
+			}
+
+			select {
+			case <-ctx.Done():
+				log.Info("Background job stopped", "job", name)
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	log.Info("Background job started", "job", name, "interval", interval)
+}
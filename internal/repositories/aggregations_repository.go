@@ -10,6 +10,7 @@ package repositories
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
@@ -23,6 +24,11 @@ type AggregationRepositoryInterface interface {
 	GetAggregationData() (orders []*models.Order, menuItems []*models.MenuItem, err error)
 	SearchFullText(query string, filters []string, minPrice, maxPrice *float64) (*SearchResult, error)
 	GetOrderedItemsByPeriod(period, month, year string) (*OrderedItemsByPeriodResult, error)
+	GetClosedOrderLines(from, to *time.Time) ([]ClosedOrderLine, error)
+	GetOrderConsumptionCosts(from, to *time.Time) (map[string]float64, error)
+	GetDiscountSummary(from, to *time.Time) ([]DiscountSummary, error)
+	GetTaxSummary(from, to *time.Time) ([]TaxSummary, error)
+	GetOrderServiceTimes(from, to *time.Time) ([]OrderServiceTime, error)
 }
 
 type AggregationRepository struct {
@@ -52,6 +58,49 @@ type OrderSearchResult struct {
 	Relevance    float64  `json:"relevance"`
 }
 
+// ClosedOrderLine is one order item of a closed order, priced at the time it was ordered
+type ClosedOrderLine struct {
+	OrderID        string
+	MenuItemID     string
+	Name           string
+	Category       string
+	Quantity       int
+	PriceAtTime    float64
+	DiscountAmount float64 // The line's share of the order's discounts
+	OrderTax       float64 // Tax on the whole order
+	TaxInclusive   bool    // Whether the order's prices contain its tax
+}
+
+// OrderServiceTime is when an order was placed and first reached each later status, nil for statuses it never reached
+type OrderServiceTime struct {
+	OrderID      string
+	CustomerName string
+	CreatedAt    time.Time
+	PreparingAt  *time.Time
+	ReadyAt      *time.Time
+	ClosedAt     *time.Time
+	Categories   []string // Distinct menu categories of the order's items
+	Items        int
+}
+
+// DiscountSummary totals the discounts a pricing rule or promo code gave on closed orders
+type DiscountSummary struct {
+	Name      string  `json:"name"`
+	PromoCode string  `json:"promo_code,omitempty"`
+	Orders    int     `json:"orders"`
+	Amount    float64 `json:"amount"`
+}
+
+// TaxSummary totals the tax owed on closed orders at one rate for one order type, for filing
+type TaxSummary struct {
+	Name      string  `json:"name"`
+	Rate      float64 `json:"rate"`
+	OrderType string  `json:"order_type"`
+	Orders    int     `json:"orders"`
+	NetAmount float64 `json:"net_amount"`
+	TaxAmount float64 `json:"tax_amount"`
+}
+
 type OrderedItemsByPeriodResult struct {
 	Period       string           `json:"period"`
 	Month        string           `json:"month,omitempty"`
@@ -71,7 +120,7 @@ func (r *AggregationRepository) GetAggregationData() (orders []*models.Order, me
 
 	ordersQuery := `
 		SELECT o.id, o.customer_name, o.special_instructions, o.status, 
-		       o.total_amount, o.created_at, o.updated_at
+		       o.total_amount, o.tax_amount, o.tax_inclusive, o.created_at, o.updated_at
 		FROM orders o
 		ORDER BY o.created_at DESC`
 
@@ -87,14 +136,14 @@ func (r *AggregationRepository) GetAggregationData() (orders []*models.Order, me
 		order := &models.Order{}
 		var specialInstructions sql.NullString
 
-		err := orderRows.Scan(&order.ID, &order.CustomerName, &specialInstructions, &order.Status, &order.TotalAmount, &order.CreatedAt, &order.UpdatedAt)
+		err := orderRows.Scan(&order.ID, &order.CustomerName, &specialInstructions, &order.Status, &order.TotalAmount, &order.TaxAmount, &order.TaxInclusive, &order.CreatedAt, &order.UpdatedAt)
 		if err != nil {
 			r.logger.Error("Failed to scan order", "error", err)
 			return nil, nil, fmt.Errorf("failed to scan order: %v", err)
 		}
 
 		if specialInstructions.Valid {
-			order.SpecialInstructions = specialInstructions.String
+			order.SpecialInstructions = json.RawMessage(specialInstructions.String)
 		}
 
 		order.Items = []models.OrderItem{}
@@ -104,7 +153,7 @@ func (r *AggregationRepository) GetAggregationData() (orders []*models.Order, me
 
 	itemsQuery := `
 		SELECT oi.id, oi.order_id, oi.menu_item_id, oi.quantity, 
-		       oi.price_at_time, oi.customizations
+		       oi.price_at_time, oi.discount_amount, oi.customizations
 		FROM order_items oi
 		WHERE oi.order_id = ANY($1)`
 
@@ -125,14 +174,14 @@ func (r *AggregationRepository) GetAggregationData() (orders []*models.Order, me
 			item := models.OrderItem{}
 			var customizations sql.NullString
 
-			err := itemRows.Scan(&item.ID, &item.OrderID, &item.MenuItemID, &item.Quantity, &item.PriceAtTime, &customizations)
+			err := itemRows.Scan(&item.ID, &item.OrderID, &item.MenuItemID, &item.Quantity, &item.PriceAtTime, &item.DiscountAmount, &customizations)
 			if err != nil {
 				r.logger.Error("Failed to scan order item", "error", err)
 				return nil, nil, fmt.Errorf("failed to scan order item: %v", err)
 			}
 
 			if customizations.Valid {
-				item.Customizations = customizations.String
+				item.Customizations = parseCustomizations(customizations.String)
 			}
 
 			item.ProductID = item.MenuItemID
@@ -145,7 +194,7 @@ func (r *AggregationRepository) GetAggregationData() (orders []*models.Order, me
 
 	menuQuery := `
 		SELECT m.id, m.name, m.description, m.category, m.price, 
-		       m.available, m.metadata, m.tags, m.allergens, 
+		       m.available, m.metadata, m.tags, ` + menuAllergensSQL + `,
 		       m.available_sizes, m.created_at, m.updated_at
 		FROM menu_items m
 		ORDER BY m.name`
@@ -174,6 +223,9 @@ func (r *AggregationRepository) GetAggregationData() (orders []*models.Order, me
 		if allergens.Valid && allergens.String != "" {
 			item.Allergens = parsePostgreSQLArray(allergens.String)
 		}
+		if availableSizes.Valid && availableSizes.String != "" {
+			item.Sizes = parsePostgreSQLArray(availableSizes.String)
+		}
 		if metadata.Valid {
 			item.CustomizationOptions = []byte(metadata.String)
 		}
@@ -183,7 +235,7 @@ func (r *AggregationRepository) GetAggregationData() (orders []*models.Order, me
 	}
 
 	ingredientsQuery := `
-		SELECT mii.menu_item_id, mii.ingredient_id, mii.required_quantity
+		SELECT mii.menu_item_id, mii.ingredient_id, mii.required_quantity, mii.unit
 		FROM menu_item_ingredients mii
 		WHERE mii.menu_item_id = ANY($1)`
 
@@ -200,10 +252,10 @@ func (r *AggregationRepository) GetAggregationData() (orders []*models.Order, me
 		defer ingredientRows.Close()
 
 		for ingredientRows.Next() {
-			var menuItemID, ingredientID string
+			var menuItemID, ingredientID, unit string
 			var quantity float64
 
-			err := ingredientRows.Scan(&menuItemID, &ingredientID, &quantity)
+			err := ingredientRows.Scan(&menuItemID, &ingredientID, &quantity, &unit)
 			if err != nil {
 				r.logger.Error("Failed to scan menu item ingredient", "error", err)
 				return nil, nil, fmt.Errorf("failed to scan menu item ingredient: %v", err)
@@ -213,6 +265,7 @@ func (r *AggregationRepository) GetAggregationData() (orders []*models.Order, me
 				ingredient := models.MenuItemIngredient{
 					IngredientID: ingredientID,
 					Quantity:     quantity,
+					Unit:         unit,
 				}
 				menuItem.Ingredients = append(menuItem.Ingredients, ingredient)
 			}
@@ -463,12 +516,100 @@ func (r *AggregationRepository) getOrderedItemsByMonth(year string, result *Orde
 	return result, nil
 }
 
+// GetClosedOrderLines returns the items of closed orders created in [from, to); nil bounds are open
+func (r *AggregationRepository) GetClosedOrderLines(from, to *time.Time) ([]ClosedOrderLine, error) {
+	r.logger.Debug("Fetching closed order lines", "from", from, "to", to)
+
+	query := `
+		SELECT o.id, oi.menu_item_id, mi.name, mi.category, oi.quantity, oi.price_at_time, oi.discount_amount,
+		       o.tax_amount, o.tax_inclusive
+		FROM orders o
+		JOIN order_items oi ON oi.order_id = o.id
+		JOIN menu_items mi ON mi.id = oi.menu_item_id
+		WHERE o.status = 'closed'
+		  AND ($1::timestamptz IS NULL OR o.created_at >= $1)
+		  AND ($2::timestamptz IS NULL OR o.created_at < $2)
+		ORDER BY o.created_at`
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		r.logger.Error("Failed to query closed order lines", "error", err)
+		return nil, fmt.Errorf("failed to query closed order lines: %v", err)
+	}
+	defer rows.Close()
+
+	var lines []ClosedOrderLine
+	for rows.Next() {
+		var line ClosedOrderLine
+		if err := rows.Scan(&line.OrderID, &line.MenuItemID, &line.Name, &line.Category, &line.Quantity, &line.PriceAtTime, &line.DiscountAmount, &line.OrderTax, &line.TaxInclusive); err != nil {
+			r.logger.Error("Failed to scan closed order line", "error", err)
+			return nil, fmt.Errorf("failed to scan closed order line: %v", err)
+		}
+		lines = append(lines, line)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating closed order lines", "error", err)
+		return nil, fmt.Errorf("error iterating closed order lines: %v", err)
+	}
+
+	r.logger.Debug("Fetched closed order lines", "count", len(lines))
+	return lines, nil
+}
+
+// GetOrderConsumptionCosts returns the net ingredient cost recorded in the inventory ledger
+// for each closed order created in [from, to). Orders without ledger rows are absent from the map.
+func (r *AggregationRepository) GetOrderConsumptionCosts(from, to *time.Time) (map[string]float64, error) {
+	r.logger.Debug("Fetching order consumption costs", "from", from, "to", to)
+
+	query := `
+		SELECT it.reference_id, SUM(-it.quantity_change * it.unit_cost)
+		FROM inventory_transactions it
+		JOIN orders o ON o.id = it.reference_id
+		WHERE it.reference_type = 'order'
+		  AND it.transaction_type IN ('usage', 'return')
+		  AND o.status = 'closed'
+		  AND ($1::timestamptz IS NULL OR o.created_at >= $1)
+		  AND ($2::timestamptz IS NULL OR o.created_at < $2)
+		GROUP BY it.reference_id`
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		r.logger.Error("Failed to query order consumption costs", "error", err)
+		return nil, fmt.Errorf("failed to query order consumption costs: %v", err)
+	}
+	defer rows.Close()
+
+	costs := make(map[string]float64)
+	for rows.Next() {
+		var orderID string
+		var cost float64
+		if err := rows.Scan(&orderID, &cost); err != nil {
+			r.logger.Error("Failed to scan order consumption cost", "error", err)
+			return nil, fmt.Errorf("failed to scan order consumption cost: %v", err)
+		}
+		costs[orderID] = cost
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating order consumption costs", "error", err)
+		return nil, fmt.Errorf("error iterating order consumption costs: %v", err)
+	}
+
+	r.logger.Debug("Fetched order consumption costs", "orders", len(costs))
+	return costs, nil
+}
+
+// parsePostgreSQLArray splits a text array literal. Elements containing spaces come back quoted; values
+// with commas, quotes or backslashes aren't stored in arrays read this way.
 func parsePostgreSQLArray(s string) []string {
 	s = strings.Trim(s, "{}")
 	if s == "" {
 		return []string{}
 	}
-	return strings.Split(s, ",")
+	elements := strings.Split(s, ",")
+	for i, element := range elements {
+		elements[i] = strings.Trim(element, `"`)
+	}
+	return elements
 }
 
 func contains(slice []string, item string) bool {
@@ -497,3 +638,130 @@ func getDaysInMonth(month, year int) int {
 	t := time.Date(year, time.Month(month+1), 0, 0, 0, 0, 0, time.UTC)
 	return t.Day()
 }
+
+// GetDiscountSummary totals the discounts given on closed orders created in [from, to) by rule name and promo code
+func (r *AggregationRepository) GetDiscountSummary(from, to *time.Time) ([]DiscountSummary, error) {
+	r.logger.Debug("Fetching discount summary", "from", from, "to", to)
+
+	query := `
+		SELECT od.name, COALESCE(od.promo_code, ''), COUNT(DISTINCT od.order_id), SUM(od.amount)
+		FROM order_discounts od
+		JOIN orders o ON o.id = od.order_id
+		WHERE o.status = 'closed'
+		  AND ($1::timestamptz IS NULL OR o.created_at >= $1)
+		  AND ($2::timestamptz IS NULL OR o.created_at < $2)
+		GROUP BY od.name, od.promo_code
+		ORDER BY SUM(od.amount) DESC, od.name`
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		r.logger.Error("Failed to query discount summary", "error", err)
+		return nil, fmt.Errorf("failed to query discount summary: %v", err)
+	}
+	defer rows.Close()
+
+	summary := []DiscountSummary{}
+	for rows.Next() {
+		var discount DiscountSummary
+		if err := rows.Scan(&discount.Name, &discount.PromoCode, &discount.Orders, &discount.Amount); err != nil {
+			r.logger.Error("Failed to scan discount summary", "error", err)
+			return nil, fmt.Errorf("failed to scan discount summary: %v", err)
+		}
+		summary = append(summary, discount)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating discount summary", "error", err)
+		return nil, fmt.Errorf("error iterating discount summary: %v", err)
+	}
+	return summary, nil
+}
+
+// GetTaxSummary totals the tax lines of closed orders created in [from, to) by rate and order type
+func (r *AggregationRepository) GetTaxSummary(from, to *time.Time) ([]TaxSummary, error) {
+	r.logger.Debug("Fetching tax summary", "from", from, "to", to)
+
+	query := `
+		SELECT ot.name, ot.rate, o.order_type, COUNT(DISTINCT ot.order_id), SUM(ot.net_amount), SUM(ot.tax_amount)
+		FROM order_taxes ot
+		JOIN orders o ON o.id = ot.order_id
+		WHERE o.status = 'closed'
+		  AND ($1::timestamptz IS NULL OR o.created_at >= $1)
+		  AND ($2::timestamptz IS NULL OR o.created_at < $2)
+		GROUP BY ot.name, ot.rate, o.order_type
+		ORDER BY ot.rate DESC, ot.name, o.order_type`
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		r.logger.Error("Failed to query tax summary", "error", err)
+		return nil, fmt.Errorf("failed to query tax summary: %v", err)
+	}
+	defer rows.Close()
+
+	summary := []TaxSummary{}
+	for rows.Next() {
+		var tax TaxSummary
+		if err := rows.Scan(&tax.Name, &tax.Rate, &tax.OrderType, &tax.Orders, &tax.NetAmount, &tax.TaxAmount); err != nil {
+			r.logger.Error("Failed to scan tax summary", "error", err)
+			return nil, fmt.Errorf("failed to scan tax summary: %v", err)
+		}
+		summary = append(summary, tax)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating tax summary", "error", err)
+		return nil, fmt.Errorf("error iterating tax summary: %v", err)
+	}
+	return summary, nil
+}
+
+// GetOrderServiceTimes returns the status timeline of every order created in [from, to) that wasn't cancelled,
+// from order_status_history
+func (r *AggregationRepository) GetOrderServiceTimes(from, to *time.Time) ([]OrderServiceTime, error) {
+	r.logger.Debug("Fetching order service times", "from", from, "to", to)
+
+	query := `
+		SELECT o.id, o.customer_name, o.created_at,
+		       MIN(h.changed_at) FILTER (WHERE h.new_status = 'preparing'),
+		       MIN(h.changed_at) FILTER (WHERE h.new_status = 'ready'),
+		       MIN(h.changed_at) FILTER (WHERE h.new_status = 'closed'),
+		       COALESCE((
+		           SELECT array_agg(DISTINCT mi.category ORDER BY mi.category)
+		           FROM order_items oi
+		           JOIN menu_items mi ON mi.id = oi.menu_item_id
+		           WHERE oi.order_id = o.id
+		       ), '{}'),
+		       COALESCE((SELECT SUM(oi.quantity) FROM order_items oi WHERE oi.order_id = o.id), 0)
+		FROM orders o
+		LEFT JOIN order_status_history h ON h.order_id = o.id
+		WHERE o.status != 'cancelled'
+		  AND ($1::timestamptz IS NULL OR o.created_at >= $1)
+		  AND ($2::timestamptz IS NULL OR o.created_at < $2)
+		GROUP BY o.id
+		ORDER BY o.created_at`
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		r.logger.Error("Failed to query order service times", "error", err)
+		return nil, fmt.Errorf("failed to query order service times: %v", err)
+	}
+	defer rows.Close()
+
+	var timings []OrderServiceTime
+	for rows.Next() {
+		var timing OrderServiceTime
+		var categories string
+		if err := rows.Scan(&timing.OrderID, &timing.CustomerName, &timing.CreatedAt, &timing.PreparingAt, &timing.ReadyAt, &timing.ClosedAt,
+			&categories, &timing.Items); err != nil {
+			r.logger.Error("Failed to scan order service time", "error", err)
+			return nil, fmt.Errorf("failed to scan order service time: %v", err)
+		}
+		timing.Categories = parsePostgreSQLArray(categories)
+		timings = append(timings, timing)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating order service times", "error", err)
+		return nil, fmt.Errorf("error iterating order service times: %v", err)
+	}
+
+	r.logger.Debug("Fetched order service times", "count", len(timings))
+	return timings, nil
+}
@@ -0,0 +1,203 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"frappuccino/models"
+	"frappuccino/pkg/database"
+	"frappuccino/pkg/logger"
+)
+
+type AlertRepositoryInterface interface {
+	OpenAlert(item *models.InventoryItem, alertType string) (*models.InventoryAlert, bool, error)
+	ResolveAlert(ingredientID, alertType string) (bool, error)
+	GetOpenAlerts() ([]*models.InventoryAlert, error)
+	GetUndeliveredAlerts(createdBefore time.Time) ([]*models.InventoryAlert, error)
+	GetDeliveredURLs(id string) (map[string]bool, error)
+	RecordDelivery(id, url string, attempts int, deliveredAt *time.Time, lastError string) error
+	MarkDelivered(id string, deliveredAt time.Time) error
+}
+
+type AlertRepository struct {
+	logger *logger.Logger
+	db     *database.DB
+}
+
+func NewAlertRepository(logger *logger.Logger, db *database.DB) *AlertRepository {
+	return &AlertRepository{
+		logger: logger.WithComponent("alert_repository"),
+		db:     db,
+	}
+}
+
+// OpenAlert raises an alert for the item unless one of the same type is already open.
+// The returned bool reports whether a new alert was created.
+func (r *AlertRepository) OpenAlert(item *models.InventoryItem, alertType string) (*models.InventoryAlert, bool, error) {
+	r.logger.Debug("Opening inventory alert", "ingredient_id", item.IngredientID, "alert_type", alertType)
+
+	query := `
+		INSERT INTO inventory_alerts (ingredient_id, alert_type, quantity, min_threshold)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (ingredient_id, alert_type) WHERE status = 'open' DO NOTHING
+		RETURNING id, status, created_at`
+
+	alert := &models.InventoryAlert{
+		IngredientID: item.IngredientID,
+		Name:         item.Name,
+		Unit:         item.Unit,
+		AlertType:    alertType,
+		Quantity:     item.Quantity,
+		MinThreshold: item.MinThreshold,
+	}
+
+	err := r.db.QueryRow(query, item.IngredientID, alertType, item.Quantity, item.MinThreshold).Scan(&alert.ID, &alert.Status, &alert.CreatedAt)
+	if err == sql.ErrNoRows {
+		r.logger.Debug("Inventory alert already open", "ingredient_id", item.IngredientID, "alert_type", alertType)
+		return nil, false, nil
+	}
+	if err != nil {
+		r.logger.Error("Failed to open inventory alert", "error", err, "ingredient_id", item.IngredientID)
+		return nil, false, fmt.Errorf("failed to open inventory alert: %v", err)
+	}
+
+	r.logger.Info("Opened inventory alert", "alert_id", alert.ID, "ingredient_id", item.IngredientID, "alert_type", alertType)
+	return alert, true, nil
+}
+
+// ResolveAlert closes the open alert of the given type for an ingredient, if any
+func (r *AlertRepository) ResolveAlert(ingredientID, alertType string) (bool, error) {
+	query := `
+		UPDATE inventory_alerts
+		SET status = 'resolved', resolved_at = CURRENT_TIMESTAMP
+		WHERE ingredient_id = $1 AND alert_type = $2 AND status = 'open'`
+
+	result, err := r.db.Exec(query, ingredientID, alertType)
+	if err != nil {
+		r.logger.Error("Failed to resolve inventory alert", "error", err, "ingredient_id", ingredientID)
+		return false, fmt.Errorf("failed to resolve inventory alert: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error("Failed to get rows affected", "error", err, "ingredient_id", ingredientID)
+		return false, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+
+	if rowsAffected > 0 {
+		r.logger.Info("Resolved inventory alert", "ingredient_id", ingredientID, "alert_type", alertType)
+	}
+	return rowsAffected > 0, nil
+}
+
+// GetOpenAlerts lists open alerts, newest first
+func (r *AlertRepository) GetOpenAlerts() ([]*models.InventoryAlert, error) {
+	r.logger.Debug("Fetching open inventory alerts")
+	return r.queryAlerts("WHERE a.status = 'open' ORDER BY a.created_at DESC")
+}
+
+// GetUndeliveredAlerts lists open alerts raised before the given time that some webhook has not received yet, oldest first
+func (r *AlertRepository) GetUndeliveredAlerts(createdBefore time.Time) ([]*models.InventoryAlert, error) {
+	r.logger.Debug("Fetching undelivered inventory alerts", "created_before", createdBefore)
+	return r.queryAlerts("WHERE a.status = 'open' AND a.delivered_at IS NULL AND a.created_at < $1 ORDER BY a.created_at", createdBefore)
+}
+
+func (r *AlertRepository) queryAlerts(where string, args ...interface{}) ([]*models.InventoryAlert, error) {
+	query := `
+		SELECT a.id, a.ingredient_id, i.name, i.unit, a.alert_type, a.status, a.quantity, a.min_threshold,
+			a.created_at, a.resolved_at, a.delivery_attempts, a.delivered_at, COALESCE(a.last_error, '')
+		FROM inventory_alerts a
+		JOIN inventory i ON i.id = a.ingredient_id
+		` + where
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query inventory alerts", "error", err)
+		return nil, fmt.Errorf("failed to query inventory alerts: %v", err)
+	}
+	defer rows.Close()
+
+	alerts := make([]*models.InventoryAlert, 0)
+	for rows.Next() {
+		alert := &models.InventoryAlert{}
+		var resolvedAt, deliveredAt sql.NullTime
+		err := rows.Scan(&alert.ID, &alert.IngredientID, &alert.Name, &alert.Unit, &alert.AlertType, &alert.Status,
+			&alert.Quantity, &alert.MinThreshold, &alert.CreatedAt, &resolvedAt, &alert.DeliveryAttempts, &deliveredAt, &alert.LastError)
+		if err != nil {
+			r.logger.Error("Failed to scan inventory alert", "error", err)
+			return nil, fmt.Errorf("failed to scan inventory alert: %v", err)
+		}
+		if resolvedAt.Valid {
+			alert.ResolvedAt = &resolvedAt.Time
+		}
+		if deliveredAt.Valid {
+			alert.DeliveredAt = &deliveredAt.Time
+		}
+		alerts = append(alerts, alert)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Error("Error iterating inventory alert rows", "error", err)
+		return nil, fmt.Errorf("error iterating inventory alert rows: %v", err)
+	}
+
+	r.logger.Debug("Fetched inventory alerts", "count", len(alerts))
+	return alerts, nil
+}
+
+// GetDeliveredURLs lists the receivers that have accepted the alert
+func (r *AlertRepository) GetDeliveredURLs(id string) (map[string]bool, error) {
+	rows, err := r.db.Query(`SELECT url FROM alert_deliveries WHERE alert_id = $1 AND delivered_at IS NOT NULL`, id)
+	if err != nil {
+		r.logger.Error("Failed to query alert deliveries", "error", err, "alert_id", id)
+		return nil, fmt.Errorf("failed to query alert deliveries: %v", err)
+	}
+	defer rows.Close()
+
+	urls := make(map[string]bool)
+	for rows.Next() {
+		var url string
+		if err := rows.Scan(&url); err != nil {
+			return nil, fmt.Errorf("failed to scan alert delivery: %v", err)
+		}
+		urls[url] = true
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating alert delivery rows: %v", err)
+	}
+	return urls, nil
+}
+
+// RecordDelivery stores the outcome of delivering an alert to one receiver, adding its attempts to earlier ones
+// for that receiver and for the alert as a whole
+func (r *AlertRepository) RecordDelivery(id, url string, attempts int, deliveredAt *time.Time, lastError string) error {
+	query := `
+		WITH receiver AS (
+			INSERT INTO alert_deliveries (alert_id, url, attempts, delivered_at, last_error)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+			ON CONFLICT (alert_id, url) DO UPDATE
+			SET attempts = alert_deliveries.attempts + EXCLUDED.attempts,
+			    delivered_at = EXCLUDED.delivered_at,
+			    last_error = EXCLUDED.last_error
+		)
+		UPDATE inventory_alerts
+		SET delivery_attempts = delivery_attempts + $3, last_error = COALESCE(NULLIF($5, ''), last_error)
+		WHERE id = $1`
+
+	if _, err := r.db.Exec(query, id, url, attempts, deliveredAt, lastError); err != nil {
+		r.logger.Error("Failed to record alert delivery", "error", err, "alert_id", id, "url", url)
+		return fmt.Errorf("failed to record alert delivery: %v", err)
+	}
+	return nil
+}
+
+// MarkDelivered records that every receiver has accepted the alert, so it is no longer redelivered
+func (r *AlertRepository) MarkDelivered(id string, deliveredAt time.Time) error {
+	query := `UPDATE inventory_alerts SET delivered_at = $1, last_error = NULL WHERE id = $2`
+	if _, err := r.db.Exec(query, deliveredAt, id); err != nil {
+		r.logger.Error("Failed to mark alert delivered", "error", err, "alert_id", id)
+		return fmt.Errorf("failed to mark alert delivered: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,272 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"frappuccino/models"
+	"frappuccino/pkg/database"
+	"frappuccino/pkg/logger"
+)
+
+type CustomerRepositoryInterface interface {
+	GetAll(search string) ([]*models.Customer, error)
+	GetByID(id string) (*models.Customer, error)
+	Create(customer *models.Customer) error
+	Update(customer *models.Customer) error
+	Delete(id string) error
+	Merge(sourceID, targetID string) (*models.Customer, error)
+}
+
+type CustomerRepository struct {
+	logger *logger.Logger
+	db     *database.DB
+}
+
+func NewCustomerRepository(logger *logger.Logger, db *database.DB) *CustomerRepository {
+	return &CustomerRepository{
+		logger: logger.WithComponent("customer_repository"),
+		db:     db,
+	}
+}
+
+const customerSelectQuery = `
+	SELECT id, name, COALESCE(phone, ''), COALESCE(email, ''), preferences, array_to_string(allergens, ','),
+	       created_at, updated_at
+	FROM customers`
+
+// GetAll retrieves customers by name, optionally those whose name, phone or email contains search
+func (r *CustomerRepository) GetAll(search string) ([]*models.Customer, error) {
+	r.logger.Debug("Retrieving customers", "search", search)
+
+	rows, err := r.db.Query(customerSelectQuery+`
+	WHERE $1 = '' OR name ILIKE '%' || $1 || '%' OR phone ILIKE '%' || $1 || '%' OR email ILIKE '%' || $1 || '%'
+	ORDER BY name, created_at`, search)
+	if err != nil {
+		r.logger.Error("Failed to query customers", "error", err)
+		return nil, fmt.Errorf("failed to query customers: %v", err)
+	}
+	defer rows.Close()
+
+	customers := []*models.Customer{}
+	for rows.Next() {
+		customer, err := scanCustomer(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan customer", "error", err)
+			return nil, err
+		}
+		customers = append(customers, customer)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating customers", "error", err)
+		return nil, fmt.Errorf("error iterating customers: %v", err)
+	}
+	return customers, nil
+}
+
+// GetByID retrieves a customer
+func (r *CustomerRepository) GetByID(id string) (*models.Customer, error) {
+	customer, err := scanCustomer(r.db.QueryRow(customerSelectQuery+` WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("customer with id %s not found", id)
+	}
+	if err != nil {
+		r.logger.Error("Failed to get customer", "error", err, "customer_id", id)
+		return nil, err
+	}
+	return customer, nil
+}
+
+// Create stores a new customer
+func (r *CustomerRepository) Create(customer *models.Customer) error {
+	r.logger.Debug("Creating customer", "name", customer.Name)
+
+	preferences, err := json.Marshal(customer.Preferences)
+	if err != nil {
+		return fmt.Errorf("failed to encode preferences: %v", err)
+	}
+
+	err = r.db.QueryRow(`
+		INSERT INTO customers (name, phone, email, preferences, allergens)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), $4, $5)
+		RETURNING id, created_at, updated_at`,
+		customer.Name, customer.Phone, customer.Email, preferences, "{"+strings.Join(customer.Allergens, ",")+"}",
+	).Scan(&customer.ID, &customer.CreatedAt, &customer.UpdatedAt)
+	if err != nil {
+		r.logger.Warn("Failed to create customer", "error", err)
+		return customerError(err, "create")
+	}
+
+	r.logger.Info("Created customer", "customer_id", customer.ID)
+	return nil
+}
+
+// Update replaces a customer's details
+func (r *CustomerRepository) Update(customer *models.Customer) error {
+	r.logger.Debug("Updating customer", "customer_id", customer.ID)
+
+	preferences, err := json.Marshal(customer.Preferences)
+	if err != nil {
+		return fmt.Errorf("failed to encode preferences: %v", err)
+	}
+
+	err = r.db.QueryRow(`
+		UPDATE customers
+		SET name = $2, phone = NULLIF($3, ''), email = NULLIF($4, ''), preferences = $5, allergens = $6
+		WHERE id = $1
+		RETURNING created_at, updated_at`,
+		customer.ID, customer.Name, customer.Phone, customer.Email, preferences, "{"+strings.Join(customer.Allergens, ",")+"}",
+	).Scan(&customer.CreatedAt, &customer.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("customer with id %s not found", customer.ID)
+	}
+	if err != nil {
+		r.logger.Warn("Failed to update customer", "error", err, "customer_id", customer.ID)
+		return customerError(err, "update")
+	}
+
+	r.logger.Info("Updated customer", "customer_id", customer.ID)
+	return nil
+}
+
+// Delete removes a customer; their orders stay as walk-ins under the customer_name they were placed with
+func (r *CustomerRepository) Delete(id string) error {
+	r.logger.Debug("Deleting customer", "customer_id", id)
+
+	result, err := r.db.Exec(`DELETE FROM customers WHERE id = $1`, id)
+	if err != nil {
+		r.logger.Error("Failed to delete customer", "error", err, "customer_id", id)
+		return fmt.Errorf("failed to delete customer: %v", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("customer with id %s not found", id)
+	}
+
+	r.logger.Info("Deleted customer", "customer_id", id)
+	return nil
+}
+
+// Merge folds a duplicate profile into the one being kept. The source's orders and loyalty ledger move to the target,
+// the target gains the source's allergens, preferences and contact details it doesn't have yet, and
+// the source is deleted.
+func (r *CustomerRepository) Merge(sourceID, targetID string) (*models.Customer, error) {
+	r.logger.Debug("Merging customers", "source_id", sourceID, "target_id", targetID)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Lock both profiles in a fixed order so concurrent merges can't deadlock
+	rows, err := tx.Query(`SELECT id FROM customers WHERE id IN ($1, $2) ORDER BY id FOR UPDATE`, sourceID, targetID)
+	if err != nil {
+		r.logger.Error("Failed to lock customers", "error", err)
+		return nil, fmt.Errorf("failed to lock customers: %v", err)
+	}
+	found := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to lock customers: %v", err)
+		}
+		found[id] = true
+	}
+	rows.Close()
+	for _, id := range []string{sourceID, targetID} {
+		if !found[id] {
+			return nil, fmt.Errorf("customer with id %s not found", id)
+		}
+	}
+
+	source, err := scanCustomer(tx.QueryRow(customerSelectQuery+` WHERE id = $1`, sourceID))
+	if err != nil {
+		r.logger.Error("Failed to read merged customer", "error", err, "customer_id", sourceID)
+		return nil, fmt.Errorf("failed to read customer: %v", err)
+	}
+	sourcePreferences, err := json.Marshal(source.Preferences)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode preferences: %v", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE orders SET customer_id = $2 WHERE customer_id = $1`, sourceID, targetID); err != nil {
+		r.logger.Error("Failed to move customer orders", "error", err, "source_id", sourceID)
+		return nil, fmt.Errorf("failed to move customer orders: %v", err)
+	}
+	if _, err := tx.Exec(`UPDATE loyalty_ledger SET customer_id = $2 WHERE customer_id = $1`, sourceID, targetID); err != nil {
+		r.logger.Error("Failed to move customer loyalty", "error", err, "source_id", sourceID)
+		return nil, fmt.Errorf("failed to move customer loyalty: %v", err)
+	}
+
+	// The source goes first so its phone and email are free for the target
+	if _, err := tx.Exec(`DELETE FROM customers WHERE id = $1`, sourceID); err != nil {
+		r.logger.Error("Failed to delete merged customer", "error", err, "source_id", sourceID)
+		return nil, fmt.Errorf("failed to delete merged customer: %v", err)
+	}
+
+	_, err = tx.Exec(`
+		UPDATE customers
+		SET phone = COALESCE(phone, NULLIF($2, '')),
+		    email = COALESCE(email, NULLIF($3, '')),
+		    preferences = $4::jsonb || preferences,
+		    allergens = ARRAY(SELECT DISTINCT unnest(allergens || $5::text[]) ORDER BY 1)
+		WHERE id = $1`,
+		targetID, source.Phone, source.Email, sourcePreferences, "{"+strings.Join(source.Allergens, ",")+"}")
+	if err != nil {
+		r.logger.Error("Failed to update merged customer", "error", err, "target_id", targetID)
+		return nil, fmt.Errorf("failed to update merged customer: %v", err)
+	}
+
+	target, err := scanCustomer(tx.QueryRow(customerSelectQuery+` WHERE id = $1`, targetID))
+	if err != nil {
+		r.logger.Error("Failed to read merged customer", "error", err, "customer_id", targetID)
+		return nil, fmt.Errorf("failed to read customer: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	r.logger.Info("Merged customers", "source_id", sourceID, "target_id", targetID)
+	return target, nil
+}
+
+func scanCustomer(row interface{ Scan(...any) error }) (*models.Customer, error) {
+	customer := &models.Customer{}
+	var preferences []byte
+	var allergens string
+
+	err := row.Scan(&customer.ID, &customer.Name, &customer.Phone, &customer.Email, &preferences, &allergens,
+		&customer.CreatedAt, &customer.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan customer: %v", err)
+	}
+
+	customer.Preferences = map[string]string{}
+	if err := json.Unmarshal(preferences, &customer.Preferences); err != nil {
+		return nil, fmt.Errorf("failed to decode customer preferences: %v", err)
+	}
+	customer.Allergens = parsePostgreSQLArray(allergens)
+	return customer, nil
+}
+
+// customerError turns unique violations on phone or email into readable conflicts
+func customerError(err error, action string) error {
+	message := err.Error()
+	switch {
+	case strings.Contains(message, "idx_customers_phone"):
+		return fmt.Errorf("customer with this phone already exists")
+	case strings.Contains(message, "idx_customers_email"):
+		return fmt.Errorf("customer with this email already exists")
+	default:
+		return fmt.Errorf("failed to %s customer: %v", action, err)
+	}
+}
@@ -0,0 +1,405 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"frappuccino/models"
+	"frappuccino/pkg/database"
+	"frappuccino/pkg/logger"
+)
+
+type DrawerRepositoryInterface interface {
+	GetAll(status string) ([]*models.DrawerSession, error)
+	GetByID(id string) (*models.DrawerSession, error)
+	Open(session *models.DrawerSession) error
+	AddMovement(movement *models.DrawerMovement) error
+	Close(id, closedBy string, countedCash float64) (*models.ZReport, error)
+	GetZReport(sessionID string) (*models.ZReport, error)
+}
+
+type DrawerRepository struct {
+	logger *logger.Logger
+	db     *database.DB
+}
+
+func NewDrawerRepository(logger *logger.Logger, db *database.DB) *DrawerRepository {
+	return &DrawerRepository{
+		logger: logger.WithComponent("drawer_repository"),
+		db:     db,
+	}
+}
+
+const drawerSessionSelectQuery = `
+	SELECT id, status, opened_by, opening_float, opened_at, COALESCE(closed_by, ''), counted_cash, closed_at
+	FROM drawer_sessions`
+
+// GetAll retrieves drawer sessions, optionally filtered by status, newest first
+func (r *DrawerRepository) GetAll(status string) ([]*models.DrawerSession, error) {
+	r.logger.Debug("Retrieving drawer sessions", "status", status)
+
+	rows, err := r.db.Query(drawerSessionSelectQuery+`
+	WHERE ($1 = '' OR status = $1)
+	ORDER BY opened_at DESC`, status)
+	if err != nil {
+		r.logger.Error("Failed to query drawer sessions", "error", err)
+		return nil, fmt.Errorf("failed to query drawer sessions: %v", err)
+	}
+	defer rows.Close()
+
+	sessions := []*models.DrawerSession{}
+	for rows.Next() {
+		session, err := scanDrawerSession(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan drawer session", "error", err)
+			return nil, err
+		}
+		sessions = append(sessions, session)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating drawer sessions", "error", err)
+		return nil, fmt.Errorf("error iterating drawer sessions: %v", err)
+	}
+	return sessions, nil
+}
+
+// GetByID retrieves a drawer session with its paid-in and paid-out movements
+func (r *DrawerRepository) GetByID(id string) (*models.DrawerSession, error) {
+	session, err := scanDrawerSession(r.db.QueryRow(drawerSessionSelectQuery+` WHERE id = $1`, id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("drawer session with id %s not found", id)
+	}
+	if err != nil {
+		r.logger.Error("Failed to get drawer session", "error", err, "session_id", id)
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT id, session_id, type, amount, reason, COALESCE(created_by, ''), created_at
+		FROM drawer_movements
+		WHERE session_id = $1
+		ORDER BY created_at, id`, id)
+	if err != nil {
+		r.logger.Error("Failed to query drawer movements", "error", err, "session_id", id)
+		return nil, fmt.Errorf("failed to query drawer movements: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var movement models.DrawerMovement
+		if err := rows.Scan(&movement.ID, &movement.SessionID, &movement.Type, &movement.Amount, &movement.Reason,
+			&movement.CreatedBy, &movement.CreatedAt); err != nil {
+			r.logger.Error("Failed to scan drawer movement", "error", err)
+			return nil, fmt.Errorf("failed to scan drawer movement: %v", err)
+		}
+		session.Movements = append(session.Movements, movement)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating drawer movements", "error", err)
+		return nil, fmt.Errorf("error iterating drawer movements: %v", err)
+	}
+	return session, nil
+}
+
+// Open starts a drawer session; only one can be open at a time
+func (r *DrawerRepository) Open(session *models.DrawerSession) error {
+	r.logger.Debug("Opening drawer session", "opened_by", session.OpenedBy)
+
+	err := r.db.QueryRow(`
+		INSERT INTO drawer_sessions (opened_by, opening_float)
+		VALUES ($1, $2)
+		RETURNING id, status, opened_at`,
+		session.OpenedBy, session.OpeningFloat,
+	).Scan(&session.ID, &session.Status, &session.OpenedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value") {
+			r.logger.Warn("Attempted to open a second drawer session", "error", err)
+			return fmt.Errorf("drawer session is already open, close it first")
+		}
+		r.logger.Error("Failed to open drawer session", "error", err)
+		return fmt.Errorf("failed to open drawer session: %v", err)
+	}
+
+	session.Movements = []models.DrawerMovement{}
+	r.logger.Info("Opened drawer session", "session_id", session.ID)
+	return nil
+}
+
+// AddMovement records cash paid into or out of an open drawer session
+func (r *DrawerRepository) AddMovement(movement *models.DrawerMovement) error {
+	r.logger.Debug("Recording drawer movement", "session_id", movement.SessionID, "type", movement.Type)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := r.lockOpenSession(tx, movement.SessionID, "paid into or out of"); err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO drawer_movements (session_id, type, amount, reason, created_by)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		RETURNING id, created_at`,
+		movement.SessionID, movement.Type, movement.Amount, movement.Reason, movement.CreatedBy,
+	).Scan(&movement.ID, &movement.CreatedAt)
+	if err != nil {
+		r.logger.Error("Failed to insert drawer movement", "error", err, "session_id", movement.SessionID)
+		return fmt.Errorf("failed to insert drawer movement: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	r.logger.Info("Recorded drawer movement", "movement_id", movement.ID, "session_id", movement.SessionID, "amount", movement.Amount)
+	return nil
+}
+
+// Close closes an open drawer session with the counted cash and writes its Z-report in the same
+// transaction. The report covers payments, refunds and orders closed or cancelled while the session was open.
+func (r *DrawerRepository) Close(id, closedBy string, countedCash float64) (*models.ZReport, error) {
+	r.logger.Debug("Closing drawer session", "session_id", id)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	session, err := r.lockOpenSession(tx, id, "closed")
+	if err != nil {
+		return nil, err
+	}
+
+	report := &models.ZReport{
+		SessionID:    id,
+		OpenedBy:     session.OpenedBy,
+		ClosedBy:     closedBy,
+		OpenedAt:     session.OpenedAt,
+		OpeningFloat: session.OpeningFloat,
+		CountedCash:  countedCash,
+	}
+	err = tx.QueryRow(`
+		UPDATE drawer_sessions
+		SET status = 'closed', closed_by = $2, counted_cash = $3, closed_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+		RETURNING closed_at`, id, closedBy, countedCash).Scan(&report.ClosedAt)
+	if err != nil {
+		r.logger.Error("Failed to close drawer session", "error", err, "session_id", id)
+		return nil, fmt.Errorf("failed to close drawer session: %v", err)
+	}
+
+	if err := r.collectZReport(tx, report); err != nil {
+		r.logger.Error("Failed to build z-report", "error", err, "session_id", id)
+		return nil, err
+	}
+
+	reportJSON, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode z-report: %v", err)
+	}
+	err = tx.QueryRow(`
+		INSERT INTO z_reports (session_id, report)
+		VALUES ($1, $2)
+		RETURNING id, created_at`, id, reportJSON).Scan(&report.ID, &report.CreatedAt)
+	if err != nil {
+		r.logger.Error("Failed to insert z-report", "error", err, "session_id", id)
+		return nil, fmt.Errorf("failed to insert z-report: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	r.logger.Info("Closed drawer session", "session_id", id, "z_report_id", report.ID, "cash_variance", report.CashVariance)
+	return report, nil
+}
+
+// GetZReport returns the Z-report written when a drawer session was closed
+func (r *DrawerRepository) GetZReport(sessionID string) (*models.ZReport, error) {
+	var report models.ZReport
+	var reportJSON []byte
+	err := r.db.QueryRow(`SELECT id, report, created_at FROM z_reports WHERE session_id = $1`, sessionID).
+		Scan(&report.ID, &reportJSON, &report.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("z-report for drawer session with id %s not found", sessionID)
+	}
+	if err != nil {
+		r.logger.Error("Failed to get z-report", "error", err, "session_id", sessionID)
+		return nil, fmt.Errorf("failed to get z-report: %v", err)
+	}
+
+	id, createdAt := report.ID, report.CreatedAt
+	if err := json.Unmarshal(reportJSON, &report); err != nil {
+		r.logger.Error("Failed to decode z-report", "error", err, "session_id", sessionID)
+		return nil, fmt.Errorf("failed to decode z-report: %v", err)
+	}
+	report.ID, report.CreatedAt = id, createdAt
+	return &report, nil
+}
+
+// collectZReport fills in the sales, refunds, discounts, taxes, order counts and cash figures
+// between report.OpenedAt and report.ClosedAt
+func (r *DrawerRepository) collectZReport(tx *sql.Tx, report *models.ZReport) error {
+	from, to := report.OpenedAt, report.ClosedAt
+
+	rows, err := tx.Query(`
+		WITH paid AS (
+			SELECT tender, COUNT(*) AS payments, SUM(amount) AS amount, SUM(tip) AS tips
+			FROM payments
+			WHERE created_at >= $1 AND created_at <= $2
+			GROUP BY tender
+		), refunded AS (
+			SELECT p.tender, COUNT(*) AS refunds, SUM(rf.amount) AS amount
+			FROM refunds rf
+			JOIN payments p ON p.id = rf.payment_id
+			WHERE rf.created_at >= $1 AND rf.created_at <= $2
+			GROUP BY p.tender
+		)
+		SELECT COALESCE(paid.tender, refunded.tender), COALESCE(paid.payments, 0), COALESCE(paid.amount, 0),
+		       COALESCE(paid.tips, 0), COALESCE(refunded.refunds, 0), COALESCE(refunded.amount, 0)
+		FROM paid
+		FULL JOIN refunded ON refunded.tender = paid.tender
+		ORDER BY 1`, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to query tender totals: %v", err)
+	}
+	report.Tenders = []models.ZReportTender{}
+	cashTaken := 0.0
+	for rows.Next() {
+		var tender models.ZReportTender
+		var refundCount int
+		if err := rows.Scan(&tender.Tender, &tender.Payments, &tender.Amount, &tender.Tips, &refundCount, &tender.Refunds); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan tender totals: %v", err)
+		}
+		tender.Net = roundCents(tender.Amount + tender.Tips - tender.Refunds)
+		report.Sales += tender.Amount - tender.Refunds
+		report.Tips += tender.Tips
+		report.Refunds += tender.Refunds
+		report.RefundCount += refundCount
+		if tender.Tender == models.TenderCash {
+			cashTaken = tender.Net
+		}
+		report.Tenders = append(report.Tenders, tender)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating tender totals: %v", err)
+	}
+
+	// Orders count towards the session they were closed or cancelled in
+	err = tx.QueryRow(`
+		WITH finished AS (
+			SELECT DISTINCT ON (h.order_id) h.order_id, h.new_status
+			FROM order_status_history h
+			WHERE h.new_status IN ('closed', 'cancelled') AND h.changed_at >= $1 AND h.changed_at <= $2
+			ORDER BY h.order_id, h.changed_at DESC
+		)
+		SELECT COUNT(*) FILTER (WHERE f.new_status = 'closed'),
+		       COUNT(*) FILTER (WHERE f.new_status = 'cancelled'),
+		       COALESCE(SUM(o.discount_amount) FILTER (WHERE f.new_status = 'closed'), 0)
+		FROM finished f
+		JOIN orders o ON o.id = f.order_id`, from, to).Scan(&report.OrdersClosed, &report.OrdersCancelled, &report.Discounts)
+	if err != nil {
+		return fmt.Errorf("failed to count orders: %v", err)
+	}
+
+	rows, err = tx.Query(`
+		SELECT ot.name, ot.rate, SUM(ot.net_amount), SUM(ot.tax_amount)
+		FROM order_taxes ot
+		WHERE ot.order_id IN (
+			SELECT h.order_id FROM order_status_history h
+			WHERE h.new_status = 'closed' AND h.changed_at >= $1 AND h.changed_at <= $2
+		)
+		GROUP BY ot.name, ot.rate
+		ORDER BY ot.rate DESC, ot.name`, from, to)
+	if err != nil {
+		return fmt.Errorf("failed to query taxes: %v", err)
+	}
+	defer rows.Close()
+	report.Taxes = []models.ZReportTax{}
+	for rows.Next() {
+		var tax models.ZReportTax
+		if err := rows.Scan(&tax.Name, &tax.Rate, &tax.NetAmount, &tax.TaxAmount); err != nil {
+			return fmt.Errorf("failed to scan taxes: %v", err)
+		}
+		report.TaxTotal += tax.TaxAmount
+		report.Taxes = append(report.Taxes, tax)
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating taxes: %v", err)
+	}
+
+	err = tx.QueryRow(`
+		SELECT COALESCE(SUM(amount) FILTER (WHERE type = 'paid_in'), 0),
+		       COALESCE(SUM(amount) FILTER (WHERE type = 'paid_out'), 0)
+		FROM drawer_movements
+		WHERE session_id = $1`, report.SessionID).Scan(&report.PaidIn, &report.PaidOut)
+	if err != nil {
+		return fmt.Errorf("failed to sum drawer movements: %v", err)
+	}
+
+	report.Sales = roundCents(report.Sales)
+	report.Tips = roundCents(report.Tips)
+	report.Refunds = roundCents(report.Refunds)
+	report.TaxTotal = roundCents(report.TaxTotal)
+	report.ExpectedCash = roundCents(report.OpeningFloat + cashTaken + report.PaidIn - report.PaidOut)
+	report.CashVariance = roundCents(report.CountedCash - report.ExpectedCash)
+	return nil
+}
+
+// lockOpenSession locks a drawer session for the rest of the transaction, failing unless it is open
+func (r *DrawerRepository) lockOpenSession(tx *sql.Tx, id, action string) (*models.DrawerSession, error) {
+	session, err := scanDrawerSession(tx.QueryRow(drawerSessionSelectQuery+` WHERE id = $1 FOR UPDATE`, id))
+	if err == sql.ErrNoRows {
+		r.logger.Warn("Drawer session not found", "session_id", id)
+		return nil, fmt.Errorf("drawer session with id %s not found", id)
+	}
+	if err != nil {
+		r.logger.Error("Failed to lock drawer session", "error", err, "session_id", id)
+		return nil, fmt.Errorf("failed to lock drawer session: %v", err)
+	}
+	if session.Status != models.DrawerOpen {
+		r.logger.Warn("Drawer session is not open", "session_id", id, "status", session.Status, "action", action)
+		return nil, fmt.Errorf("drawer session is %s, only open sessions can be %s", session.Status, action)
+	}
+	return session, nil
+}
+
+func scanDrawerSession(row interface{ Scan(...any) error }) (*models.DrawerSession, error) {
+	session := &models.DrawerSession{Movements: []models.DrawerMovement{}}
+	var countedCash sql.NullFloat64
+	var closedAt sql.NullTime
+
+	err := row.Scan(&session.ID, &session.Status, &session.OpenedBy, &session.OpeningFloat, &session.OpenedAt,
+		&session.ClosedBy, &countedCash, &closedAt)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan drawer session: %v", err)
+	}
+
+	if countedCash.Valid {
+		session.CountedCash = &countedCash.Float64
+	}
+	if closedAt.Valid {
+		session.ClosedAt = &closedAt.Time
+	}
+	return session, nil
+}
+
+func roundCents(value float64) float64 {
+	return math.Round(value*100) / 100
+}
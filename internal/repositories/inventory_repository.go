@@ -13,6 +13,7 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
 	"strings"
 
 	"frappuccino/models"
@@ -28,9 +29,12 @@ type InventoryRepositoryInterface interface {
 	Add(item *models.InventoryItem) error
 	GetByID(id string) (*models.InventoryItem, error)
 	Delete(id string) error
-	GetLeftOvers(sortBy string, page, pageSize int) ([]*models.InventoryItem, int, error)
+	GetLeftOvers(sortBy string, page, pageSize, usageWindowDays int) ([]*models.InventoryStockLevel, int, error)
+	GetValuation() (*models.InventoryValuation, error)
+	GetUsageRates(windowDays int) (map[string]float64, error)
 	CheckInventoryAvailability(requirements map[string]float64) (map[string]*models.InventoryItem, error)
 	BatchUpdateInventory(updates map[string]float64) ([]models.InventoryUpdateResult, error)
+	ApplyTransactions(transactions []*models.InventoryTransaction) error
 }
 
 // Add adds a new inventory item
@@ -42,14 +46,27 @@ func (r *InventoryRepository) Add(item *models.InventoryItem) error {
 		return err
 	}
 
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
 	query := `
-		INSERT INTO inventory (name, quantity, unit, min_threshold) 
-		VALUES ($1, $2, $3, $4)
+		INSERT INTO inventory (name, quantity, unit, min_threshold, cost_per_unit, shelf_life_days,
+		                       allergens, kcal_per_unit, sugar_g_per_unit, fat_g_per_unit, caffeine_mg_per_unit)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING id
 	`
 
 	var generatedID string
-	err := r.db.QueryRow(query, item.Name, item.Quantity, item.Unit, item.MinThreshold).Scan(&generatedID)
+	err = tx.QueryRow(query, item.Name, item.Quantity, item.Unit, item.MinThreshold, item.CostPerUnit, item.ShelfLifeDays,
+		"{"+strings.Join(item.Allergens, ",")+"}", item.Nutrition.Kcal, item.Nutrition.SugarG, item.Nutrition.FatG, item.Nutrition.CaffeineMg).Scan(&generatedID)
 	if err != nil {
 		// Check if this is a duplicate key error (PostgreSQL constraint violation)
 		if strings.Contains(err.Error(), "duplicate key value") || strings.Contains(err.Error(), "violates unique constraint") {
@@ -60,6 +77,19 @@ func (r *InventoryRepository) Add(item *models.InventoryItem) error {
 		return fmt.Errorf("failed to add inventory item: %v", err)
 	}
 
+	// Opening stock becomes the first lot
+	if item.Quantity > 0 {
+		if _, err = addLot(tx, generatedID, item.Quantity, item.CostPerUnit, nil, "", ""); err != nil {
+			r.logger.Error("Failed to add opening lot", "error", err, "item_name", item.Name)
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit inventory item", "error", err, "item_name", item.Name)
+		return fmt.Errorf("failed to add inventory item: %v", err)
+	}
+
 	// Update the item with the generated ID
 	item.IngredientID = generatedID
 
@@ -72,20 +102,32 @@ func (r *InventoryRepository) GetByID(id string) (*models.InventoryItem, error)
 	r.logger.Debug("Retrieving inventory item from database", "item_id", id)
 
 	query := `
-		SELECT id, name, quantity, unit, min_threshold 
-		FROM inventory 
-		WHERE id = $1
+		SELECT i.id, i.name, i.quantity, i.unit, i.min_threshold, i.cost_per_unit, i.shelf_life_days,
+			i.allergens, i.kcal_per_unit, i.sugar_g_per_unit, i.fat_g_per_unit, i.caffeine_mg_per_unit,
+			` + reservedStockSQL + `, ` + usableStockSQL + ` - ` + reservedStockSQL + `
+		FROM inventory i
+		WHERE i.id = $1
 	`
 
 	row := r.db.QueryRow(query, id)
 
 	item := &models.InventoryItem{}
+	var allergens string
 	err := row.Scan(
 		&item.IngredientID,
 		&item.Name,
 		&item.Quantity,
 		&item.Unit,
 		&item.MinThreshold,
+		&item.CostPerUnit,
+		&item.ShelfLifeDays,
+		&allergens,
+		&item.Nutrition.Kcal,
+		&item.Nutrition.SugarG,
+		&item.Nutrition.FatG,
+		&item.Nutrition.CaffeineMg,
+		&item.Reserved,
+		&item.Available,
 	)
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -96,6 +138,8 @@ func (r *InventoryRepository) GetByID(id string) (*models.InventoryItem, error)
 		return nil, fmt.Errorf("failed to retrieve inventory item: %v", err)
 	}
 
+	item.Allergens = parsePostgreSQLArray(allergens)
+
 	r.logger.Debug("Retrieved inventory item", "item_id", id, "name", item.Name)
 	return item, nil
 }
@@ -149,9 +193,11 @@ func (r *InventoryRepository) GetAll() ([]*models.InventoryItem, error) {
 	r.logger.Debug("Retrieving all inventory items from database")
 
 	query := `
-		SELECT id, name, quantity, unit, min_threshold 
-		FROM inventory 
-		ORDER BY name
+		SELECT i.id, i.name, i.quantity, i.unit, i.min_threshold, i.cost_per_unit, i.shelf_life_days,
+			i.allergens, i.kcal_per_unit, i.sugar_g_per_unit, i.fat_g_per_unit, i.caffeine_mg_per_unit,
+			` + reservedStockSQL + `, ` + usableStockSQL + ` - ` + reservedStockSQL + `
+		FROM inventory i
+		ORDER BY i.name
 	`
 
 	rows, err := r.db.Query(query)
@@ -164,17 +210,28 @@ func (r *InventoryRepository) GetAll() ([]*models.InventoryItem, error) {
 	var items []*models.InventoryItem
 	for rows.Next() {
 		item := &models.InventoryItem{}
+		var allergens string
 		err := rows.Scan(
 			&item.IngredientID,
 			&item.Name,
 			&item.Quantity,
 			&item.Unit,
 			&item.MinThreshold,
+			&item.CostPerUnit,
+			&item.ShelfLifeDays,
+			&allergens,
+			&item.Nutrition.Kcal,
+			&item.Nutrition.SugarG,
+			&item.Nutrition.FatG,
+			&item.Nutrition.CaffeineMg,
+			&item.Reserved,
+			&item.Available,
 		)
 		if err != nil {
 			r.logger.Error("Failed to scan inventory item", "error", err)
 			return nil, fmt.Errorf("failed to scan inventory item: %v", err)
 		}
+		item.Allergens = parsePostgreSQLArray(allergens)
 		items = append(items, item)
 	}
 
@@ -198,45 +255,93 @@ func (r *InventoryRepository) Update(id string, item *models.InventoryItem) erro
 	// Ensure the item ID matches the parameter
 	item.IngredientID = id
 
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	var previousQuantity float64
+	err = tx.QueryRow(`SELECT quantity FROM inventory WHERE id = $1 FOR UPDATE`, id).Scan(&previousQuantity)
+	if err == sql.ErrNoRows {
+		r.logger.Warn("Attempted to update non-existent inventory item", "item_id", id)
+		return fmt.Errorf("inventory item with id %s not found", id)
+	}
+	if err != nil {
+		r.logger.Error("Failed to lock inventory item", "error", err, "item_id", id)
+		return fmt.Errorf("failed to update inventory item: %v", err)
+	}
+
 	query := `
 		UPDATE inventory 
-		SET name = $1, quantity = $2, unit = $3, min_threshold = $4
-		WHERE id = $5
+		SET name = $1, quantity = $2, unit = $3, min_threshold = $4, cost_per_unit = $5, shelf_life_days = $6,
+		    allergens = $8, kcal_per_unit = $9, sugar_g_per_unit = $10, fat_g_per_unit = $11, caffeine_mg_per_unit = $12
+		WHERE id = $7
 	`
 
-	result, err := r.db.Exec(query, item.Name, item.Quantity, item.Unit, item.MinThreshold, id)
+	_, err = tx.Exec(query, item.Name, item.Quantity, item.Unit, item.MinThreshold, item.CostPerUnit, item.ShelfLifeDays, id,
+		"{"+strings.Join(item.Allergens, ",")+"}", item.Nutrition.Kcal, item.Nutrition.SugarG, item.Nutrition.FatG, item.Nutrition.CaffeineMg)
 	if err != nil {
 		r.logger.Error("Failed to update inventory item", "error", err, "item_id", id)
 		return fmt.Errorf("failed to update inventory item: %v", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		r.logger.Error("Failed to get rows affected", "error", err, "item_id", id)
-		return fmt.Errorf("failed to get rows affected: %v", err)
+	// A changed quantity is a manual correction: book it against the lots and the ledger
+	if delta := item.Quantity - previousQuantity; math.Abs(delta) > lotEpsilon {
+		transaction := &models.InventoryTransaction{
+			IngredientID:    id,
+			TransactionType: models.TransactionAdjustment,
+			QuantityChange:  delta,
+			QuantityBefore:  previousQuantity,
+			QuantityAfter:   item.Quantity,
+			UnitCost:        item.CostPerUnit,
+			Notes:           "Manual inventory update",
+		}
+		if delta > 0 {
+			_, err = addLot(tx, id, delta, item.CostPerUnit, nil, "", "")
+		} else {
+			transaction.UnitCost, err = drawLots(tx, id, -delta, true)
+		}
+		if err != nil {
+			r.logger.Error("Failed to adjust inventory lots", "error", err, "item_id", id)
+			return err
+		}
+		if err = insertTransaction(tx, transaction); err != nil {
+			r.logger.Error("Failed to record inventory adjustment", "error", err, "item_id", id)
+			return err
+		}
 	}
 
-	if rowsAffected == 0 {
-		r.logger.Warn("Attempted to update non-existent inventory item", "item_id", id)
-		return fmt.Errorf("inventory item with id %s not found", id)
+	if err = tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit inventory update", "error", err, "item_id", id)
+		return fmt.Errorf("failed to update inventory item: %v", err)
 	}
 
 	r.logger.Info("Updated inventory item", "item_id", id, "name", item.Name)
 	return nil
 }
 
-// GetLeftOvers retrieves inventory items with pagination and sorting
-func (r *InventoryRepository) GetLeftOvers(sortBy string, page, pageSize int) ([]*models.InventoryItem, int, error) {
-	r.logger.Debug("Retrieving inventory leftovers", "sortBy", sortBy, "page", page, "pageSize", pageSize)
+// GetLeftOvers retrieves inventory stock levels with pagination and sorting.
+// Daily usage is the net usage recorded in the ledger over the last usageWindowDays.
+func (r *InventoryRepository) GetLeftOvers(sortBy string, page, pageSize, usageWindowDays int) ([]*models.InventoryStockLevel, int, error) {
+	r.logger.Debug("Retrieving inventory leftovers", "sortBy", sortBy, "page", page, "pageSize", pageSize, "usageWindowDays", usageWindowDays)
 
 	validSortColumns := map[string]string{
-		"price":    "min_threshold",
-		"quantity": "quantity",
+		"name":     "i.name ASC",
+		"price":    "i.cost_per_unit DESC, i.name ASC",
+		"quantity": "i.quantity ASC, i.name ASC",
+		"value":    "stock_value DESC, i.name ASC",
+		"cover":    "days_of_cover ASC NULLS LAST, i.name ASC",
 	}
 
-	sortColumn, exists := validSortColumns[sortBy]
+	orderBy, exists := validSortColumns[sortBy]
 	if !exists {
-		sortColumn = "name"
+		orderBy = "i.name ASC"
 	}
 
 	offset := (page - 1) * pageSize
@@ -250,26 +355,43 @@ func (r *InventoryRepository) GetLeftOvers(sortBy string, page, pageSize int) ([
 	}
 
 	query := fmt.Sprintf(`
-		SELECT id, name, quantity, unit, min_threshold 
-		FROM inventory 
-		ORDER BY %s ASC
-		LIMIT $1 OFFSET $2`, sortColumn)
-
-	rows, err := r.db.Query(query, pageSize, offset)
+		WITH usage AS (
+			SELECT ingredient_id, SUM(-quantity_change) / $3::int AS daily_usage
+			FROM inventory_transactions
+			WHERE transaction_type IN ('usage', 'return')
+			  AND transaction_date >= CURRENT_TIMESTAMP - make_interval(days => $3::int)
+			GROUP BY ingredient_id
+		)
+		SELECT i.id, i.name, i.quantity, i.unit, i.min_threshold, i.cost_per_unit,
+			`+reservedStockSQL+`, `+usableStockSQL+` - `+reservedStockSQL+`,
+			i.quantity * i.cost_per_unit AS stock_value,
+			GREATEST(COALESCE(u.daily_usage, 0), 0) AS daily_usage,
+			CASE WHEN u.daily_usage > 0 THEN i.quantity / u.daily_usage END AS days_of_cover
+		FROM inventory i
+		LEFT JOIN usage u ON u.ingredient_id = i.id
+		ORDER BY %s
+		LIMIT $1 OFFSET $2`, orderBy)
+
+	rows, err := r.db.Query(query, pageSize, offset, usageWindowDays)
 	if err != nil {
 		r.logger.Error("Failed to query inventory leftovers", "error", err)
 		return nil, 0, fmt.Errorf("failed to query inventory leftovers: %v", err)
 	}
 	defer rows.Close()
 
-	var items []*models.InventoryItem
+	var items []*models.InventoryStockLevel
 	for rows.Next() {
-		item := &models.InventoryItem{}
-		err := rows.Scan(&item.IngredientID, &item.Name, &item.Quantity, &item.Unit, &item.MinThreshold)
+		item := &models.InventoryStockLevel{}
+		var daysOfCover sql.NullFloat64
+		err := rows.Scan(&item.IngredientID, &item.Name, &item.Quantity, &item.Unit, &item.MinThreshold, &item.CostPerUnit,
+			&item.Reserved, &item.Available, &item.StockValue, &item.DailyUsage, &daysOfCover)
 		if err != nil {
 			r.logger.Error("Failed to scan inventory item", "error", err)
 			return nil, 0, fmt.Errorf("failed to scan inventory item: %v", err)
 		}
+		if daysOfCover.Valid {
+			item.DaysOfCover = &daysOfCover.Float64
+		}
 		items = append(items, item)
 	}
 
@@ -282,6 +404,73 @@ func (r *InventoryRepository) GetLeftOvers(sortBy string, page, pageSize int) ([
 	return items, totalRecords, nil
 }
 
+// GetUsageRates returns the average net daily usage per ingredient over the last windowDays
+func (r *InventoryRepository) GetUsageRates(windowDays int) (map[string]float64, error) {
+	r.logger.Debug("Calculating inventory usage rates", "windowDays", windowDays)
+
+	query := `
+		SELECT ingredient_id, GREATEST(SUM(-quantity_change) / $1::int, 0)
+		FROM inventory_transactions
+		WHERE transaction_type IN ('usage', 'return')
+		  AND transaction_date >= CURRENT_TIMESTAMP - make_interval(days => $1::int)
+		GROUP BY ingredient_id`
+
+	rows, err := r.db.Query(query, windowDays)
+	if err != nil {
+		r.logger.Error("Failed to query inventory usage rates", "error", err)
+		return nil, fmt.Errorf("failed to query inventory usage rates: %v", err)
+	}
+	defer rows.Close()
+
+	rates := make(map[string]float64)
+	for rows.Next() {
+		var ingredientID string
+		var rate float64
+		if err := rows.Scan(&ingredientID, &rate); err != nil {
+			r.logger.Error("Failed to scan inventory usage rate", "error", err)
+			return nil, fmt.Errorf("failed to scan inventory usage rate: %v", err)
+		}
+		rates[ingredientID] = rate
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating inventory usage rates", "error", err)
+		return nil, fmt.Errorf("error iterating inventory usage rates: %v", err)
+	}
+
+	return rates, nil
+}
+
+// GetValuation totals the value of stock on hand at current cost
+func (r *InventoryRepository) GetValuation() (*models.InventoryValuation, error) {
+	r.logger.Debug("Calculating inventory valuation")
+
+	query := `
+		SELECT COUNT(*),
+			COALESCE(SUM(quantity * cost_per_unit), 0),
+			COUNT(*) FILTER (WHERE quantity < min_threshold),
+			COALESCE(SUM(quantity * cost_per_unit) FILTER (WHERE quantity < min_threshold), 0),
+			COUNT(*) FILTER (WHERE quantity > 0 AND cost_per_unit = 0),
+			CURRENT_TIMESTAMP
+		FROM inventory`
+
+	valuation := &models.InventoryValuation{}
+	err := r.db.QueryRow(query).Scan(
+		&valuation.ItemsCount,
+		&valuation.TotalValue,
+		&valuation.BelowThresholdCount,
+		&valuation.BelowThresholdValue,
+		&valuation.UnpricedCount,
+		&valuation.ValuedAt,
+	)
+	if err != nil {
+		r.logger.Error("Failed to calculate inventory valuation", "error", err)
+		return nil, fmt.Errorf("failed to calculate inventory valuation: %v", err)
+	}
+
+	r.logger.Info("Calculated inventory valuation", "items", valuation.ItemsCount, "total_value", valuation.TotalValue)
+	return valuation, nil
+}
+
 // CheckInventoryAvailability checks if there's sufficient inventory for given requirements
 func (r *InventoryRepository) CheckInventoryAvailability(requirements map[string]float64) (map[string]*models.InventoryItem, error) {
 	r.logger.Debug("Checking inventory availability", "ingredients_count", len(requirements))
@@ -296,9 +485,10 @@ func (r *InventoryRepository) CheckInventoryAvailability(requirements map[string
 			return nil, fmt.Errorf("ingredient %s not found in inventory", ingredientID)
 		}
 
-		if item.Quantity < requiredQuantity {
-			insufficientItems = append(insufficientItems, fmt.Sprintf("%s (need %.2f, have %.2f)",
-				item.Name, requiredQuantity, item.Quantity))
+		// Expired lots can't be used and stock reserved by open orders is already promised
+		if item.Available < requiredQuantity {
+			insufficientItems = append(insufficientItems, fmt.Sprintf("%s (need %.2f, available %.2f)",
+				item.Name, requiredQuantity, item.Available))
 		}
 
 		inventory[ingredientID] = item
@@ -337,14 +527,35 @@ func (r *InventoryRepository) BatchUpdateInventory(updates map[string]float64) (
 
 	for ingredientID, quantityUsed := range updates {
 		var name string
-		var remainingQuantity float64
+		var remainingQuantity, unitCost float64
 
-		err := tx.QueryRow(query, quantityUsed, ingredientID).Scan(&name, &remainingQuantity)
+		err = tx.QueryRow(query, quantityUsed, ingredientID).Scan(&name, &remainingQuantity)
 		if err != nil {
 			r.logger.Error("Failed to update inventory item", "error", err, "ingredient_id", ingredientID)
 			return nil, fmt.Errorf("failed to update inventory for ingredient %s: %v", ingredientID, err)
 		}
 
+		unitCost, err = drawLots(tx, ingredientID, quantityUsed, false)
+		if err != nil {
+			r.logger.Warn("Failed to draw inventory lots", "error", err, "ingredient_id", ingredientID)
+			return nil, err
+		}
+
+		err = insertTransaction(tx, &models.InventoryTransaction{
+			IngredientID:    ingredientID,
+			TransactionType: models.TransactionUsage,
+			QuantityChange:  -quantityUsed,
+			QuantityBefore:  remainingQuantity + quantityUsed,
+			QuantityAfter:   remainingQuantity,
+			UnitCost:        unitCost,
+			ReferenceType:   "batch",
+			Notes:           "Batch order processing",
+		})
+		if err != nil {
+			r.logger.Error("Failed to record inventory transaction", "error", err, "ingredient_id", ingredientID)
+			return nil, err
+		}
+
 		result := models.InventoryUpdateResult{
 			IngredientID: ingredientID,
 			Name:         name,
@@ -371,6 +582,56 @@ func (r *InventoryRepository) BatchUpdateInventory(updates map[string]float64) (
 	return results, nil
 }
 
+// ApplyTransactions applies stock movements and records them in the inventory ledger atomically.
+// Stock leaving inventory is drawn from the oldest lots first and its unit cost is the cost of those lots;
+// usage never touches expired lots. Stock coming back is added as a new lot.
+// Quantities before/after and the unit cost are filled in on each transaction.
+func (r *InventoryRepository) ApplyTransactions(transactions []*models.InventoryTransaction) error {
+	r.logger.Debug("Applying inventory transactions", "count", len(transactions))
+
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin inventory transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			r.logger.Warn("Rolling back inventory transaction due to error", "error", err)
+			tx.Rollback()
+		}
+	}()
+
+	for _, transaction := range transactions {
+		if err = applyTransaction(tx, transaction); err != nil {
+			if isInsufficientStock(err) {
+				r.logger.Warn("Inventory movement exceeds usable stock", "ingredient_id", transaction.IngredientID, "change", transaction.QuantityChange, "error", err)
+				err = fmt.Errorf("insufficient inventory for ingredient %s", transaction.IngredientID)
+				return err
+			}
+			r.logger.Error("Failed to apply inventory movement", "error", err, "ingredient_id", transaction.IngredientID)
+			return err
+		}
+
+		r.logger.Debug("Applied inventory transaction",
+			"ingredient_id", transaction.IngredientID,
+			"type", transaction.TransactionType,
+			"change", transaction.QuantityChange,
+			"remaining", transaction.QuantityAfter)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit inventory transaction", "error", err)
+		return fmt.Errorf("failed to commit inventory transaction: %v", err)
+	}
+
+	r.logger.Info("Applied inventory transactions", "count", len(transactions))
+	return nil
+}
+
 func (r *InventoryRepository) validateInventoryItemForUpdate(item *models.InventoryItem, id string) error {
 	if id == "" {
 		return errors.New("ingredient ID cannot be empty for updates")
@@ -392,6 +653,9 @@ func (r *InventoryRepository) validateInventoryItem(item *models.InventoryItem)
 	if item.Unit == "" {
 		return errors.New("unit cannot be empty")
 	}
+	if item.CostPerUnit < 0 {
+		return errors.New("cost per unit cannot be negative")
+	}
 
 	return nil
 }
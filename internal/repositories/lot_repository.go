@@ -0,0 +1,258 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"frappuccino/models"
+	"frappuccino/pkg/database"
+	"frappuccino/pkg/logger"
+)
+
+type LotRepositoryInterface interface {
+	GetLots(ingredientID string) ([]*models.InventoryLot, error)
+	GetExpiring(withinDays int) ([]*models.InventoryLot, error)
+	ReceiveLot(lot *models.InventoryLot, notes string) (*models.InventoryTransaction, error)
+	WriteOffExpired() ([]*models.InventoryTransaction, error)
+}
+
+type LotRepository struct {
+	logger *logger.Logger
+	db     *database.DB
+}
+
+func NewLotRepository(logger *logger.Logger, db *database.DB) *LotRepository {
+	return &LotRepository{
+		logger: logger.WithComponent("lot_repository"),
+		db:     db,
+	}
+}
+
+const lotSelectQuery = `
+	SELECT l.id, l.ingredient_id, i.name, i.unit, l.quantity, l.initial_quantity, l.unit_cost,
+		l.received_at, l.expires_at, COALESCE(l.reference_type, ''), COALESCE(l.reference_id::text, '')
+	FROM inventory_lots l
+	JOIN inventory i ON i.id = l.ingredient_id`
+
+// GetLots returns the lots of an ingredient that still hold stock, in the order they are consumed
+func (r *LotRepository) GetLots(ingredientID string) ([]*models.InventoryLot, error) {
+	r.logger.Debug("Retrieving inventory lots", "ingredient_id", ingredientID)
+
+	query := lotSelectQuery + `
+		WHERE l.ingredient_id = $1 AND l.quantity > 0
+		ORDER BY l.received_at, l.id`
+
+	return r.queryLots(query, ingredientID)
+}
+
+// GetExpiring returns lots with stock that expire within the given number of days, including those already expired
+func (r *LotRepository) GetExpiring(withinDays int) ([]*models.InventoryLot, error) {
+	r.logger.Debug("Retrieving expiring inventory lots", "within_days", withinDays)
+
+	query := lotSelectQuery + `
+		WHERE l.quantity > 0
+		AND l.expires_at <= CURRENT_TIMESTAMP + make_interval(days => $1::int)
+		ORDER BY l.expires_at, i.name`
+
+	return r.queryLots(query, withinDays)
+}
+
+// ReceiveLot books stock with an explicit expiry date as a purchase.
+// The lot's ID and received date are filled in from the database.
+func (r *LotRepository) ReceiveLot(lot *models.InventoryLot, notes string) (*models.InventoryTransaction, error) {
+	r.logger.Debug("Receiving inventory lot", "ingredient_id", lot.IngredientID, "quantity", lot.Quantity)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	transaction := &models.InventoryTransaction{
+		IngredientID:    lot.IngredientID,
+		TransactionType: models.TransactionPurchase,
+		QuantityChange:  lot.Quantity,
+		UnitCost:        lot.UnitCost,
+		Notes:           notes,
+	}
+
+	lot.ID, err = receiveStock(tx, transaction, lot.ExpiresAt)
+	if err != nil {
+		r.logger.Warn("Failed to receive inventory lot", "error", err, "ingredient_id", lot.IngredientID)
+		return nil, err
+	}
+
+	err = tx.QueryRow(`SELECT received_at, expires_at FROM inventory_lots WHERE id = $1`, lot.ID).Scan(&lot.ReceivedAt, &lot.ExpiresAt)
+	if err != nil {
+		r.logger.Error("Failed to read received lot", "error", err, "lot_id", lot.ID)
+		return nil, fmt.Errorf("failed to read received lot: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit lot receipt", "error", err, "lot_id", lot.ID)
+		return nil, fmt.Errorf("failed to commit lot receipt: %v", err)
+	}
+
+	lot.InitialQuantity = lot.Quantity
+	r.logger.Info("Received inventory lot", "lot_id", lot.ID, "ingredient_id", lot.IngredientID, "quantity", lot.Quantity)
+	return transaction, nil
+}
+
+// WriteOffExpired empties every expired lot and records the loss as a waste transaction per lot.
+// Each ingredient is written off in its own transaction; on error the write-offs that succeeded are still returned.
+func (r *LotRepository) WriteOffExpired() ([]*models.InventoryTransaction, error) {
+	r.logger.Debug("Writing off expired inventory lots")
+
+	rows, err := r.db.Query(`
+		SELECT DISTINCT ingredient_id
+		FROM inventory_lots
+		WHERE quantity > 0 AND expires_at <= CURRENT_TIMESTAMP`)
+	if err != nil {
+		r.logger.Error("Failed to query expired lots", "error", err)
+		return nil, fmt.Errorf("failed to query expired lots: %v", err)
+	}
+	var ingredientIDs []string
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			r.logger.Error("Failed to scan expired lot", "error", err)
+			return nil, fmt.Errorf("failed to scan expired lot: %v", err)
+		}
+		ingredientIDs = append(ingredientIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating expired lots", "error", err)
+		return nil, fmt.Errorf("error iterating expired lots: %v", err)
+	}
+
+	var transactions []*models.InventoryTransaction
+	var failed []string
+	for _, ingredientID := range ingredientIDs {
+		written, err := r.writeOffIngredient(ingredientID)
+		if err != nil {
+			r.logger.Error("Failed to write off expired lots", "error", err, "ingredient_id", ingredientID)
+			failed = append(failed, ingredientID)
+			continue
+		}
+		transactions = append(transactions, written...)
+	}
+
+	if len(failed) > 0 {
+		return transactions, fmt.Errorf("failed to write off expired lots for ingredients: %s", strings.Join(failed, ", "))
+	}
+
+	r.logger.Info("Wrote off expired inventory lots", "lots", len(transactions))
+	return transactions, nil
+}
+
+func (r *LotRepository) writeOffIngredient(ingredientID string) ([]*models.InventoryTransaction, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	// Lock the inventory row before its lots, the same order stock movements use
+	var quantity float64
+	if err = tx.QueryRow(`SELECT quantity FROM inventory WHERE id = $1 FOR UPDATE`, ingredientID).Scan(&quantity); err != nil {
+		return nil, fmt.Errorf("failed to lock inventory item: %v", err)
+	}
+
+	rows, err := tx.Query(`
+		SELECT id, quantity, unit_cost
+		FROM inventory_lots
+		WHERE ingredient_id = $1 AND quantity > 0 AND expires_at <= CURRENT_TIMESTAMP
+		ORDER BY expires_at
+		FOR UPDATE`, ingredientID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expired lots: %v", err)
+	}
+	var transactions []*models.InventoryTransaction
+	for rows.Next() {
+		transaction := &models.InventoryTransaction{
+			IngredientID:    ingredientID,
+			TransactionType: models.TransactionWaste,
+			ReferenceType:   models.ReferenceTypeLot,
+			Notes:           "Expired lot written off",
+		}
+		var lotQuantity float64
+		if err = rows.Scan(&transaction.ReferenceID, &lotQuantity, &transaction.UnitCost); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan expired lot: %v", err)
+		}
+		transaction.QuantityChange = -lotQuantity
+		transactions = append(transactions, transaction)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating expired lots: %v", err)
+	}
+
+	for _, transaction := range transactions {
+		lotID := transaction.ReferenceID
+		if _, err = tx.Exec(`UPDATE inventory_lots SET quantity = 0 WHERE id = $1`, lotID); err != nil {
+			return nil, fmt.Errorf("failed to empty lot %s: %v", lotID, err)
+		}
+
+		transaction.QuantityBefore = quantity
+		err = tx.QueryRow(`UPDATE inventory SET quantity = quantity + $1 WHERE id = $2 RETURNING quantity`,
+			transaction.QuantityChange, ingredientID).Scan(&transaction.QuantityAfter)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write off lot %s: %v", lotID, err)
+		}
+		quantity = transaction.QuantityAfter
+
+		if err = insertTransaction(tx, transaction); err != nil {
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit write-off: %v", err)
+	}
+	return transactions, nil
+}
+
+func (r *LotRepository) queryLots(query string, args ...any) ([]*models.InventoryLot, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query inventory lots", "error", err)
+		return nil, fmt.Errorf("failed to query inventory lots: %v", err)
+	}
+	defer rows.Close()
+
+	lots := make([]*models.InventoryLot, 0)
+	for rows.Next() {
+		lot := &models.InventoryLot{}
+		var expiresAt sql.NullTime
+		err := rows.Scan(&lot.ID, &lot.IngredientID, &lot.Name, &lot.Unit, &lot.Quantity, &lot.InitialQuantity,
+			&lot.UnitCost, &lot.ReceivedAt, &expiresAt, &lot.ReferenceType, &lot.ReferenceID)
+		if err != nil {
+			r.logger.Error("Failed to scan inventory lot", "error", err)
+			return nil, fmt.Errorf("failed to scan inventory lot: %v", err)
+		}
+		if expiresAt.Valid {
+			lot.ExpiresAt = &expiresAt.Time
+		}
+		lots = append(lots, lot)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Error("Error iterating inventory lots", "error", err)
+		return nil, fmt.Errorf("error iterating inventory lots: %v", err)
+	}
+
+	return lots, nil
+}
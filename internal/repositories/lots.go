@@ -0,0 +1,217 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"frappuccino/models"
+)
+
+// Stock movements go through these helpers so that inventory.quantity always equals the sum of its lots.
+// They run inside the caller's transaction and always lock the inventory row before its lots.
+
+// lotEpsilon absorbs rounding left over from DECIMAL(10,3) quantities
+const lotEpsilon = 0.0005
+
+// addLot stores stock entering inventory as a new lot. Without an explicit expiry
+// the lot expires after the ingredient's shelf_life_days, or never when that is 0.
+func addLot(tx *sql.Tx, ingredientID string, quantity, unitCost float64, expiresAt *time.Time, referenceType, referenceID string) (string, error) {
+	query := `
+		INSERT INTO inventory_lots (ingredient_id, quantity, initial_quantity, unit_cost, expires_at, reference_type, reference_id)
+		SELECT id, $2, $2, ROUND($3::numeric, 4),
+			COALESCE($4::timestamptz, CASE WHEN shelf_life_days > 0 THEN CURRENT_TIMESTAMP + make_interval(days => shelf_life_days) END),
+			NULLIF($5, ''), NULLIF($6, '')::uuid
+		FROM inventory
+		WHERE id = $1
+		RETURNING id`
+
+	var lotID string
+	if err := tx.QueryRow(query, ingredientID, quantity, unitCost, expiresAt, referenceType, referenceID).Scan(&lotID); err != nil {
+		return "", fmt.Errorf("failed to add lot for ingredient %s: %v", ingredientID, err)
+	}
+	return lotID, nil
+}
+
+// stockLot is a lot's remaining stock and what it cost per unit
+type stockLot struct {
+	id       string
+	quantity float64
+	unitCost float64
+}
+
+// lotDraw is the quantity to take out of a lot
+type lotDraw struct {
+	lotID    string
+	quantity float64
+}
+
+// drawLots takes quantity out of the oldest lots first and returns the average unit cost of what was taken.
+// Expired lots are skipped unless includeExpired is set, so they can't be sold before they are written off.
+func drawLots(tx *sql.Tx, ingredientID string, quantity float64, includeExpired bool) (float64, error) {
+	rows, err := tx.Query(`
+		SELECT id, quantity, unit_cost
+		FROM inventory_lots
+		WHERE ingredient_id = $1 AND quantity > 0
+		AND ($2 OR expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+		ORDER BY received_at, id
+		FOR UPDATE`, ingredientID, includeExpired)
+	if err != nil {
+		return 0, fmt.Errorf("failed to query lots for ingredient %s: %v", ingredientID, err)
+	}
+
+	var lots []stockLot
+	for rows.Next() {
+		var l stockLot
+		if err := rows.Scan(&l.id, &l.quantity, &l.unitCost); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("failed to scan lot: %v", err)
+		}
+		lots = append(lots, l)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, fmt.Errorf("error iterating lots: %v", err)
+	}
+
+	draws, unitCost, short := planLotDraws(lots, quantity)
+	if short > lotEpsilon {
+		return 0, fmt.Errorf("insufficient inventory for ingredient %s: %.3f short in usable lots", ingredientID, short)
+	}
+	for _, draw := range draws {
+		if _, err := tx.Exec(`UPDATE inventory_lots SET quantity = GREATEST(quantity - $1, 0) WHERE id = $2`, draw.quantity, draw.lotID); err != nil {
+			return 0, fmt.Errorf("failed to draw from lot %s: %v", draw.lotID, err)
+		}
+	}
+	return unitCost, nil
+}
+
+// planLotDraws takes quantity out of lots in the order given. It returns the draws, the average unit cost of
+// what they take, unrounded since per g or ml costs are fractions of a cent, and the quantity the lots fall short by.
+func planLotDraws(lots []stockLot, quantity float64) ([]lotDraw, float64, float64) {
+	var draws []lotDraw
+	remaining := quantity
+	totalCost := 0.0
+	for _, l := range lots {
+		if remaining <= lotEpsilon {
+			break
+		}
+		taken := math.Min(l.quantity, remaining)
+		draws = append(draws, lotDraw{lotID: l.id, quantity: taken})
+		remaining -= taken
+		totalCost += taken * l.unitCost
+	}
+
+	if quantity <= 0 {
+		return draws, 0, 0
+	}
+	return draws, totalCost / quantity, math.Max(remaining, 0)
+}
+
+// applyTransaction moves stock by transaction.QuantityChange and records it in the ledger.
+// Stock leaving inventory is drawn from the oldest lots and costed at those lots; usage skips expired lots.
+// Stock coming back becomes a new lot at transaction.UnitCost, or cost_per_unit when that is 0.
+func applyTransaction(tx *sql.Tx, transaction *models.InventoryTransaction) error {
+	var costPerUnit float64
+	err := tx.QueryRow(`
+		UPDATE inventory
+		SET quantity = quantity + $1
+		WHERE id = $2
+		RETURNING quantity, cost_per_unit`, transaction.QuantityChange, transaction.IngredientID).Scan(&transaction.QuantityAfter, &costPerUnit)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("inventory item with id %s not found", transaction.IngredientID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to update inventory for ingredient %s: %v", transaction.IngredientID, err)
+	}
+	transaction.QuantityBefore = transaction.QuantityAfter - transaction.QuantityChange
+
+	if transaction.QuantityChange < 0 {
+		includeExpired := transaction.TransactionType != models.TransactionUsage
+		transaction.UnitCost, err = drawLots(tx, transaction.IngredientID, -transaction.QuantityChange, includeExpired)
+		if err != nil {
+			return err
+		}
+	} else {
+		if transaction.UnitCost == 0 {
+			transaction.UnitCost = costPerUnit
+		}
+		_, err = addLot(tx, transaction.IngredientID, transaction.QuantityChange, transaction.UnitCost, nil, transaction.ReferenceType, transaction.ReferenceID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return insertTransaction(tx, transaction)
+}
+
+// receiveStock books purchased stock: it moves cost_per_unit to the weighted average of stock on hand
+// and the received goods, adds a lot and records the purchase in the ledger.
+// The ledger row references the new lot unless the transaction already carries a reference.
+func receiveStock(tx *sql.Tx, transaction *models.InventoryTransaction, expiresAt *time.Time) (string, error) {
+	// SET expressions see the row before the update, so cost is averaged over the old quantity
+	stockQuery := `
+		UPDATE inventory
+		SET cost_per_unit = CASE
+		        WHEN quantity + $1 > 0 THEN ROUND((quantity * cost_per_unit + $1 * $2) / (quantity + $1), 4)
+		        ELSE $2
+		    END,
+		    quantity = quantity + $1
+		WHERE id = $3
+		RETURNING quantity`
+
+	err := tx.QueryRow(stockQuery, transaction.QuantityChange, transaction.UnitCost, transaction.IngredientID).Scan(&transaction.QuantityAfter)
+	if err == sql.ErrNoRows {
+		return "", fmt.Errorf("inventory item with id %s not found", transaction.IngredientID)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to add received stock for ingredient %s: %v", transaction.IngredientID, err)
+	}
+	transaction.QuantityBefore = transaction.QuantityAfter - transaction.QuantityChange
+
+	lotID, err := addLot(tx, transaction.IngredientID, transaction.QuantityChange, transaction.UnitCost, expiresAt, transaction.ReferenceType, transaction.ReferenceID)
+	if err != nil {
+		return "", err
+	}
+
+	if transaction.ReferenceType == "" {
+		transaction.ReferenceType = models.ReferenceTypeLot
+		transaction.ReferenceID = lotID
+	}
+	if err := insertTransaction(tx, transaction); err != nil {
+		return "", err
+	}
+	return lotID, nil
+}
+
+// insertTransaction records a movement in the inventory ledger and fills in its ID and date
+func insertTransaction(tx *sql.Tx, transaction *models.InventoryTransaction) error {
+	query := `
+		INSERT INTO inventory_transactions
+			(ingredient_id, transaction_type, quantity_change, quantity_before, quantity_after, unit_cost, reference_type, reference_id, notes)
+		VALUES ($1, $2, $3, $4, $5, ROUND($6::numeric, 4), NULLIF($7, ''), NULLIF($8, '')::uuid, NULLIF($9, ''))
+		RETURNING id, transaction_date`
+
+	err := tx.QueryRow(query,
+		transaction.IngredientID,
+		transaction.TransactionType,
+		transaction.QuantityChange,
+		transaction.QuantityBefore,
+		transaction.QuantityAfter,
+		transaction.UnitCost,
+		transaction.ReferenceType,
+		transaction.ReferenceID,
+		transaction.Notes,
+	).Scan(&transaction.ID, &transaction.TransactionDate)
+	if err != nil {
+		return fmt.Errorf("failed to record inventory transaction for ingredient %s: %v", transaction.IngredientID, err)
+	}
+	return nil
+}
+
+// isInsufficientStock reports whether err means a movement would take more than is on hand
+func isInsufficientStock(err error) bool {
+	return strings.Contains(err.Error(), "violates check constraint") || strings.Contains(err.Error(), "insufficient inventory")
+}
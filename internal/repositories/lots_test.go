@@ -0,0 +1,68 @@
+package repositories
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestPlanLotDraws(t *testing.T) {
+	tests := []struct {
+		name         string
+		lots         []stockLot
+		quantity     float64
+		wantDraws    []lotDraw
+		wantUnitCost float64
+		wantShort    float64
+	}{
+		{
+			name:         "per gram cost across two lots",
+			lots:         []stockLot{{id: "old", quantity: 100, unitCost: 0.0042}, {id: "new", quantity: 500, unitCost: 0.005}},
+			quantity:     150,
+			wantDraws:    []lotDraw{{lotID: "old", quantity: 100}, {lotID: "new", quantity: 50}},
+			wantUnitCost: (100*0.0042 + 50*0.005) / 150,
+		},
+		{
+			name:         "small quantity keeps fractions of a cent",
+			lots:         []stockLot{{id: "old", quantity: 10, unitCost: 0.0042}, {id: "new", quantity: 500, unitCost: 0.0048}},
+			quantity:     18,
+			wantDraws:    []lotDraw{{lotID: "old", quantity: 10}, {lotID: "new", quantity: 8}},
+			wantUnitCost: (10*0.0042 + 8*0.0048) / 18,
+		},
+		{
+			name:         "oldest lot covers it",
+			lots:         []stockLot{{id: "old", quantity: 100, unitCost: 0.018}, {id: "new", quantity: 100, unitCost: 0.02}},
+			quantity:     18,
+			wantDraws:    []lotDraw{{lotID: "old", quantity: 18}},
+			wantUnitCost: 0.018,
+		},
+		{
+			name:         "lots fall short",
+			lots:         []stockLot{{id: "old", quantity: 5, unitCost: 0.01}},
+			quantity:     8,
+			wantDraws:    []lotDraw{{lotID: "old", quantity: 5}},
+			wantUnitCost: 5 * 0.01 / 8,
+			wantShort:    3,
+		},
+		{
+			name:      "no lots",
+			quantity:  2,
+			wantShort: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			draws, unitCost, short := planLotDraws(tt.lots, tt.quantity)
+			if !reflect.DeepEqual(draws, tt.wantDraws) {
+				t.Errorf("draws = %+v, want %+v", draws, tt.wantDraws)
+			}
+			if math.Abs(unitCost-tt.wantUnitCost) > 1e-12 {
+				t.Errorf("unit cost = %v, want %v", unitCost, tt.wantUnitCost)
+			}
+			if math.Abs(short-tt.wantShort) > 1e-12 {
+				t.Errorf("short = %v, want %v", short, tt.wantShort)
+			}
+		})
+	}
+}
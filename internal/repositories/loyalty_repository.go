@@ -0,0 +1,463 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+
+	"frappuccino/models"
+	"frappuccino/pkg/database"
+	"frappuccino/pkg/logger"
+)
+
+type LoyaltyRepositoryInterface interface {
+	GetAll() ([]*models.LoyaltyRule, error)
+	GetActive() ([]*models.LoyaltyRule, error)
+	GetByID(id string) (*models.LoyaltyRule, error)
+	Create(rule *models.LoyaltyRule) error
+	Update(rule *models.LoyaltyRule) error
+	Delete(id string) error
+	GetBalance(customerID, ruleID string) (int, error)
+	GetCustomerLoyalty(customerID string) (*models.CustomerLoyalty, error)
+	Accrue(entries []*models.LoyaltyEntry) error
+	ReverseRefund(orderID string, fraction float64) error
+	GetLiability() (*models.LoyaltyLiabilityReport, error)
+}
+
+type LoyaltyRepository struct {
+	logger *logger.Logger
+	db     *database.DB
+}
+
+func NewLoyaltyRepository(logger *logger.Logger, db *database.DB) *LoyaltyRepository {
+	return &LoyaltyRepository{
+		logger: logger.WithComponent("loyalty_repository"),
+		db:     db,
+	}
+}
+
+const loyaltyRuleSelectQuery = `
+	SELECT id, name, rule_type, COALESCE(category, ''), COALESCE(points_per_unit, 0), COALESCE(point_value, 0),
+		COALESCE(stamps_required, 0), max_reward_value, active, created_at, updated_at
+	FROM loyalty_rules`
+
+// GetAll returns every loyalty rule, oldest first
+func (r *LoyaltyRepository) GetAll() ([]*models.LoyaltyRule, error) {
+	r.logger.Debug("Retrieving loyalty rules")
+	return r.queryRules(loyaltyRuleSelectQuery + ` ORDER BY created_at, id`)
+}
+
+// GetActive returns the rules orders currently earn under
+func (r *LoyaltyRepository) GetActive() ([]*models.LoyaltyRule, error) {
+	return r.queryRules(loyaltyRuleSelectQuery + ` WHERE active ORDER BY created_at, id`)
+}
+
+// GetByID returns a loyalty rule
+func (r *LoyaltyRepository) GetByID(id string) (*models.LoyaltyRule, error) {
+	rules, err := r.queryRules(loyaltyRuleSelectQuery+` WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("loyalty rule with id %s not found", id)
+	}
+	return rules[0], nil
+}
+
+// Create stores a loyalty rule; its ID and timestamps are filled in from the database
+func (r *LoyaltyRepository) Create(rule *models.LoyaltyRule) error {
+	r.logger.Debug("Creating loyalty rule", "name", rule.Name, "type", rule.Type)
+
+	err := r.db.QueryRow(`
+		INSERT INTO loyalty_rules (name, rule_type, category, points_per_unit, point_value, stamps_required,
+			max_reward_value, active)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, 0), NULLIF($5, 0), NULLIF($6, 0), $7, $8)
+		RETURNING id, created_at, updated_at`,
+		rule.Name, rule.Type, string(rule.Category), rule.PointsPerUnit, rule.PointValue, rule.StampsRequired,
+		rule.MaxRewardValue, rule.Active,
+	).Scan(&rule.ID, &rule.CreatedAt, &rule.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Failed to create loyalty rule", "error", err, "name", rule.Name)
+		return fmt.Errorf("failed to create loyalty rule: %v", err)
+	}
+
+	r.logger.Info("Created loyalty rule", "id", rule.ID, "name", rule.Name)
+	return nil
+}
+
+// Update replaces a loyalty rule's settings; points already earned or spent stay as they are
+func (r *LoyaltyRepository) Update(rule *models.LoyaltyRule) error {
+	r.logger.Debug("Updating loyalty rule", "id", rule.ID)
+
+	err := r.db.QueryRow(`
+		UPDATE loyalty_rules
+		SET name = $2, category = NULLIF($3, ''), points_per_unit = NULLIF($4, 0), point_value = NULLIF($5, 0),
+		    stamps_required = NULLIF($6, 0), max_reward_value = $7, active = $8
+		WHERE id = $1
+		RETURNING created_at, updated_at`,
+		rule.ID, rule.Name, string(rule.Category), rule.PointsPerUnit, rule.PointValue, rule.StampsRequired,
+		rule.MaxRewardValue, rule.Active,
+	).Scan(&rule.CreatedAt, &rule.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("loyalty rule with id %s not found", rule.ID)
+	}
+	if err != nil {
+		r.logger.Error("Failed to update loyalty rule", "error", err, "id", rule.ID)
+		return fmt.Errorf("failed to update loyalty rule: %v", err)
+	}
+
+	r.logger.Info("Updated loyalty rule", "id", rule.ID)
+	return nil
+}
+
+// Delete removes a loyalty rule nobody has collected under yet
+func (r *LoyaltyRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM loyalty_rules WHERE id = $1`, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "foreign key") {
+			return fmt.Errorf("loyalty rule %s has ledger entries: deactivate it instead", id)
+		}
+		r.logger.Error("Failed to delete loyalty rule", "error", err, "id", id)
+		return fmt.Errorf("failed to delete loyalty rule: %v", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("loyalty rule with id %s not found", id)
+	}
+
+	r.logger.Info("Deleted loyalty rule", "id", id)
+	return nil
+}
+
+// GetBalance returns what a customer holds under a rule
+func (r *LoyaltyRepository) GetBalance(customerID, ruleID string) (int, error) {
+	var balance int
+	err := r.db.QueryRow(`
+		SELECT COALESCE(SUM(points), 0) FROM loyalty_ledger WHERE customer_id = $1 AND rule_id = $2`,
+		customerID, ruleID).Scan(&balance)
+	if err != nil {
+		r.logger.Error("Failed to get loyalty balance", "error", err, "customer_id", customerID, "rule_id", ruleID)
+		return 0, fmt.Errorf("failed to get loyalty balance: %v", err)
+	}
+	return balance, nil
+}
+
+// GetCustomerLoyalty returns a customer's balance under every rule they have collected under, and their ledger
+func (r *LoyaltyRepository) GetCustomerLoyalty(customerID string) (*models.CustomerLoyalty, error) {
+	r.logger.Debug("Retrieving customer loyalty", "customer_id", customerID)
+
+	loyalty := &models.CustomerLoyalty{
+		CustomerID: customerID,
+		Balances:   []models.LoyaltyBalance{},
+		Entries:    []models.LoyaltyEntry{},
+	}
+
+	rows, err := r.db.Query(`
+		SELECT lr.id, lr.name, lr.rule_type, COALESCE(lr.point_value, 0), COALESCE(lr.stamps_required, 0),
+		       SUM(l.points)
+		FROM loyalty_ledger l
+		JOIN loyalty_rules lr ON lr.id = l.rule_id
+		WHERE l.customer_id = $1
+		GROUP BY lr.id
+		ORDER BY lr.created_at, lr.id`, customerID)
+	if err != nil {
+		r.logger.Error("Failed to query loyalty balances", "error", err, "customer_id", customerID)
+		return nil, fmt.Errorf("failed to query loyalty balances: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var balance models.LoyaltyBalance
+		var pointValue float64
+		var stampsRequired int
+		if err := rows.Scan(&balance.RuleID, &balance.Name, &balance.Type, &pointValue, &stampsRequired, &balance.Points); err != nil {
+			r.logger.Error("Failed to scan loyalty balance", "error", err)
+			return nil, fmt.Errorf("failed to scan loyalty balance: %v", err)
+		}
+		if balance.Points > 0 {
+			switch balance.Type {
+			case models.LoyaltyPoints:
+				balance.Value = roundCents(float64(balance.Points) * pointValue)
+			case models.LoyaltyStamps:
+				balance.Rewards = balance.Points / stampsRequired
+			}
+		}
+		loyalty.Balances = append(loyalty.Balances, balance)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating loyalty balances", "error", err)
+		return nil, fmt.Errorf("error iterating loyalty balances: %v", err)
+	}
+
+	entries, err := r.db.Query(`
+		SELECT l.id, l.customer_id, l.rule_id, lr.name, COALESCE(l.order_id::text, ''), l.type, l.points,
+		       COALESCE(l.reason, ''), l.created_at
+		FROM loyalty_ledger l
+		JOIN loyalty_rules lr ON lr.id = l.rule_id
+		WHERE l.customer_id = $1
+		ORDER BY l.created_at DESC, l.id`, customerID)
+	if err != nil {
+		r.logger.Error("Failed to query loyalty ledger", "error", err, "customer_id", customerID)
+		return nil, fmt.Errorf("failed to query loyalty ledger: %v", err)
+	}
+	defer entries.Close()
+
+	for entries.Next() {
+		var entry models.LoyaltyEntry
+		err := entries.Scan(&entry.ID, &entry.CustomerID, &entry.RuleID, &entry.RuleName, &entry.OrderID, &entry.Type,
+			&entry.Points, &entry.Reason, &entry.CreatedAt)
+		if err != nil {
+			r.logger.Error("Failed to scan loyalty entry", "error", err)
+			return nil, fmt.Errorf("failed to scan loyalty entry: %v", err)
+		}
+		loyalty.Entries = append(loyalty.Entries, entry)
+	}
+	if err := entries.Err(); err != nil {
+		r.logger.Error("Error iterating loyalty ledger", "error", err)
+		return nil, fmt.Errorf("error iterating loyalty ledger: %v", err)
+	}
+	return loyalty, nil
+}
+
+// Accrue records what closed orders earned. An order earns once per rule, so entries already recorded are skipped.
+func (r *LoyaltyRepository) Accrue(entries []*models.LoyaltyEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	for _, entry := range entries {
+		err := tx.QueryRow(`
+			INSERT INTO loyalty_ledger (customer_id, rule_id, order_id, type, points, reason)
+			VALUES ($1, $2, $3, 'earn', $4, NULLIF($5, ''))
+			ON CONFLICT (order_id, rule_id) WHERE type = 'earn' DO NOTHING
+			RETURNING id, created_at`,
+			entry.CustomerID, entry.RuleID, entry.OrderID, entry.Points, entry.Reason).Scan(&entry.ID, &entry.CreatedAt)
+		if err != nil && err != sql.ErrNoRows {
+			r.logger.Error("Failed to record loyalty accrual", "error", err, "order_id", entry.OrderID, "rule_id", entry.RuleID)
+			return fmt.Errorf("failed to record loyalty accrual: %v", err)
+		}
+		entry.Type = models.LoyaltyEarn
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// ReverseRefund takes back the share of an order's earned points that has been refunded, fraction being the
+// part of the order refunded so far. Points already taken back for earlier refunds are not taken twice.
+func (r *LoyaltyRepository) ReverseRefund(orderID string, fraction float64) error {
+	r.logger.Debug("Reversing refunded loyalty", "order_id", orderID, "fraction", fraction)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// Refunds of the same order are serialised on the order row
+	if _, err := tx.Exec(`SELECT id FROM orders WHERE id = $1 FOR UPDATE`, orderID); err != nil {
+		r.logger.Error("Failed to lock order", "error", err, "order_id", orderID)
+		return fmt.Errorf("failed to lock order: %v", err)
+	}
+
+	rows, err := tx.Query(`
+		SELECT customer_id, rule_id,
+		       COALESCE(SUM(points) FILTER (WHERE type = 'earn'), 0),
+		       COALESCE(-SUM(points) FILTER (WHERE type = 'reversal' AND points < 0), 0)
+		FROM loyalty_ledger
+		WHERE order_id = $1
+		GROUP BY customer_id, rule_id`, orderID)
+	if err != nil {
+		r.logger.Error("Failed to query order loyalty", "error", err, "order_id", orderID)
+		return fmt.Errorf("failed to query order loyalty: %v", err)
+	}
+
+	var reversals []models.LoyaltyEntry
+	for rows.Next() {
+		var entry models.LoyaltyEntry
+		var earned, reversed int
+		if err := rows.Scan(&entry.CustomerID, &entry.RuleID, &earned, &reversed); err != nil {
+			rows.Close()
+			return fmt.Errorf("failed to scan order loyalty: %v", err)
+		}
+		due := int(math.Floor(float64(earned) * math.Min(fraction, 1)))
+		if due > reversed {
+			entry.Points = -(due - reversed)
+			reversals = append(reversals, entry)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("error iterating order loyalty: %v", err)
+	}
+
+	for _, entry := range reversals {
+		_, err := tx.Exec(`
+			INSERT INTO loyalty_ledger (customer_id, rule_id, order_id, type, points, reason)
+			VALUES ($1, $2, $3, 'reversal', $4, 'order refunded')`,
+			entry.CustomerID, entry.RuleID, orderID, entry.Points)
+		if err != nil {
+			r.logger.Error("Failed to reverse loyalty", "error", err, "order_id", orderID)
+			return fmt.Errorf("failed to reverse loyalty: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return nil
+}
+
+// GetLiability values what customers hold under each rule: points at their point value, and stamp rewards
+// already earned at max_reward_value or, without a cap, the average price of the items they can be spent on
+func (r *LoyaltyRepository) GetLiability() (*models.LoyaltyLiabilityReport, error) {
+	r.logger.Debug("Retrieving loyalty liability")
+
+	rows, err := r.db.Query(`
+		WITH balances AS (
+			SELECT rule_id, customer_id, SUM(points) AS balance
+			FROM loyalty_ledger
+			GROUP BY rule_id, customer_id
+			HAVING SUM(points) > 0
+		)
+		SELECT lr.id, lr.name, lr.rule_type, COALESCE(lr.point_value, 0),
+		       COUNT(b.customer_id), COALESCE(SUM(b.balance), 0),
+		       COALESCE(SUM(b.balance / lr.stamps_required), 0),
+		       COALESCE(LEAST(lr.max_reward_value, (
+		           SELECT AVG(m.price) FROM menu_items m WHERE lr.category IS NULL OR m.category = lr.category
+		       )), 0)
+		FROM loyalty_rules lr
+		LEFT JOIN balances b ON b.rule_id = lr.id
+		GROUP BY lr.id
+		ORDER BY lr.created_at, lr.id`)
+	if err != nil {
+		r.logger.Error("Failed to query loyalty liability", "error", err)
+		return nil, fmt.Errorf("failed to query loyalty liability: %v", err)
+	}
+	defer rows.Close()
+
+	report := &models.LoyaltyLiabilityReport{Rules: []models.LoyaltyLiability{}}
+	for rows.Next() {
+		var liability models.LoyaltyLiability
+		var pointValue, rewardValue float64
+		if err := rows.Scan(&liability.RuleID, &liability.Name, &liability.Type, &pointValue, &liability.Customers,
+			&liability.Outstanding, &liability.Rewards, &rewardValue); err != nil {
+			r.logger.Error("Failed to scan loyalty liability", "error", err)
+			return nil, fmt.Errorf("failed to scan loyalty liability: %v", err)
+		}
+
+		switch liability.Type {
+		case models.LoyaltyPoints:
+			liability.Value = roundCents(float64(liability.Outstanding) * pointValue)
+		case models.LoyaltyStamps:
+			liability.RewardValue = roundCents(rewardValue)
+			liability.Value = roundCents(float64(liability.Rewards) * rewardValue)
+		}
+		report.Rules = append(report.Rules, liability)
+		report.TotalValue += liability.Value
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating loyalty liability", "error", err)
+		return nil, fmt.Errorf("error iterating loyalty liability: %v", err)
+	}
+
+	report.TotalValue = roundCents(report.TotalValue)
+	return report, nil
+}
+
+func (r *LoyaltyRepository) queryRules(query string, args ...interface{}) ([]*models.LoyaltyRule, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query loyalty rules", "error", err)
+		return nil, fmt.Errorf("failed to query loyalty rules: %v", err)
+	}
+	defer rows.Close()
+
+	rules := []*models.LoyaltyRule{}
+	for rows.Next() {
+		rule := &models.LoyaltyRule{}
+		var maxRewardValue sql.NullFloat64
+
+		err := rows.Scan(&rule.ID, &rule.Name, &rule.Type, &rule.Category, &rule.PointsPerUnit, &rule.PointValue,
+			&rule.StampsRequired, &maxRewardValue, &rule.Active, &rule.CreatedAt, &rule.UpdatedAt)
+		if err != nil {
+			r.logger.Error("Failed to scan loyalty rule", "error", err)
+			return nil, fmt.Errorf("failed to scan loyalty rule: %v", err)
+		}
+		if maxRewardValue.Valid {
+			rule.MaxRewardValue = &maxRewardValue.Float64
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating loyalty rules", "error", err)
+		return nil, fmt.Errorf("error iterating loyalty rules: %v", err)
+	}
+	return rules, nil
+}
+
+// spendLoyalty takes a redemption off the customer's balance, failing if they don't hold enough.
+// The customer row is locked so two orders can't spend the same points.
+func spendLoyalty(tx *sql.Tx, customerID, orderID string, discount models.OrderDiscount) error {
+	if customerID == "" {
+		return fmt.Errorf("loyalty redemption requires a customer")
+	}
+
+	var locked string
+	err := tx.QueryRow(`SELECT id FROM customers WHERE id = $1 FOR UPDATE`, customerID).Scan(&locked)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("customer with id %s not found", customerID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock customer: %v", err)
+	}
+
+	var balance int
+	err = tx.QueryRow(`
+		SELECT COALESCE(SUM(points), 0) FROM loyalty_ledger WHERE customer_id = $1 AND rule_id = $2`,
+		customerID, discount.LoyaltyRuleID).Scan(&balance)
+	if err != nil {
+		return fmt.Errorf("failed to get loyalty balance: %v", err)
+	}
+	if balance < discount.LoyaltyPoints {
+		return fmt.Errorf("insufficient loyalty balance for '%s': %d available, %d needed", discount.Name, balance, discount.LoyaltyPoints)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO loyalty_ledger (customer_id, rule_id, order_id, type, points, reason)
+		VALUES ($1, $2, $3, 'redeem', $4, 'redeemed on order')`,
+		customerID, discount.LoyaltyRuleID, orderID, -discount.LoyaltyPoints)
+	if err != nil {
+		return fmt.Errorf("failed to redeem loyalty: %v", err)
+	}
+	return nil
+}
+
+// reverseOrderLoyalty undoes everything an order did to loyalty balances: points it earned are taken back
+// and points spent on it are given back
+func reverseOrderLoyalty(tx *sql.Tx, orderID, reason string) error {
+	_, err := tx.Exec(`
+		INSERT INTO loyalty_ledger (customer_id, rule_id, order_id, type, points, reason)
+		SELECT customer_id, rule_id, order_id, 'reversal', -SUM(points), $2
+		FROM loyalty_ledger
+		WHERE order_id = $1
+		GROUP BY customer_id, rule_id, order_id
+		HAVING SUM(points) != 0`, orderID, reason)
+	if err != nil {
+		return fmt.Errorf("failed to reverse order loyalty: %v", err)
+	}
+	return nil
+}
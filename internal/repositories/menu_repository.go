@@ -27,9 +27,11 @@ import (
 type MenuRepositoryInterface interface {
 	GetAll() ([]*models.MenuItem, error)
 	Create(item *models.MenuItem) error
-	Update(id string, item *models.MenuItem) error
+	Update(id string, item *models.MenuItem, note models.PriceChangeNote) error
 	Delete(id string) error
 	GetByID(id string) (*models.MenuItem, error)
+	SetStockAvailability(id string, inStock bool) (bool, error)
+	GetStockDependents(ingredientIDs []string) ([]*models.MenuItem, error)
 }
 
 // TODO: Transition State: JSON → PostgreSQL
@@ -55,18 +57,25 @@ func (r *MenuRepository) GetAll() ([]*models.MenuItem, error) {
 	r.logger.Debug("Retrieving all menu items from database")
 
 	query := `
-        SELECT m.id, m.name, m.description, m.category, m.price, m.available,
+        SELECT m.id, m.name, m.description, m.category, m.price, m.available, m.out_of_stock,
+               ` + menuAllergensSQL + `, m.allergens_added, m.allergens_removed, m.nutrition_overrides, m.available_sizes,
+               ` + menuStationSQL + `,
+               COALESCE(to_char(m.available_from, 'YYYY-MM-DD'), ''), COALESCE(to_char(m.available_until, 'YYYY-MM-DD'), ''),
+               ` + menuScheduleWindowsSQL + `,
+               ` + menuBundleSlotsSQL + `,
+               ` + menuModifiersSQL + `,
                COALESCE(
                    json_agg(
                        json_build_object(
                            'ingredient_id', mi.ingredient_id,
-                           'quantity', mi.required_quantity
+                           'quantity', mi.required_quantity,
+                           'unit', mi.unit
                        )
                    ) FILTER (WHERE mi.ingredient_id IS NOT NULL), '[]'::json
                ) as ingredients
         FROM menu_items m
         LEFT JOIN menu_item_ingredients mi ON m.id = mi.menu_item_id
-        GROUP BY m.id, m.name, m.description, m.category, m.price, m.available
+        GROUP BY m.id, m.name, m.description, m.category, m.price, m.available, m.out_of_stock, m.available_from, m.available_until
         ORDER BY m.name
     `
 
@@ -80,18 +89,37 @@ func (r *MenuRepository) GetAll() ([]*models.MenuItem, error) {
 	items := []*models.MenuItem{}
 	for rows.Next() {
 		item := &models.MenuItem{}
-		var ingredientsJSON string
+		var ingredientsJSON, windowsJSON, slotsJSON, modifiersJSON, startDate, endDate string
+		var allergens, added, removed, nutritionJSON, sizes string
 
-		err := rows.Scan(&item.ID, &item.Name, &item.Description, &item.Category, &item.Price, &item.Available, &ingredientsJSON)
+		err := rows.Scan(&item.ID, &item.Name, &item.Description, &item.Category, &item.Price, &item.Available, &item.OutOfStock,
+			&allergens, &added, &removed, &nutritionJSON, &sizes, &item.Station, &item.StationOverride,
+			&startDate, &endDate, &windowsJSON, &slotsJSON, &modifiersJSON, &ingredientsJSON)
 		if err != nil {
 			r.logger.Error("Failed to scan menu items", "error", err)
 			return nil, fmt.Errorf("failed to scan menu item: %v", err)
 		}
 
+		if err = parseMenuAllergensAndNutrition(item, allergens, added, removed, nutritionJSON, sizes); err != nil {
+			r.logger.Error("Failed to parse nutrition overrides", "error", err, "item_id", item.ID)
+			return nil, fmt.Errorf("failed to parse nutrition overrides for item %s: %v", item.ID, err)
+		}
 		if err = r.parseIngredients(ingredientsJSON, &item.Ingredients); err != nil {
 			r.logger.Error("Failed to parse ingredients", "error", err, "item_id", item.ID)
 			return nil, fmt.Errorf("failed to parse ingredients for item %s: %v", item.ID, err)
 		}
+		if item.Schedule, err = parseSchedule(windowsJSON, startDate, endDate); err != nil {
+			r.logger.Error("Failed to parse schedule", "error", err, "item_id", item.ID)
+			return nil, fmt.Errorf("failed to parse schedule for item %s: %v", item.ID, err)
+		}
+		if item.Bundle, err = parseBundle(slotsJSON); err != nil {
+			r.logger.Error("Failed to parse bundle", "error", err, "item_id", item.ID)
+			return nil, fmt.Errorf("failed to parse bundle for item %s: %v", item.ID, err)
+		}
+		if err = json.Unmarshal([]byte(modifiersJSON), &item.Modifiers); err != nil {
+			r.logger.Error("Failed to parse modifiers", "error", err, "item_id", item.ID)
+			return nil, fmt.Errorf("failed to parse modifiers for item %s: %v", item.ID, err)
+		}
 
 		items = append(items, item)
 	}
@@ -127,11 +155,19 @@ func (r *MenuRepository) Create(item *models.MenuItem) error {
 	}()
 
 	query := `
-        INSERT INTO menu_items (id, name, description, category, price, available)
-        VALUES ($1, $2, $3, $4, $5, $6)
+        INSERT INTO menu_items (id, name, description, category, price, available, available_from, available_until,
+                                allergens_added, allergens_removed, nutrition_overrides, available_sizes, station)
+        VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, '')::date, NULLIF($8, '')::date, $9, $10, $11, $12::item_size[], NULLIF($13, '')::station)
     `
 
-	_, err = tx.Exec(query, item.ID, item.Name, item.Description, item.Category, item.Price, item.Available)
+	startDate, endDate := scheduleDates(item.Schedule)
+	nutritionJSON, err := json.Marshal(item.NutritionOverrides)
+	if err != nil {
+		return fmt.Errorf("failed to encode nutrition overrides: %v", err)
+	}
+	_, err = tx.Exec(query, item.ID, item.Name, item.Description, item.Category, item.Price, item.Available, startDate, endDate,
+		"{"+strings.Join(item.AllergenOverrides.Added, ",")+"}", "{"+strings.Join(item.AllergenOverrides.Removed, ",")+"}",
+		string(nutritionJSON), "{"+strings.Join(item.Sizes, ",")+"}", string(item.StationOverride))
 	if err != nil {
 		if strings.Contains(err.Error(), "duplicate key value") || strings.Contains(err.Error(), "violates unique constraint") {
 			r.logger.Warn("Attempted to add duplicate menu item", "item_id", item.ID, "error", err)
@@ -146,6 +182,21 @@ func (r *MenuRepository) Create(item *models.MenuItem) error {
 		return fmt.Errorf("failed to add menu item ingredients: %v", err)
 	}
 
+	if err = r.insertScheduleWindows(tx, item.ID, item.Schedule); err != nil {
+		r.logger.Error("Failed to add menu item schedule", "error", err, "item_id", item.ID)
+		return fmt.Errorf("failed to add menu item schedule: %v", err)
+	}
+
+	if err = r.insertBundleSlots(tx, item.ID, item.Bundle); err != nil {
+		r.logger.Error("Failed to add bundle slots", "error", err, "item_id", item.ID)
+		return fmt.Errorf("failed to add bundle slots: %v", err)
+	}
+
+	if err = r.insertModifiers(tx, item.ID, item.Modifiers); err != nil {
+		r.logger.Error("Failed to add modifiers", "error", err, "item_id", item.ID)
+		return fmt.Errorf("failed to add modifiers: %v", err)
+	}
+
 	if err = tx.Commit(); err != nil {
 		r.logger.Error("Failed to commit transaction", "error", err)
 		return fmt.Errorf("failed to commit transaction: %v", err)
@@ -156,8 +207,8 @@ func (r *MenuRepository) Create(item *models.MenuItem) error {
 	return nil
 }
 
-// Update - updates existing menu item
-func (r *MenuRepository) Update(id string, item *models.MenuItem) error {
+// Update - updates existing menu item; note is recorded in the price history if the price changes
+func (r *MenuRepository) Update(id string, item *models.MenuItem, note models.PriceChangeNote) error {
 	r.logger.Debug("Updating menu item in database", "item_id", id)
 
 	if err := r.validateMenuItemForUpdate(item, id); err != nil {
@@ -177,13 +228,29 @@ func (r *MenuRepository) Update(id string, item *models.MenuItem) error {
 		}
 	}()
 
+	if err = setPriceChangeNote(tx, note); err != nil {
+		r.logger.Error("Failed to set price change note", "error", err, "item_id", id)
+		return fmt.Errorf("failed to set price change note: %v", err)
+	}
+
 	query := `
         UPDATE menu_items
-        SET name = $1, description = $2, category = $3, price = $4, available = $5
+        SET name = $1, description = $2, category = $3, price = $4, available = $5,
+            out_of_stock = out_of_stock AND available = $5,
+            available_from = NULLIF($7, '')::date, available_until = NULLIF($8, '')::date,
+            allergens_added = $9, allergens_removed = $10, nutrition_overrides = $11, available_sizes = $12::item_size[],
+            station = NULLIF($13, '')::station
         WHERE id = $6
     `
 
-	result, err := tx.Exec(query, item.Name, item.Description, item.Category, item.Price, item.Available, id)
+	startDate, endDate := scheduleDates(item.Schedule)
+	nutritionJSON, err := json.Marshal(item.NutritionOverrides)
+	if err != nil {
+		return fmt.Errorf("failed to encode nutrition overrides: %v", err)
+	}
+	result, err := tx.Exec(query, item.Name, item.Description, item.Category, item.Price, item.Available, id, startDate, endDate,
+		"{"+strings.Join(item.AllergenOverrides.Added, ",")+"}", "{"+strings.Join(item.AllergenOverrides.Removed, ",")+"}",
+		string(nutritionJSON), "{"+strings.Join(item.Sizes, ",")+"}", string(item.StationOverride))
 	if err != nil {
 		r.logger.Error("Failed to update menu item", "error", err, "item_id", item.ID)
 		return fmt.Errorf("failed to update menu item: %v", err)
@@ -209,6 +276,36 @@ func (r *MenuRepository) Update(id string, item *models.MenuItem) error {
 		return fmt.Errorf("failed to update menu item ingredients: %v", err)
 	}
 
+	if _, err = tx.Exec(`DELETE FROM menu_item_schedules WHERE menu_item_id = $1`, id); err != nil {
+		r.logger.Error("Failed to delete existing schedule", "error", err, "item_id", id)
+		return fmt.Errorf("failed to delete existing schedule: %v", err)
+	}
+
+	if err = r.insertScheduleWindows(tx, id, item.Schedule); err != nil {
+		r.logger.Error("Failed to update menu item schedule", "error", err, "item_id", id)
+		return fmt.Errorf("failed to update menu item schedule: %v", err)
+	}
+
+	if _, err = tx.Exec(`DELETE FROM menu_bundle_slots WHERE bundle_id = $1`, id); err != nil {
+		r.logger.Error("Failed to delete existing bundle slots", "error", err, "item_id", id)
+		return fmt.Errorf("failed to delete existing bundle slots: %v", err)
+	}
+
+	if err = r.insertBundleSlots(tx, id, item.Bundle); err != nil {
+		r.logger.Error("Failed to update bundle slots", "error", err, "item_id", id)
+		return fmt.Errorf("failed to update bundle slots: %v", err)
+	}
+
+	if _, err = tx.Exec(`DELETE FROM menu_item_modifiers WHERE menu_item_id = $1`, id); err != nil {
+		r.logger.Error("Failed to delete existing modifiers", "error", err, "item_id", id)
+		return fmt.Errorf("failed to delete existing modifiers: %v", err)
+	}
+
+	if err = r.insertModifiers(tx, id, item.Modifiers); err != nil {
+		r.logger.Error("Failed to update modifiers", "error", err, "item_id", id)
+		return fmt.Errorf("failed to update modifiers: %v", err)
+	}
+
 	if err = tx.Commit(); err != nil {
 		r.logger.Error("Failed to commit transaction", "error", err, "item_id", id)
 		return fmt.Errorf("failed to commit transaction: %v", err)
@@ -269,27 +366,37 @@ func (r *MenuRepository) GetByID(id string) (*models.MenuItem, error) {
 	r.logger.Debug("Retrieving menu item from database", "item_id", id)
 
 	query := `
-        SELECT m.id, m.name, m.description, m.category, m.price, m.available,
+        SELECT m.id, m.name, m.description, m.category, m.price, m.available, m.out_of_stock,
+               ` + menuAllergensSQL + `, m.allergens_added, m.allergens_removed, m.nutrition_overrides, m.available_sizes,
+               ` + menuStationSQL + `,
+               COALESCE(to_char(m.available_from, 'YYYY-MM-DD'), ''), COALESCE(to_char(m.available_until, 'YYYY-MM-DD'), ''),
+               ` + menuScheduleWindowsSQL + `,
+               ` + menuBundleSlotsSQL + `,
+               ` + menuModifiersSQL + `,
                COALESCE(
                    json_agg(
                        json_build_object(
                            'ingredient_id', mi.ingredient_id,
-                           'quantity', mi.required_quantity
+                           'quantity', mi.required_quantity,
+                           'unit', mi.unit
                        )
                    ) FILTER (WHERE mi.ingredient_id IS NOT NULL), '[]'::json
                ) as ingredients
         FROM menu_items m
         LEFT JOIN menu_item_ingredients mi ON m.id = mi.menu_item_id
         WHERE m.id = $1
-        GROUP BY m.id, m.name, m.description, m.category, m.price, m.available
+        GROUP BY m.id, m.name, m.description, m.category, m.price, m.available, m.out_of_stock, m.available_from, m.available_until
     `
 
 	row := r.db.QueryRow(query, id)
 
 	item := &models.MenuItem{}
-	var ingredientsJSON string
+	var ingredientsJSON, windowsJSON, slotsJSON, modifiersJSON, startDate, endDate string
+	var allergens, added, removed, nutritionJSON, sizes string
 
-	err := row.Scan(&item.ID, &item.Name, &item.Description, &item.Category, &item.Price, &item.Available, &ingredientsJSON)
+	err := row.Scan(&item.ID, &item.Name, &item.Description, &item.Category, &item.Price, &item.Available, &item.OutOfStock,
+		&allergens, &added, &removed, &nutritionJSON, &sizes, &item.Station, &item.StationOverride,
+		&startDate, &endDate, &windowsJSON, &slotsJSON, &modifiersJSON, &ingredientsJSON)
 	if err != nil {
 		if err == sql.ErrNoRows {
 			r.logger.Warn("Menu item not found", "item_id", id)
@@ -299,15 +406,101 @@ func (r *MenuRepository) GetByID(id string) (*models.MenuItem, error) {
 		return nil, fmt.Errorf("failed to retrieve menu item: %v", err)
 	}
 
+	if err := parseMenuAllergensAndNutrition(item, allergens, added, removed, nutritionJSON, sizes); err != nil {
+		r.logger.Error("Failed to parse nutrition overrides", "error", err, "item_id", item.ID)
+		return nil, fmt.Errorf("failed to parse nutrition overrides for item %s: %v", item.ID, err)
+	}
 	if err := r.parseIngredients(ingredientsJSON, &item.Ingredients); err != nil {
 		r.logger.Error("Failed to parse ingredients", "error", err, "item_id", item.ID)
 		return nil, fmt.Errorf("failed to parse ingredients for item %s: %v", item.ID, err)
 	}
+	if item.Schedule, err = parseSchedule(windowsJSON, startDate, endDate); err != nil {
+		r.logger.Error("Failed to parse schedule", "error", err, "item_id", item.ID)
+		return nil, fmt.Errorf("failed to parse schedule for item %s: %v", item.ID, err)
+	}
+	if item.Bundle, err = parseBundle(slotsJSON); err != nil {
+		r.logger.Error("Failed to parse bundle", "error", err, "item_id", item.ID)
+		return nil, fmt.Errorf("failed to parse bundle for item %s: %v", item.ID, err)
+	}
+	if err = json.Unmarshal([]byte(modifiersJSON), &item.Modifiers); err != nil {
+		r.logger.Error("Failed to parse modifiers", "error", err, "item_id", item.ID)
+		return nil, fmt.Errorf("failed to parse modifiers for item %s: %v", item.ID, err)
+	}
 
 	r.logger.Debug("Retrieved menu item", "item_id", id, "name", item.Name)
 	return item, nil
 }
 
+// SetStockAvailability switches an available item off when it runs out of stock, and back on when it is
+// restocked if it was switched off that way. Items switched off by hand are left alone. Reports whether it changed.
+func (r *MenuRepository) SetStockAvailability(id string, inStock bool) (bool, error) {
+	query := `
+        UPDATE menu_items
+        SET available = false, out_of_stock = true
+        WHERE id = $1 AND available`
+	if inStock {
+		query = `
+        UPDATE menu_items
+        SET available = true, out_of_stock = false
+        WHERE id = $1 AND out_of_stock`
+	}
+
+	result, err := r.db.Exec(query, id)
+	if err != nil {
+		r.logger.Error("Failed to update menu item availability", "error", err, "item_id", id)
+		return false, fmt.Errorf("failed to update menu item availability: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	return rowsAffected > 0, nil
+}
+
+// GetStockDependents loads the menu items whose recipe uses any of the ingredients, with only what stock
+// availability needs: ID, name, availability flags and recipe
+func (r *MenuRepository) GetStockDependents(ingredientIDs []string) ([]*models.MenuItem, error) {
+	query := `
+        SELECT m.id, m.name, m.available, m.out_of_stock,
+               json_agg(
+                   json_build_object(
+                       'ingredient_id', mi.ingredient_id,
+                       'quantity', mi.required_quantity,
+                       'unit', mi.unit
+                   )
+               ) as ingredients
+        FROM menu_items m
+        JOIN menu_item_ingredients mi ON m.id = mi.menu_item_id
+        WHERE m.id IN (SELECT menu_item_id FROM menu_item_ingredients WHERE ingredient_id = ANY($1))
+        GROUP BY m.id, m.name, m.available, m.out_of_stock
+        ORDER BY m.name`
+
+	rows, err := r.db.Query(query, "{"+strings.Join(ingredientIDs, ",")+"}")
+	if err != nil {
+		r.logger.Error("Failed to query menu items by ingredient", "error", err)
+		return nil, fmt.Errorf("failed to query menu items by ingredient: %v", err)
+	}
+	defer rows.Close()
+
+	items := []*models.MenuItem{}
+	for rows.Next() {
+		item := &models.MenuItem{}
+		var ingredientsJSON string
+		if err := rows.Scan(&item.ID, &item.Name, &item.Available, &item.OutOfStock, &ingredientsJSON); err != nil {
+			return nil, fmt.Errorf("failed to scan menu item: %v", err)
+		}
+		if err := r.parseIngredients(ingredientsJSON, &item.Ingredients); err != nil {
+			return nil, fmt.Errorf("failed to parse ingredients for item %s: %v", item.ID, err)
+		}
+		items = append(items, item)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating menu rows: %v", err)
+	}
+	return items, nil
+}
+
 // TODO: Implement GetPopularItems method - Get popular menu items aggregation
 // - Analyze order history
 // - Count item frequencies
@@ -321,18 +514,178 @@ func (r *MenuRepository) GetByID(id string) (*models.MenuItem, error) {
 // - backupFile() → Database backup strategies
 // - validateMenuItem() → Database constraints and validation
 
+// menuScheduleWindowsSQL selects a menu item's schedule windows as JSON, menu_items aliased as m
+const menuScheduleWindowsSQL = `COALESCE((
+                   SELECT json_agg(json_build_object(
+                       'weekdays', s.weekdays,
+                       'start', to_char(s.start_time, 'HH24:MI'),
+                       'end', to_char(s.end_time, 'HH24:MI')
+                   ) ORDER BY s.start_time)
+                   FROM menu_item_schedules s
+                   WHERE s.menu_item_id = m.id
+               ), '[]'::json)`
+
+// menuStationSQL selects where a menu item is made, then the station set on the item itself, menu_items aliased as m.
+// Items go to their own station, else their category's, else the kitchen.
+const menuStationSQL = `COALESCE(m.station, (SELECT cs.station FROM category_stations cs WHERE cs.category = m.category), 'kitchen')::text,
+               COALESCE(m.station::text, '')`
+
+// menuAllergensSQL selects a menu item's allergens: those of its recipe's ingredients and the ones added
+// by hand, less the ones removed by hand, menu_items aliased as m
+const menuAllergensSQL = `COALESCE((
+                   SELECT array_agg(DISTINCT a.allergen ORDER BY a.allergen)
+                   FROM (
+                       SELECT unnest(inv.allergens) AS allergen
+                       FROM menu_item_ingredients ri
+                       JOIN inventory inv ON inv.id = ri.ingredient_id
+                       WHERE ri.menu_item_id = m.id
+                       UNION
+                       SELECT unnest(m.allergens_added)
+                   ) a
+                   WHERE a.allergen <> ALL(m.allergens_removed)
+               ), '{}')`
+
+// parseMenuAllergensAndNutrition sets a menu item's allergens, their overrides, its nutrition overrides and sizes from their columns
+func parseMenuAllergensAndNutrition(item *models.MenuItem, allergens, added, removed, nutritionJSON, sizes string) error {
+	item.Allergens = parsePostgreSQLArray(allergens)
+	item.AllergenOverrides = models.AllergenOverrides{
+		Added:   parsePostgreSQLArray(added),
+		Removed: parsePostgreSQLArray(removed),
+	}
+	item.Sizes = parsePostgreSQLArray(sizes)
+	return json.Unmarshal([]byte(nutritionJSON), &item.NutritionOverrides)
+}
+
+func (r *MenuRepository) insertScheduleWindows(tx *sql.Tx, menuItemID string, schedule *models.MenuSchedule) error {
+	if schedule == nil {
+		return nil
+	}
+
+	query := `
+		INSERT INTO menu_item_schedules (menu_item_id, weekdays, start_time, end_time)
+		VALUES ($1, $2, $3::time, $4::time)`
+	for _, window := range schedule.Windows {
+		if _, err := tx.Exec(query, menuItemID, "{"+strings.Join(window.Weekdays, ",")+"}", window.Start, window.End); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseSchedule builds a menu item's schedule from its columns, nil when it is always sold
+func parseSchedule(windowsJSON, startDate, endDate string) (*models.MenuSchedule, error) {
+	schedule := &models.MenuSchedule{StartDate: startDate, EndDate: endDate}
+	if err := json.Unmarshal([]byte(windowsJSON), &schedule.Windows); err != nil {
+		return nil, err
+	}
+	if schedule.Empty() {
+		return nil, nil
+	}
+	return schedule, nil
+}
+
+func scheduleDates(schedule *models.MenuSchedule) (string, string) {
+	if schedule == nil {
+		return "", ""
+	}
+	return schedule.StartDate, schedule.EndDate
+}
+
+// menuBundleSlotsSQL selects a bundle's slots as JSON, empty for regular items, menu_items aliased as m
+const menuBundleSlotsSQL = `COALESCE((
+                   SELECT json_agg(json_build_object(
+                       'name', b.name,
+                       'quantity', b.quantity,
+                       'category', COALESCE(b.category, ''),
+                       'options', COALESCE((
+                           SELECT json_agg(o.menu_item_id ORDER BY o.menu_item_id)
+                           FROM menu_bundle_slot_options o
+                           WHERE o.slot_id = b.id
+                       ), '[]'::json)
+                   ) ORDER BY b.position)
+                   FROM menu_bundle_slots b
+                   WHERE b.bundle_id = m.id
+               ), '[]'::json)`
+
+func (r *MenuRepository) insertBundleSlots(tx *sql.Tx, bundleID string, bundle *models.MenuBundle) error {
+	if bundle == nil {
+		return nil
+	}
+
+	for i, slot := range bundle.Slots {
+		var slotID string
+		err := tx.QueryRow(`
+			INSERT INTO menu_bundle_slots (bundle_id, position, name, quantity, category)
+			VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+			RETURNING id`,
+			bundleID, i+1, slot.Name, slot.Quantity, string(slot.Category)).Scan(&slotID)
+		if err != nil {
+			return fmt.Errorf("failed to insert slot '%s': %v", slot.Name, err)
+		}
+
+		for _, option := range slot.Options {
+			if _, err := tx.Exec(`INSERT INTO menu_bundle_slot_options (slot_id, menu_item_id) VALUES ($1, $2)`, slotID, option); err != nil {
+				return fmt.Errorf("failed to insert option %s of slot '%s': %v", option, slot.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// menuModifiersSQL selects a menu item's modifiers with their ingredients as JSON, menu_items aliased as m
+const menuModifiersSQL = `COALESCE((
+                   SELECT json_agg(json_build_object('name', md.name, 'ingredients', md.ingredients) ORDER BY md.name)
+                   FROM (
+                       SELECT name, json_agg(json_build_object(
+                           'ingredient_id', ingredient_id,
+                           'quantity', quantity,
+                           'unit', unit
+                       ) ORDER BY ingredient_id) AS ingredients
+                       FROM menu_item_modifiers
+                       WHERE menu_item_id = m.id
+                       GROUP BY name
+                   ) md
+               ), '[]'::json)`
+
+func (r *MenuRepository) insertModifiers(tx *sql.Tx, menuItemID string, modifiers []models.MenuModifier) error {
+	query := `
+		INSERT INTO menu_item_modifiers (menu_item_id, name, ingredient_id, quantity, unit)
+		VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, '')::unit_type, (SELECT unit FROM inventory WHERE id = $3)))`
+
+	for _, modifier := range modifiers {
+		for _, ingredient := range modifier.Ingredients {
+			if _, err := tx.Exec(query, menuItemID, modifier.Name, ingredient.IngredientID, ingredient.Quantity, ingredient.Unit); err != nil {
+				return fmt.Errorf("failed to insert ingredient %s of modifier '%s': %v", ingredient.IngredientID, modifier.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// parseBundle builds a menu item's bundle from its slots, nil for regular items
+func parseBundle(slotsJSON string) (*models.MenuBundle, error) {
+	bundle := &models.MenuBundle{}
+	if err := json.Unmarshal([]byte(slotsJSON), &bundle.Slots); err != nil {
+		return nil, err
+	}
+	if len(bundle.Slots) == 0 {
+		return nil, nil
+	}
+	return bundle, nil
+}
+
 func (r *MenuRepository) insertIngredients(tx *sql.Tx, menuItemId string, ingredients []models.MenuItemIngredient) error {
 	if len(ingredients) == 0 {
 		return nil
 	}
 
 	query := `
-		INSERT INTO menu_item_ingredients (menu_item_id, ingredient_id, required_quantity)
-		VALUES ($1, $2, $3)
+		INSERT INTO menu_item_ingredients (menu_item_id, ingredient_id, required_quantity, unit)
+		VALUES ($1, $2, $3, COALESCE(NULLIF($4, '')::unit_type, (SELECT unit FROM inventory WHERE id = $2)))
 	`
 
 	for _, ingredient := range ingredients {
-		_, err := tx.Exec(query, menuItemId, ingredient.IngredientID, ingredient.Quantity)
+		_, err := tx.Exec(query, menuItemId, ingredient.IngredientID, ingredient.Quantity, ingredient.Unit)
 		if err != nil {
 			return fmt.Errorf("failed to insert ingredient %s: %v", ingredient.IngredientID, err)
 		}
@@ -367,6 +720,7 @@ func (r *MenuRepository) parseIngredients(ingredientsJSON string, ingredients *[
 		parsed = append(parsed, models.MenuItemIngredient{
 			IngredientID: ingredient.IngredientID,
 			Quantity:     ingredient.Quantity,
+			Unit:         ingredient.Unit,
 		})
 	}
 
@@ -395,7 +749,8 @@ func (r *MenuRepository) validateMenuItem(item *models.MenuItem) error {
 		return errors.New("price cannot be negative")
 	}
 
-	if len(item.Ingredients) == 0 {
+	// Bundles are made of other menu items, so they have no recipe of their own
+	if len(item.Ingredients) == 0 && item.Bundle == nil {
 		return errors.New("menu item must have at least 1 ingredient")
 	}
 	for i, ingredient := range item.Ingredients {
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"frappuccino/models"
+)
+
+// recordAllergenOverride writes the allergen warnings accepted for a new or updated order to its status history,
+// with who accepted them, so there is a record of the decision
+func recordAllergenOverride(tx *sql.Tx, order *models.Order) error {
+	if len(order.AllergenWarnings) == 0 {
+		return nil
+	}
+
+	items := make([]string, len(order.AllergenWarnings))
+	for i, warning := range order.AllergenWarnings {
+		items[i] = fmt.Sprintf("%s (%s)", warning.Name, strings.Join(warning.Allergens, ", "))
+	}
+
+	_, err := tx.Exec(`
+		INSERT INTO order_status_history (order_id, old_status, new_status, changed_by, reason)
+		VALUES ($1, NULL, $2, $3, $4)`,
+		order.ID, order.Status, order.AllergenOverrideBy, "Allergen override: "+strings.Join(items, "; "))
+	if err != nil {
+		return fmt.Errorf("failed to record allergen override: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"frappuccino/models"
+)
+
+// insertOrderDiscounts stores the discounts applied to an order. With redeem, promo codes count a use
+// against their rule, failing once the rule's max_uses is reached, and loyalty redemptions are spent
+// from the customer's balance.
+func insertOrderDiscounts(tx *sql.Tx, orderID, customerID string, discounts []models.OrderDiscount, redeem bool) error {
+	for i, discount := range discounts {
+		if redeem && discount.PromoCode != "" {
+			result, err := tx.Exec(`
+				UPDATE pricing_rules SET uses = uses + 1
+				WHERE id = $1 AND (max_uses IS NULL OR uses < max_uses)`, discount.RuleID)
+			if err != nil {
+				return fmt.Errorf("failed to redeem promo code '%s': %v", discount.PromoCode, err)
+			}
+			if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+				return fmt.Errorf("promo code '%s' has been used up", discount.PromoCode)
+			}
+		}
+		if redeem && discount.LoyaltyRuleID != "" {
+			if err := spendLoyalty(tx, customerID, orderID, discount); err != nil {
+				return err
+			}
+		}
+
+		err := tx.QueryRow(`
+			INSERT INTO order_discounts (order_id, rule_id, name, promo_code, amount, loyalty_rule_id, loyalty_points)
+			VALUES ($1, NULLIF($2, '')::uuid, $3, NULLIF($4, ''), $5, NULLIF($6, '')::uuid, NULLIF($7, 0))
+			RETURNING id`,
+			orderID, discount.RuleID, discount.Name, discount.PromoCode, discount.Amount,
+			discount.LoyaltyRuleID, discount.LoyaltyPoints).Scan(&discounts[i].ID)
+		if err != nil {
+			return fmt.Errorf("failed to insert order discount: %v", err)
+		}
+	}
+	return nil
+}
+
+// releasePromoUses gives back the promo code uses of an order that is being cancelled or deleted.
+// A cancelled order already gave them back, so it releases nothing.
+func releasePromoUses(tx *sql.Tx, orderID string) error {
+	_, err := tx.Exec(`
+		UPDATE pricing_rules SET uses = uses - 1
+		WHERE uses > 0 AND id IN (
+			SELECT rule_id FROM order_discounts
+			WHERE order_id = $1 AND promo_code IS NOT NULL
+		)
+		AND NOT EXISTS (SELECT 1 FROM orders WHERE id = $1 AND status = 'cancelled')`, orderID)
+	if err != nil {
+		return fmt.Errorf("failed to release promo code uses: %v", err)
+	}
+	return nil
+}
+
+// getOrderDiscounts loads the discounts of the given orders, keyed by order ID
+func (r *OrderRepository) getOrderDiscounts(orderIDs []string) (map[string][]models.OrderDiscount, error) {
+	discounts := make(map[string][]models.OrderDiscount)
+	if len(orderIDs) == 0 {
+		return discounts, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT order_id, id, COALESCE(rule_id::text, ''), name, COALESCE(promo_code, ''), amount,
+			COALESCE(loyalty_rule_id::text, ''), COALESCE(loyalty_points, 0)
+		FROM order_discounts
+		WHERE order_id = ANY($1)
+		ORDER BY order_id, id`, "{"+strings.Join(orderIDs, ",")+"}")
+	if err != nil {
+		r.logger.Error("Failed to query order discounts", "error", err)
+		return nil, fmt.Errorf("failed to query order discounts: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID string
+		var discount models.OrderDiscount
+		if err := rows.Scan(&orderID, &discount.ID, &discount.RuleID, &discount.Name, &discount.PromoCode, &discount.Amount,
+			&discount.LoyaltyRuleID, &discount.LoyaltyPoints); err != nil {
+			r.logger.Error("Failed to scan order discount", "error", err)
+			return nil, fmt.Errorf("failed to scan order discount: %v", err)
+		}
+		discounts[orderID] = append(discounts[orderID], discount)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating order discounts", "error", err)
+		return nil, fmt.Errorf("error iterating order discounts: %v", err)
+	}
+	return discounts, nil
+}
@@ -11,6 +11,7 @@ package repositories
 // 6. Implement proper SQL schema for orders table with relationships
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"strings"
@@ -26,14 +27,25 @@ import (
 // Interface should remain the same but implementation will change from JSON files to SQL operations
 type OrderRepositoryInterface interface {
 	GetAll() ([]*models.Order, error)
+	GetByCustomer(customerID string) ([]*models.Order, error)
+	GetByStatus(statuses ...string) ([]*models.Order, error)
 	GetByID(id string) (*models.Order, error)
 	Add(order *models.Order) error
 	Update(id string, order *models.Order) error
 	Delete(id string) error
 	CloseOrder(id string) error
+	UpdateStatus(id, from, to string) error
+	UpdateItemPrepStatus(orderID, itemID, status string) error
 	GetNumberOfOrderedItems(startDate, endDate *time.Time) (map[string]int, error)
+	GetItemPrepTimes(since time.Time) ([]ItemPrepTime, error)
 	BatchProcessOrders(orders []*models.Order) ([]*models.Order, error)
-	GetInventoryRequirements(orders []*models.Order) (map[string]float64, error)
+}
+
+// ItemPrepTime is how long a menu item has taken to make, from orders going from preparing to ready
+type ItemPrepTime struct {
+	MenuItemID     string
+	Orders         int     // Orders the time was measured on
+	SecondsPerUnit float64 // Average of each order's preparing time divided by the units on it
 }
 
 // TODO: Transition State: JSON → PostgreSQL
@@ -56,6 +68,15 @@ func NewOrderRepository(logger *logger.Logger, db *database.DB) *OrderRepository
 	}
 }
 
+const orderColumns = `id, customer_name, COALESCE(customer_id::text, ''), status, order_type, subtotal, discount_amount, tax_amount, tax_inclusive,
+		total_amount, COALESCE(special_instructions, '{}'), created_at, updated_at`
+
+const orderInsertQuery = `
+	INSERT INTO orders (customer_name, status, total_amount, special_instructions, discount_amount,
+		order_type, subtotal, tax_amount, tax_inclusive, customer_id)
+	VALUES ($1, $2, $3, $4, $5, COALESCE(NULLIF($6, ''), 'dine_in'), $7, $8, $9, NULLIF($10, '')::uuid)
+	RETURNING id, created_at, updated_at`
+
 // Add adds a new order
 func (r *OrderRepository) Add(order *models.Order) error {
 	r.logger.Debug("Adding new order to database", "customer_name", order.CustomerName)
@@ -78,15 +99,11 @@ func (r *OrderRepository) Add(order *models.Order) error {
 		}
 	}()
 
-	query := `
-		INSERT INTO orders (customer_name, status, total_amount, special_instructions)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, created_at, updated_at`
-
 	generatedID := ""
 	var createdAt, updatedAt time.Time
 
-	err = tx.QueryRow(query, order.CustomerName, order.Status, order.TotalAmount, order.SpecialInstructions).Scan(&generatedID, &createdAt, &updatedAt)
+	err = tx.QueryRow(orderInsertQuery, order.CustomerName, order.Status, order.TotalAmount, specialInstructionsJSON(order), order.DiscountAmount,
+		order.OrderType, order.Subtotal, order.TaxAmount, order.TaxInclusive, order.CustomerID).Scan(&generatedID, &createdAt, &updatedAt)
 	if err != nil {
 		r.logger.Error("Failed to insert order", "error", err, "customer_name", order.CustomerName)
 		return fmt.Errorf("failed to insert order: %v", err)
@@ -98,13 +115,16 @@ func (r *OrderRepository) Add(order *models.Order) error {
 
 	if len(order.Items) > 0 {
 		itemQuery := `
-			INSERT INTO order_items (order_id, menu_item_id, quantity, price_at_time, customizations)
-			VALUES ($1, $2, $3, $4, $5)
+			INSERT INTO order_items (order_id, menu_item_id, quantity, price_at_time, customizations, bundle_id, bundle_line, discount_amount,
+				station, prep_status, prep_started_at, prep_done_at)
+			VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), '{}')::jsonb, NULLIF($6, '')::uuid, NULLIF($7, 0), $8,
+				COALESCE(NULLIF($9, ''), 'kitchen')::station, COALESCE(NULLIF($10, ''), 'queued')::prep_status, $11, $12)
 			RETURNING id`
 
 		for i, item := range order.Items {
 			itemID := ""
-			err := tx.QueryRow(itemQuery, order.ID, item.MenuItemID, item.Quantity, item.PriceAtTime, item.Customizations).Scan(&itemID)
+			err := tx.QueryRow(itemQuery, order.ID, item.MenuItemID, item.Quantity, item.PriceAtTime, string(item.Customizations), item.BundleID, item.BundleLine, item.DiscountAmount,
+				string(item.Station), item.PrepStatus, item.PrepStartedAt, item.PrepDoneAt).Scan(&itemID)
 			if err != nil {
 				r.logger.Error("Failed to insert order item", "error", err, "order_id", order.ID, "menu_item_id", item.MenuItemID)
 				return fmt.Errorf("failed to insert order item: %v", err)
@@ -114,6 +134,19 @@ func (r *OrderRepository) Add(order *models.Order) error {
 		}
 	}
 
+	if err = insertOrderDiscounts(tx, order.ID, order.CustomerID, order.Discounts, true); err != nil {
+		r.logger.Warn("Failed to add order discounts", "error", err, "order_id", order.ID)
+		return err
+	}
+	if err = insertOrderTaxes(tx, order.ID, order.Taxes); err != nil {
+		r.logger.Error("Failed to add order taxes", "error", err, "order_id", order.ID)
+		return err
+	}
+	if err = recordAllergenOverride(tx, order); err != nil {
+		r.logger.Error("Failed to record allergen override", "error", err, "order_id", order.ID)
+		return err
+	}
+
 	err = tx.Commit()
 	if err != nil {
 		r.logger.Error("Failed to commit transaction", "error", err, "order_id", order.ID)
@@ -130,20 +163,24 @@ func (r *OrderRepository) GetByID(id string) (*models.Order, error) {
 	r.logger.Debug("Retrieving order from database", "order_id", id)
 
 	query := `
-		SELECT id, customer_name, status, total_amount, special_instructions, created_at, updated_at
+		SELECT ` + orderColumns + `
 		FROM orders
 		WHERE id = $1`
 
 	order := &models.Order{}
 	var specialInstructions string
-	err := r.db.QueryRow(query, id).Scan(&order.ID, &order.CustomerName, &order.Status, &order.TotalAmount, &specialInstructions, &order.CreatedAt, &order.UpdatedAt)
+	err := r.db.QueryRow(query, id).Scan(&order.ID, &order.CustomerName, &order.CustomerID, &order.Status, &order.OrderType, &order.Subtotal, &order.DiscountAmount,
+		&order.TaxAmount, &order.TaxInclusive, &order.TotalAmount, &specialInstructions, &order.CreatedAt, &order.UpdatedAt)
 	if err != nil {
 		r.logger.Error("Failed to retrieve order", "error", err, "order_id", id)
 		return nil, fmt.Errorf("failed to retrieve order: %v", err)
 	}
+	order.SpecialInstructions = parseSpecialInstructions(specialInstructions)
 
 	itemsQuery := `
-		SELECT id, menu_item_id, quantity, price_at_time, customizations
+		SELECT id, menu_item_id, quantity, price_at_time, customizations,
+		       COALESCE(bundle_id::text, ''), COALESCE(bundle_line, 0), discount_amount,
+		       station, prep_status, prep_started_at, prep_done_at
 		FROM order_items
 		WHERE order_id = $1
 		ORDER BY id`
@@ -159,12 +196,14 @@ func (r *OrderRepository) GetByID(id string) (*models.Order, error) {
 	for rows.Next() {
 		item := models.OrderItem{OrderID: id}
 		customizations := ""
-		err := rows.Scan(&item.ID, &item.MenuItemID, &item.Quantity, &item.PriceAtTime, &customizations)
+		err := rows.Scan(&item.ID, &item.MenuItemID, &item.Quantity, &item.PriceAtTime, &customizations,
+			&item.BundleID, &item.BundleLine, &item.DiscountAmount, &item.Station, &item.PrepStatus, &item.PrepStartedAt, &item.PrepDoneAt)
 		if err != nil {
 			r.logger.Error("Failed to scan order item", "error", err, "order_id", id)
 			return nil, fmt.Errorf("failed to scan order item: %v", err)
 		}
 		item.ProductID = item.MenuItemID
+		item.Customizations = parseCustomizations(customizations)
 		items = append(items, item)
 	}
 
@@ -175,6 +214,19 @@ func (r *OrderRepository) GetByID(id string) (*models.Order, error) {
 	}
 
 	order.Items = items
+
+	discounts, err := r.getOrderDiscounts([]string{id})
+	if err != nil {
+		return nil, err
+	}
+	order.Discounts = discounts[id]
+
+	taxes, err := r.getOrderTaxes([]string{id})
+	if err != nil {
+		return nil, err
+	}
+	order.Taxes = taxes[id]
+
 	r.logger.Debug("Retrieved order with items", "order_id", id, "items_count", len(items))
 	return order, nil
 }
@@ -183,12 +235,38 @@ func (r *OrderRepository) GetByID(id string) (*models.Order, error) {
 func (r *OrderRepository) GetAll() ([]*models.Order, error) {
 	r.logger.Debug("Retrieving all orders from database")
 
+	orders, err := r.queryOrders("")
+	if err != nil {
+		return nil, err
+	}
+
+	r.logger.Info("Retrieved all orders", "count", len(orders))
+	return orders, nil
+}
+
+// GetByCustomer retrieves a customer's orders, newest first
+func (r *OrderRepository) GetByCustomer(customerID string) ([]*models.Order, error) {
+	r.logger.Debug("Retrieving customer orders from database", "customer_id", customerID)
+
+	return r.queryOrders("WHERE customer_id = $1", customerID)
+}
+
+// GetByStatus retrieves the orders in any of the given statuses, newest first
+func (r *OrderRepository) GetByStatus(statuses ...string) ([]*models.Order, error) {
+	r.logger.Debug("Retrieving orders by status from database", "statuses", statuses)
+
+	return r.queryOrders("WHERE status = ANY($1)", "{"+strings.Join(statuses, ",")+"}")
+}
+
+// queryOrders retrieves the orders matching the where clause with their items, discounts and taxes, newest first
+func (r *OrderRepository) queryOrders(where string, args ...interface{}) ([]*models.Order, error) {
 	query := `
-		SELECT id, customer_name, status, total_amount, special_instructions, created_at, updated_at
+		SELECT ` + orderColumns + `
 		FROM orders
+		` + where + `
 		ORDER BY created_at DESC`
 
-	rows, err := r.db.Query(query)
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		r.logger.Error("Failed to query orders", "error", err)
 		return nil, fmt.Errorf("failed to query orders: %v", err)
@@ -201,11 +279,13 @@ func (r *OrderRepository) GetAll() ([]*models.Order, error) {
 	for rows.Next() {
 		order := &models.Order{}
 		var specialInstructions string
-		err := rows.Scan(&order.ID, &order.CustomerName, &order.Status, &order.TotalAmount, &specialInstructions, &order.CreatedAt, &order.UpdatedAt)
+		err := rows.Scan(&order.ID, &order.CustomerName, &order.CustomerID, &order.Status, &order.OrderType, &order.Subtotal, &order.DiscountAmount,
+			&order.TaxAmount, &order.TaxInclusive, &order.TotalAmount, &specialInstructions, &order.CreatedAt, &order.UpdatedAt)
 		if err != nil {
 			r.logger.Error("Failed to scan order", "error", err)
 			return nil, fmt.Errorf("failed to scan order: %v", err)
 		}
+		order.SpecialInstructions = parseSpecialInstructions(specialInstructions)
 		order.Items = []models.OrderItem{}
 		orders = append(orders, order)
 		orderMap[order.ID] = order
@@ -218,7 +298,9 @@ func (r *OrderRepository) GetAll() ([]*models.Order, error) {
 
 	if len(orders) > 0 {
 		itemsQuery := `
-			SELECT order_id, id, menu_item_id, quantity, price_at_time, customizations
+			SELECT order_id, id, menu_item_id, quantity, price_at_time, customizations,
+			       COALESCE(bundle_id::text, ''), COALESCE(bundle_line, 0), discount_amount,
+			       station, prep_status, prep_started_at, prep_done_at
 			FROM order_items
 			WHERE order_id = ANY($1)
 			ORDER BY order_id, id`
@@ -238,12 +320,14 @@ func (r *OrderRepository) GetAll() ([]*models.Order, error) {
 		for itemRows.Next() {
 			item := models.OrderItem{}
 			var customizations string
-			err := itemRows.Scan(&item.OrderID, &item.ID, &item.MenuItemID, &item.Quantity, &item.PriceAtTime, &customizations)
+			err := itemRows.Scan(&item.OrderID, &item.ID, &item.MenuItemID, &item.Quantity, &item.PriceAtTime, &customizations,
+				&item.BundleID, &item.BundleLine, &item.DiscountAmount, &item.Station, &item.PrepStatus, &item.PrepStartedAt, &item.PrepDoneAt)
 			if err != nil {
 				r.logger.Error("Failed to scan order item", "error", err)
 				return nil, fmt.Errorf("failed to scan order item: %v", err)
 			}
 			item.ProductID = item.MenuItemID
+			item.Customizations = parseCustomizations(customizations)
 
 			if order, exists := orderMap[item.OrderID]; exists {
 				order.Items = append(order.Items, item)
@@ -254,9 +338,21 @@ func (r *OrderRepository) GetAll() ([]*models.Order, error) {
 			r.logger.Error("Error iterating order items", "error", err)
 			return nil, fmt.Errorf("error iterating order items: %v", err)
 		}
+
+		discounts, err := r.getOrderDiscounts(orderIDs)
+		if err != nil {
+			return nil, err
+		}
+		taxes, err := r.getOrderTaxes(orderIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, order := range orders {
+			order.Discounts = discounts[order.ID]
+			order.Taxes = taxes[order.ID]
+		}
 	}
 
-	r.logger.Info("Retrieved all orders", "count", len(orders))
 	return orders, nil
 }
 
@@ -281,12 +377,27 @@ func (r *OrderRepository) Update(id string, order *models.Order) error {
 		}
 	}()
 
+	// A cancelled order gives back the promo code uses and loyalty points spent on it. This runs before the
+	// status is saved, so cancelling an order that already is cancelled releases nothing twice.
+	if order.Status == models.OrderCancelled {
+		if err = releasePromoUses(tx, id); err != nil {
+			r.logger.Error("Failed to release promo code uses", "error", err, "order_id", id)
+			return err
+		}
+		if err = reverseOrderLoyalty(tx, id, "order cancelled"); err != nil {
+			r.logger.Error("Failed to reverse order loyalty", "error", err, "order_id", id)
+			return err
+		}
+	}
+
 	query := `
 		UPDATE orders
-		SET customer_name = $1, status = $2, total_amount = $3, special_instructions = $4
+		SET customer_name = $1, status = $2, total_amount = $3, special_instructions = $4, discount_amount = $6,
+		    order_type = $7, subtotal = $8, tax_amount = $9, tax_inclusive = $10, customer_id = NULLIF($11, '')::uuid
 		WHERE id = $5`
 
-	result, err := tx.Exec(query, order.CustomerName, order.Status, order.TotalAmount, "{}", id)
+	result, err := tx.Exec(query, order.CustomerName, order.Status, order.TotalAmount, specialInstructionsJSON(order), id, order.DiscountAmount,
+		order.OrderType, order.Subtotal, order.TaxAmount, order.TaxInclusive, order.CustomerID)
 	if err != nil {
 		r.logger.Error("Failed to update order", "error", err, "order_id", id)
 		return fmt.Errorf("failed to update order: %v", err)
@@ -311,11 +422,14 @@ func (r *OrderRepository) Update(id string, order *models.Order) error {
 
 	if len(order.Items) > 0 {
 		itemQuery := `
-			INSERT INTO order_items (order_id, menu_item_id, quantity, price_at_time, customizations)
-			VALUES ($1, $2, $3, $4, $5)`
+			INSERT INTO order_items (order_id, menu_item_id, quantity, price_at_time, customizations, bundle_id, bundle_line, discount_amount,
+				station, prep_status, prep_started_at, prep_done_at)
+			VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), '{}')::jsonb, NULLIF($6, '')::uuid, NULLIF($7, 0), $8,
+				COALESCE(NULLIF($9, ''), 'kitchen')::station, COALESCE(NULLIF($10, ''), 'queued')::prep_status, $11, $12)`
 
 		for _, item := range order.Items {
-			_, err = tx.Exec(itemQuery, id, item.MenuItemID, item.Quantity, item.PriceAtTime, "{}")
+			_, err = tx.Exec(itemQuery, id, item.MenuItemID, item.Quantity, item.PriceAtTime, string(item.Customizations), item.BundleID, item.BundleLine, item.DiscountAmount,
+				string(item.Station), item.PrepStatus, item.PrepStartedAt, item.PrepDoneAt)
 			if err != nil {
 				r.logger.Error("Failed to insert updated order item", "error", err, "order_id", id, "menu_item_id", item.MenuItemID)
 				return fmt.Errorf("failed to insert updated order item: %v", err)
@@ -323,6 +437,30 @@ func (r *OrderRepository) Update(id string, order *models.Order) error {
 		}
 	}
 
+	// Promo codes were redeemed when the order was created, repricing doesn't count them again
+	if _, err = tx.Exec(`DELETE FROM order_discounts WHERE order_id = $1`, id); err != nil {
+		r.logger.Error("Failed to delete existing order discounts", "error", err, "order_id", id)
+		return fmt.Errorf("failed to delete existing order discounts: %v", err)
+	}
+	if err = insertOrderDiscounts(tx, id, order.CustomerID, order.Discounts, false); err != nil {
+		r.logger.Error("Failed to insert order discounts", "error", err, "order_id", id)
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM order_taxes WHERE order_id = $1`, id); err != nil {
+		r.logger.Error("Failed to delete existing order taxes", "error", err, "order_id", id)
+		return fmt.Errorf("failed to delete existing order taxes: %v", err)
+	}
+	if err = insertOrderTaxes(tx, id, order.Taxes); err != nil {
+		r.logger.Error("Failed to insert order taxes", "error", err, "order_id", id)
+		return err
+	}
+
+	if err = recordAllergenOverride(tx, order); err != nil {
+		r.logger.Error("Failed to record allergen override", "error", err, "order_id", id)
+		return err
+	}
+
 	if err = tx.Commit(); err != nil {
 		r.logger.Error("Failed to commit transaction", "error", err, "order_id", id)
 		return fmt.Errorf("failed to commit transaction: %v", err)
@@ -337,9 +475,26 @@ func (r *OrderRepository) Update(id string, order *models.Order) error {
 func (r *OrderRepository) Delete(id string) error {
 	r.logger.Debug("Deleting order from database", "order_id", id)
 
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	// A deleted order never happened, so its promo codes can be used again
+	if err := releasePromoUses(tx, id); err != nil {
+		r.logger.Error("Failed to release promo code uses", "error", err, "order_id", id)
+		return err
+	}
+	if err := reverseOrderLoyalty(tx, id, "order deleted"); err != nil {
+		r.logger.Error("Failed to reverse order loyalty", "error", err, "order_id", id)
+		return err
+	}
+
 	query := `DELETE FROM orders WHERE id = $1`
 
-	result, err := r.db.Exec(query, id)
+	result, err := tx.Exec(query, id)
 	if err != nil {
 		r.logger.Error("Failed to delete order", "error", err, "order_id", id)
 		return fmt.Errorf("failed to delete order: %v", err)
@@ -355,6 +510,11 @@ func (r *OrderRepository) Delete(id string) error {
 		return fmt.Errorf("order with id %s not found", id)
 	}
 
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err, "order_id", id)
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
 	r.logger.Info("Deleted order", "order_id", id)
 	return nil
 }
@@ -388,6 +548,61 @@ func (r *OrderRepository) CloseOrder(id string) error {
 	return nil
 }
 
+// UpdateStatus moves an order from one status to another without touching its items. It fails if the order
+// is no longer in the from status, so concurrent changes don't both apply.
+func (r *OrderRepository) UpdateStatus(id, from, to string) error {
+	r.logger.Debug("Updating order status in database", "order_id", id, "from", from, "to", to)
+
+	result, err := r.db.Exec(`UPDATE orders SET status = $3 WHERE id = $1 AND status = $2`, id, from, to)
+	if err != nil {
+		r.logger.Error("Failed to update order status", "error", err, "order_id", id)
+		return fmt.Errorf("failed to update order status: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error("Failed to get rows affected", "error", err, "order_id", id)
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		r.logger.Warn("Order not found in expected status", "order_id", id, "status", from)
+		return fmt.Errorf("order with id %s not found or no longer %s", id, from)
+	}
+
+	r.logger.Info("Updated order status", "order_id", id, "status", to)
+	return nil
+}
+
+// UpdateItemPrepStatus sets how far an order item is made, stamping when it was started and finished.
+// Moving an item back clears the times it no longer has.
+func (r *OrderRepository) UpdateItemPrepStatus(orderID, itemID, status string) error {
+	r.logger.Debug("Updating order item prep status in database", "order_id", orderID, "item_id", itemID, "status", status)
+
+	query := `
+		UPDATE order_items
+		SET prep_status = $3::prep_status,
+		    prep_started_at = CASE WHEN $3::prep_status = 'queued' THEN NULL ELSE COALESCE(prep_started_at, CURRENT_TIMESTAMP) END,
+		    prep_done_at = CASE WHEN $3::prep_status = 'done' THEN COALESCE(prep_done_at, CURRENT_TIMESTAMP) END
+		WHERE order_id = $1 AND id = $2`
+
+	result, err := r.db.Exec(query, orderID, itemID, status)
+	if err != nil {
+		r.logger.Error("Failed to update order item prep status", "error", err, "item_id", itemID)
+		return fmt.Errorf("failed to update order item prep status: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error("Failed to get rows affected", "error", err, "item_id", itemID)
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		r.logger.Warn("Attempted to update non-existent order item", "order_id", orderID, "item_id", itemID)
+		return fmt.Errorf("order item with id %s not found", itemID)
+	}
+	return nil
+}
+
 // GetNumberOfOrderedItems retrieves number of ordered items count by date interval
 func (r *OrderRepository) GetNumberOfOrderedItems(startDate, endDate *time.Time) (map[string]int, error) {
 	r.logger.Debug("Retrieving number of ordered items", "startDate", startDate, "endDate", endDate)
@@ -439,6 +654,56 @@ func (r *OrderRepository) GetNumberOfOrderedItems(startDate, endDate *time.Time)
 	return result, nil
 }
 
+// GetItemPrepTimes measures the prep time per unit of each menu item on the orders that became ready since the given time
+func (r *OrderRepository) GetItemPrepTimes(since time.Time) ([]ItemPrepTime, error) {
+	r.logger.Debug("Retrieving item prep times", "since", since)
+
+	query := `
+		WITH timings AS (
+			SELECT order_id,
+			       EXTRACT(EPOCH FROM MIN(changed_at) FILTER (WHERE new_status = 'ready')
+			                        - MIN(changed_at) FILTER (WHERE new_status = 'preparing')) AS seconds
+			FROM order_status_history
+			GROUP BY order_id
+			HAVING MIN(changed_at) FILTER (WHERE new_status = 'ready') >= $1
+		), units AS (
+			SELECT order_id, SUM(quantity) AS quantity
+			FROM order_items
+			WHERE order_id IN (SELECT order_id FROM timings WHERE seconds > 0)
+			GROUP BY order_id
+		)
+		SELECT oi.menu_item_id, COUNT(DISTINCT t.order_id), AVG(t.seconds / u.quantity)
+		FROM timings t
+		JOIN units u ON u.order_id = t.order_id
+		JOIN order_items oi ON oi.order_id = t.order_id
+		WHERE t.seconds > 0 AND u.quantity > 0
+		GROUP BY oi.menu_item_id`
+
+	rows, err := r.db.Query(query, since)
+	if err != nil {
+		r.logger.Error("Failed to query item prep times", "error", err)
+		return nil, fmt.Errorf("failed to query item prep times: %v", err)
+	}
+	defer rows.Close()
+
+	var times []ItemPrepTime
+	for rows.Next() {
+		var prep ItemPrepTime
+		if err := rows.Scan(&prep.MenuItemID, &prep.Orders, &prep.SecondsPerUnit); err != nil {
+			r.logger.Error("Failed to scan item prep time", "error", err)
+			return nil, fmt.Errorf("failed to scan item prep time: %v", err)
+		}
+		times = append(times, prep)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating item prep times", "error", err)
+		return nil, fmt.Errorf("error iterating item prep times: %v", err)
+	}
+
+	r.logger.Debug("Retrieved item prep times", "count", len(times))
+	return times, nil
+}
+
 // BatchProcessOrders processes multiple orders in a single transaction
 func (r *OrderRepository) BatchProcessOrders(orders []*models.Order) ([]*models.Order, error) {
 	r.logger.Debug("Batch processing orders", "count", len(orders))
@@ -458,15 +723,11 @@ func (r *OrderRepository) BatchProcessOrders(orders []*models.Order) ([]*models.
 			return nil, fmt.Errorf("order %d validation failed: %v", i, err)
 		}
 
-		query := `
-			INSERT INTO orders (customer_name, status, total_amount, special_instructions)
-			VALUES ($1, $2, $3, $4)
-			RETURNING id, created_at, updated_at`
-
 		var generatedID string
 		var createdAt, updatedAt time.Time
 
-		err = tx.QueryRow(query, order.CustomerName, order.Status, order.TotalAmount, order.SpecialInstructions).Scan(&generatedID, &createdAt, &updatedAt)
+		err = tx.QueryRow(orderInsertQuery, order.CustomerName, order.Status, order.TotalAmount, specialInstructionsJSON(order), order.DiscountAmount,
+			order.OrderType, order.Subtotal, order.TaxAmount, order.TaxInclusive, order.CustomerID).Scan(&generatedID, &createdAt, &updatedAt)
 		if err != nil {
 			r.logger.Error("Failed to insert order in batch", "error", err, "customer", order.CustomerName)
 			return nil, fmt.Errorf("failed to insert order %d: %v", i, err)
@@ -478,13 +739,16 @@ func (r *OrderRepository) BatchProcessOrders(orders []*models.Order) ([]*models.
 
 		if len(order.Items) > 0 {
 			itemQuery := `
-				INSERT INTO order_items (order_id, menu_item_id, quantity, price_at_time, customizations)
-				VALUES ($1, $2, $3, $4, $5)
+				INSERT INTO order_items (order_id, menu_item_id, quantity, price_at_time, customizations, bundle_id, bundle_line, discount_amount,
+				station, prep_status, prep_started_at, prep_done_at)
+				VALUES ($1, $2, $3, $4, COALESCE(NULLIF($5, ''), '{}')::jsonb, NULLIF($6, '')::uuid, NULLIF($7, 0), $8,
+				COALESCE(NULLIF($9, ''), 'kitchen')::station, COALESCE(NULLIF($10, ''), 'queued')::prep_status, $11, $12)
 				RETURNING id`
 
 			for j, item := range order.Items {
 				itemID := ""
-				err := tx.QueryRow(itemQuery, order.ID, item.MenuItemID, item.Quantity, item.PriceAtTime, item.Customizations).Scan(&itemID)
+				err := tx.QueryRow(itemQuery, order.ID, item.MenuItemID, item.Quantity, item.PriceAtTime, string(item.Customizations), item.BundleID, item.BundleLine, item.DiscountAmount,
+					string(item.Station), item.PrepStatus, item.PrepStartedAt, item.PrepDoneAt).Scan(&itemID)
 				if err != nil {
 					r.logger.Error("Failed to insert order item in batch", "error", err, "order_id", order.ID, "menu_item_id", item.MenuItemID)
 					return nil, fmt.Errorf("failed to insert order item for order %d: %v", i, err)
@@ -494,6 +758,19 @@ func (r *OrderRepository) BatchProcessOrders(orders []*models.Order) ([]*models.
 			}
 		}
 
+		if err := insertOrderDiscounts(tx, order.ID, order.CustomerID, order.Discounts, true); err != nil {
+			r.logger.Warn("Failed to add order discounts in batch", "error", err, "order_id", order.ID)
+			return nil, fmt.Errorf("order %d: %v", i, err)
+		}
+		if err := insertOrderTaxes(tx, order.ID, order.Taxes); err != nil {
+			r.logger.Error("Failed to add order taxes in batch", "error", err, "order_id", order.ID)
+			return nil, fmt.Errorf("order %d: %v", i, err)
+		}
+		if err := recordAllergenOverride(tx, order); err != nil {
+			r.logger.Error("Failed to record allergen override in batch", "error", err, "order_id", order.ID)
+			return nil, fmt.Errorf("order %d: %v", i, err)
+		}
+
 		processedOrders[i] = order
 	}
 
@@ -507,51 +784,6 @@ func (r *OrderRepository) BatchProcessOrders(orders []*models.Order) ([]*models.
 	return processedOrders, nil
 }
 
-// GetInventoryRequirements calculates total ingredient requirements for multiple orders
-func (r *OrderRepository) GetInventoryRequirements(orders []*models.Order) (map[string]float64, error) {
-	r.logger.Debug("Calculating inventory requirements for batch orders", "count", len(orders))
-
-	requirements := make(map[string]float64)
-
-	for _, order := range orders {
-		for _, item := range order.Items {
-			query := `
-				SELECT mi.ingredient_id, mi.required_quantity
-				FROM menu_item_ingredients mi
-				WHERE mi.menu_item_id = $1`
-
-			rows, err := r.db.Query(query, item.MenuItemID)
-			if err != nil {
-				r.logger.Error("Failed to query ingredient requirements", "error", err, "menu_item_id", item.MenuItemID)
-				return nil, fmt.Errorf("failed to query ingredient requirements: %v", err)
-			}
-			defer rows.Close()
-
-			for rows.Next() {
-				var ingredientID string
-				var quantity float64
-
-				err := rows.Scan(&ingredientID, &quantity)
-				if err != nil {
-					r.logger.Error("Failed to scan ingredient requirement", "error", err)
-					return nil, fmt.Errorf("failed to scan ingredient requirement: %v", err)
-				}
-
-				totalNeeded := quantity * float64(item.Quantity)
-				requirements[ingredientID] += totalNeeded
-			}
-
-			if err = rows.Err(); err != nil {
-				r.logger.Error("Error iterating ingredient requirements", "error", err)
-				return nil, fmt.Errorf("error iterating ingredient requirements: %v", err)
-			}
-		}
-	}
-
-	r.logger.Info("Calculated inventory requirements", "ingredient_count", len(requirements))
-	return requirements, nil
-}
-
 // TODO: Transition State: JSON → PostgreSQL
 // DEPRECATED: All file operations below should be removed and replaced with SQL queries
 // - validateOrder() → Database constraints and triggers
@@ -587,6 +819,27 @@ func (r *OrderRepository) validateOrder(order *models.Order) error {
 	return nil
 }
 
+// specialInstructionsJSON is the value stored in orders.special_instructions, an empty object when there are none
+func specialInstructionsJSON(order *models.Order) string {
+	if len(order.SpecialInstructions) == 0 {
+		return "{}"
+	}
+	return string(order.SpecialInstructions)
+}
+
+// parseSpecialInstructions leaves an empty object out of the order
+func parseSpecialInstructions(value string) json.RawMessage {
+	if value == "" || value == "{}" {
+		return nil
+	}
+	return json.RawMessage(value)
+}
+
+// parseCustomizations reads an order item's customizations, nil when there are none
+func parseCustomizations(value string) json.RawMessage {
+	return parseSpecialInstructions(value)
+}
+
 func (r *OrderRepository) validateOrderForUpdate(order *models.Order, id string) error {
 	if id == "" {
 		return errors.New("order ID cannot be empty")
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"frappuccino/models"
+)
+
+// insertOrderTaxes stores the tax lines of an order
+func insertOrderTaxes(tx *sql.Tx, orderID string, taxes []models.OrderTax) error {
+	for i, tax := range taxes {
+		err := tx.QueryRow(`
+			INSERT INTO order_taxes (order_id, tax_rate_id, name, rate, net_amount, tax_amount)
+			VALUES ($1, NULLIF($2, '')::uuid, $3, $4, $5, $6)
+			RETURNING id`,
+			orderID, tax.TaxRateID, tax.Name, tax.Rate, tax.NetAmount, tax.TaxAmount).Scan(&taxes[i].ID)
+		if err != nil {
+			return fmt.Errorf("failed to insert order tax: %v", err)
+		}
+	}
+	return nil
+}
+
+// getOrderTaxes loads the tax lines of the given orders, keyed by order ID
+func (r *OrderRepository) getOrderTaxes(orderIDs []string) (map[string][]models.OrderTax, error) {
+	taxes := make(map[string][]models.OrderTax)
+	if len(orderIDs) == 0 {
+		return taxes, nil
+	}
+
+	rows, err := r.db.Query(`
+		SELECT order_id, id, COALESCE(tax_rate_id::text, ''), name, rate, net_amount, tax_amount
+		FROM order_taxes
+		WHERE order_id = ANY($1)
+		ORDER BY order_id, rate DESC, name`, "{"+strings.Join(orderIDs, ",")+"}")
+	if err != nil {
+		r.logger.Error("Failed to query order taxes", "error", err)
+		return nil, fmt.Errorf("failed to query order taxes: %v", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var orderID string
+		var tax models.OrderTax
+		if err := rows.Scan(&orderID, &tax.ID, &tax.TaxRateID, &tax.Name, &tax.Rate, &tax.NetAmount, &tax.TaxAmount); err != nil {
+			r.logger.Error("Failed to scan order tax", "error", err)
+			return nil, fmt.Errorf("failed to scan order tax: %v", err)
+		}
+		taxes[orderID] = append(taxes[orderID], tax)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating order taxes", "error", err)
+		return nil, fmt.Errorf("error iterating order taxes: %v", err)
+	}
+	return taxes, nil
+}
@@ -0,0 +1,213 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+
+	"frappuccino/models"
+	"frappuccino/pkg/database"
+	"frappuccino/pkg/logger"
+)
+
+type PaymentRepositoryInterface interface {
+	GetByOrder(orderID string) ([]*models.Payment, []*models.Refund, error)
+	GetByID(id string) (*models.Payment, error)
+	Create(payment *models.Payment) error
+	Refund(refund *models.Refund) error
+}
+
+type PaymentRepository struct {
+	logger *logger.Logger
+	db     *database.DB
+}
+
+func NewPaymentRepository(logger *logger.Logger, db *database.DB) *PaymentRepository {
+	return &PaymentRepository{
+		logger: logger.WithComponent("payment_repository"),
+		db:     db,
+	}
+}
+
+const paymentSelectQuery = `
+	SELECT id, order_id, tender, amount, tip, COALESCE(tendered, 0), change_given, COALESCE(guest, ''),
+		COALESCE(reference, ''), refunded_amount, created_at
+	FROM payments`
+
+// GetByOrder returns an order's payments and refunds, oldest first
+func (r *PaymentRepository) GetByOrder(orderID string) ([]*models.Payment, []*models.Refund, error) {
+	r.logger.Debug("Retrieving order payments", "order_id", orderID)
+
+	rows, err := r.db.Query(paymentSelectQuery+` WHERE order_id = $1 ORDER BY created_at, id`, orderID)
+	if err != nil {
+		r.logger.Error("Failed to query payments", "error", err, "order_id", orderID)
+		return nil, nil, fmt.Errorf("failed to query payments: %v", err)
+	}
+	payments, err := scanPayments(rows)
+	rows.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows, err = r.db.Query(`
+		SELECT rf.id, rf.payment_id, rf.order_id, p.tender, rf.amount, rf.reason, COALESCE(rf.reference, ''), rf.created_at
+		FROM refunds rf
+		JOIN payments p ON p.id = rf.payment_id
+		WHERE rf.order_id = $1
+		ORDER BY rf.created_at, rf.id`, orderID)
+	if err != nil {
+		r.logger.Error("Failed to query refunds", "error", err, "order_id", orderID)
+		return nil, nil, fmt.Errorf("failed to query refunds: %v", err)
+	}
+	defer rows.Close()
+
+	refunds := []*models.Refund{}
+	for rows.Next() {
+		refund := &models.Refund{}
+		if err := rows.Scan(&refund.ID, &refund.PaymentID, &refund.OrderID, &refund.Tender, &refund.Amount,
+			&refund.Reason, &refund.Reference, &refund.CreatedAt); err != nil {
+			r.logger.Error("Failed to scan refund", "error", err)
+			return nil, nil, fmt.Errorf("failed to scan refund: %v", err)
+		}
+		refunds = append(refunds, refund)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating refunds", "error", err)
+		return nil, nil, fmt.Errorf("error iterating refunds: %v", err)
+	}
+
+	return payments, refunds, nil
+}
+
+// GetByID returns a payment
+func (r *PaymentRepository) GetByID(id string) (*models.Payment, error) {
+	rows, err := r.db.Query(paymentSelectQuery+` WHERE id = $1`, id)
+	if err != nil {
+		r.logger.Error("Failed to query payment", "error", err, "payment_id", id)
+		return nil, fmt.Errorf("failed to query payment: %v", err)
+	}
+	defer rows.Close()
+
+	payments, err := scanPayments(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(payments) == 0 {
+		return nil, fmt.Errorf("payment with id %s not found", id)
+	}
+	return payments[0], nil
+}
+
+// Create records a payment. The order row is locked while the balance is checked, so concurrent
+// payments can't take more than the order total.
+func (r *PaymentRepository) Create(payment *models.Payment) error {
+	r.logger.Debug("Recording payment", "order_id", payment.OrderID, "tender", payment.Tender, "amount", payment.Amount)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	var total float64
+	err = tx.QueryRow(`SELECT total_amount FROM orders WHERE id = $1 FOR UPDATE`, payment.OrderID).Scan(&total)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("order with id %s not found", payment.OrderID)
+	}
+	if err != nil {
+		r.logger.Error("Failed to lock order", "error", err, "order_id", payment.OrderID)
+		return fmt.Errorf("failed to lock order: %v", err)
+	}
+
+	var paid float64
+	if err := tx.QueryRow(`
+		SELECT COALESCE(SUM(amount - refunded_amount), 0) FROM payments WHERE order_id = $1`,
+		payment.OrderID).Scan(&paid); err != nil {
+		r.logger.Error("Failed to sum payments", "error", err, "order_id", payment.OrderID)
+		return fmt.Errorf("failed to sum payments: %v", err)
+	}
+	if balance := total - paid; payment.Amount > balance+0.005 {
+		return fmt.Errorf("payment of %.2f exceeds the balance of %.2f", payment.Amount, balance)
+	}
+
+	var tendered interface{}
+	if payment.Tender == models.TenderCash {
+		tendered = payment.Tendered
+	}
+	err = tx.QueryRow(`
+		INSERT INTO payments (order_id, tender, amount, tip, tendered, change_given, guest, reference)
+		VALUES ($1, $2, $3, $4, $5, $6, NULLIF($7, ''), NULLIF($8, ''))
+		RETURNING id, created_at`,
+		payment.OrderID, payment.Tender, payment.Amount, payment.Tip, tendered, payment.Change, payment.Guest, payment.Reference,
+	).Scan(&payment.ID, &payment.CreatedAt)
+	if err != nil {
+		r.logger.Error("Failed to insert payment", "error", err, "order_id", payment.OrderID)
+		return fmt.Errorf("failed to insert payment: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	r.logger.Info("Recorded payment", "payment_id", payment.ID, "order_id", payment.OrderID, "amount", payment.Amount)
+	return nil
+}
+
+// Refund records a refund against a payment, failing when it exceeds what is left to refund
+func (r *PaymentRepository) Refund(refund *models.Refund) error {
+	r.logger.Debug("Recording refund", "payment_id", refund.PaymentID, "amount", refund.Amount)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE payments SET refunded_amount = refunded_amount + $2
+		WHERE id = $1 AND refunded_amount + $2 <= amount`, refund.PaymentID, refund.Amount)
+	if err != nil {
+		r.logger.Error("Failed to update refunded amount", "error", err, "payment_id", refund.PaymentID)
+		return fmt.Errorf("failed to update refunded amount: %v", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("refund of %.2f exceeds what is left to refund on payment %s", refund.Amount, refund.PaymentID)
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO refunds (payment_id, order_id, amount, reason, reference)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		RETURNING id, created_at`,
+		refund.PaymentID, refund.OrderID, refund.Amount, refund.Reason, refund.Reference,
+	).Scan(&refund.ID, &refund.CreatedAt)
+	if err != nil {
+		r.logger.Error("Failed to insert refund", "error", err, "payment_id", refund.PaymentID)
+		return fmt.Errorf("failed to insert refund: %v", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	r.logger.Info("Recorded refund", "refund_id", refund.ID, "payment_id", refund.PaymentID, "amount", refund.Amount)
+	return nil
+}
+
+func scanPayments(rows *sql.Rows) ([]*models.Payment, error) {
+	payments := []*models.Payment{}
+	for rows.Next() {
+		payment := &models.Payment{}
+		if err := rows.Scan(&payment.ID, &payment.OrderID, &payment.Tender, &payment.Amount, &payment.Tip, &payment.Tendered,
+			&payment.Change, &payment.Guest, &payment.Reference, &payment.RefundedAmount, &payment.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan payment: %v", err)
+		}
+		payments = append(payments, payment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating payments: %v", err)
+	}
+	return payments, nil
+}
@@ -0,0 +1,229 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"frappuccino/models"
+	"frappuccino/pkg/database"
+	"frappuccino/pkg/logger"
+)
+
+type PriceRepositoryInterface interface {
+	GetHistory(menuItemID string) ([]*models.PriceHistoryEntry, error)
+	GetScheduled(menuItemID string) ([]*models.ScheduledPriceChange, error)
+	Schedule(change *models.ScheduledPriceChange) error
+	Cancel(menuItemID, changeID string) error
+	ApplyDue(now time.Time) ([]*models.ScheduledPriceChange, error)
+}
+
+type PriceRepository struct {
+	logger *logger.Logger
+	db     *database.DB
+}
+
+func NewPriceRepository(logger *logger.Logger, db *database.DB) *PriceRepository {
+	return &PriceRepository{
+		logger: logger.WithComponent("price_repository"),
+		db:     db,
+	}
+}
+
+const scheduledPriceSelectQuery = `
+	SELECT id, menu_item_id, new_price, effective_at, changed_by, COALESCE(reason, ''), status, created_at, applied_at
+	FROM scheduled_price_changes`
+
+// GetHistory returns the recorded price changes of a menu item, newest first
+func (r *PriceRepository) GetHistory(menuItemID string) ([]*models.PriceHistoryEntry, error) {
+	r.logger.Debug("Retrieving price history", "menu_item_id", menuItemID)
+
+	rows, err := r.db.Query(`
+		SELECT id, menu_item_id, old_price, new_price, changed_at, COALESCE(changed_by, 'system'), COALESCE(reason, '')
+		FROM price_history
+		WHERE menu_item_id = $1
+		ORDER BY changed_at DESC, id`, menuItemID)
+	if err != nil {
+		r.logger.Error("Failed to query price history", "error", err, "menu_item_id", menuItemID)
+		return nil, fmt.Errorf("failed to query price history: %v", err)
+	}
+	defer rows.Close()
+
+	entries := []*models.PriceHistoryEntry{}
+	for rows.Next() {
+		entry := &models.PriceHistoryEntry{}
+		var oldPrice sql.NullFloat64
+		if err := rows.Scan(&entry.ID, &entry.MenuItemID, &oldPrice, &entry.NewPrice, &entry.ChangedAt, &entry.ChangedBy, &entry.Reason); err != nil {
+			r.logger.Error("Failed to scan price history", "error", err)
+			return nil, fmt.Errorf("failed to scan price history: %v", err)
+		}
+		if oldPrice.Valid {
+			entry.OldPrice = &oldPrice.Float64
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating price history rows", "error", err)
+		return nil, fmt.Errorf("error iterating price history rows: %v", err)
+	}
+	return entries, nil
+}
+
+// GetScheduled returns the scheduled price changes of a menu item, in the order they take effect
+func (r *PriceRepository) GetScheduled(menuItemID string) ([]*models.ScheduledPriceChange, error) {
+	r.logger.Debug("Retrieving scheduled price changes", "menu_item_id", menuItemID)
+
+	rows, err := r.db.Query(scheduledPriceSelectQuery+`
+		WHERE menu_item_id = $1
+		ORDER BY effective_at, created_at`, menuItemID)
+	if err != nil {
+		r.logger.Error("Failed to query scheduled price changes", "error", err, "menu_item_id", menuItemID)
+		return nil, fmt.Errorf("failed to query scheduled price changes: %v", err)
+	}
+	defer rows.Close()
+
+	return scanScheduledPriceChanges(rows)
+}
+
+// Schedule stores a pending price change; its ID, status and creation time are filled in from the database
+func (r *PriceRepository) Schedule(change *models.ScheduledPriceChange) error {
+	r.logger.Debug("Scheduling price change", "menu_item_id", change.MenuItemID, "effective_at", change.EffectiveAt)
+
+	err := r.db.QueryRow(`
+		INSERT INTO scheduled_price_changes (menu_item_id, new_price, effective_at, changed_by, reason)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		RETURNING id, status, created_at`,
+		change.MenuItemID, change.NewPrice, change.EffectiveAt, change.ChangedBy, change.Reason,
+	).Scan(&change.ID, &change.Status, &change.CreatedAt)
+	if err != nil {
+		r.logger.Error("Failed to schedule price change", "error", err, "menu_item_id", change.MenuItemID)
+		return fmt.Errorf("failed to schedule price change: %v", err)
+	}
+
+	r.logger.Info("Scheduled price change", "id", change.ID, "menu_item_id", change.MenuItemID, "new_price", change.NewPrice)
+	return nil
+}
+
+// Cancel withdraws a pending price change of a menu item
+func (r *PriceRepository) Cancel(menuItemID, changeID string) error {
+	r.logger.Debug("Cancelling scheduled price change", "menu_item_id", menuItemID, "id", changeID)
+
+	var status string
+	err := r.db.QueryRow(`
+		SELECT status FROM scheduled_price_changes
+		WHERE id = $1 AND menu_item_id = $2`, changeID, menuItemID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("scheduled price change with id %s not found", changeID)
+	}
+	if err != nil {
+		r.logger.Error("Failed to get scheduled price change", "error", err, "id", changeID)
+		return fmt.Errorf("failed to get scheduled price change: %v", err)
+	}
+	if status != models.PriceChangePending {
+		return fmt.Errorf("cannot cancel %s price change", status)
+	}
+
+	// The status guard loses a race with the job applying it at the same moment
+	result, err := r.db.Exec(`
+		UPDATE scheduled_price_changes SET status = $2
+		WHERE id = $1 AND status = $3`, changeID, models.PriceChangeCancelled, models.PriceChangePending)
+	if err != nil {
+		r.logger.Error("Failed to cancel scheduled price change", "error", err, "id", changeID)
+		return fmt.Errorf("failed to cancel scheduled price change: %v", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("cannot cancel applied price change")
+	}
+
+	r.logger.Info("Cancelled scheduled price change", "id", changeID)
+	return nil
+}
+
+// ApplyDue sets the price of every pending change effective at or before now, oldest first,
+// and records each in the price history under its actor and reason
+func (r *PriceRepository) ApplyDue(now time.Time) ([]*models.ScheduledPriceChange, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	rows, err := tx.Query(scheduledPriceSelectQuery+`
+		WHERE status = $1 AND effective_at <= $2
+		ORDER BY effective_at, created_at
+		FOR UPDATE SKIP LOCKED`, models.PriceChangePending, now)
+	if err != nil {
+		r.logger.Error("Failed to query due price changes", "error", err)
+		return nil, fmt.Errorf("failed to query due price changes: %v", err)
+	}
+	changes, err := scanScheduledPriceChanges(rows)
+	rows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range changes {
+		reason := change.Reason
+		if reason == "" {
+			reason = "Scheduled price change"
+		}
+		if err = setPriceChangeNote(tx, models.PriceChangeNote{ChangedBy: change.ChangedBy, Reason: reason}); err != nil {
+			r.logger.Error("Failed to set price change note", "error", err, "id", change.ID)
+			return nil, fmt.Errorf("failed to set price change note: %v", err)
+		}
+		if _, err = tx.Exec(`UPDATE menu_items SET price = $2 WHERE id = $1`, change.MenuItemID, change.NewPrice); err != nil {
+			r.logger.Error("Failed to apply price change", "error", err, "id", change.ID)
+			return nil, fmt.Errorf("failed to apply price change %s: %v", change.ID, err)
+		}
+
+		appliedAt := now
+		if _, err = tx.Exec(`
+			UPDATE scheduled_price_changes SET status = $2, applied_at = $3
+			WHERE id = $1`, change.ID, models.PriceChangeApplied, appliedAt); err != nil {
+			r.logger.Error("Failed to mark price change applied", "error", err, "id", change.ID)
+			return nil, fmt.Errorf("failed to mark price change %s applied: %v", change.ID, err)
+		}
+		change.Status = models.PriceChangeApplied
+		change.AppliedAt = &appliedAt
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+	return changes, nil
+}
+
+// setPriceChangeNote names the actor and reason the price history trigger records for the rest of tx
+func setPriceChangeNote(tx *sql.Tx, note models.PriceChangeNote) error {
+	_, err := tx.Exec(`
+		SELECT set_config('app.price_changed_by', $1, true), set_config('app.price_change_reason', $2, true)`,
+		note.ChangedBy, note.Reason)
+	return err
+}
+
+func scanScheduledPriceChanges(rows *sql.Rows) ([]*models.ScheduledPriceChange, error) {
+	changes := []*models.ScheduledPriceChange{}
+	for rows.Next() {
+		change := &models.ScheduledPriceChange{}
+		var appliedAt sql.NullTime
+		if err := rows.Scan(&change.ID, &change.MenuItemID, &change.NewPrice, &change.EffectiveAt, &change.ChangedBy,
+			&change.Reason, &change.Status, &change.CreatedAt, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan scheduled price change: %v", err)
+		}
+		if appliedAt.Valid {
+			change.AppliedAt = &appliedAt.Time
+		}
+		changes = append(changes, change)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating scheduled price changes: %v", err)
+	}
+	return changes, nil
+}
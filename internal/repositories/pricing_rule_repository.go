@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"frappuccino/models"
+	"frappuccino/pkg/database"
+	"frappuccino/pkg/logger"
+)
+
+type PricingRuleRepositoryInterface interface {
+	GetAll() ([]*models.PricingRule, error)
+	GetByID(id string) (*models.PricingRule, error)
+	GetByPromoCode(code string) (*models.PricingRule, error)
+	GetAutomatic() ([]*models.PricingRule, error)
+	Create(rule *models.PricingRule) error
+	SetActive(id string, active bool) error
+	Delete(id string) error
+}
+
+type PricingRuleRepository struct {
+	logger *logger.Logger
+	db     *database.DB
+}
+
+func NewPricingRuleRepository(logger *logger.Logger, db *database.DB) *PricingRuleRepository {
+	return &PricingRuleRepository{
+		logger: logger.WithComponent("pricing_rule_repository"),
+		db:     db,
+	}
+}
+
+const pricingRuleSelectQuery = `
+	SELECT id, name, rule_type, value, COALESCE(buy_quantity, 0), COALESCE(get_quantity, 0),
+		menu_item_ids, COALESCE(category, ''), COALESCE(schedule::text, ''), COALESCE(promo_code, ''),
+		max_uses, uses, expires_at, active, created_at
+	FROM pricing_rules`
+
+// GetAll returns every pricing rule, newest first
+func (r *PricingRuleRepository) GetAll() ([]*models.PricingRule, error) {
+	r.logger.Debug("Retrieving pricing rules")
+	return r.queryRules(pricingRuleSelectQuery + ` ORDER BY created_at DESC`)
+}
+
+// GetByID returns a pricing rule
+func (r *PricingRuleRepository) GetByID(id string) (*models.PricingRule, error) {
+	rules, err := r.queryRules(pricingRuleSelectQuery+` WHERE id = $1`, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("pricing rule with id %s not found", id)
+	}
+	return rules[0], nil
+}
+
+// GetByPromoCode returns the rule behind a promo code, matched case-insensitively
+func (r *PricingRuleRepository) GetByPromoCode(code string) (*models.PricingRule, error) {
+	rules, err := r.queryRules(pricingRuleSelectQuery+` WHERE upper(promo_code) = upper($1)`, code)
+	if err != nil {
+		return nil, err
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("promo code '%s' not found", code)
+	}
+	return rules[0], nil
+}
+
+// GetAutomatic returns the active rules that apply without a promo code, in the order they were created
+func (r *PricingRuleRepository) GetAutomatic() ([]*models.PricingRule, error) {
+	return r.queryRules(pricingRuleSelectQuery + `
+		WHERE active AND promo_code IS NULL
+		  AND (expires_at IS NULL OR expires_at > CURRENT_TIMESTAMP)
+		ORDER BY created_at, id`)
+}
+
+// Create stores a pricing rule; its ID and creation time are filled in from the database
+func (r *PricingRuleRepository) Create(rule *models.PricingRule) error {
+	r.logger.Debug("Creating pricing rule", "name", rule.Name, "type", rule.Type)
+
+	var schedule interface{}
+	if rule.Schedule != nil {
+		data, err := json.Marshal(rule.Schedule)
+		if err != nil {
+			return fmt.Errorf("failed to encode schedule: %v", err)
+		}
+		schedule = string(data)
+	}
+
+	err := r.db.QueryRow(`
+		INSERT INTO pricing_rules (name, rule_type, value, buy_quantity, get_quantity, menu_item_ids, category,
+			schedule, promo_code, max_uses, expires_at, active)
+		VALUES ($1, $2, $3, NULLIF($4, 0), NULLIF($5, 0), $6, NULLIF($7, ''), $8::jsonb, NULLIF($9, ''), $10, $11, $12)
+		RETURNING id, created_at`,
+		rule.Name, rule.Type, rule.Value, rule.BuyQuantity, rule.GetQuantity, "{"+strings.Join(rule.MenuItemIDs, ",")+"}",
+		string(rule.Category), schedule, rule.PromoCode, rule.MaxUses, rule.ExpiresAt, rule.Active,
+	).Scan(&rule.ID, &rule.CreatedAt)
+	if err != nil {
+		r.logger.Error("Failed to create pricing rule", "error", err, "name", rule.Name)
+		if strings.Contains(err.Error(), "promo_code") && strings.Contains(err.Error(), "unique") {
+			return fmt.Errorf("promo code '%s' already exists", rule.PromoCode)
+		}
+		return fmt.Errorf("failed to create pricing rule: %v", err)
+	}
+
+	r.logger.Info("Created pricing rule", "id", rule.ID, "name", rule.Name)
+	return nil
+}
+
+// SetActive switches a pricing rule on or off
+func (r *PricingRuleRepository) SetActive(id string, active bool) error {
+	result, err := r.db.Exec(`UPDATE pricing_rules SET active = $2 WHERE id = $1`, id, active)
+	if err != nil {
+		r.logger.Error("Failed to update pricing rule", "error", err, "id", id)
+		return fmt.Errorf("failed to update pricing rule: %v", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("pricing rule with id %s not found", id)
+	}
+	return nil
+}
+
+// Delete removes a pricing rule; discounts it gave keep their name and amount
+func (r *PricingRuleRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM pricing_rules WHERE id = $1`, id)
+	if err != nil {
+		r.logger.Error("Failed to delete pricing rule", "error", err, "id", id)
+		return fmt.Errorf("failed to delete pricing rule: %v", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("pricing rule with id %s not found", id)
+	}
+
+	r.logger.Info("Deleted pricing rule", "id", id)
+	return nil
+}
+
+func (r *PricingRuleRepository) queryRules(query string, args ...interface{}) ([]*models.PricingRule, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query pricing rules", "error", err)
+		return nil, fmt.Errorf("failed to query pricing rules: %v", err)
+	}
+	defer rows.Close()
+
+	rules := []*models.PricingRule{}
+	for rows.Next() {
+		rule := &models.PricingRule{}
+		var menuItemIDs, schedule string
+		var maxUses sql.NullInt64
+		var expiresAt sql.NullTime
+
+		err := rows.Scan(&rule.ID, &rule.Name, &rule.Type, &rule.Value, &rule.BuyQuantity, &rule.GetQuantity,
+			&menuItemIDs, &rule.Category, &schedule, &rule.PromoCode, &maxUses, &rule.Uses, &expiresAt, &rule.Active, &rule.CreatedAt)
+		if err != nil {
+			r.logger.Error("Failed to scan pricing rule", "error", err)
+			return nil, fmt.Errorf("failed to scan pricing rule: %v", err)
+		}
+
+		rule.MenuItemIDs = parsePostgreSQLArray(menuItemIDs)
+		if schedule != "" {
+			rule.Schedule = &models.MenuSchedule{}
+			if err := json.Unmarshal([]byte(schedule), rule.Schedule); err != nil {
+				return nil, fmt.Errorf("failed to parse schedule of pricing rule %s: %v", rule.ID, err)
+			}
+		}
+		if maxUses.Valid {
+			uses := int(maxUses.Int64)
+			rule.MaxUses = &uses
+		}
+		if expiresAt.Valid {
+			rule.ExpiresAt = &expiresAt.Time
+		}
+		rules = append(rules, rule)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating pricing rules", "error", err)
+		return nil, fmt.Errorf("error iterating pricing rules: %v", err)
+	}
+	return rules, nil
+}
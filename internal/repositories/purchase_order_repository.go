@@ -0,0 +1,380 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"frappuccino/models"
+	"frappuccino/pkg/database"
+	"frappuccino/pkg/logger"
+)
+
+type PurchaseOrderRepositoryInterface interface {
+	GetAll(status string) ([]*models.PurchaseOrder, error)
+	GetByID(id string) (*models.PurchaseOrder, error)
+	Create(order *models.PurchaseOrder) error
+	Delete(id string) error
+	UpdateStatus(id, fromStatus, toStatus string) error
+	Receive(id string) ([]*models.InventoryTransaction, error)
+	GetOnOrderQuantities() (map[string]float64, error)
+}
+
+type PurchaseOrderRepository struct {
+	logger *logger.Logger
+	db     *database.DB
+}
+
+func NewPurchaseOrderRepository(logger *logger.Logger, db *database.DB) *PurchaseOrderRepository {
+	return &PurchaseOrderRepository{
+		logger: logger.WithComponent("purchase_order_repository"),
+		db:     db,
+	}
+}
+
+const purchaseOrderSelectQuery = `
+	SELECT po.id, po.supplier_id, s.name, po.status, po.total_amount, COALESCE(po.notes, ''),
+	       po.created_at, po.updated_at, po.sent_at, po.received_at,
+	       COALESCE(
+	           json_agg(
+	               json_build_object(
+	                   'ingredient_id', poi.ingredient_id,
+	                   'name', i.name,
+	                   'unit', i.unit,
+	                   'packs', poi.packs,
+	                   'pack_size', poi.pack_size,
+	                   'pack_price', poi.pack_price
+	               ) ORDER BY i.name
+	           ) FILTER (WHERE poi.ingredient_id IS NOT NULL), '[]'::json
+	       ) AS items
+	FROM purchase_orders po
+	JOIN suppliers s ON s.id = po.supplier_id
+	LEFT JOIN purchase_order_items poi ON poi.purchase_order_id = po.id
+	LEFT JOIN inventory i ON i.id = poi.ingredient_id`
+
+// GetAll retrieves purchase orders, optionally filtered by status, newest first
+func (r *PurchaseOrderRepository) GetAll(status string) ([]*models.PurchaseOrder, error) {
+	r.logger.Debug("Retrieving purchase orders from database", "status", status)
+
+	query := purchaseOrderSelectQuery + `
+	WHERE ($1 = '' OR po.status::text = $1)
+	GROUP BY po.id, s.name
+	ORDER BY po.created_at DESC`
+
+	rows, err := r.db.Query(query, status)
+	if err != nil {
+		r.logger.Error("Failed to query purchase orders", "error", err)
+		return nil, fmt.Errorf("failed to query purchase orders: %v", err)
+	}
+	defer rows.Close()
+
+	orders := []*models.PurchaseOrder{}
+	for rows.Next() {
+		order, err := r.scanPurchaseOrder(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan purchase order", "error", err)
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Error("Error iterating purchase order rows", "error", err)
+		return nil, fmt.Errorf("error iterating purchase order rows: %v", err)
+	}
+
+	r.logger.Info("Retrieved purchase orders", "count", len(orders))
+	return orders, nil
+}
+
+// GetByID retrieves a purchase order by ID
+func (r *PurchaseOrderRepository) GetByID(id string) (*models.PurchaseOrder, error) {
+	r.logger.Debug("Retrieving purchase order from database", "purchase_order_id", id)
+
+	query := purchaseOrderSelectQuery + `
+	WHERE po.id = $1
+	GROUP BY po.id, s.name`
+
+	order, err := r.scanPurchaseOrder(r.db.QueryRow(query, id))
+	if err != nil {
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			r.logger.Warn("Purchase order not found", "purchase_order_id", id)
+			return nil, fmt.Errorf("purchase order with id %s not found", id)
+		}
+		r.logger.Error("Failed to retrieve purchase order", "error", err, "purchase_order_id", id)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// Create inserts a purchase order and its lines; the total is computed from the lines
+func (r *PurchaseOrderRepository) Create(order *models.PurchaseOrder) error {
+	r.logger.Debug("Creating purchase order", "supplier_id", order.SupplierID, "items", len(order.Items))
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			r.logger.Warn("Rolling back purchase order creation transaction due to error", "error", err)
+			tx.Rollback()
+		}
+	}()
+
+	order.TotalAmount = 0
+	for _, item := range order.Items {
+		order.TotalAmount += item.Total()
+	}
+
+	query := `
+		INSERT INTO purchase_orders (supplier_id, status, total_amount, notes)
+		VALUES ($1, $2, $3, NULLIF($4, ''))
+		RETURNING id, created_at, updated_at`
+
+	err = tx.QueryRow(query, order.SupplierID, order.Status, order.TotalAmount, order.Notes).
+		Scan(&order.ID, &order.CreatedAt, &order.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Failed to insert purchase order", "error", err, "supplier_id", order.SupplierID)
+		return fmt.Errorf("failed to create purchase order: %v", err)
+	}
+
+	itemQuery := `
+		INSERT INTO purchase_order_items (purchase_order_id, ingredient_id, packs, pack_size, pack_price)
+		VALUES ($1, $2, $3, $4, $5)`
+
+	for _, item := range order.Items {
+		_, err = tx.Exec(itemQuery, order.ID, item.IngredientID, item.Packs, item.PackSize, item.PackPrice)
+		if err != nil {
+			r.logger.Error("Failed to insert purchase order item", "error", err, "purchase_order_id", order.ID, "ingredient_id", item.IngredientID)
+			return fmt.Errorf("failed to insert purchase order item %s: %v", item.IngredientID, err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	r.logger.Info("Created purchase order", "purchase_order_id", order.ID, "supplier_id", order.SupplierID, "total_amount", order.TotalAmount)
+	return nil
+}
+
+// Delete removes a draft purchase order
+func (r *PurchaseOrderRepository) Delete(id string) error {
+	r.logger.Debug("Deleting purchase order", "purchase_order_id", id)
+
+	result, err := r.db.Exec(`DELETE FROM purchase_orders WHERE id = $1 AND status = 'draft'`, id)
+	if err != nil {
+		r.logger.Error("Failed to delete purchase order", "error", err, "purchase_order_id", id)
+		return fmt.Errorf("failed to delete purchase order: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error("Failed to get rows affected", "error", err, "purchase_order_id", id)
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return r.transitionError(id, models.PurchaseOrderDraft, "deleted")
+	}
+
+	r.logger.Info("Deleted purchase order", "purchase_order_id", id)
+	return nil
+}
+
+// UpdateStatus moves a purchase order from one status to another, stamping sent_at when it is sent
+func (r *PurchaseOrderRepository) UpdateStatus(id, fromStatus, toStatus string) error {
+	r.logger.Debug("Updating purchase order status", "purchase_order_id", id, "from", fromStatus, "to", toStatus)
+
+	query := `
+		UPDATE purchase_orders
+		SET status = $1::purchase_order_status,
+		    sent_at = CASE WHEN $1 = 'sent' THEN CURRENT_TIMESTAMP ELSE sent_at END
+		WHERE id = $2 AND status = $3::purchase_order_status`
+
+	result, err := r.db.Exec(query, toStatus, id, fromStatus)
+	if err != nil {
+		r.logger.Error("Failed to update purchase order status", "error", err, "purchase_order_id", id)
+		return fmt.Errorf("failed to update purchase order status: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error("Failed to get rows affected", "error", err, "purchase_order_id", id)
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		return r.transitionError(id, fromStatus, "moved to "+toStatus)
+	}
+
+	r.logger.Info("Updated purchase order status", "purchase_order_id", id, "status", toStatus)
+	return nil
+}
+
+// Receive books a sent purchase order into stock: each line becomes a new lot, adds a purchase transaction
+// to the ledger and moves cost_per_unit to the weighted average of stock on hand and the received goods.
+func (r *PurchaseOrderRepository) Receive(id string) ([]*models.InventoryTransaction, error) {
+	r.logger.Debug("Receiving purchase order", "purchase_order_id", id)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			r.logger.Warn("Rolling back purchase order receipt due to error", "error", err, "purchase_order_id", id)
+			tx.Rollback()
+		}
+	}()
+
+	var status string
+	err = tx.QueryRow(`SELECT status FROM purchase_orders WHERE id = $1 FOR UPDATE`, id).Scan(&status)
+	if err == sql.ErrNoRows {
+		r.logger.Warn("Purchase order not found", "purchase_order_id", id)
+		return nil, fmt.Errorf("purchase order with id %s not found", id)
+	}
+	if err != nil {
+		r.logger.Error("Failed to lock purchase order", "error", err, "purchase_order_id", id)
+		return nil, fmt.Errorf("failed to lock purchase order: %v", err)
+	}
+	if status != models.PurchaseOrderSent {
+		err = fmt.Errorf("purchase order is %s, only sent orders can be received", status)
+		return nil, err
+	}
+
+	rows, err := tx.Query(`SELECT ingredient_id, packs, pack_size, pack_price FROM purchase_order_items WHERE purchase_order_id = $1`, id)
+	if err != nil {
+		r.logger.Error("Failed to query purchase order items", "error", err, "purchase_order_id", id)
+		return nil, fmt.Errorf("failed to query purchase order items: %v", err)
+	}
+	var items []models.PurchaseOrderItem
+	for rows.Next() {
+		var item models.PurchaseOrderItem
+		if err = rows.Scan(&item.IngredientID, &item.Packs, &item.PackSize, &item.PackPrice); err != nil {
+			rows.Close()
+			r.logger.Error("Failed to scan purchase order item", "error", err, "purchase_order_id", id)
+			return nil, fmt.Errorf("failed to scan purchase order item: %v", err)
+		}
+		items = append(items, item)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		r.logger.Error("Error iterating purchase order items", "error", err, "purchase_order_id", id)
+		return nil, fmt.Errorf("error iterating purchase order items: %v", err)
+	}
+
+	transactions := make([]*models.InventoryTransaction, 0, len(items))
+	for _, item := range items {
+		transaction := &models.InventoryTransaction{
+			IngredientID:    item.IngredientID,
+			TransactionType: models.TransactionPurchase,
+			QuantityChange:  item.Quantity(),
+			UnitCost:        item.PackPrice / item.PackSize,
+			ReferenceType:   models.ReferenceTypePurchaseOrder,
+			ReferenceID:     id,
+			Notes:           "Purchase order received",
+		}
+
+		if _, err = receiveStock(tx, transaction, nil); err != nil {
+			r.logger.Error("Failed to receive purchase order line", "error", err, "ingredient_id", item.IngredientID)
+			return nil, err
+		}
+
+		transactions = append(transactions, transaction)
+	}
+
+	_, err = tx.Exec(`UPDATE purchase_orders SET status = 'received', received_at = CURRENT_TIMESTAMP WHERE id = $1`, id)
+	if err != nil {
+		r.logger.Error("Failed to mark purchase order received", "error", err, "purchase_order_id", id)
+		return nil, fmt.Errorf("failed to mark purchase order received: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err, "purchase_order_id", id)
+		return nil, fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	r.logger.Info("Received purchase order", "purchase_order_id", id, "lines", len(transactions))
+	return transactions, nil
+}
+
+// GetOnOrderQuantities sums inventory units on draft and sent purchase orders per ingredient
+func (r *PurchaseOrderRepository) GetOnOrderQuantities() (map[string]float64, error) {
+	query := `
+		SELECT poi.ingredient_id, SUM(poi.packs * poi.pack_size)
+		FROM purchase_order_items poi
+		JOIN purchase_orders po ON po.id = poi.purchase_order_id
+		WHERE po.status IN ('draft', 'sent')
+		GROUP BY poi.ingredient_id`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		r.logger.Error("Failed to query on-order quantities", "error", err)
+		return nil, fmt.Errorf("failed to query on-order quantities: %v", err)
+	}
+	defer rows.Close()
+
+	onOrder := make(map[string]float64)
+	for rows.Next() {
+		var ingredientID string
+		var quantity float64
+		if err := rows.Scan(&ingredientID, &quantity); err != nil {
+			r.logger.Error("Failed to scan on-order quantity", "error", err)
+			return nil, fmt.Errorf("failed to scan on-order quantity: %v", err)
+		}
+		onOrder[ingredientID] = quantity
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating on-order quantities", "error", err)
+		return nil, fmt.Errorf("error iterating on-order quantities: %v", err)
+	}
+
+	return onOrder, nil
+}
+
+// transitionError explains why a conditional status change matched no rows
+func (r *PurchaseOrderRepository) transitionError(id, requiredStatus, action string) error {
+	var status string
+	err := r.db.QueryRow(`SELECT status FROM purchase_orders WHERE id = $1`, id).Scan(&status)
+	if err == sql.ErrNoRows {
+		r.logger.Warn("Purchase order not found", "purchase_order_id", id)
+		return fmt.Errorf("purchase order with id %s not found", id)
+	}
+	if err != nil {
+		r.logger.Error("Failed to read purchase order status", "error", err, "purchase_order_id", id)
+		return fmt.Errorf("failed to read purchase order status: %v", err)
+	}
+
+	r.logger.Warn("Invalid purchase order transition", "purchase_order_id", id, "status", status, "required", requiredStatus)
+	return fmt.Errorf("purchase order is %s, only %s orders can be %s", status, requiredStatus, action)
+}
+
+// scanPurchaseOrder reads a row produced by purchaseOrderSelectQuery
+func (r *PurchaseOrderRepository) scanPurchaseOrder(row interface{ Scan(...any) error }) (*models.PurchaseOrder, error) {
+	order := &models.PurchaseOrder{}
+	var sentAt, receivedAt sql.NullTime
+	var itemsJSON string
+
+	err := row.Scan(&order.ID, &order.SupplierID, &order.SupplierName, &order.Status, &order.TotalAmount, &order.Notes,
+		&order.CreatedAt, &order.UpdatedAt, &sentAt, &receivedAt, &itemsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan purchase order: %v", err)
+	}
+	if sentAt.Valid {
+		order.SentAt = &sentAt.Time
+	}
+	if receivedAt.Valid {
+		order.ReceivedAt = &receivedAt.Time
+	}
+
+	if err := json.Unmarshal([]byte(itemsJSON), &order.Items); err != nil {
+		return nil, fmt.Errorf("failed to parse items for purchase order %s: %v", order.ID, err)
+	}
+
+	return order, nil
+}
@@ -0,0 +1,211 @@
+package repositories
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+
+	"frappuccino/models"
+	"frappuccino/pkg/database"
+	"frappuccino/pkg/logger"
+)
+
+// Open orders hold reservations instead of consuming stock. Available-to-promise is usable
+// (non-expired) stock minus everything reserved; these expressions expect inventory aliased as i.
+const (
+	usableStockSQL = `COALESCE((SELECT SUM(l.quantity) FROM inventory_lots l
+		WHERE l.ingredient_id = i.id AND (l.expires_at IS NULL OR l.expires_at > CURRENT_TIMESTAMP)), 0)`
+	reservedStockSQL = `COALESCE((SELECT SUM(rs.quantity) FROM inventory_reservations rs WHERE rs.ingredient_id = i.id), 0)`
+)
+
+type ReservationRepositoryInterface interface {
+	Reserve(orderID string, quantities map[string]float64) error
+	ReserveOrders(reservations map[string]map[string]float64) error
+	Release(orderID string) error
+	Consume(orderID string) ([]*models.InventoryTransaction, error)
+}
+
+type ReservationRepository struct {
+	logger *logger.Logger
+	db     *database.DB
+}
+
+func NewReservationRepository(logger *logger.Logger, db *database.DB) *ReservationRepository {
+	return &ReservationRepository{
+		logger: logger.WithComponent("reservation_repository"),
+		db:     db,
+	}
+}
+
+// Reserve replaces the reservation held by an order, failing if any ingredient lacks available-to-promise stock
+func (r *ReservationRepository) Reserve(orderID string, quantities map[string]float64) error {
+	return r.ReserveOrders(map[string]map[string]float64{orderID: quantities})
+}
+
+// ReserveOrders reserves stock for several orders atomically
+func (r *ReservationRepository) ReserveOrders(reservations map[string]map[string]float64) error {
+	r.logger.Debug("Reserving inventory", "orders", len(reservations))
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	orderIDs := make([]string, 0, len(reservations))
+	for orderID := range reservations {
+		orderIDs = append(orderIDs, orderID)
+	}
+	sort.Strings(orderIDs)
+
+	for _, orderID := range orderIDs {
+		if err = reserve(tx, orderID, reservations[orderID]); err != nil {
+			r.logger.Warn("Failed to reserve inventory", "order_id", orderID, "error", err)
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit reservation", "error", err)
+		return fmt.Errorf("failed to commit reservation: %v", err)
+	}
+
+	r.logger.Info("Reserved inventory", "orders", len(reservations))
+	return nil
+}
+
+// Release drops an order's reservation, making the stock available again
+func (r *ReservationRepository) Release(orderID string) error {
+	r.logger.Debug("Releasing inventory reservation", "order_id", orderID)
+
+	result, err := r.db.Exec(`DELETE FROM inventory_reservations WHERE order_id = $1`, orderID)
+	if err != nil {
+		r.logger.Error("Failed to release reservation", "error", err, "order_id", orderID)
+		return fmt.Errorf("failed to release reservation: %v", err)
+	}
+
+	released, _ := result.RowsAffected()
+	r.logger.Info("Released inventory reservation", "order_id", orderID, "ingredients", released)
+	return nil
+}
+
+// Consume turns an order's reservation into usage: stock is drawn from the lots and recorded in the ledger.
+// An order without a reservation consumes nothing.
+func (r *ReservationRepository) Consume(orderID string) ([]*models.InventoryTransaction, error) {
+	r.logger.Debug("Consuming inventory reservation", "order_id", orderID)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	rows, err := tx.Query(`
+		DELETE FROM inventory_reservations
+		WHERE order_id = $1
+		RETURNING ingredient_id, quantity`, orderID)
+	if err != nil {
+		r.logger.Error("Failed to take reservation", "error", err, "order_id", orderID)
+		return nil, fmt.Errorf("failed to take reservation for order %s: %v", orderID, err)
+	}
+
+	var transactions []*models.InventoryTransaction
+	for rows.Next() {
+		transaction := &models.InventoryTransaction{
+			TransactionType: models.TransactionUsage,
+			ReferenceType:   models.ReferenceTypeOrder,
+			ReferenceID:     orderID,
+		}
+		if err = rows.Scan(&transaction.IngredientID, &transaction.QuantityChange); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan reservation: %v", err)
+		}
+		transaction.QuantityChange = -transaction.QuantityChange
+		transactions = append(transactions, transaction)
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating reservation: %v", err)
+	}
+
+	// Lock inventory rows in a fixed order, as reserve does
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].IngredientID < transactions[j].IngredientID
+	})
+
+	for _, transaction := range transactions {
+		if err = applyTransaction(tx, transaction); err != nil {
+			if isInsufficientStock(err) {
+				r.logger.Warn("Reserved stock is no longer usable", "order_id", orderID, "ingredient_id", transaction.IngredientID, "error", err)
+				err = fmt.Errorf("insufficient inventory for ingredient %s", transaction.IngredientID)
+				return nil, err
+			}
+			r.logger.Error("Failed to consume reserved stock", "error", err, "order_id", orderID)
+			return nil, err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit reservation consumption", "error", err, "order_id", orderID)
+		return nil, fmt.Errorf("failed to commit reservation consumption: %v", err)
+	}
+
+	r.logger.Info("Consumed inventory reservation", "order_id", orderID, "ingredients", len(transactions))
+	return transactions, nil
+}
+
+// reserve replaces an order's reservation inside tx. Inventory rows are locked in ingredient order
+// so concurrent reservations and stock movements can't promise the same stock twice.
+func reserve(tx *sql.Tx, orderID string, quantities map[string]float64) error {
+	if _, err := tx.Exec(`DELETE FROM inventory_reservations WHERE order_id = $1`, orderID); err != nil {
+		return fmt.Errorf("failed to clear reservation for order %s: %v", orderID, err)
+	}
+
+	ingredientIDs := make([]string, 0, len(quantities))
+	for ingredientID, quantity := range quantities {
+		if quantity > 0 {
+			ingredientIDs = append(ingredientIDs, ingredientID)
+		}
+	}
+	sort.Strings(ingredientIDs)
+
+	for _, ingredientID := range ingredientIDs {
+		quantity := quantities[ingredientID]
+
+		var name string
+		var available float64
+		err := tx.QueryRow(`
+			SELECT i.name, `+usableStockSQL+` - `+reservedStockSQL+`
+			FROM inventory i
+			WHERE i.id = $1
+			FOR UPDATE OF i`, ingredientID).Scan(&name, &available)
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("inventory item with id %s not found", ingredientID)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to check available stock for ingredient %s: %v", ingredientID, err)
+		}
+
+		if available+lotEpsilon < quantity {
+			return fmt.Errorf("insufficient inventory for ingredient '%s' (need %.2f, available %.2f)", name, quantity, available)
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO inventory_reservations (order_id, ingredient_id, quantity)
+			VALUES ($1, $2, $3)`, orderID, ingredientID, quantity)
+		if err != nil {
+			return fmt.Errorf("failed to reserve ingredient %s for order %s: %v", ingredientID, orderID, err)
+		}
+	}
+	return nil
+}
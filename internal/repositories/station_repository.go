@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"fmt"
+
+	"frappuccino/models"
+	"frappuccino/pkg/database"
+	"frappuccino/pkg/logger"
+)
+
+type StationRepositoryInterface interface {
+	GetRoutes() ([]models.StationRoute, error)
+	SetRoute(route models.StationRoute) error
+}
+
+type StationRepository struct {
+	logger *logger.Logger
+	db     *database.DB
+}
+
+func NewStationRepository(logger *logger.Logger, db *database.DB) *StationRepository {
+	return &StationRepository{
+		logger: logger.WithComponent("station_repository"),
+		db:     db,
+	}
+}
+
+// GetRoutes retrieves the station of every routed menu category
+func (r *StationRepository) GetRoutes() ([]models.StationRoute, error) {
+	rows, err := r.db.Query(`SELECT category, station FROM category_stations ORDER BY category`)
+	if err != nil {
+		r.logger.Error("Failed to query station routes", "error", err)
+		return nil, fmt.Errorf("failed to query station routes: %v", err)
+	}
+	defer rows.Close()
+
+	routes := []models.StationRoute{}
+	for rows.Next() {
+		var route models.StationRoute
+		if err := rows.Scan(&route.Category, &route.Station); err != nil {
+			r.logger.Error("Failed to scan station route", "error", err)
+			return nil, fmt.Errorf("failed to scan station route: %v", err)
+		}
+		routes = append(routes, route)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating station routes: %v", err)
+	}
+	return routes, nil
+}
+
+// SetRoute sends a category's items to a station. Orders already placed keep the stations they were routed to.
+func (r *StationRepository) SetRoute(route models.StationRoute) error {
+	query := `
+		INSERT INTO category_stations (category, station)
+		VALUES ($1, $2)
+		ON CONFLICT (category) DO UPDATE SET station = EXCLUDED.station`
+
+	if _, err := r.db.Exec(query, route.Category, route.Station); err != nil {
+		r.logger.Error("Failed to set station route", "error", err, "category", route.Category)
+		return fmt.Errorf("failed to set station route: %v", err)
+	}
+
+	r.logger.Info("Set station route", "category", route.Category, "station", route.Station)
+	return nil
+}
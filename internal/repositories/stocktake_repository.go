@@ -0,0 +1,443 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"frappuccino/models"
+	"frappuccino/pkg/database"
+	"frappuccino/pkg/logger"
+)
+
+type StocktakeRepositoryInterface interface {
+	GetAll(status string) ([]*models.Stocktake, error)
+	GetByID(id string) (*models.Stocktake, error)
+	Create(stocktake *models.Stocktake) error
+	SubmitCounts(id string, counts []models.StocktakeCount) error
+	Approve(id, approvedBy string) ([]*models.InventoryTransaction, error)
+	Cancel(id string) error
+	GetSessionVariances(from, to *time.Time) ([]models.StocktakeSessionVariance, error)
+	GetIngredientVariances(from, to *time.Time) ([]models.IngredientVariance, error)
+}
+
+type StocktakeRepository struct {
+	logger *logger.Logger
+	db     *database.DB
+}
+
+func NewStocktakeRepository(logger *logger.Logger, db *database.DB) *StocktakeRepository {
+	return &StocktakeRepository{
+		logger: logger.WithComponent("stocktake_repository"),
+		db:     db,
+	}
+}
+
+const stocktakeSelectQuery = `
+	SELECT st.id, st.status, COALESCE(st.notes, ''), COALESCE(st.approved_by, ''),
+	       st.created_at, st.updated_at, st.approved_at, st.cancelled_at,
+	       COALESCE(SUM((sc.counted_quantity - sc.system_quantity) * sc.unit_cost), 0),
+	       COALESCE(
+	           json_agg(
+	               json_build_object(
+	                   'ingredient_id', sc.ingredient_id,
+	                   'name', i.name,
+	                   'unit', i.unit,
+	                   'counted_quantity', sc.counted_quantity,
+	                   'system_quantity', sc.system_quantity,
+	                   'variance', sc.counted_quantity - sc.system_quantity,
+	                   'unit_cost', sc.unit_cost,
+	                   'variance_value', ROUND((sc.counted_quantity - sc.system_quantity) * sc.unit_cost, 2),
+	                   'counted_at', sc.counted_at
+	               ) ORDER BY i.name
+	           ) FILTER (WHERE sc.ingredient_id IS NOT NULL), '[]'::json
+	       ) AS counts
+	FROM stocktakes st
+	LEFT JOIN stocktake_counts sc ON sc.stocktake_id = st.id
+	LEFT JOIN inventory i ON i.id = sc.ingredient_id`
+
+// GetAll retrieves stocktakes, optionally filtered by status, newest first
+func (r *StocktakeRepository) GetAll(status string) ([]*models.Stocktake, error) {
+	r.logger.Debug("Retrieving stocktakes from database", "status", status)
+
+	query := stocktakeSelectQuery + `
+	WHERE ($1 = '' OR st.status::text = $1)
+	GROUP BY st.id
+	ORDER BY st.created_at DESC`
+
+	rows, err := r.db.Query(query, status)
+	if err != nil {
+		r.logger.Error("Failed to query stocktakes", "error", err)
+		return nil, fmt.Errorf("failed to query stocktakes: %v", err)
+	}
+	defer rows.Close()
+
+	stocktakes := []*models.Stocktake{}
+	for rows.Next() {
+		stocktake, err := r.scanStocktake(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan stocktake", "error", err)
+			return nil, err
+		}
+		stocktakes = append(stocktakes, stocktake)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Error("Error iterating stocktake rows", "error", err)
+		return nil, fmt.Errorf("error iterating stocktake rows: %v", err)
+	}
+
+	r.logger.Info("Retrieved stocktakes", "count", len(stocktakes))
+	return stocktakes, nil
+}
+
+// GetByID retrieves a stocktake with its counts
+func (r *StocktakeRepository) GetByID(id string) (*models.Stocktake, error) {
+	r.logger.Debug("Retrieving stocktake from database", "stocktake_id", id)
+
+	query := stocktakeSelectQuery + `
+	WHERE st.id = $1
+	GROUP BY st.id`
+
+	stocktake, err := r.scanStocktake(r.db.QueryRow(query, id))
+	if err != nil {
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			r.logger.Warn("Stocktake not found", "stocktake_id", id)
+			return nil, fmt.Errorf("stocktake with id %s not found", id)
+		}
+		r.logger.Error("Failed to retrieve stocktake", "error", err, "stocktake_id", id)
+		return nil, err
+	}
+
+	return stocktake, nil
+}
+
+// Create opens a new stocktake; only one can be open at a time
+func (r *StocktakeRepository) Create(stocktake *models.Stocktake) error {
+	r.logger.Debug("Creating stocktake")
+
+	query := `
+		INSERT INTO stocktakes (notes)
+		VALUES (NULLIF($1, ''))
+		RETURNING id, status, created_at, updated_at`
+
+	err := r.db.QueryRow(query, stocktake.Notes).Scan(&stocktake.ID, &stocktake.Status, &stocktake.CreatedAt, &stocktake.UpdatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value") {
+			r.logger.Warn("Attempted to open a second stocktake", "error", err)
+			return fmt.Errorf("stocktake is already open, approve or cancel it first")
+		}
+		r.logger.Error("Failed to create stocktake", "error", err)
+		return fmt.Errorf("failed to create stocktake: %v", err)
+	}
+
+	stocktake.Counts = []models.StocktakeCount{}
+	r.logger.Info("Created stocktake", "stocktake_id", stocktake.ID)
+	return nil
+}
+
+// SubmitCounts records counted quantities on an open stocktake, replacing earlier counts of the same ingredient.
+// The current system quantity and cost_per_unit are captured with each count.
+func (r *StocktakeRepository) SubmitCounts(id string, counts []models.StocktakeCount) error {
+	r.logger.Debug("Submitting stocktake counts", "stocktake_id", id, "counts", len(counts))
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = r.lockOpenStocktake(tx, id, "counted"); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO stocktake_counts (stocktake_id, ingredient_id, counted_quantity, system_quantity, unit_cost)
+		SELECT $1, id, $3, quantity, cost_per_unit
+		FROM inventory
+		WHERE id = $2
+		ON CONFLICT (stocktake_id, ingredient_id) DO UPDATE
+		SET counted_quantity = EXCLUDED.counted_quantity,
+		    system_quantity = EXCLUDED.system_quantity,
+		    unit_cost = EXCLUDED.unit_cost,
+		    counted_at = CURRENT_TIMESTAMP`
+
+	for _, count := range counts {
+		var result sql.Result
+		result, err = tx.Exec(query, id, count.IngredientID, count.CountedQuantity)
+		if err != nil {
+			r.logger.Error("Failed to record stocktake count", "error", err, "stocktake_id", id, "ingredient_id", count.IngredientID)
+			return fmt.Errorf("failed to record count for ingredient %s: %v", count.IngredientID, err)
+		}
+		var rowsAffected int64
+		if rowsAffected, err = result.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to get rows affected: %v", err)
+		}
+		if rowsAffected == 0 {
+			err = fmt.Errorf("inventory item with id %s not found", count.IngredientID)
+			return err
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit stocktake counts", "error", err, "stocktake_id", id)
+		return fmt.Errorf("failed to commit stocktake counts: %v", err)
+	}
+
+	r.logger.Info("Submitted stocktake counts", "stocktake_id", id, "counts", len(counts))
+	return nil
+}
+
+// Approve posts an adjustment transaction for every count that differs from the system quantity
+// and closes the stocktake, all in one transaction.
+func (r *StocktakeRepository) Approve(id, approvedBy string) ([]*models.InventoryTransaction, error) {
+	r.logger.Debug("Approving stocktake", "stocktake_id", id)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			r.logger.Warn("Rolling back stocktake approval due to error", "error", err, "stocktake_id", id)
+			tx.Rollback()
+		}
+	}()
+
+	if err = r.lockOpenStocktake(tx, id, "approved"); err != nil {
+		return nil, err
+	}
+
+	rows, err := tx.Query(`
+		SELECT ingredient_id, counted_quantity - system_quantity, unit_cost
+		FROM stocktake_counts
+		WHERE stocktake_id = $1
+		ORDER BY ingredient_id`, id)
+	if err != nil {
+		r.logger.Error("Failed to query stocktake counts", "error", err, "stocktake_id", id)
+		return nil, fmt.Errorf("failed to query stocktake counts: %v", err)
+	}
+	var transactions []*models.InventoryTransaction
+	for rows.Next() {
+		transaction := &models.InventoryTransaction{
+			TransactionType: models.TransactionAdjustment,
+			ReferenceType:   models.ReferenceTypeStocktake,
+			ReferenceID:     id,
+			Notes:           "Stocktake variance",
+		}
+		if err = rows.Scan(&transaction.IngredientID, &transaction.QuantityChange, &transaction.UnitCost); err != nil {
+			rows.Close()
+			r.logger.Error("Failed to scan stocktake count", "error", err, "stocktake_id", id)
+			return nil, fmt.Errorf("failed to scan stocktake count: %v", err)
+		}
+		if math.Abs(transaction.QuantityChange) > lotEpsilon {
+			transactions = append(transactions, transaction)
+		}
+	}
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		r.logger.Error("Error iterating stocktake counts", "error", err, "stocktake_id", id)
+		return nil, fmt.Errorf("error iterating stocktake counts: %v", err)
+	}
+
+	for _, transaction := range transactions {
+		if err = applyTransaction(tx, transaction); err != nil {
+			if isInsufficientStock(err) {
+				r.logger.Warn("Stocktake variance exceeds current stock", "stocktake_id", id, "ingredient_id", transaction.IngredientID)
+				err = fmt.Errorf("insufficient inventory for ingredient %s: stock moved since it was counted, count it again", transaction.IngredientID)
+				return nil, err
+			}
+			r.logger.Error("Failed to post stocktake adjustment", "error", err, "stocktake_id", id, "ingredient_id", transaction.IngredientID)
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE stocktakes
+		SET status = 'approved', approved_at = CURRENT_TIMESTAMP, approved_by = NULLIF($2, '')
+		WHERE id = $1`, id, approvedBy)
+	if err != nil {
+		r.logger.Error("Failed to mark stocktake approved", "error", err, "stocktake_id", id)
+		return nil, fmt.Errorf("failed to mark stocktake approved: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit stocktake approval", "error", err, "stocktake_id", id)
+		return nil, fmt.Errorf("failed to commit stocktake approval: %v", err)
+	}
+
+	r.logger.Info("Approved stocktake", "stocktake_id", id, "adjustments", len(transactions))
+	return transactions, nil
+}
+
+// Cancel discards an open stocktake without touching stock
+func (r *StocktakeRepository) Cancel(id string) error {
+	r.logger.Debug("Cancelling stocktake", "stocktake_id", id)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback()
+		}
+	}()
+
+	if err = r.lockOpenStocktake(tx, id, "cancelled"); err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`UPDATE stocktakes SET status = 'cancelled', cancelled_at = CURRENT_TIMESTAMP WHERE id = $1`, id)
+	if err != nil {
+		r.logger.Error("Failed to cancel stocktake", "error", err, "stocktake_id", id)
+		return fmt.Errorf("failed to cancel stocktake: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit stocktake cancellation", "error", err, "stocktake_id", id)
+		return fmt.Errorf("failed to commit stocktake cancellation: %v", err)
+	}
+
+	r.logger.Info("Cancelled stocktake", "stocktake_id", id)
+	return nil
+}
+
+// GetSessionVariances summarises approved stocktakes in [from, to), oldest first
+func (r *StocktakeRepository) GetSessionVariances(from, to *time.Time) ([]models.StocktakeSessionVariance, error) {
+	query := `
+		SELECT st.id, st.approved_at,
+		       COUNT(sc.id),
+		       COUNT(sc.id) FILTER (WHERE sc.counted_quantity <> sc.system_quantity),
+		       COALESCE(SUM((sc.counted_quantity - sc.system_quantity) * sc.unit_cost), 0),
+		       COALESCE(SUM((sc.system_quantity - sc.counted_quantity) * sc.unit_cost) FILTER (WHERE sc.counted_quantity < sc.system_quantity), 0)
+		FROM stocktakes st
+		LEFT JOIN stocktake_counts sc ON sc.stocktake_id = st.id
+		WHERE st.status = 'approved'
+		  AND ($1::timestamptz IS NULL OR st.approved_at >= $1)
+		  AND ($2::timestamptz IS NULL OR st.approved_at < $2)
+		GROUP BY st.id
+		ORDER BY st.approved_at`
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		r.logger.Error("Failed to query stocktake variances", "error", err)
+		return nil, fmt.Errorf("failed to query stocktake variances: %v", err)
+	}
+	defer rows.Close()
+
+	sessions := []models.StocktakeSessionVariance{}
+	for rows.Next() {
+		var session models.StocktakeSessionVariance
+		err := rows.Scan(&session.StocktakeID, &session.ApprovedAt, &session.ItemsCounted, &session.ItemsVarying,
+			&session.VarianceValue, &session.ShrinkageValue)
+		if err != nil {
+			r.logger.Error("Failed to scan stocktake variance", "error", err)
+			return nil, fmt.Errorf("failed to scan stocktake variance: %v", err)
+		}
+		sessions = append(sessions, session)
+	}
+	if err = rows.Err(); err != nil {
+		r.logger.Error("Error iterating stocktake variances", "error", err)
+		return nil, fmt.Errorf("error iterating stocktake variances: %v", err)
+	}
+
+	return sessions, nil
+}
+
+// GetIngredientVariances totals variance per ingredient over approved stocktakes in [from, to), biggest shrinkage first
+func (r *StocktakeRepository) GetIngredientVariances(from, to *time.Time) ([]models.IngredientVariance, error) {
+	query := `
+		SELECT i.id, i.name, i.unit,
+		       COUNT(*),
+		       SUM(sc.counted_quantity - sc.system_quantity),
+		       SUM((sc.counted_quantity - sc.system_quantity) * sc.unit_cost),
+		       COALESCE(SUM((sc.system_quantity - sc.counted_quantity) * sc.unit_cost) FILTER (WHERE sc.counted_quantity < sc.system_quantity), 0) AS shrinkage
+		FROM stocktake_counts sc
+		JOIN stocktakes st ON st.id = sc.stocktake_id
+		JOIN inventory i ON i.id = sc.ingredient_id
+		WHERE st.status = 'approved'
+		  AND ($1::timestamptz IS NULL OR st.approved_at >= $1)
+		  AND ($2::timestamptz IS NULL OR st.approved_at < $2)
+		GROUP BY i.id, i.name, i.unit
+		ORDER BY shrinkage DESC, i.name`
+
+	rows, err := r.db.Query(query, from, to)
+	if err != nil {
+		r.logger.Error("Failed to query ingredient variances", "error", err)
+		return nil, fmt.Errorf("failed to query ingredient variances: %v", err)
+	}
+	defer rows.Close()
+
+	items := []models.IngredientVariance{}
+	for rows.Next() {
+		var item models.IngredientVariance
+		err := rows.Scan(&item.IngredientID, &item.Name, &item.Unit, &item.Counts, &item.Variance,
+			&item.VarianceValue, &item.ShrinkageValue)
+		if err != nil {
+			r.logger.Error("Failed to scan ingredient variance", "error", err)
+			return nil, fmt.Errorf("failed to scan ingredient variance: %v", err)
+		}
+		items = append(items, item)
+	}
+	if err = rows.Err(); err != nil {
+		r.logger.Error("Error iterating ingredient variances", "error", err)
+		return nil, fmt.Errorf("error iterating ingredient variances: %v", err)
+	}
+
+	return items, nil
+}
+
+// lockOpenStocktake locks the stocktake row and fails unless it is still open
+func (r *StocktakeRepository) lockOpenStocktake(tx *sql.Tx, id, action string) error {
+	var status string
+	err := tx.QueryRow(`SELECT status FROM stocktakes WHERE id = $1 FOR UPDATE`, id).Scan(&status)
+	if err == sql.ErrNoRows {
+		r.logger.Warn("Stocktake not found", "stocktake_id", id)
+		return fmt.Errorf("stocktake with id %s not found", id)
+	}
+	if err != nil {
+		r.logger.Error("Failed to lock stocktake", "error", err, "stocktake_id", id)
+		return fmt.Errorf("failed to lock stocktake: %v", err)
+	}
+	if status != models.StocktakeOpen {
+		r.logger.Warn("Invalid stocktake transition", "stocktake_id", id, "status", status, "action", action)
+		return fmt.Errorf("stocktake is %s, only open stocktakes can be %s", status, action)
+	}
+	return nil
+}
+
+// scanStocktake reads a row produced by stocktakeSelectQuery
+func (r *StocktakeRepository) scanStocktake(row interface{ Scan(...any) error }) (*models.Stocktake, error) {
+	stocktake := &models.Stocktake{}
+	var approvedAt, cancelledAt sql.NullTime
+	var countsJSON string
+
+	err := row.Scan(&stocktake.ID, &stocktake.Status, &stocktake.Notes, &stocktake.ApprovedBy,
+		&stocktake.CreatedAt, &stocktake.UpdatedAt, &approvedAt, &cancelledAt, &stocktake.VarianceValue, &countsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan stocktake: %v", err)
+	}
+	if approvedAt.Valid {
+		stocktake.ApprovedAt = &approvedAt.Time
+	}
+	if cancelledAt.Valid {
+		stocktake.CancelledAt = &cancelledAt.Time
+	}
+
+	if err := json.Unmarshal([]byte(countsJSON), &stocktake.Counts); err != nil {
+		return nil, fmt.Errorf("failed to parse counts for stocktake %s: %v", stocktake.ID, err)
+	}
+
+	return stocktake, nil
+}
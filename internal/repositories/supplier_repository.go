@@ -0,0 +1,269 @@
+package repositories
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"frappuccino/models"
+	"frappuccino/pkg/database"
+	"frappuccino/pkg/logger"
+)
+
+type SupplierRepositoryInterface interface {
+	GetAll() ([]*models.Supplier, error)
+	GetByID(id string) (*models.Supplier, error)
+	Create(supplier *models.Supplier) error
+	Update(id string, supplier *models.Supplier) error
+	Delete(id string) error
+}
+
+type SupplierRepository struct {
+	logger *logger.Logger
+	db     *database.DB
+}
+
+func NewSupplierRepository(logger *logger.Logger, db *database.DB) *SupplierRepository {
+	return &SupplierRepository{
+		logger: logger.WithComponent("supplier_repository"),
+		db:     db,
+	}
+}
+
+const supplierSelectQuery = `
+	SELECT s.id, s.name, COALESCE(s.contact_name, ''), COALESCE(s.email, ''), COALESCE(s.phone, ''),
+	       s.lead_time_days, s.created_at, s.updated_at,
+	       COALESCE(
+	           json_agg(
+	               json_build_object(
+	                   'ingredient_id', si.ingredient_id,
+	                   'pack_size', si.pack_size,
+	                   'pack_price', si.pack_price
+	               )
+	           ) FILTER (WHERE si.ingredient_id IS NOT NULL), '[]'::json
+	       ) AS items
+	FROM suppliers s
+	LEFT JOIN supplier_items si ON si.supplier_id = s.id`
+
+// GetAll retrieves all suppliers with the items they sell
+func (r *SupplierRepository) GetAll() ([]*models.Supplier, error) {
+	r.logger.Debug("Retrieving all suppliers from database")
+
+	query := supplierSelectQuery + `
+	GROUP BY s.id
+	ORDER BY s.name`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		r.logger.Error("Failed to query suppliers", "error", err)
+		return nil, fmt.Errorf("failed to query suppliers: %v", err)
+	}
+	defer rows.Close()
+
+	suppliers := []*models.Supplier{}
+	for rows.Next() {
+		supplier, err := r.scanSupplier(rows)
+		if err != nil {
+			r.logger.Error("Failed to scan supplier", "error", err)
+			return nil, err
+		}
+		suppliers = append(suppliers, supplier)
+	}
+
+	if err = rows.Err(); err != nil {
+		r.logger.Error("Error iterating supplier rows", "error", err)
+		return nil, fmt.Errorf("error iterating supplier rows: %v", err)
+	}
+
+	r.logger.Info("Retrieved all suppliers", "count", len(suppliers))
+	return suppliers, nil
+}
+
+// GetByID retrieves a supplier by ID
+func (r *SupplierRepository) GetByID(id string) (*models.Supplier, error) {
+	r.logger.Debug("Retrieving supplier from database", "supplier_id", id)
+
+	query := supplierSelectQuery + `
+	WHERE s.id = $1
+	GROUP BY s.id`
+
+	supplier, err := r.scanSupplier(r.db.QueryRow(query, id))
+	if err != nil {
+		if strings.Contains(err.Error(), sql.ErrNoRows.Error()) {
+			r.logger.Warn("Supplier not found", "supplier_id", id)
+			return nil, fmt.Errorf("supplier with id %s not found", id)
+		}
+		r.logger.Error("Failed to retrieve supplier", "error", err, "supplier_id", id)
+		return nil, err
+	}
+
+	r.logger.Debug("Retrieved supplier", "supplier_id", id, "name", supplier.Name)
+	return supplier, nil
+}
+
+// Create inserts a supplier and its items
+func (r *SupplierRepository) Create(supplier *models.Supplier) error {
+	r.logger.Debug("Adding new supplier", "name", supplier.Name)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			r.logger.Warn("Rolling back supplier creation transaction due to error", "error", err, "name", supplier.Name)
+			tx.Rollback()
+		}
+	}()
+
+	query := `
+		INSERT INTO suppliers (name, contact_name, email, phone, lead_time_days)
+		VALUES ($1, NULLIF($2, ''), NULLIF($3, ''), NULLIF($4, ''), $5)
+		RETURNING id, created_at, updated_at`
+
+	err = tx.QueryRow(query, supplier.Name, supplier.ContactName, supplier.Email, supplier.Phone, supplier.LeadTimeDays).
+		Scan(&supplier.ID, &supplier.CreatedAt, &supplier.UpdatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value") || strings.Contains(err.Error(), "violates unique constraint") {
+			r.logger.Warn("Attempted to add duplicate supplier", "name", supplier.Name, "error", err)
+			return fmt.Errorf("supplier with name %s already exists", supplier.Name)
+		}
+		r.logger.Error("Failed to add supplier", "error", err, "name", supplier.Name)
+		return fmt.Errorf("failed to add supplier: %v", err)
+	}
+
+	if err = r.insertItems(tx, supplier.ID, supplier.Items); err != nil {
+		r.logger.Error("Failed to add supplier items", "error", err, "supplier_id", supplier.ID)
+		return fmt.Errorf("failed to add supplier items: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err)
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	r.logger.Info("Added new supplier", "supplier_id", supplier.ID, "name", supplier.Name)
+	return nil
+}
+
+// Update replaces a supplier's details and items
+func (r *SupplierRepository) Update(id string, supplier *models.Supplier) error {
+	r.logger.Debug("Updating supplier in database", "supplier_id", id)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		r.logger.Error("Failed to begin transaction", "error", err)
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	defer func() {
+		if err != nil {
+			r.logger.Warn("Rolling back supplier update transaction due to error", "error", err, "supplier_id", id)
+			tx.Rollback()
+		}
+	}()
+
+	query := `
+		UPDATE suppliers
+		SET name = $1, contact_name = NULLIF($2, ''), email = NULLIF($3, ''), phone = NULLIF($4, ''), lead_time_days = $5
+		WHERE id = $6`
+
+	result, err := tx.Exec(query, supplier.Name, supplier.ContactName, supplier.Email, supplier.Phone, supplier.LeadTimeDays, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "duplicate key value") || strings.Contains(err.Error(), "violates unique constraint") {
+			r.logger.Warn("Supplier name already taken", "name", supplier.Name, "error", err)
+			return fmt.Errorf("supplier with name %s already exists", supplier.Name)
+		}
+		r.logger.Error("Failed to update supplier", "error", err, "supplier_id", id)
+		return fmt.Errorf("failed to update supplier: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error("Failed to get rows affected", "error", err, "supplier_id", id)
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		r.logger.Warn("Attempted to update non-existent supplier", "supplier_id", id)
+		err = fmt.Errorf("supplier with id %s not found", id)
+		return err
+	}
+
+	if _, err = tx.Exec(`DELETE FROM supplier_items WHERE supplier_id = $1`, id); err != nil {
+		r.logger.Error("Failed to delete existing supplier items", "error", err, "supplier_id", id)
+		return fmt.Errorf("failed to delete existing supplier items: %v", err)
+	}
+
+	if err = r.insertItems(tx, id, supplier.Items); err != nil {
+		r.logger.Error("Failed to update supplier items", "error", err, "supplier_id", id)
+		return fmt.Errorf("failed to update supplier items: %v", err)
+	}
+
+	if err = tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit transaction", "error", err, "supplier_id", id)
+		return fmt.Errorf("failed to commit transaction: %v", err)
+	}
+
+	r.logger.Info("Updated supplier", "supplier_id", id, "name", supplier.Name)
+	return nil
+}
+
+// Delete removes a supplier; suppliers referenced by purchase orders cannot be deleted
+func (r *SupplierRepository) Delete(id string) error {
+	r.logger.Debug("Deleting supplier from database", "supplier_id", id)
+
+	result, err := r.db.Exec(`DELETE FROM suppliers WHERE id = $1`, id)
+	if err != nil {
+		if strings.Contains(err.Error(), "violates foreign key constraint") {
+			r.logger.Warn("Attempted to delete supplier with purchase orders", "supplier_id", id)
+			return fmt.Errorf("supplier with id %s has purchase orders and cannot be deleted", id)
+		}
+		r.logger.Error("Failed to delete supplier", "error", err, "supplier_id", id)
+		return fmt.Errorf("failed to delete supplier: %v", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		r.logger.Error("Failed to get rows affected", "error", err, "supplier_id", id)
+		return fmt.Errorf("failed to get rows affected: %v", err)
+	}
+	if rowsAffected == 0 {
+		r.logger.Warn("Attempted to delete non-existent supplier", "supplier_id", id)
+		return fmt.Errorf("supplier with id %s not found", id)
+	}
+
+	r.logger.Info("Deleted supplier", "supplier_id", id)
+	return nil
+}
+
+func (r *SupplierRepository) insertItems(tx *sql.Tx, supplierID string, items []models.SupplierItem) error {
+	query := `
+		INSERT INTO supplier_items (supplier_id, ingredient_id, pack_size, pack_price)
+		VALUES ($1, $2, $3, $4)`
+
+	for _, item := range items {
+		if _, err := tx.Exec(query, supplierID, item.IngredientID, item.PackSize, item.PackPrice); err != nil {
+			return fmt.Errorf("failed to insert supplier item %s: %v", item.IngredientID, err)
+		}
+	}
+	return nil
+}
+
+// scanSupplier reads a row produced by supplierSelectQuery
+func (r *SupplierRepository) scanSupplier(row interface{ Scan(...any) error }) (*models.Supplier, error) {
+	supplier := &models.Supplier{}
+	var itemsJSON string
+
+	err := row.Scan(&supplier.ID, &supplier.Name, &supplier.ContactName, &supplier.Email, &supplier.Phone,
+		&supplier.LeadTimeDays, &supplier.CreatedAt, &supplier.UpdatedAt, &itemsJSON)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan supplier: %v", err)
+	}
+
+	if err := json.Unmarshal([]byte(itemsJSON), &supplier.Items); err != nil {
+		return nil, fmt.Errorf("failed to parse items for supplier %s: %v", supplier.ID, err)
+	}
+
+	return supplier, nil
+}
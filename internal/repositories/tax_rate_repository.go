@@ -0,0 +1,144 @@
+package repositories
+
+import (
+	"fmt"
+	"strings"
+
+	"frappuccino/models"
+	"frappuccino/pkg/database"
+	"frappuccino/pkg/logger"
+)
+
+type TaxRateRepositoryInterface interface {
+	GetAll() ([]*models.TaxRate, error)
+	GetByID(id string) (*models.TaxRate, error)
+	Create(rate *models.TaxRate) error
+	Update(id string, rate *models.TaxRate) error
+	Delete(id string) error
+}
+
+type TaxRateRepository struct {
+	logger *logger.Logger
+	db     *database.DB
+}
+
+func NewTaxRateRepository(logger *logger.Logger, db *database.DB) *TaxRateRepository {
+	return &TaxRateRepository{
+		logger: logger.WithComponent("tax_rate_repository"),
+		db:     db,
+	}
+}
+
+const taxRateSelectQuery = `
+	SELECT id, name, rate, COALESCE(category, ''), COALESCE(order_type, ''), created_at, updated_at
+	FROM tax_rates`
+
+// GetAll returns every tax rate, the general ones first
+func (r *TaxRateRepository) GetAll() ([]*models.TaxRate, error) {
+	r.logger.Debug("Retrieving tax rates")
+
+	rows, err := r.db.Query(taxRateSelectQuery + ` ORDER BY category NULLS FIRST, order_type NULLS FIRST`)
+	if err != nil {
+		r.logger.Error("Failed to query tax rates", "error", err)
+		return nil, fmt.Errorf("failed to query tax rates: %v", err)
+	}
+	defer rows.Close()
+
+	rates := []*models.TaxRate{}
+	for rows.Next() {
+		rate := &models.TaxRate{}
+		if err := rows.Scan(&rate.ID, &rate.Name, &rate.Rate, &rate.Category, &rate.OrderType, &rate.CreatedAt, &rate.UpdatedAt); err != nil {
+			r.logger.Error("Failed to scan tax rate", "error", err)
+			return nil, fmt.Errorf("failed to scan tax rate: %v", err)
+		}
+		rates = append(rates, rate)
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating tax rates", "error", err)
+		return nil, fmt.Errorf("error iterating tax rates: %v", err)
+	}
+	return rates, nil
+}
+
+// GetByID returns a tax rate
+func (r *TaxRateRepository) GetByID(id string) (*models.TaxRate, error) {
+	rate := &models.TaxRate{}
+	err := r.db.QueryRow(taxRateSelectQuery+` WHERE id = $1`, id).
+		Scan(&rate.ID, &rate.Name, &rate.Rate, &rate.Category, &rate.OrderType, &rate.CreatedAt, &rate.UpdatedAt)
+	if err != nil {
+		r.logger.Warn("Failed to get tax rate", "error", err, "id", id)
+		return nil, fmt.Errorf("tax rate with id %s not found", id)
+	}
+	return rate, nil
+}
+
+// Create stores a tax rate; its ID and timestamps are filled in from the database
+func (r *TaxRateRepository) Create(rate *models.TaxRate) error {
+	r.logger.Debug("Creating tax rate", "name", rate.Name, "category", rate.Category, "order_type", rate.OrderType)
+
+	err := r.db.QueryRow(`
+		INSERT INTO tax_rates (name, rate, category, order_type)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''))
+		RETURNING id, created_at, updated_at`,
+		rate.Name, rate.Rate, string(rate.Category), rate.OrderType,
+	).Scan(&rate.ID, &rate.CreatedAt, &rate.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Failed to create tax rate", "error", err, "name", rate.Name)
+		return taxRateError("create", rate, err)
+	}
+
+	r.logger.Info("Created tax rate", "id", rate.ID, "name", rate.Name, "rate", rate.Rate)
+	return nil
+}
+
+// Update replaces a tax rate; orders already placed keep the tax they were charged
+func (r *TaxRateRepository) Update(id string, rate *models.TaxRate) error {
+	r.logger.Debug("Updating tax rate", "id", id)
+
+	err := r.db.QueryRow(`
+		UPDATE tax_rates SET name = $2, rate = $3, category = NULLIF($4, ''), order_type = NULLIF($5, '')
+		WHERE id = $1
+		RETURNING id, created_at, updated_at`,
+		id, rate.Name, rate.Rate, string(rate.Category), rate.OrderType,
+	).Scan(&rate.ID, &rate.CreatedAt, &rate.UpdatedAt)
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows") {
+			return fmt.Errorf("tax rate with id %s not found", id)
+		}
+		r.logger.Error("Failed to update tax rate", "error", err, "id", id)
+		return taxRateError("update", rate, err)
+	}
+
+	r.logger.Info("Updated tax rate", "id", id, "rate", rate.Rate)
+	return nil
+}
+
+// Delete removes a tax rate; orders keep their tax lines
+func (r *TaxRateRepository) Delete(id string) error {
+	result, err := r.db.Exec(`DELETE FROM tax_rates WHERE id = $1`, id)
+	if err != nil {
+		r.logger.Error("Failed to delete tax rate", "error", err, "id", id)
+		return fmt.Errorf("failed to delete tax rate: %v", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return fmt.Errorf("tax rate with id %s not found", id)
+	}
+
+	r.logger.Info("Deleted tax rate", "id", id)
+	return nil
+}
+
+// taxRateError reports a second rate for the same category and order type as a conflict
+func taxRateError(action string, rate *models.TaxRate, err error) error {
+	if strings.Contains(err.Error(), "idx_tax_rates_scope") {
+		category, orderType := string(rate.Category), rate.OrderType
+		if category == "" {
+			category = "all categories"
+		}
+		if orderType == "" {
+			orderType = "all order types"
+		}
+		return fmt.Errorf("tax rate for %s and %s already exists", category, orderType)
+	}
+	return fmt.Errorf("failed to %s tax rate: %v", action, err)
+}
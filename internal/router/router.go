@@ -7,7 +7,7 @@ import (
 	"frappuccino/internal/handler"
 )
 
-func NewRouter(orderHandler *handler.OrderHandler, menuHandler *handler.MenuHandler, inventoryHandler *handler.InventoryHandler, aggregationHandler *handler.AggregationHandler) *http.ServeMux {
+func NewRouter(orderHandler *handler.OrderHandler, menuHandler *handler.MenuHandler, inventoryHandler *handler.InventoryHandler, aggregationHandler *handler.AggregationHandler, supplierHandler *handler.SupplierHandler, purchaseOrderHandler *handler.PurchaseOrderHandler, stocktakeHandler *handler.StocktakeHandler, pricingRuleHandler *handler.PricingRuleHandler, taxRateHandler *handler.TaxRateHandler, paymentHandler *handler.PaymentHandler, drawerHandler *handler.DrawerHandler, customerHandler *handler.CustomerHandler, loyaltyHandler *handler.LoyaltyHandler, kdsHandler *handler.KDSHandler) *http.ServeMux {
 	mux := http.NewServeMux()
 
 	api := "/api/v1"
@@ -46,6 +46,62 @@ func NewRouter(orderHandler *handler.OrderHandler, menuHandler *handler.MenuHand
 		w.WriteHeader(http.StatusMethodNotAllowed)
 	})
 
+	// Menu margin report endpoint
+	mux.HandleFunc(api+"/reports/margins", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			aggregationHandler.GetMarginReport(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	mux.HandleFunc(api+"/reports/profit", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			aggregationHandler.GetProfitReport(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	mux.HandleFunc(api+"/reports/service-times", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			aggregationHandler.GetServiceTimesReport(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	mux.HandleFunc(api+"/reports/inventory-valuation", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			inventoryHandler.GetInventoryValuation(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	mux.HandleFunc(api+"/reports/stocktake-variance", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			stocktakeHandler.GetVarianceReport(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	mux.HandleFunc(api+"/reports/expiring", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			inventoryHandler.GetExpiringLots(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+	mux.HandleFunc(api+"/reports/loyalty-liability", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			loyaltyHandler.GetLiabilityReport(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
 	// Order collection routes: POST (create), GET (all)
 	mux.HandleFunc(api+"/orders", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodPost {
@@ -67,6 +123,46 @@ func NewRouter(orderHandler *handler.OrderHandler, menuHandler *handler.MenuHand
 			return
 		}
 
+		// Order payments: GET, POST /api/v1/orders/{id}/payments and POST /api/v1/orders/{id}/refunds
+		if strings.HasSuffix(r.URL.Path, "/payments") {
+			if r.Method == http.MethodGet {
+				paymentHandler.GetOrderPayments(w, r)
+				return
+			}
+			if r.Method == http.MethodPost {
+				paymentHandler.AddPayment(w, r)
+				return
+			}
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/refunds") {
+			if r.Method == http.MethodPost {
+				paymentHandler.RefundPayment(w, r)
+				return
+			}
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		// Station tickets: GET /api/v1/orders/{id}/tickets and PUT /api/v1/orders/{id}/items/{itemId}/status
+		if strings.HasSuffix(r.URL.Path, "/tickets") {
+			if r.Method == http.MethodGet {
+				orderHandler.GetOrderTickets(w, r)
+				return
+			}
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if strings.Contains(r.URL.Path, "/items/") {
+			if r.Method == http.MethodPut {
+				orderHandler.UpdateItemStatus(w, r)
+				return
+			}
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
 		// Regular order operations: GET, PUT, DELETE /api/v1/orders/{id}
 		if r.Method == http.MethodGet {
 			orderHandler.GetOrderByID(w, r)
@@ -101,6 +197,22 @@ func NewRouter(orderHandler *handler.OrderHandler, menuHandler *handler.MenuHand
 		w.WriteHeader(http.StatusMethodNotAllowed)
 	})
 
+	// Station routes: GET (all), PUT {category}
+	mux.HandleFunc(api+"/station-routes", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			menuHandler.GetStationRoutes(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+	mux.HandleFunc(api+"/station-routes/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			menuHandler.SetStationRoute(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
 	// Menu collection routes: POST (create), GET (all)
 	mux.HandleFunc(api+"/menu", func(w http.ResponseWriter, r *http.Request) {
 		if r.Method == http.MethodPost {
@@ -114,8 +226,39 @@ func NewRouter(orderHandler *handler.OrderHandler, menuHandler *handler.MenuHand
 		w.WriteHeader(http.StatusMethodNotAllowed)
 	})
 
-	// Menu item routes: GET (by id), PUT (update), DELETE (delete)
+	// Menu item routes: GET (by id), PUT (update), DELETE (delete), GET {id}/price-history,
+	// GET/POST {id}/price-changes, DELETE {id}/price-changes/{changeId}
 	mux.HandleFunc(api+"/menu/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/price-history") {
+			if r.Method == http.MethodGet {
+				menuHandler.GetPriceHistory(w, r)
+				return
+			}
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/price-changes") {
+			if r.Method == http.MethodGet {
+				menuHandler.GetScheduledPriceChanges(w, r)
+				return
+			}
+			if r.Method == http.MethodPost {
+				menuHandler.SchedulePriceChange(w, r)
+				return
+			}
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		if strings.Contains(r.URL.Path, "/price-changes/") {
+			if r.Method == http.MethodDelete {
+				menuHandler.CancelPriceChange(w, r)
+				return
+			}
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		// /api/v1/menu/{id}
 		if r.Method == http.MethodGet {
 			menuHandler.GetMenuItem(w, r)
 			return
@@ -144,8 +287,21 @@ func NewRouter(orderHandler *handler.OrderHandler, menuHandler *handler.MenuHand
 		w.WriteHeader(http.StatusMethodNotAllowed)
 	})
 
-	// Inventory item routes: GET (by id), PUT (update), DELETE (delete)
+	// Inventory item routes: GET (by id), PUT (update), DELETE (delete), GET/POST {id}/lots
 	mux.HandleFunc(api+"/inventory/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/lots") {
+			if r.Method == http.MethodGet {
+				inventoryHandler.GetLots(w, r)
+				return
+			}
+			if r.Method == http.MethodPost {
+				inventoryHandler.ReceiveLot(w, r)
+				return
+			}
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
 		// /api/v1/inventory/{id}
 		if r.Method == http.MethodGet {
 			inventoryHandler.GetInventoryItem(w, r)
@@ -170,5 +326,294 @@ func NewRouter(orderHandler *handler.OrderHandler, menuHandler *handler.MenuHand
 		w.WriteHeader(http.StatusMethodNotAllowed)
 	})
 
+	mux.HandleFunc(api+"/inventory/alerts", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			inventoryHandler.GetLowStockAlerts(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	mux.HandleFunc(api+"/inventory/lots/write-off-expired", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			inventoryHandler.WriteOffExpiredLots(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Supplier collection routes: POST (create), GET (all)
+	mux.HandleFunc(api+"/suppliers", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			supplierHandler.CreateSupplier(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			supplierHandler.GetAllSuppliers(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Supplier item routes: GET (by id), PUT (update), DELETE (delete)
+	mux.HandleFunc(api+"/suppliers/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			supplierHandler.GetSupplier(w, r)
+			return
+		}
+		if r.Method == http.MethodPut {
+			supplierHandler.UpdateSupplier(w, r)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			supplierHandler.DeleteSupplier(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Purchase order collection routes: POST (create draft), GET (all, ?status=)
+	mux.HandleFunc(api+"/purchase-orders", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			purchaseOrderHandler.CreatePurchaseOrder(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			purchaseOrderHandler.GetPurchaseOrders(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Reorder engine: GET (proposal), POST (create draft purchase orders from the proposal)
+	mux.HandleFunc(api+"/purchase-orders/reorder", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			purchaseOrderHandler.GetReorderProposal(w, r)
+			return
+		}
+		if r.Method == http.MethodPost {
+			purchaseOrderHandler.GenerateDraftOrders(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Purchase order item routes: GET, DELETE (draft only), POST {id}/send|receive|cancel
+	mux.HandleFunc(api+"/purchase-orders/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			purchaseOrderHandler.TransitionPurchaseOrder(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			purchaseOrderHandler.GetPurchaseOrder(w, r)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			purchaseOrderHandler.DeletePurchaseOrder(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Stocktake collection routes: POST (open), GET (all, ?status=)
+	mux.HandleFunc(api+"/stocktakes", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			stocktakeHandler.CreateStocktake(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			stocktakeHandler.GetStocktakes(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Stocktake item routes: GET, POST {id}/counts|approve|cancel
+	mux.HandleFunc(api+"/stocktakes/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			stocktakeHandler.StocktakeAction(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			stocktakeHandler.GetStocktake(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Pricing rule collection routes: POST (create), GET (all)
+	mux.HandleFunc(api+"/pricing-rules", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			pricingRuleHandler.CreatePricingRule(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			pricingRuleHandler.GetPricingRules(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Pricing rule item routes: GET, DELETE, POST {id}/activate|deactivate
+	mux.HandleFunc(api+"/pricing-rules/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			pricingRuleHandler.PricingRuleAction(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			pricingRuleHandler.GetPricingRule(w, r)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			pricingRuleHandler.DeletePricingRule(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Loyalty rule collection routes: POST (create), GET (all)
+	mux.HandleFunc(api+"/loyalty-rules", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			loyaltyHandler.CreateLoyaltyRule(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			loyaltyHandler.GetLoyaltyRules(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Loyalty rule item routes: GET (by id), PUT (update), DELETE (delete)
+	mux.HandleFunc(api+"/loyalty-rules/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			loyaltyHandler.GetLoyaltyRule(w, r)
+			return
+		}
+		if r.Method == http.MethodPut {
+			loyaltyHandler.UpdateLoyaltyRule(w, r)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			loyaltyHandler.DeleteLoyaltyRule(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Tax rate collection routes: POST (create), GET (all)
+	mux.HandleFunc(api+"/tax-rates", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			taxRateHandler.CreateTaxRate(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			taxRateHandler.GetTaxRates(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Tax rate item routes: GET (by id), PUT (update), DELETE (delete)
+	mux.HandleFunc(api+"/tax-rates/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			taxRateHandler.GetTaxRate(w, r)
+			return
+		}
+		if r.Method == http.MethodPut {
+			taxRateHandler.UpdateTaxRate(w, r)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			taxRateHandler.DeleteTaxRate(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Customer collection routes: POST (create), GET (all, ?q=)
+	mux.HandleFunc(api+"/customers", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			customerHandler.CreateCustomer(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			customerHandler.GetCustomers(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Customer item routes: GET, PUT, DELETE {id}, GET {id}/orders, GET {id}/loyalty, POST {id}/merge
+	mux.HandleFunc(api+"/customers/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/orders") {
+			customerHandler.GetCustomerOrders(w, r)
+			return
+		}
+		if r.Method == http.MethodGet && strings.HasSuffix(r.URL.Path, "/loyalty") {
+			loyaltyHandler.GetCustomerLoyalty(w, r)
+			return
+		}
+		if r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/merge") {
+			customerHandler.MergeCustomers(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			customerHandler.GetCustomer(w, r)
+			return
+		}
+		if r.Method == http.MethodPut {
+			customerHandler.UpdateCustomer(w, r)
+			return
+		}
+		if r.Method == http.MethodDelete {
+			customerHandler.DeleteCustomer(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Drawer session collection routes: POST (open), GET (all, ?status=)
+	mux.HandleFunc(api+"/drawer-sessions", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			drawerHandler.OpenDrawerSession(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			drawerHandler.GetDrawerSessions(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Kitchen display routes: GET queue, GET stream (server-sent events)
+	mux.HandleFunc(api+"/kds/queue", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			kdsHandler.GetQueue(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+	mux.HandleFunc(api+"/kds/stream", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			kdsHandler.Stream(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
+	// Drawer session item routes: GET {id}, GET {id}/z-report, POST {id}/movements|close
+	mux.HandleFunc(api+"/drawer-sessions/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPost {
+			drawerHandler.DrawerSessionAction(w, r)
+			return
+		}
+		if r.Method == http.MethodGet {
+			drawerHandler.GetDrawerSession(w, r)
+			return
+		}
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	})
+
 	return mux
 }
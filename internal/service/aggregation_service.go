@@ -13,6 +13,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"frappuccino/internal/repositories"
 	"frappuccino/models"
@@ -24,18 +25,30 @@ type AggregationServiceInterface interface {
 	GetPopularItems() ([]PopularItem, error)
 	SearchFullText(req SearchRequest) (*repositories.SearchResult, error)
 	GetOrderedItemsByPeriod(req OrderedItemsByPeriodRequest) (*repositories.OrderedItemsByPeriodResult, error)
+	GetMarginReport(targetMarginPercent float64) (*MarginReport, error)
+	GetProfitReport(from, to *time.Time) (*ProfitReport, error)
+	GetServiceTimesReport(from, to *time.Time) (*ServiceTimesReport, error)
 }
 
+// TotalSales is what closed orders sold, at the prices they were ordered at.
+// TotalRevenue is net of discounts; GrossRevenue is before them. Both exclude tax: the tax in
+// tax-inclusive orders is taken out of their lines. TaxTotal and Taxes are the tax owed for filing.
 type TotalSales struct {
-	TotalRevenue float64    `json:"total_revenue"`
-	ItemSales    []ItemSale `json:"item_sales"`
+	TotalRevenue  float64                        `json:"total_revenue"`
+	GrossRevenue  float64                        `json:"gross_revenue"`
+	DiscountTotal float64                        `json:"discount_total"`
+	TaxTotal      float64                        `json:"tax_total"`
+	ItemSales     []ItemSale                     `json:"item_sales"`
+	Discounts     []repositories.DiscountSummary `json:"discounts"`
+	Taxes         []repositories.TaxSummary      `json:"taxes"`
 }
 
 type ItemSale struct {
 	ProductID    string  `json:"product_id"`
 	ProductName  string  `json:"product_name"`
 	QuantitySold int     `json:"quantity_sold"`
-	TotalValue   float64 `json:"total_value"`
+	TotalValue   float64 `json:"total_value"` // Net of discounts and tax
+	Discount     float64 `json:"discount"`
 }
 
 type PopularItem struct {
@@ -56,14 +69,68 @@ type OrderedItemsByPeriodRequest struct {
 	Year   string `json:"year"`
 }
 
+// DefaultTargetMarginPercent is used by the margins report when no target is given
+const DefaultTargetMarginPercent = 60.0
+
+type MarginReport struct {
+	TargetMarginPercent float64          `json:"target_margin_percent"`
+	BelowTargetCount    int              `json:"below_target_count"`
+	Items               []MenuItemMargin `json:"items"`
+}
+
+type MenuItemMargin struct {
+	ProductID      string              `json:"product_id"`
+	ProductName    string              `json:"product_name"`
+	Category       models.MenuCategory `json:"category"`
+	Price          float64             `json:"price"`
+	IngredientCost float64             `json:"ingredient_cost"`
+	GrossMargin    float64             `json:"gross_margin"`
+	MarginPercent  float64             `json:"margin_percent"`
+	BelowTarget    bool                `json:"below_target"`
+}
+
+// ProfitReport is revenue, cost of goods sold and gross profit for closed orders in a period.
+// COGS comes from the inventory ledger; orders that predate the ledger are estimated from current recipe costs.
+type ProfitReport struct {
+	From            string       `json:"from,omitempty"`
+	To              string       `json:"to,omitempty"`
+	OrdersCount     int          `json:"orders_count"`
+	EstimatedOrders int          `json:"estimated_orders"`
+	Revenue         float64      `json:"revenue"` // Net of discounts and tax
+	Discounts       float64      `json:"discounts"`
+	COGS            float64      `json:"cogs"`
+	GrossProfit     float64      `json:"gross_profit"`
+	MarginPercent   float64      `json:"margin_percent"`
+	Categories      []ProfitLine `json:"categories"`
+	Items           []ProfitLine `json:"items"`
+}
+
+// ProfitLine is a profit breakdown row, keyed by category or by menu item
+type ProfitLine struct {
+	ProductID     string  `json:"product_id,omitempty"`
+	Name          string  `json:"name"`
+	Category      string  `json:"category,omitempty"`
+	QuantitySold  int     `json:"quantity_sold"`
+	Revenue       float64 `json:"revenue"`
+	COGS          float64 `json:"cogs"`
+	GrossProfit   float64 `json:"gross_profit"`
+	MarginPercent float64 `json:"margin_percent"`
+}
+
 type AggregationService struct {
 	aggregationRepo repositories.AggregationRepositoryInterface
+	menuRepo        repositories.MenuRepositoryInterface
+	inventoryRepo   repositories.InventoryRepositoryInterface
+	location        *time.Location // Shop timezone, for grouping by hour and weekday
 	logger          *logger.Logger
 }
 
-func NewAggregationService(aggregationRepo repositories.AggregationRepositoryInterface, log *logger.Logger) *AggregationService {
+func NewAggregationService(aggregationRepo repositories.AggregationRepositoryInterface, menuRepo repositories.MenuRepositoryInterface, inventoryRepo repositories.InventoryRepositoryInterface, location *time.Location, log *logger.Logger) *AggregationService {
 	return &AggregationService{
 		aggregationRepo: aggregationRepo,
+		menuRepo:        menuRepo,
+		inventoryRepo:   inventoryRepo,
+		location:        location,
 		logger:          log.WithComponent("aggregation_service"),
 	}
 }
@@ -91,34 +158,66 @@ func (s *AggregationService) GetTotalSales() (*TotalSales, error) {
 		if order.Status != "closed" {
 			continue
 		}
-		for _, orderItem := range order.Items {
+		values := make([]float64, len(order.Items))
+		for i, orderItem := range order.Items {
+			values[i] = orderItem.PriceAtTime*float64(orderItem.Quantity) - orderItem.DiscountAmount
+		}
+		lineTaxes := inclusiveLineTaxes(values, order.TaxAmount, order.TaxInclusive)
+
+		for i, orderItem := range order.Items {
 			menuItem, ok := menuMap[orderItem.ProductID]
 			if !ok {
 				s.logger.Warn("Product ID from an order not found in menu", "product_id", orderItem.ProductID, "order_id", order.ID)
 				continue
 			}
 
-			itemValue := menuItem.Price * float64(orderItem.Quantity)
+			grossValue := orderItem.PriceAtTime*float64(orderItem.Quantity) - lineTaxes[i]
+			itemValue := values[i] - lineTaxes[i]
+			report.GrossRevenue += grossValue
 			report.TotalRevenue += itemValue
 
 			if sale, exists := itemSalesMap[orderItem.ProductID]; exists {
 				sale.QuantitySold += orderItem.Quantity
 				sale.TotalValue += itemValue
+				sale.Discount += orderItem.DiscountAmount
 			} else {
 				itemSalesMap[orderItem.ProductID] = &ItemSale{
 					ProductID:    orderItem.ProductID,
 					ProductName:  menuItem.Name,
 					QuantitySold: orderItem.Quantity,
 					TotalValue:   itemValue,
+					Discount:     orderItem.DiscountAmount,
 				}
 			}
 		}
 	}
 
 	for _, sale := range itemSalesMap {
+		sale.TotalValue = roundMoney(sale.TotalValue)
+		sale.Discount = roundMoney(sale.Discount)
 		report.ItemSales = append(report.ItemSales, *sale)
 	}
 
+	report.Discounts, err = s.aggregationRepo.GetDiscountSummary(nil, nil)
+	if err != nil {
+		s.logger.Error("Failed to get discount summary for sales report", "error", err)
+		return nil, err
+	}
+
+	report.Taxes, err = s.aggregationRepo.GetTaxSummary(nil, nil)
+	if err != nil {
+		s.logger.Error("Failed to get tax summary for sales report", "error", err)
+		return nil, err
+	}
+	for _, tax := range report.Taxes {
+		report.TaxTotal += tax.TaxAmount
+	}
+	report.TaxTotal = roundMoney(report.TaxTotal)
+
+	report.TotalRevenue = roundMoney(report.TotalRevenue)
+	report.GrossRevenue = roundMoney(report.GrossRevenue)
+	report.DiscountTotal = roundMoney(report.GrossRevenue - report.TotalRevenue)
+
 	sort.Slice(report.ItemSales, func(i, j int) bool {
 		return report.ItemSales[i].ProductName < report.ItemSales[j].ProductName
 	})
@@ -198,6 +297,241 @@ func (s *AggregationService) GetOrderedItemsByPeriod(req OrderedItemsByPeriodReq
 	return result, nil
 }
 
+// GetMarginReport computes recipe cost and gross margin for every menu item and flags those below target
+func (s *AggregationService) GetMarginReport(targetMarginPercent float64) (*MarginReport, error) {
+	s.logger.Info("Calculating menu margin report", "target_margin_percent", targetMarginPercent)
+
+	if targetMarginPercent < 0 || targetMarginPercent > 100 {
+		return nil, errors.New("target margin must be between 0 and 100")
+	}
+
+	menuItems, err := s.menuRepo.GetAll()
+	if err != nil {
+		s.logger.Error("Failed to get menu items for margin report", "error", err)
+		return nil, err
+	}
+
+	inventoryItems, err := s.inventoryRepo.GetAll()
+	if err != nil {
+		s.logger.Error("Failed to get inventory for margin report", "error", err)
+		return nil, err
+	}
+	inventory := inventoryByID(inventoryItems)
+
+	report := &MarginReport{
+		TargetMarginPercent: targetMarginPercent,
+		Items:               make([]MenuItemMargin, 0, len(menuItems)),
+	}
+
+	for _, menuItem := range menuItems {
+		costing, err := calculateMenuItemCosting(menuItem, inventory)
+		if err != nil {
+			s.logger.Warn("Skipping menu item in margin report", "product_id", menuItem.ID, "error", err)
+			continue
+		}
+
+		margin := MenuItemMargin{
+			ProductID:      menuItem.ID,
+			ProductName:    menuItem.Name,
+			Category:       menuItem.Category,
+			Price:          menuItem.Price,
+			IngredientCost: costing.IngredientCost,
+			GrossMargin:    costing.GrossMargin,
+			MarginPercent:  costing.MarginPercent,
+			BelowTarget:    costing.MarginPercent < targetMarginPercent,
+		}
+		if margin.BelowTarget {
+			report.BelowTargetCount++
+		}
+		report.Items = append(report.Items, margin)
+	}
+
+	sort.Slice(report.Items, func(i, j int) bool {
+		return report.Items[i].MarginPercent < report.Items[j].MarginPercent
+	})
+
+	s.logger.Info("Menu margin report calculated successfully", "items", len(report.Items), "below_target", report.BelowTargetCount)
+	return report, nil
+}
+
+// GetProfitReport computes revenue, COGS and gross profit for closed orders created in [from, to),
+// broken down by category and by menu item
+func (s *AggregationService) GetProfitReport(from, to *time.Time) (*ProfitReport, error) {
+	s.logger.Info("Calculating profit report", "from", from, "to", to)
+
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, errors.New("invalid date range: 'from' must be before 'to'")
+	}
+
+	lines, err := s.aggregationRepo.GetClosedOrderLines(from, to)
+	if err != nil {
+		s.logger.Error("Failed to get closed order lines for profit report", "error", err)
+		return nil, err
+	}
+
+	ledgerCosts, err := s.aggregationRepo.GetOrderConsumptionCosts(from, to)
+	if err != nil {
+		s.logger.Error("Failed to get consumption costs for profit report", "error", err)
+		return nil, err
+	}
+
+	menuItems, err := s.menuRepo.GetAll()
+	if err != nil {
+		s.logger.Error("Failed to get menu items for profit report", "error", err)
+		return nil, err
+	}
+
+	inventoryItems, err := s.inventoryRepo.GetAll()
+	if err != nil {
+		s.logger.Error("Failed to get inventory for profit report", "error", err)
+		return nil, err
+	}
+	inventory := inventoryByID(inventoryItems)
+
+	// Current recipe cost per serving, used to estimate and to allocate order COGS across items
+	recipeCost := make(map[string]float64, len(menuItems))
+	for _, menuItem := range menuItems {
+		costing, err := calculateMenuItemCosting(menuItem, inventory)
+		if err != nil {
+			s.logger.Warn("Recipe cost unavailable for profit report", "product_id", menuItem.ID, "error", err)
+			continue
+		}
+		recipeCost[menuItem.ID] = costing.IngredientCost
+	}
+
+	linesByOrder := make(map[string][]repositories.ClosedOrderLine)
+	var orderIDs []string
+	for _, line := range lines {
+		if _, ok := linesByOrder[line.OrderID]; !ok {
+			orderIDs = append(orderIDs, line.OrderID)
+		}
+		linesByOrder[line.OrderID] = append(linesByOrder[line.OrderID], line)
+	}
+
+	report := &ProfitReport{
+		OrdersCount: len(orderIDs),
+		Categories:  make([]ProfitLine, 0),
+		Items:       make([]ProfitLine, 0),
+	}
+	if from != nil {
+		report.From = from.Format("2006-01-02")
+	}
+	if to != nil {
+		report.To = to.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+
+	itemLines := make(map[string]*ProfitLine)
+	categoryLines := make(map[string]*ProfitLine)
+
+	for _, orderID := range orderIDs {
+		orderLines := linesByOrder[orderID]
+
+		revenues := make([]float64, len(orderLines))
+		for i, line := range orderLines {
+			revenues[i] = line.PriceAtTime*float64(line.Quantity) - line.DiscountAmount
+		}
+		lineTaxes := inclusiveLineTaxes(revenues, orderLines[0].OrderTax, orderLines[0].TaxInclusive)
+
+		var estimatedCOGS, orderRevenue float64
+		for i, line := range orderLines {
+			revenues[i] -= lineTaxes[i]
+			estimatedCOGS += recipeCost[line.MenuItemID] * float64(line.Quantity)
+			orderRevenue += revenues[i]
+		}
+
+		orderCOGS, recorded := ledgerCosts[orderID]
+		if !recorded {
+			orderCOGS = estimatedCOGS
+			report.EstimatedOrders++
+		}
+
+		for i, line := range orderLines {
+			revenue := revenues[i]
+
+			// Split the order's COGS by each line's share of the recipe estimate, falling back to revenue share
+			var cogs float64
+			switch {
+			case estimatedCOGS > 0:
+				cogs = orderCOGS * recipeCost[line.MenuItemID] * float64(line.Quantity) / estimatedCOGS
+			case orderRevenue > 0:
+				cogs = orderCOGS * revenue / orderRevenue
+			}
+
+			item, ok := itemLines[line.MenuItemID]
+			if !ok {
+				item = &ProfitLine{ProductID: line.MenuItemID, Name: line.Name, Category: line.Category}
+				itemLines[line.MenuItemID] = item
+			}
+			item.QuantitySold += line.Quantity
+			item.Revenue += revenue
+			item.COGS += cogs
+
+			category, ok := categoryLines[line.Category]
+			if !ok {
+				category = &ProfitLine{Name: line.Category}
+				categoryLines[line.Category] = category
+			}
+			category.QuantitySold += line.Quantity
+			category.Revenue += revenue
+			category.COGS += cogs
+
+			report.Revenue += revenue
+			report.Discounts += line.DiscountAmount
+			report.COGS += cogs
+		}
+	}
+
+	for _, item := range itemLines {
+		report.Items = append(report.Items, finalizeProfitLine(item))
+	}
+	for _, category := range categoryLines {
+		report.Categories = append(report.Categories, finalizeProfitLine(category))
+	}
+
+	sort.Slice(report.Items, func(i, j int) bool {
+		return report.Items[i].GrossProfit > report.Items[j].GrossProfit
+	})
+	sort.Slice(report.Categories, func(i, j int) bool {
+		return report.Categories[i].GrossProfit > report.Categories[j].GrossProfit
+	})
+
+	report.Revenue = roundMoney(report.Revenue)
+	report.Discounts = roundMoney(report.Discounts)
+	report.COGS = roundMoney(report.COGS)
+	report.GrossProfit = roundMoney(report.Revenue - report.COGS)
+	if report.Revenue > 0 {
+		report.MarginPercent = roundMoney(report.GrossProfit / report.Revenue * 100)
+	}
+
+	s.logger.Info("Profit report calculated successfully",
+		"orders", report.OrdersCount,
+		"estimated_orders", report.EstimatedOrders,
+		"revenue", report.Revenue,
+		"gross_profit", report.GrossProfit)
+	return report, nil
+}
+
+// inclusiveLineTaxes splits the tax of a tax-inclusive order across its lines by their value, so each line
+// can be reported net of tax. Lines of tax-exclusive orders contain no tax, the customer paid it on top.
+func inclusiveLineTaxes(values []float64, orderTax float64, inclusive bool) []float64 {
+	if !inclusive {
+		return make([]float64, len(values))
+	}
+	return allocateMoney(orderTax, values)
+}
+
+// finalizeProfitLine rounds money fields and derives gross profit and margin
+func finalizeProfitLine(line *ProfitLine) ProfitLine {
+	result := *line
+	result.Revenue = roundMoney(line.Revenue)
+	result.COGS = roundMoney(line.COGS)
+	result.GrossProfit = roundMoney(result.Revenue - result.COGS)
+	if result.Revenue > 0 {
+		result.MarginPercent = roundMoney(result.GrossProfit / result.Revenue * 100)
+	}
+	return result
+}
+
 // validation functions
 func (s *AggregationService) validateSearchRequest(req SearchRequest) error {
 	if strings.TrimSpace(req.Query) == "" {
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"frappuccino/internal/repositories"
+	"frappuccino/models"
+)
+
+func TestInclusiveLineTaxes(t *testing.T) {
+	tests := []struct {
+		name      string
+		values    []float64
+		orderTax  float64
+		inclusive bool
+		want      []float64
+	}{
+		{name: "exclusive order", values: []float64{11, 5.5}, orderTax: 1.65, inclusive: false, want: []float64{0, 0}},
+		{name: "inclusive split by value", values: []float64{11, 5.5}, orderTax: 1.5, inclusive: true, want: []float64{1, 0.5}},
+		{name: "inclusive uneven split", values: []float64{1, 1, 1}, orderTax: 0.28, inclusive: true, want: []float64{0.1, 0.09, 0.09}},
+		{name: "inclusive untaxed", values: []float64{4}, orderTax: 0, inclusive: true, want: []float64{0}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := inclusiveLineTaxes(tt.values, tt.orderTax, tt.inclusive)
+			if len(got) != len(tt.want) {
+				t.Fatalf("inclusiveLineTaxes() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("inclusiveLineTaxes() = %v, want %v", got, tt.want)
+					break
+				}
+			}
+		})
+	}
+}
+
+// reportOrder is a closed order of two lattes at 5.50 and a muffin at 5.50 with 10% tax in the given mode
+func reportOrder(id string, inclusive bool) (*models.Order, []repositories.ClosedOrderLine) {
+	tax := 1.65
+	if inclusive {
+		tax = 1.5
+	}
+	order := &models.Order{
+		ID:           id,
+		Status:       "closed",
+		TaxAmount:    tax,
+		TaxInclusive: inclusive,
+		Items: []models.OrderItem{
+			{ProductID: "latte", Quantity: 2, PriceAtTime: 5.5},
+			{ProductID: "muffin", Quantity: 1, PriceAtTime: 5.5},
+		},
+	}
+	lines := []repositories.ClosedOrderLine{
+		{OrderID: id, MenuItemID: "latte", Name: "Latte", Category: "coffee", Quantity: 2, PriceAtTime: 5.5, OrderTax: tax, TaxInclusive: inclusive},
+		{OrderID: id, MenuItemID: "muffin", Name: "Muffin", Category: "pastry", Quantity: 1, PriceAtTime: 5.5, OrderTax: tax, TaxInclusive: inclusive},
+	}
+	return order, lines
+}
+
+func newReportService(inclusive bool) *AggregationService {
+	order, lines := reportOrder("order-1", inclusive)
+	menuItems := map[string]*models.MenuItem{
+		"latte":  {ID: "latte", Name: "Latte", Category: "coffee", Price: 5.5},
+		"muffin": {ID: "muffin", Name: "Muffin", Category: "pastry", Price: 5.5},
+	}
+	aggregationRepo := &fakeAggregationRepo{
+		orders:      []*models.Order{order},
+		menuItems:   []*models.MenuItem{menuItems["latte"], menuItems["muffin"]},
+		lines:       lines,
+		ledgerCosts: map[string]float64{"order-1": 6},
+	}
+	return NewAggregationService(aggregationRepo, &fakeMenuRepo{items: menuItems}, &fakeInventoryRepo{}, time.UTC, testLogger())
+}
+
+func TestGetTotalSalesExcludesTax(t *testing.T) {
+	tests := []struct {
+		name        string
+		inclusive   bool
+		wantRevenue float64
+		wantLatte   float64
+		wantMuffin  float64
+	}{
+		{name: "exclusive pricing", inclusive: false, wantRevenue: 16.5, wantLatte: 11, wantMuffin: 5.5},
+		{name: "inclusive pricing", inclusive: true, wantRevenue: 15, wantLatte: 10, wantMuffin: 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := newReportService(tt.inclusive).GetTotalSales()
+			if err != nil {
+				t.Fatalf("GetTotalSales() error = %v", err)
+			}
+			if report.TotalRevenue != tt.wantRevenue {
+				t.Errorf("TotalRevenue = %.2f, want %.2f", report.TotalRevenue, tt.wantRevenue)
+			}
+			if report.GrossRevenue != tt.wantRevenue {
+				t.Errorf("GrossRevenue = %.2f, want %.2f", report.GrossRevenue, tt.wantRevenue)
+			}
+			if report.DiscountTotal != 0 {
+				t.Errorf("DiscountTotal = %.2f, want 0", report.DiscountTotal)
+			}
+			want := map[string]float64{"latte": tt.wantLatte, "muffin": tt.wantMuffin}
+			for _, sale := range report.ItemSales {
+				if sale.TotalValue != want[sale.ProductID] {
+					t.Errorf("%s TotalValue = %.2f, want %.2f", sale.ProductID, sale.TotalValue, want[sale.ProductID])
+				}
+			}
+		})
+	}
+}
+
+func TestGetProfitReportExcludesTax(t *testing.T) {
+	tests := []struct {
+		name        string
+		inclusive   bool
+		wantRevenue float64
+		wantProfit  float64
+		wantLatte   float64
+	}{
+		{name: "exclusive pricing", inclusive: false, wantRevenue: 16.5, wantProfit: 10.5, wantLatte: 11},
+		{name: "inclusive pricing", inclusive: true, wantRevenue: 15, wantProfit: 9, wantLatte: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report, err := newReportService(tt.inclusive).GetProfitReport(nil, nil)
+			if err != nil {
+				t.Fatalf("GetProfitReport() error = %v", err)
+			}
+			if report.Revenue != tt.wantRevenue {
+				t.Errorf("Revenue = %.2f, want %.2f", report.Revenue, tt.wantRevenue)
+			}
+			if report.COGS != 6 {
+				t.Errorf("COGS = %.2f, want 6.00", report.COGS)
+			}
+			if report.GrossProfit != tt.wantProfit {
+				t.Errorf("GrossProfit = %.2f, want %.2f", report.GrossProfit, tt.wantProfit)
+			}
+			for _, item := range report.Items {
+				if item.ProductID == "latte" && item.Revenue != tt.wantLatte {
+					t.Errorf("latte Revenue = %.2f, want %.2f", item.Revenue, tt.wantLatte)
+				}
+			}
+		})
+	}
+}
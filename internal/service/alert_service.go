@@ -0,0 +1,182 @@
+package service
+
+import (
+	"time"
+
+	"frappuccino/internal/repositories"
+	"frappuccino/models"
+	"frappuccino/pkg/logger"
+	"frappuccino/pkg/webhook"
+)
+
+// EventLowStock is the webhook event name for low-stock alerts
+const EventLowStock = "inventory.low_stock"
+
+// alertRedeliveryDelay is how old an undelivered alert must be before it is redelivered,
+// leaving the first delivery time to finish its retries
+const alertRedeliveryDelay = 5 * time.Minute
+
+type AlertServiceInterface interface {
+	CheckStockLevels(ingredientIDs []string)
+	GetOpenAlerts() ([]*models.InventoryAlert, error)
+	RedeliverAlerts() (int, error)
+}
+
+type AlertService struct {
+	alertRepo        repositories.AlertRepositoryInterface
+	inventoryRepo    repositories.InventoryRepositoryInterface
+	menuAvailability MenuAvailabilitySyncer
+	sender           *webhook.Sender
+	logger           *logger.Logger
+}
+
+// LowStockEvent is the webhook payload for a newly raised low-stock alert
+type LowStockEvent struct {
+	Event string                 `json:"event"`
+	Alert *models.InventoryAlert `json:"alert"`
+}
+
+func NewAlertService(alertRepo repositories.AlertRepositoryInterface, inventoryRepo repositories.InventoryRepositoryInterface, menuAvailability MenuAvailabilitySyncer, sender *webhook.Sender, log *logger.Logger) *AlertService {
+	return &AlertService{
+		alertRepo:        alertRepo,
+		inventoryRepo:    inventoryRepo,
+		menuAvailability: menuAvailability,
+		sender:           sender,
+		logger:           log.WithComponent("alert_service"),
+	}
+}
+
+// CheckStockLevels raises a low-stock alert for every given ingredient below its threshold
+// and resolves open alerts for those that have been restocked, then has the menu switch items using them
+// off when they run out and back on when restocked. Failures are logged, never returned,
+// so stock movements are not undone because alerting failed.
+func (s *AlertService) CheckStockLevels(ingredientIDs []string) {
+	for _, ingredientID := range ingredientIDs {
+		item, err := s.inventoryRepo.GetByID(ingredientID)
+		if err != nil {
+			s.logger.Warn("Failed to load inventory item for stock check", "ingredient_id", ingredientID, "error", err)
+			continue
+		}
+
+		if item.Quantity >= item.MinThreshold {
+			if _, err := s.alertRepo.ResolveAlert(ingredientID, models.AlertTypeLowStock); err != nil {
+				s.logger.Error("Failed to resolve low stock alert", "ingredient_id", ingredientID, "error", err)
+			}
+			continue
+		}
+
+		alert, created, err := s.alertRepo.OpenAlert(item, models.AlertTypeLowStock)
+		if err != nil {
+			s.logger.Error("Failed to open low stock alert", "ingredient_id", ingredientID, "error", err)
+			continue
+		}
+		if !created {
+			continue
+		}
+
+		s.logger.Warn("Inventory below minimum threshold",
+			"ingredient_id", ingredientID,
+			"name", item.Name,
+			"quantity", item.Quantity,
+			"min_threshold", item.MinThreshold)
+
+		if s.sender != nil && s.sender.Enabled() {
+			go s.deliver(alert)
+		}
+	}
+
+	if s.menuAvailability != nil {
+		s.menuAvailability.SyncMenuAvailability(ingredientIDs)
+	}
+}
+
+// GetOpenAlerts lists alerts that have not been resolved by a restock
+func (s *AlertService) GetOpenAlerts() ([]*models.InventoryAlert, error) {
+	s.logger.Info("Fetching open inventory alerts")
+
+	alerts, err := s.alertRepo.GetOpenAlerts()
+	if err != nil {
+		s.logger.Error("Failed to fetch open inventory alerts", "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Fetched open inventory alerts", "count", len(alerts))
+	return alerts, nil
+}
+
+// RedeliverAlerts sends the open alerts that some webhook has not received, e.g. because a receiver was down
+// or the server restarted mid-delivery, to only those webhooks. It returns how many are now fully delivered.
+func (s *AlertService) RedeliverAlerts() (int, error) {
+	if s.sender == nil || !s.sender.Enabled() {
+		return 0, nil
+	}
+
+	alerts, err := s.alertRepo.GetUndeliveredAlerts(time.Now().Add(-alertRedeliveryDelay))
+	if err != nil {
+		s.logger.Error("Failed to fetch undelivered alerts", "error", err)
+		return 0, err
+	}
+
+	delivered := 0
+	for _, alert := range alerts {
+		if s.deliver(alert) {
+			delivered++
+		}
+	}
+
+	if len(alerts) > 0 {
+		s.logger.Info("Redelivered low stock alerts", "alerts", len(alerts), "delivered", delivered)
+	}
+	return delivered, nil
+}
+
+// deliver sends the alert to the webhooks that have not accepted it yet, records the outcome per webhook
+// and reports whether every webhook has now received it
+func (s *AlertService) deliver(alert *models.InventoryAlert) bool {
+	delivered, err := s.alertRepo.GetDeliveredURLs(alert.ID)
+	if err != nil {
+		s.logger.Error("Failed to load low stock alert deliveries", "alert_id", alert.ID, "error", err)
+		return false
+	}
+	var pending []string
+	for _, url := range s.sender.URLs() {
+		if !delivered[url] {
+			pending = append(pending, url)
+		}
+	}
+
+	deliveries, err := s.sender.Send(alert.ID, EventLowStock, LowStockEvent{Event: EventLowStock, Alert: alert}, pending)
+	if err != nil {
+		s.logger.Error("Failed to deliver low stock alert", "alert_id", alert.ID, "error", err)
+		return false
+	}
+
+	complete := true
+	for _, delivery := range deliveries {
+		var deliveredAt *time.Time
+		lastError := ""
+		if delivery.Err != nil {
+			complete = false
+			lastError = delivery.Err.Error()
+			s.logger.Error("Failed to deliver low stock alert", "alert_id", alert.ID, "url", delivery.URL, "attempts", delivery.Attempts, "error", delivery.Err)
+		} else {
+			now := time.Now()
+			deliveredAt = &now
+			s.logger.Info("Delivered low stock alert", "alert_id", alert.ID, "url", delivery.URL, "attempts", delivery.Attempts)
+		}
+
+		if err := s.alertRepo.RecordDelivery(alert.ID, delivery.URL, delivery.Attempts, deliveredAt, lastError); err != nil {
+			s.logger.Error("Failed to record low stock alert delivery", "alert_id", alert.ID, "url", delivery.URL, "error", err)
+			complete = false
+		}
+	}
+	if !complete {
+		return false
+	}
+
+	if err := s.alertRepo.MarkDelivered(alert.ID, time.Now()); err != nil {
+		s.logger.Error("Failed to mark low stock alert delivered", "alert_id", alert.ID, "error", err)
+		return false
+	}
+	return true
+}
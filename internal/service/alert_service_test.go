@@ -0,0 +1,70 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"frappuccino/models"
+	"frappuccino/pkg/webhook"
+)
+
+func TestDeliverRetriesOnlyFailedReceivers(t *testing.T) {
+	var okHits, flakyHits int32
+	var flakyDown atomic.Bool
+	flakyDown.Store(true)
+	var gotIDs []string
+
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&okHits, 1)
+		gotIDs = append(gotIDs, r.Header.Get(webhook.HeaderID))
+	}))
+	defer ok.Close()
+	flaky := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&flakyHits, 1)
+		gotIDs = append(gotIDs, r.Header.Get(webhook.HeaderID))
+		if flakyDown.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer flaky.Close()
+
+	repo := &fakeAlertRepo{}
+	sender := webhook.NewSender(webhook.Config{URLs: []string{ok.URL, flaky.URL}, MaxAttempts: 2, InitialBackoff: time.Millisecond})
+	s := NewAlertService(repo, &fakeInventoryRepo{}, nil, sender, testLogger())
+	alert := &models.InventoryAlert{ID: "alert-1", IngredientID: "milk", AlertType: models.AlertTypeLowStock}
+
+	if s.deliver(alert) {
+		t.Fatal("deliver() = true with a receiver down, want false")
+	}
+	if repo.deliveredAt != nil {
+		t.Error("alert marked delivered with a receiver down")
+	}
+	if okHits != 1 || flakyHits != 2 {
+		t.Errorf("first delivery: receivers got %d and %d requests, want 1 and 2", okHits, flakyHits)
+	}
+
+	flakyDown.Store(false)
+	if !s.deliver(alert) {
+		t.Fatal("redelivery: deliver() = false, want true")
+	}
+	if okHits != 1 {
+		t.Errorf("redelivery re-sent to the receiver that accepted the alert: %d requests", okHits)
+	}
+	if flakyHits != 3 {
+		t.Errorf("redelivery: failed receiver got %d requests in total, want 3", flakyHits)
+	}
+	if repo.deliveredAt == nil {
+		t.Error("alert not marked delivered once every receiver accepted it")
+	}
+	if repo.attempts[flaky.URL] != 3 || repo.attempts[ok.URL] != 1 {
+		t.Errorf("recorded attempts %v, want 1 and 3", repo.attempts)
+	}
+	for _, id := range gotIDs {
+		if id != "alert-1" {
+			t.Errorf("%s = %q, want the alert ID on every delivery", webhook.HeaderID, id)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package service
+
+import (
+	"fmt"
+	"math"
+
+	"frappuccino/models"
+	"frappuccino/pkg/units"
+)
+
+// recipeQuantity returns the per-serving quantity of a recipe line expressed in the inventory unit
+func recipeQuantity(ingredient models.MenuItemIngredient, inventoryItem *models.InventoryItem) (float64, error) {
+	quantity, err := units.Convert(ingredient.Quantity, ingredient.Unit, inventoryItem.Unit)
+	if err != nil {
+		return 0, fmt.Errorf("ingredient '%s': %v", inventoryItem.Name, err)
+	}
+	return quantity, nil
+}
+
+// calculateMenuItemCosting prices a recipe against current inventory costs and the item's menu price
+func calculateMenuItemCosting(item *models.MenuItem, inventory map[string]*models.InventoryItem) (*models.MenuItemCosting, error) {
+	costing := &models.MenuItemCosting{
+		Ingredients: make([]models.IngredientCost, 0, len(item.Ingredients)),
+	}
+
+	for _, ingredient := range item.Ingredients {
+		inventoryItem, ok := inventory[ingredient.IngredientID]
+		if !ok {
+			return nil, fmt.Errorf("ingredient '%s' not found in inventory", ingredient.IngredientID)
+		}
+
+		quantity, err := recipeQuantity(ingredient, inventoryItem)
+		if err != nil {
+			return nil, err
+		}
+
+		cost := quantity * inventoryItem.CostPerUnit
+		costing.IngredientCost += cost
+		costing.Ingredients = append(costing.Ingredients, models.IngredientCost{
+			IngredientID: ingredient.IngredientID,
+			Name:         inventoryItem.Name,
+			Quantity:     quantity,
+			Unit:         inventoryItem.Unit,
+			CostPerUnit:  inventoryItem.CostPerUnit,
+			Cost:         roundMoney(cost),
+		})
+	}
+
+	costing.IngredientCost = roundMoney(costing.IngredientCost)
+	costing.GrossMargin = roundMoney(item.Price - costing.IngredientCost)
+	if item.Price > 0 {
+		costing.MarginPercent = roundMoney(costing.GrossMargin / item.Price * 100)
+	}
+
+	return costing, nil
+}
+
+// inventoryByID indexes inventory items by ingredient ID
+func inventoryByID(items []*models.InventoryItem) map[string]*models.InventoryItem {
+	inventory := make(map[string]*models.InventoryItem, len(items))
+	for _, item := range items {
+		inventory[item.IngredientID] = item
+	}
+	return inventory
+}
+
+// roundMoney rounds a monetary value to cents
+func roundMoney(value float64) float64 {
+	return math.Round(value*100) / 100
+}
+
+// roundUnitCost rounds a cost per unit of stock to the precision of DECIMAL(12,4) columns,
+// since ingredients costed per g or ml are worth fractions of a cent
+func roundUnitCost(value float64) float64 {
+	return math.Round(value*10000) / 10000
+}
+
+// roundQuantity rounds a stock quantity to the precision of DECIMAL(10,3) columns
+func roundQuantity(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"fmt"
+	"net/mail"
+	"sort"
+	"strings"
+
+	"frappuccino/internal/repositories"
+	"frappuccino/models"
+	"frappuccino/pkg/logger"
+)
+
+type CustomerRequest struct {
+	Name        string            `json:"name"`
+	Phone       string            `json:"phone"`
+	Email       string            `json:"email"`
+	Preferences map[string]string `json:"preferences"`
+	Allergens   []string          `json:"allergens"`
+}
+
+type MergeCustomersRequest struct {
+	SourceID string `json:"source_id"` // Duplicate profile folded into the one in the path
+}
+
+type CustomerServiceInterface interface {
+	GetCustomers(search string) ([]*models.Customer, error)
+	GetCustomer(id string) (*models.Customer, error)
+	CreateCustomer(req CustomerRequest) (*models.Customer, error)
+	UpdateCustomer(id string, req CustomerRequest) (*models.Customer, error)
+	DeleteCustomer(id string) error
+	GetCustomerOrders(id string) ([]*models.Order, error)
+	MergeCustomers(targetID string, req MergeCustomersRequest) (*models.Customer, error)
+}
+
+type CustomerService struct {
+	customerRepo repositories.CustomerRepositoryInterface
+	orderRepo    repositories.OrderRepositoryInterface
+	logger       *logger.Logger
+}
+
+func NewCustomerService(customerRepo repositories.CustomerRepositoryInterface, orderRepo repositories.OrderRepositoryInterface, log *logger.Logger) *CustomerService {
+	return &CustomerService{
+		customerRepo: customerRepo,
+		orderRepo:    orderRepo,
+		logger:       log.WithComponent("customer_service"),
+	}
+}
+
+// GetCustomers lists customers, optionally matching a name, phone or email fragment
+func (s *CustomerService) GetCustomers(search string) ([]*models.Customer, error) {
+	s.logger.Info("Fetching customers", "search", search)
+
+	customers, err := s.customerRepo.GetAll(strings.TrimSpace(search))
+	if err != nil {
+		s.logger.Error("Failed to fetch customers", "error", err)
+		return nil, err
+	}
+	return customers, nil
+}
+
+// GetCustomer returns a single customer
+func (s *CustomerService) GetCustomer(id string) (*models.Customer, error) {
+	s.logger.Info("Fetching customer", "customer_id", id)
+
+	customer, err := s.customerRepo.GetByID(id)
+	if err != nil {
+		s.logger.Warn("Customer not found", "customer_id", id, "error", err)
+		return nil, err
+	}
+	return customer, nil
+}
+
+// CreateCustomer validates and stores a new customer
+func (s *CustomerService) CreateCustomer(req CustomerRequest) (*models.Customer, error) {
+	s.logger.Info("Creating customer", "name", req.Name)
+
+	customer, err := customerFromRequest(req)
+	if err != nil {
+		s.logger.Warn("Create failed: invalid customer", "error", err)
+		return nil, err
+	}
+
+	if err := s.customerRepo.Create(customer); err != nil {
+		s.logger.Warn("Failed to create customer", "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Customer created", "customer_id", customer.ID)
+	return customer, nil
+}
+
+// UpdateCustomer replaces a customer's details
+func (s *CustomerService) UpdateCustomer(id string, req CustomerRequest) (*models.Customer, error) {
+	s.logger.Info("Updating customer", "customer_id", id)
+
+	customer, err := customerFromRequest(req)
+	if err != nil {
+		s.logger.Warn("Update failed: invalid customer", "customer_id", id, "error", err)
+		return nil, err
+	}
+	customer.ID = id
+
+	if err := s.customerRepo.Update(customer); err != nil {
+		s.logger.Warn("Failed to update customer", "customer_id", id, "error", err)
+		return nil, err
+	}
+	return customer, nil
+}
+
+// DeleteCustomer removes a customer, leaving their orders as walk-ins
+func (s *CustomerService) DeleteCustomer(id string) error {
+	s.logger.Info("Deleting customer", "customer_id", id)
+
+	if err := s.customerRepo.Delete(id); err != nil {
+		s.logger.Warn("Failed to delete customer", "customer_id", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+// GetCustomerOrders returns a customer's order history, newest first
+func (s *CustomerService) GetCustomerOrders(id string) ([]*models.Order, error) {
+	s.logger.Info("Fetching customer orders", "customer_id", id)
+
+	if _, err := s.customerRepo.GetByID(id); err != nil {
+		s.logger.Warn("Customer not found", "customer_id", id, "error", err)
+		return nil, err
+	}
+
+	orders, err := s.orderRepo.GetByCustomer(id)
+	if err != nil {
+		s.logger.Error("Failed to fetch customer orders", "customer_id", id, "error", err)
+		return nil, err
+	}
+	return orders, nil
+}
+
+// MergeCustomers folds the source profile into the target and returns the merged target
+func (s *CustomerService) MergeCustomers(targetID string, req MergeCustomersRequest) (*models.Customer, error) {
+	s.logger.Info("Merging customers", "source_id", req.SourceID, "target_id", targetID)
+
+	if req.SourceID == "" {
+		return nil, fmt.Errorf("source_id is required")
+	}
+	if req.SourceID == targetID {
+		return nil, fmt.Errorf("cannot merge a customer into itself")
+	}
+
+	customer, err := s.customerRepo.Merge(req.SourceID, targetID)
+	if err != nil {
+		s.logger.Warn("Failed to merge customers", "source_id", req.SourceID, "target_id", targetID, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Customers merged", "source_id", req.SourceID, "target_id", targetID)
+	return customer, nil
+}
+
+func customerFromRequest(req CustomerRequest) (*models.Customer, error) {
+	customer := &models.Customer{
+		Name:        strings.TrimSpace(req.Name),
+		Phone:       strings.TrimSpace(req.Phone),
+		Email:       strings.ToLower(strings.TrimSpace(req.Email)),
+		Preferences: map[string]string{},
+	}
+
+	if customer.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if customer.Email != "" {
+		if _, err := mail.ParseAddress(customer.Email); err != nil {
+			return nil, fmt.Errorf("invalid email '%s'", req.Email)
+		}
+	}
+
+	for key, value := range req.Preferences {
+		key = strings.TrimSpace(key)
+		if key == "" {
+			return nil, fmt.Errorf("preference names cannot be empty")
+		}
+		customer.Preferences[key] = strings.TrimSpace(value)
+	}
+
+	allergens, err := normalizeAllergens(req.Allergens)
+	if err != nil {
+		return nil, err
+	}
+	customer.Allergens = allergens
+	return customer, nil
+}
+
+// normalizeAllergens lowercases, dedupes and sorts allergen names so they compare across profiles and menus
+func normalizeAllergens(allergens []string) ([]string, error) {
+	seen := make(map[string]bool, len(allergens))
+	normalized := []string{}
+	for _, allergen := range allergens {
+		allergen = strings.ToLower(strings.Join(strings.Fields(allergen), " "))
+		if allergen == "" {
+			continue
+		}
+		if strings.ContainsAny(allergen, `,{}"\`) {
+			return nil, fmt.Errorf("invalid allergen '%s'", allergen)
+		}
+		if !seen[allergen] {
+			seen[allergen] = true
+			normalized = append(normalized, allergen)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"frappuccino/internal/repositories"
+	"frappuccino/models"
+	"frappuccino/pkg/logger"
+)
+
+type OpenDrawerRequest struct {
+	OpenedBy     string  `json:"opened_by"`
+	OpeningFloat float64 `json:"opening_float"`
+}
+
+type DrawerMovementRequest struct {
+	Type      string  `json:"type"`
+	Amount    float64 `json:"amount"`
+	Reason    string  `json:"reason"`
+	CreatedBy string  `json:"created_by"`
+}
+
+type CloseDrawerRequest struct {
+	ClosedBy    string   `json:"closed_by"`
+	CountedCash *float64 `json:"counted_cash"`
+}
+
+type DrawerServiceInterface interface {
+	GetDrawerSessions(status string) ([]*models.DrawerSession, error)
+	GetDrawerSession(id string) (*models.DrawerSession, error)
+	OpenDrawerSession(req OpenDrawerRequest) (*models.DrawerSession, error)
+	AddDrawerMovement(id string, req DrawerMovementRequest) (*models.DrawerMovement, error)
+	CloseDrawerSession(id string, req CloseDrawerRequest) (*models.ZReport, error)
+	GetZReport(id string) (*models.ZReport, error)
+}
+
+type DrawerService struct {
+	drawerRepo repositories.DrawerRepositoryInterface
+	logger     *logger.Logger
+}
+
+func NewDrawerService(drawerRepo repositories.DrawerRepositoryInterface, log *logger.Logger) *DrawerService {
+	return &DrawerService{
+		drawerRepo: drawerRepo,
+		logger:     log.WithComponent("drawer_service"),
+	}
+}
+
+// GetDrawerSessions lists drawer sessions, optionally only open or closed ones
+func (s *DrawerService) GetDrawerSessions(status string) ([]*models.DrawerSession, error) {
+	s.logger.Info("Fetching drawer sessions", "status", status)
+
+	if status != "" && status != models.DrawerOpen && status != models.DrawerClosed {
+		return nil, fmt.Errorf("invalid status '%s': must be %s or %s", status, models.DrawerOpen, models.DrawerClosed)
+	}
+
+	sessions, err := s.drawerRepo.GetAll(status)
+	if err != nil {
+		s.logger.Error("Failed to fetch drawer sessions", "error", err)
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// GetDrawerSession returns a drawer session with its movements
+func (s *DrawerService) GetDrawerSession(id string) (*models.DrawerSession, error) {
+	s.logger.Info("Fetching drawer session", "session_id", id)
+
+	session, err := s.drawerRepo.GetByID(id)
+	if err != nil {
+		s.logger.Warn("Drawer session not found", "session_id", id, "error", err)
+		return nil, err
+	}
+	return session, nil
+}
+
+// OpenDrawerSession starts a drawer session with the float counted into the drawer
+func (s *DrawerService) OpenDrawerSession(req OpenDrawerRequest) (*models.DrawerSession, error) {
+	s.logger.Info("Opening drawer session", "opened_by", req.OpenedBy, "opening_float", req.OpeningFloat)
+
+	session := &models.DrawerSession{
+		OpenedBy:     strings.TrimSpace(req.OpenedBy),
+		OpeningFloat: roundMoney(req.OpeningFloat),
+	}
+	if session.OpenedBy == "" {
+		return nil, fmt.Errorf("opened_by is required")
+	}
+	if session.OpeningFloat < 0 {
+		return nil, fmt.Errorf("opening_float cannot be negative")
+	}
+
+	if err := s.drawerRepo.Open(session); err != nil {
+		s.logger.Warn("Failed to open drawer session", "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Drawer session opened", "session_id", session.ID)
+	return session, nil
+}
+
+// AddDrawerMovement records cash paid into or out of the drawer, such as change brought in or a supplier paid in cash
+func (s *DrawerService) AddDrawerMovement(id string, req DrawerMovementRequest) (*models.DrawerMovement, error) {
+	s.logger.Info("Adding drawer movement", "session_id", id, "type", req.Type, "amount", req.Amount)
+
+	movement := &models.DrawerMovement{
+		SessionID: id,
+		Type:      req.Type,
+		Amount:    roundMoney(req.Amount),
+		Reason:    strings.TrimSpace(req.Reason),
+		CreatedBy: strings.TrimSpace(req.CreatedBy),
+	}
+	if movement.Type != models.DrawerPaidIn && movement.Type != models.DrawerPaidOut {
+		return nil, fmt.Errorf("invalid type '%s': must be %s or %s", req.Type, models.DrawerPaidIn, models.DrawerPaidOut)
+	}
+	if movement.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be positive")
+	}
+	if movement.Reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+
+	if err := s.drawerRepo.AddMovement(movement); err != nil {
+		s.logger.Warn("Failed to add drawer movement", "session_id", id, "error", err)
+		return nil, err
+	}
+	return movement, nil
+}
+
+// CloseDrawerSession closes the session with the cash counted in the drawer and returns its Z-report
+func (s *DrawerService) CloseDrawerSession(id string, req CloseDrawerRequest) (*models.ZReport, error) {
+	s.logger.Info("Closing drawer session", "session_id", id, "closed_by", req.ClosedBy)
+
+	closedBy := strings.TrimSpace(req.ClosedBy)
+	if closedBy == "" {
+		return nil, fmt.Errorf("closed_by is required")
+	}
+	if req.CountedCash == nil {
+		return nil, fmt.Errorf("counted_cash is required")
+	}
+	countedCash := roundMoney(*req.CountedCash)
+	if countedCash < 0 {
+		return nil, fmt.Errorf("counted_cash cannot be negative")
+	}
+
+	report, err := s.drawerRepo.Close(id, closedBy, countedCash)
+	if err != nil {
+		s.logger.Warn("Failed to close drawer session", "session_id", id, "error", err)
+		return nil, err
+	}
+
+	if report.CashVariance != 0 {
+		s.logger.Warn("Drawer count does not match", "session_id", id, "expected", report.ExpectedCash, "counted", report.CountedCash)
+	}
+	s.logger.Info("Drawer session closed", "session_id", id, "z_report_id", report.ID)
+	return report, nil
+}
+
+// GetZReport returns the Z-report of a closed drawer session
+func (s *DrawerService) GetZReport(id string) (*models.ZReport, error) {
+	s.logger.Info("Fetching z-report", "session_id", id)
+
+	report, err := s.drawerRepo.GetZReport(id)
+	if err != nil {
+		s.logger.Warn("Z-report not found", "session_id", id, "error", err)
+		return nil, err
+	}
+	return report, nil
+}
@@ -0,0 +1,302 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"frappuccino/internal/repositories"
+	"frappuccino/models"
+	"frappuccino/pkg/logger"
+)
+
+// fakeMenuRepo serves menu items from memory. Methods the tests don't use panic through the nil interface.
+type fakeMenuRepo struct {
+	repositories.MenuRepositoryInterface
+	items  map[string]*models.MenuItem
+	loaded []string // IDs of the items GetStockDependents returned
+}
+
+func (r *fakeMenuRepo) GetByID(id string) (*models.MenuItem, error) {
+	item, ok := r.items[id]
+	if !ok {
+		return nil, fmt.Errorf("menu item with id %s not found", id)
+	}
+	return item, nil
+}
+
+func (r *fakeMenuRepo) GetAll() ([]*models.MenuItem, error) {
+	items := make([]*models.MenuItem, 0, len(r.items))
+	for _, item := range r.items {
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (r *fakeMenuRepo) GetStockDependents(ingredientIDs []string) ([]*models.MenuItem, error) {
+	var items []*models.MenuItem
+	for _, item := range r.items {
+		for _, ingredient := range item.Ingredients {
+			if contains(ingredientIDs, ingredient.IngredientID) {
+				items = append(items, item)
+				r.loaded = append(r.loaded, item.ID)
+				break
+			}
+		}
+	}
+	return items, nil
+}
+
+// SetStockAvailability follows the repository: only stock-driven changes flip an item
+func (r *fakeMenuRepo) SetStockAvailability(id string, inStock bool) (bool, error) {
+	item := r.items[id]
+	switch {
+	case !inStock && item.Available:
+		item.Available, item.OutOfStock = false, true
+	case inStock && item.OutOfStock:
+		item.Available, item.OutOfStock = true, false
+	default:
+		return false, nil
+	}
+	return true, nil
+}
+
+// fakeInventoryRepo serves inventory items from memory and applies stock movements to their quantities
+type fakeInventoryRepo struct {
+	repositories.InventoryRepositoryInterface
+	items  map[string]*models.InventoryItem
+	ledger []*models.InventoryTransaction
+}
+
+func (r *fakeInventoryRepo) GetAll() ([]*models.InventoryItem, error) {
+	items := make([]*models.InventoryItem, 0, len(r.items))
+	for _, item := range r.items {
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (r *fakeInventoryRepo) GetByID(id string) (*models.InventoryItem, error) {
+	item, ok := r.items[id]
+	if !ok {
+		return nil, fmt.Errorf("inventory item with id %s not found", id)
+	}
+	return item, nil
+}
+
+func (r *fakeInventoryRepo) ApplyTransactions(transactions []*models.InventoryTransaction) error {
+	for _, transaction := range transactions {
+		item := r.items[transaction.IngredientID]
+		if item.Quantity+transaction.QuantityChange < 0 {
+			return fmt.Errorf("insufficient inventory for ingredient %s", transaction.IngredientID)
+		}
+	}
+	for _, transaction := range transactions {
+		item := r.items[transaction.IngredientID]
+		transaction.QuantityBefore = item.Quantity
+		item.Quantity += transaction.QuantityChange
+		transaction.QuantityAfter = item.Quantity
+		r.ledger = append(r.ledger, transaction)
+	}
+	return nil
+}
+
+func (r *fakeInventoryRepo) CheckInventoryAvailability(requirements map[string]float64) (map[string]*models.InventoryItem, error) {
+	for id, quantity := range requirements {
+		if r.items[id].Quantity < quantity {
+			return nil, fmt.Errorf("insufficient inventory for: %s", id)
+		}
+	}
+	return r.items, nil
+}
+
+// fakeReservationRepo holds order reservations in memory. Consuming one draws it from the fake inventory.
+type fakeReservationRepo struct {
+	repositories.ReservationRepositoryInterface
+	inventory    *fakeInventoryRepo
+	reserved     map[string]map[string]float64 // Order ID to quantity per ingredient
+	consumeError error
+}
+
+func (r *fakeReservationRepo) Reserve(orderID string, quantities map[string]float64) error {
+	if r.reserved == nil {
+		r.reserved = make(map[string]map[string]float64)
+	}
+	r.reserved[orderID] = quantities
+	return nil
+}
+
+func (r *fakeReservationRepo) Release(orderID string) error {
+	delete(r.reserved, orderID)
+	return nil
+}
+
+func (r *fakeReservationRepo) Consume(orderID string) ([]*models.InventoryTransaction, error) {
+	if r.consumeError != nil {
+		return nil, r.consumeError
+	}
+	var transactions []*models.InventoryTransaction
+	for ingredientID, quantity := range r.reserved[orderID] {
+		transactions = append(transactions, &models.InventoryTransaction{
+			IngredientID:    ingredientID,
+			TransactionType: models.TransactionUsage,
+			QuantityChange:  -quantity,
+			ReferenceType:   models.ReferenceTypeOrder,
+			ReferenceID:     orderID,
+		})
+	}
+	if err := r.inventory.ApplyTransactions(transactions); err != nil {
+		return nil, err
+	}
+	delete(r.reserved, orderID)
+	return transactions, nil
+}
+
+// fakeOrderRepo serves orders from memory. Saving fails with saveError, like a lost connection would.
+type fakeOrderRepo struct {
+	repositories.OrderRepositoryInterface
+	orders    map[string]*models.Order
+	saveError error
+}
+
+func (r *fakeOrderRepo) GetByID(id string) (*models.Order, error) {
+	order, ok := r.orders[id]
+	if !ok {
+		return nil, fmt.Errorf("order with id %s not found", id)
+	}
+	copied := *order
+	return &copied, nil
+}
+
+func (r *fakeOrderRepo) Update(id string, order *models.Order) error {
+	if r.saveError != nil {
+		return r.saveError
+	}
+	r.orders[id] = order
+	return nil
+}
+
+func (r *fakeOrderRepo) UpdateStatus(id, from, to string) error {
+	if r.saveError != nil {
+		return r.saveError
+	}
+	if r.orders[id].Status != from {
+		return fmt.Errorf("order %s is no longer %s", id, from)
+	}
+	r.orders[id].Status = to
+	return nil
+}
+
+// fakePricingRepo has no pricing rules
+type fakePricingRepo struct {
+	repositories.PricingRuleRepositoryInterface
+}
+
+func (r *fakePricingRepo) GetAutomatic() ([]*models.PricingRule, error) {
+	return nil, nil
+}
+
+// fakeTaxRepo has no tax rates
+type fakeTaxRepo struct {
+	repositories.TaxRateRepositoryInterface
+}
+
+func (r *fakeTaxRepo) GetAll() ([]*models.TaxRate, error) {
+	return nil, nil
+}
+
+// fakePaymentRepo has no payments
+type fakePaymentRepo struct {
+	repositories.PaymentRepositoryInterface
+}
+
+func (r *fakePaymentRepo) GetByOrder(orderID string) ([]*models.Payment, []*models.Refund, error) {
+	return nil, nil, nil
+}
+
+// fakeAlertService records the ingredients it is asked to check
+type fakeAlertService struct {
+	AlertServiceInterface
+	checked []string
+}
+
+func (s *fakeAlertService) CheckStockLevels(ingredientIDs []string) {
+	s.checked = append(s.checked, ingredientIDs...)
+}
+
+// fakeAlertRepo accepts every alert change and keeps delivery outcomes in memory
+type fakeAlertRepo struct {
+	repositories.AlertRepositoryInterface
+	delivered   map[string]bool // Receivers that accepted the alert
+	attempts    map[string]int  // Attempts per receiver
+	deliveredAt *time.Time      // Set once every receiver accepted the alert
+}
+
+func (r *fakeAlertRepo) OpenAlert(item *models.InventoryItem, alertType string) (*models.InventoryAlert, bool, error) {
+	return &models.InventoryAlert{IngredientID: item.IngredientID, AlertType: alertType}, true, nil
+}
+
+func (r *fakeAlertRepo) ResolveAlert(ingredientID, alertType string) (bool, error) {
+	return false, nil
+}
+
+func (r *fakeAlertRepo) GetDeliveredURLs(id string) (map[string]bool, error) {
+	return r.delivered, nil
+}
+
+func (r *fakeAlertRepo) RecordDelivery(id, url string, attempts int, deliveredAt *time.Time, lastError string) error {
+	if r.delivered == nil {
+		r.delivered, r.attempts = map[string]bool{}, map[string]int{}
+	}
+	r.attempts[url] += attempts
+	r.delivered[url] = deliveredAt != nil
+	return nil
+}
+
+func (r *fakeAlertRepo) MarkDelivered(id string, deliveredAt time.Time) error {
+	r.deliveredAt = &deliveredAt
+	return nil
+}
+
+// fakeLotRepo writes off a fixed set of expired lots
+type fakeLotRepo struct {
+	repositories.LotRepositoryInterface
+	expired []*models.InventoryTransaction
+}
+
+func (r *fakeLotRepo) WriteOffExpired() ([]*models.InventoryTransaction, error) {
+	return r.expired, nil
+}
+
+// fakeAggregationRepo serves report data from memory
+type fakeAggregationRepo struct {
+	repositories.AggregationRepositoryInterface
+	orders      []*models.Order
+	menuItems   []*models.MenuItem
+	lines       []repositories.ClosedOrderLine
+	ledgerCosts map[string]float64
+}
+
+func (r *fakeAggregationRepo) GetAggregationData() ([]*models.Order, []*models.MenuItem, error) {
+	return r.orders, r.menuItems, nil
+}
+
+func (r *fakeAggregationRepo) GetClosedOrderLines(from, to *time.Time) ([]repositories.ClosedOrderLine, error) {
+	return r.lines, nil
+}
+
+func (r *fakeAggregationRepo) GetOrderConsumptionCosts(from, to *time.Time) (map[string]float64, error) {
+	return r.ledgerCosts, nil
+}
+
+func (r *fakeAggregationRepo) GetDiscountSummary(from, to *time.Time) ([]repositories.DiscountSummary, error) {
+	return nil, nil
+}
+
+func (r *fakeAggregationRepo) GetTaxSummary(from, to *time.Time) ([]repositories.TaxSummary, error) {
+	return nil, nil
+}
+
+// testLogger only logs errors, to keep test output readable
+func testLogger() *logger.Logger {
+	return logger.New(logger.Config{Level: logger.LevelError, Format: "text", Output: "stderr"})
+}
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"frappuccino/models"
+)
+
+// DefaultExpiringWithinDays is the look-ahead of the expiring-soon report
+const DefaultExpiringWithinDays = 3
+
+type ReceiveLotRequest struct {
+	Quantity  float64    `json:"quantity"`
+	UnitCost  *float64   `json:"unit_cost"`  // Defaults to the item's cost_per_unit
+	ExpiresAt *time.Time `json:"expires_at"` // Defaults to now + shelf_life_days
+	Notes     string     `json:"notes"`
+}
+
+type ExpiringLot struct {
+	models.InventoryLot
+	Value    float64 `json:"value"`     // quantity × unit_cost
+	Expired  bool    `json:"expired"`   // Past expiry but not written off yet
+	DaysLeft float64 `json:"days_left"` // Negative once expired
+}
+
+type ExpiringLotsReport struct {
+	WithinDays    int           `json:"within_days"`
+	GeneratedAt   time.Time     `json:"generated_at"`
+	ExpiredCount  int           `json:"expired_count"`
+	ExpiredValue  float64       `json:"expired_value"`
+	ExpiringCount int           `json:"expiring_count"`
+	ExpiringValue float64       `json:"expiring_value"`
+	Lots          []ExpiringLot `json:"lots"`
+}
+
+type WriteOffResult struct {
+	LotsWrittenOff int                            `json:"lots_written_off"`
+	TotalValue     float64                        `json:"total_value"`
+	Transactions   []*models.InventoryTransaction `json:"transactions"`
+}
+
+// GetLots lists the lots of an ingredient that still hold stock, oldest first
+func (s *InventoryService) GetLots(ingredientID string) ([]*models.InventoryLot, error) {
+	s.logger.Info("Fetching inventory lots", "ingredient_id", ingredientID)
+
+	if _, err := s.inventoryRepo.GetByID(ingredientID); err != nil {
+		s.logger.Warn("Inventory item not found", "id", ingredientID, "error", err)
+		return nil, err
+	}
+
+	lots, err := s.lotRepo.GetLots(ingredientID)
+	if err != nil {
+		s.logger.Error("Failed to fetch inventory lots", "ingredient_id", ingredientID, "error", err)
+		return nil, err
+	}
+	return lots, nil
+}
+
+// ReceiveLot adds stock to an ingredient as a new lot
+func (s *InventoryService) ReceiveLot(ingredientID string, req ReceiveLotRequest) (*models.InventoryLot, error) {
+	s.logger.Info("Receiving inventory lot", "ingredient_id", ingredientID, "quantity", req.Quantity)
+
+	item, err := s.inventoryRepo.GetByID(ingredientID)
+	if err != nil {
+		s.logger.Warn("Inventory item not found", "id", ingredientID, "error", err)
+		return nil, err
+	}
+
+	if req.Quantity <= 0 {
+		return nil, fmt.Errorf("quantity must be positive")
+	}
+	unitCost := item.CostPerUnit
+	if req.UnitCost != nil {
+		if *req.UnitCost < 0 {
+			return nil, fmt.Errorf("unit cost must be non-negative")
+		}
+		unitCost = *req.UnitCost
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("expiry date must be in the future")
+	}
+
+	notes := req.Notes
+	if notes == "" {
+		notes = "Lot received"
+	}
+
+	lot := &models.InventoryLot{
+		IngredientID: ingredientID,
+		Name:         item.Name,
+		Unit:         item.Unit,
+		Quantity:     roundQuantity(req.Quantity),
+		UnitCost:     roundUnitCost(unitCost),
+		ExpiresAt:    req.ExpiresAt,
+	}
+	if _, err := s.lotRepo.ReceiveLot(lot, notes); err != nil {
+		s.logger.Error("Failed to receive inventory lot", "ingredient_id", ingredientID, "error", err)
+		return nil, err
+	}
+
+	s.alertService.CheckStockLevels([]string{ingredientID})
+
+	s.logger.Info("Inventory lot received", "lot_id", lot.ID, "ingredient_id", ingredientID)
+	return lot, nil
+}
+
+// GetExpiringLots reports stock that expires within the given number of days, and stock already expired
+func (s *InventoryService) GetExpiringLots(withinDays int) (*ExpiringLotsReport, error) {
+	s.logger.Info("Building expiring lots report", "within_days", withinDays)
+
+	lots, err := s.lotRepo.GetExpiring(withinDays)
+	if err != nil {
+		s.logger.Error("Failed to fetch expiring lots", "error", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	report := &ExpiringLotsReport{
+		WithinDays:  withinDays,
+		GeneratedAt: now,
+		Lots:        make([]ExpiringLot, 0, len(lots)),
+	}
+	for _, lot := range lots {
+		entry := ExpiringLot{
+			InventoryLot: *lot,
+			Value:        roundMoney(lot.Quantity * lot.UnitCost),
+			Expired:      lot.Expired(now),
+			DaysLeft:     roundQuantity(lot.ExpiresAt.Sub(now).Hours() / 24),
+		}
+		if entry.Expired {
+			report.ExpiredCount++
+			report.ExpiredValue += entry.Value
+		} else {
+			report.ExpiringCount++
+			report.ExpiringValue += entry.Value
+		}
+		report.Lots = append(report.Lots, entry)
+	}
+	report.ExpiredValue = roundMoney(report.ExpiredValue)
+	report.ExpiringValue = roundMoney(report.ExpiringValue)
+
+	return report, nil
+}
+
+// WriteOffExpiredLots moves every expired lot out of stock as waste
+func (s *InventoryService) WriteOffExpiredLots() (*WriteOffResult, error) {
+	s.logger.Info("Writing off expired lots")
+
+	transactions, err := s.lotRepo.WriteOffExpired()
+	result := &WriteOffResult{
+		LotsWrittenOff: len(transactions),
+		Transactions:   transactions,
+	}
+	for _, transaction := range transactions {
+		result.TotalValue += -transaction.QuantityChange * transaction.UnitCost
+	}
+	result.TotalValue = roundMoney(result.TotalValue)
+
+	// Write-offs can push items below their threshold, and menu items made from them out of stock
+	if len(transactions) > 0 {
+		s.alertService.CheckStockLevels(transactionIngredientIDs(transactions))
+	}
+
+	if err != nil {
+		s.logger.Error("Failed to write off some expired lots", "written_off", len(transactions), "error", err)
+		return result, err
+	}
+
+	s.logger.Info("Expired lots written off", "lots", result.LotsWrittenOff, "value", result.TotalValue)
+	return result, nil
+}
@@ -11,18 +11,26 @@ package service
 
 import (
 	"fmt"
+	"math"
+	"reflect"
 
 	"frappuccino/internal/repositories"
 	"frappuccino/models"
 	"frappuccino/pkg/logger"
+	"frappuccino/pkg/units"
 )
 
 type UpdateInventoryItemRequest struct {
-	Name         string `json:"name"`
-	Description  string `json:"description"`
-	Quantity     int    `json:"quantity"`
-	MinThreshold int    `json:"min_threshold"`
-	Unit         string `json:"unit"`
+	Name          string  `json:"name"`
+	Description   string  `json:"description"`
+	Quantity      int     `json:"quantity"`
+	MinThreshold  int     `json:"min_threshold"`
+	Unit          string  `json:"unit"`
+	CostPerUnit   float64 `json:"cost_per_unit"`
+	ShelfLifeDays int     `json:"shelf_life_days"`
+
+	Allergens *[]string         `json:"allergens"` // Left out on update to keep them
+	Nutrition *models.Nutrition `json:"nutrition"` // Per unit, left out on update to keep it
 }
 
 type InventoryServiceInterface interface {
@@ -32,6 +40,12 @@ type InventoryServiceInterface interface {
 	GetInventoryItem(id string) (*models.InventoryItem, error)
 	DeleteInventoryItem(id string) error
 	GetLeftOvers(req GetLeftOversRequest) (*GetLeftOversResponse, error)
+	GetInventoryValuation() (*models.InventoryValuation, error)
+	GetLowStockAlerts() ([]*models.InventoryAlert, error)
+	GetLots(ingredientID string) ([]*models.InventoryLot, error)
+	ReceiveLot(ingredientID string, req ReceiveLotRequest) (*models.InventoryLot, error)
+	GetExpiringLots(withinDays int) (*ExpiringLotsReport, error)
+	WriteOffExpiredLots() (*WriteOffResult, error)
 }
 
 type GetLeftOversRequest struct {
@@ -40,10 +54,20 @@ type GetLeftOversRequest struct {
 	PageSize int    `json:"pageSize"`
 }
 
+// DefaultUsageWindowDays is how far back consumption is averaged for days-of-cover
+const DefaultUsageWindowDays = 30
+
 type LeftOverItem struct {
-	Name     string  `json:"name"`
-	Quantity float64 `json:"quantity"`
-	Price    float64 `json:"price"` // using min_threshold as price for now
+	IngredientID   string   `json:"ingredient_id"`
+	Name           string   `json:"name"`
+	Quantity       float64  `json:"quantity"`
+	Unit           string   `json:"unit"`
+	Price          float64  `json:"price"` // cost_per_unit
+	StockValue     float64  `json:"stock_value"`
+	DailyUsage     float64  `json:"daily_usage"`
+	DaysOfCover    *float64 `json:"days_of_cover"`
+	MinThreshold   float64  `json:"min_threshold"`
+	BelowThreshold bool     `json:"below_threshold"`
 }
 
 type GetLeftOversResponse struct {
@@ -65,15 +89,23 @@ func (s *InventoryService) CreateInventoryItem(req UpdateInventoryItemRequest) (
 	}
 	item := &models.InventoryItem{
 		// IngredientID will be auto-generated by the database
-		Name:         req.Name,
-		Quantity:     float64(req.Quantity),
-		MinThreshold: float64(req.MinThreshold),
-		Unit:         req.Unit,
+		Name:          req.Name,
+		Quantity:      float64(req.Quantity),
+		MinThreshold:  float64(req.MinThreshold),
+		Unit:          req.Unit,
+		CostPerUnit:   req.CostPerUnit,
+		ShelfLifeDays: req.ShelfLifeDays,
+		Allergens:     []string{},
+	}
+	if err := applyIngredientLabelling(item, req); err != nil {
+		s.logger.Warn("Create failed: invalid allergens or nutrition", "name", req.Name, "error", err)
+		return nil, err
 	}
 	if err := s.inventoryRepo.Add(item); err != nil {
 		s.logger.Error("Failed to add inventory item in repository", "name", req.Name, "error", err)
 		return nil, err
 	}
+	s.alertService.CheckStockLevels([]string{item.IngredientID})
 	s.logger.Info("Inventory item created", "id", item.IngredientID, "name", req.Name)
 	return item, nil
 }
@@ -116,17 +148,21 @@ func (s *InventoryService) DeleteInventoryItem(id string) error {
 
 type InventoryService struct {
 	inventoryRepo repositories.InventoryRepositoryInterface
+	lotRepo       repositories.LotRepositoryInterface
 	orderRepo     repositories.OrderRepositoryInterface
 	menuRepo      repositories.MenuRepositoryInterface
+	alertService  AlertServiceInterface
 	logger        *logger.Logger
 }
 
 // NewInventoryService creates a new instance of InventoryService
-func NewInventoryService(inventoryRepo repositories.InventoryRepositoryInterface, orderRepo repositories.OrderRepositoryInterface, menuRepo repositories.MenuRepositoryInterface, logger *logger.Logger) *InventoryService {
+func NewInventoryService(inventoryRepo repositories.InventoryRepositoryInterface, lotRepo repositories.LotRepositoryInterface, orderRepo repositories.OrderRepositoryInterface, menuRepo repositories.MenuRepositoryInterface, alertService AlertServiceInterface, logger *logger.Logger) *InventoryService {
 	return &InventoryService{
 		inventoryRepo: inventoryRepo,
+		lotRepo:       lotRepo,
 		orderRepo:     orderRepo,
 		menuRepo:      menuRepo,
+		alertService:  alertService,
 		logger:        logger.WithComponent("inventory_service"),
 	}
 }
@@ -153,7 +189,25 @@ func (s *InventoryService) UpdateInventoryItem(id string, req UpdateInventoryIte
 		return err
 	}
 
-	if existingItem.Name == req.Name && existingItem.Quantity == float64(req.Quantity) && existingItem.MinThreshold == float64(req.MinThreshold) && existingItem.Unit == req.Unit {
+	// Build item struct for update, keeping the allergens and nutrition unless they are given
+	item := &models.InventoryItem{
+		IngredientID:  id,
+		Name:          req.Name,
+		Quantity:      float64(req.Quantity),
+		MinThreshold:  float64(req.MinThreshold),
+		Unit:          req.Unit,
+		CostPerUnit:   req.CostPerUnit,
+		ShelfLifeDays: req.ShelfLifeDays,
+		Allergens:     existingItem.Allergens,
+		Nutrition:     existingItem.Nutrition,
+	}
+	if err := applyIngredientLabelling(item, req); err != nil {
+		s.logger.Warn("Update failed: invalid allergens or nutrition", "id", id, "error", err)
+		return err
+	}
+
+	if existingItem.Name == req.Name && existingItem.Quantity == float64(req.Quantity) && existingItem.MinThreshold == float64(req.MinThreshold) && existingItem.Unit == req.Unit && existingItem.CostPerUnit == req.CostPerUnit && existingItem.ShelfLifeDays == req.ShelfLifeDays &&
+		reflect.DeepEqual(existingItem.Allergens, item.Allergens) && existingItem.Nutrition == item.Nutrition {
 		s.logger.Warn("Update canceled: no changes detected", "id", id)
 		return fmt.Errorf("no changes detected for inventory item with ID %s", id)
 	}
@@ -163,25 +217,24 @@ func (s *InventoryService) UpdateInventoryItem(id string, req UpdateInventoryIte
 		return err
 	}
 
-	// Build item struct for update
-	item := &models.InventoryItem{
-		IngredientID: id,
-		Name:         req.Name,
-		Quantity:     float64(req.Quantity),
-		MinThreshold: float64(req.MinThreshold),
-		Unit:         req.Unit,
-	}
-
 	err = s.inventoryRepo.Update(id, item)
 	if err != nil {
 		s.logger.Error("Failed to update inventory item in repository", "id", id, "error", err)
 		return err
 	}
 
+	// A manual update can either restock the item or push it below its threshold
+	s.alertService.CheckStockLevels([]string{id})
+
 	s.logger.Info("Inventory item updated", "id", id)
 	return nil
 }
 
+// GetLowStockAlerts lists open low-stock alerts
+func (s *InventoryService) GetLowStockAlerts() ([]*models.InventoryAlert, error) {
+	return s.alertService.GetOpenAlerts()
+}
+
 // GetLeftOvers retrieves inventory leftovers with pagination and sorting
 func (s *InventoryService) GetLeftOvers(req GetLeftOversRequest) (*GetLeftOversResponse, error) {
 	s.logger.Info("Getting inventory leftovers", "sortBy", req.SortBy, "page", req.Page, "pageSize", req.PageSize)
@@ -196,7 +249,7 @@ func (s *InventoryService) GetLeftOvers(req GetLeftOversRequest) (*GetLeftOversR
 		req.SortBy = "quantity"
 	}
 
-	validSorts := []string{"price", "quantity"}
+	validSorts := []string{"name", "price", "quantity", "value", "cover"}
 	sortValid := false
 	for _, sort := range validSorts {
 		if req.SortBy == sort {
@@ -209,7 +262,7 @@ func (s *InventoryService) GetLeftOvers(req GetLeftOversRequest) (*GetLeftOversR
 		req.SortBy = "quantity"
 	}
 
-	items, totalRecords, err := s.inventoryRepo.GetLeftOvers(req.SortBy, req.Page, req.PageSize)
+	items, totalRecords, err := s.inventoryRepo.GetLeftOvers(req.SortBy, req.Page, req.PageSize, DefaultUsageWindowDays)
 	if err != nil {
 		s.logger.Error("Failed to get leftovers from repository", "error", err)
 		return nil, fmt.Errorf("failed to get inventory leftovers: %v", err)
@@ -221,9 +274,19 @@ func (s *InventoryService) GetLeftOvers(req GetLeftOversRequest) (*GetLeftOversR
 	data := make([]LeftOverItem, len(items))
 	for i, item := range items {
 		data[i] = LeftOverItem{
-			Name:     item.Name,
-			Quantity: item.Quantity,
-			Price:    item.MinThreshold,
+			IngredientID:   item.IngredientID,
+			Name:           item.Name,
+			Quantity:       item.Quantity,
+			Unit:           item.Unit,
+			Price:          item.CostPerUnit,
+			StockValue:     roundMoney(item.StockValue),
+			DailyUsage:     roundQuantity(item.DailyUsage),
+			MinThreshold:   item.MinThreshold,
+			BelowThreshold: item.Quantity < item.MinThreshold,
+		}
+		if item.DaysOfCover != nil {
+			cover := math.Round(*item.DaysOfCover*10) / 10
+			data[i].DaysOfCover = &cover
 		}
 	}
 
@@ -239,6 +302,23 @@ func (s *InventoryService) GetLeftOvers(req GetLeftOversRequest) (*GetLeftOversR
 	return response, nil
 }
 
+// GetInventoryValuation totals the value of stock on hand at current cost
+func (s *InventoryService) GetInventoryValuation() (*models.InventoryValuation, error) {
+	s.logger.Info("Calculating inventory valuation")
+
+	valuation, err := s.inventoryRepo.GetValuation()
+	if err != nil {
+		s.logger.Error("Failed to get inventory valuation from repository", "error", err)
+		return nil, fmt.Errorf("failed to get inventory valuation: %v", err)
+	}
+
+	valuation.TotalValue = roundMoney(valuation.TotalValue)
+	valuation.BelowThresholdValue = roundMoney(valuation.BelowThresholdValue)
+
+	s.logger.Info("Calculated inventory valuation", "total_value", valuation.TotalValue, "unpriced", valuation.UnpricedCount)
+	return valuation, nil
+}
+
 // Private business logic methods
 
 // validateCreateInventoryItemData validates data for creation
@@ -255,6 +335,33 @@ func validateCreateInventoryItemData(req UpdateInventoryItemRequest) error {
 	if req.Unit == "" {
 		return fmt.Errorf("unit is required")
 	}
+	if !units.IsValid(req.Unit) {
+		return fmt.Errorf("invalid unit: %s", req.Unit)
+	}
+	if req.CostPerUnit < 0 {
+		return fmt.Errorf("cost per unit must be non-negative")
+	}
+	if req.ShelfLifeDays < 0 {
+		return fmt.Errorf("shelf life must be non-negative")
+	}
+	return nil
+}
+
+// applyIngredientLabelling sets the allergens and per-unit nutrition given in a request on an inventory item
+func applyIngredientLabelling(item *models.InventoryItem, req UpdateInventoryItemRequest) error {
+	if req.Allergens != nil {
+		allergens, err := normalizeAllergens(*req.Allergens)
+		if err != nil {
+			return err
+		}
+		item.Allergens = allergens
+	}
+	if n := req.Nutrition; n != nil {
+		if err := validateNutrition(&n.Kcal, &n.SugarG, &n.FatG, &n.CaffeineMg); err != nil {
+			return err
+		}
+		item.Nutrition = *n
+	}
 	return nil
 }
 
@@ -272,6 +379,15 @@ func validateUpdateInventoryItemData(req UpdateInventoryItemRequest) error {
 	if req.Unit == "" {
 		return fmt.Errorf("unit is required")
 	}
+	if !units.IsValid(req.Unit) {
+		return fmt.Errorf("invalid unit: %s", req.Unit)
+	}
+	if req.CostPerUnit < 0 {
+		return fmt.Errorf("cost per unit must be non-negative")
+	}
+	if req.ShelfLifeDays < 0 {
+		return fmt.Errorf("shelf life must be non-negative")
+	}
 	return nil
 }
 
@@ -320,6 +436,14 @@ func (s *InventoryService) checkIngredientUsageInMenu(ingredientID string) error
 					ingredientID, menuItem.ID, menuItem.Name)
 			}
 		}
+		for _, modifier := range menuItem.Modifiers {
+			for _, ingredient := range modifier.Ingredients {
+				if ingredient.IngredientID == ingredientID {
+					return fmt.Errorf("ingredient '%s' is used in modifier '%s' of menu item '%s' (%s)",
+						ingredientID, modifier.Name, menuItem.ID, menuItem.Name)
+				}
+			}
+		}
 	}
 	return nil
 }
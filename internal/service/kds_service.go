@@ -0,0 +1,121 @@
+package service
+
+import (
+	"sort"
+	"time"
+
+	"frappuccino/internal/repositories"
+	"frappuccino/models"
+	"frappuccino/pkg/events"
+	"frappuccino/pkg/logger"
+)
+
+type KDSServiceInterface interface {
+	GetQueue(station models.Station) (*models.KDSQueue, error)
+	Subscribe() (<-chan events.Event, func())
+}
+
+// KDSService feeds the kitchen display: the queue of orders to make and the live order events
+type KDSService struct {
+	orderRepo repositories.OrderRepositoryInterface
+	menuRepo  repositories.MenuRepositoryInterface
+	events    *events.Bus
+	prepTimes *prepTimeCache
+	logger    *logger.Logger
+}
+
+func NewKDSService(orderRepo repositories.OrderRepositoryInterface, menuRepo repositories.MenuRepositoryInterface, eventBus *events.Bus, log *logger.Logger) *KDSService {
+	return &KDSService{
+		orderRepo: orderRepo,
+		menuRepo:  menuRepo,
+		events:    eventBus,
+		prepTimes: newPrepTimeCache(orderRepo),
+		logger:    log.WithComponent("kds_service"),
+	}
+}
+
+// GetQueue returns the pending and preparing orders, oldest first. Given a station, only the orders with
+// a ticket still open there are returned, showing just that ticket.
+func (s *KDSService) GetQueue(station models.Station) (*models.KDSQueue, error) {
+	s.logger.Info("Fetching kitchen queue", "station", station)
+
+	if station != "" {
+		if err := validateStation(station); err != nil {
+			return nil, err
+		}
+	}
+
+	orders, err := s.orderRepo.GetByStatus(models.OrderPending, models.OrderPreparing)
+	if err != nil {
+		s.logger.Error("Failed to fetch queued orders", "error", err)
+		return nil, err
+	}
+
+	menu, err := s.menuRepo.GetAll()
+	if err != nil {
+		s.logger.Error("Failed to fetch menu for kitchen queue", "error", err)
+		return nil, err
+	}
+	names := make(map[string]string, len(menu))
+	for _, item := range menu {
+		names[item.ID] = item.Name
+	}
+
+	sort.SliceStable(orders, func(i, j int) bool { return orders[i].CreatedAt.Before(orders[j].CreatedAt) })
+
+	now := time.Now()
+	prep, err := s.prepTimes.get(now)
+	if err != nil {
+		s.logger.Warn("Failed to load prep times, using previous or default times", "error", err)
+	}
+	estimates := estimateReadyTimes(orders, prep, now)
+
+	queue := &models.KDSQueue{Orders: make([]models.KDSOrder, 0, len(orders)), GeneratedAt: now}
+	for _, order := range orders {
+		kds := kdsOrder(order, names, now)
+		if estimate, ok := estimates[order.ID]; ok {
+			kds.EstimatedReadyAt = &estimate
+		}
+		if station != "" {
+			kds.Tickets = openTickets(kds.Tickets, station)
+			if len(kds.Tickets) == 0 {
+				continue
+			}
+		}
+		queue.Orders = append(queue.Orders, kds)
+	}
+	return queue, nil
+}
+
+// Subscribe follows the order events as they are published
+func (s *KDSService) Subscribe() (<-chan events.Event, func()) {
+	return s.events.Subscribe()
+}
+
+// openTickets keeps a station's ticket if it isn't done yet
+func openTickets(tickets []models.StationTicket, station models.Station) []models.StationTicket {
+	for _, ticket := range tickets {
+		if ticket.Station == station && ticket.Status != models.PrepDone {
+			return []models.StationTicket{ticket}
+		}
+	}
+	return nil
+}
+
+// kdsOrder turns an order into what the kitchen display shows, naming its items from names by menu item ID
+func kdsOrder(order *models.Order, names map[string]string, now time.Time) models.KDSOrder {
+	kds := models.KDSOrder{
+		OrderID:             order.ID,
+		CustomerName:        order.CustomerName,
+		OrderType:           order.OrderType,
+		Status:              order.Status,
+		Tickets:             stationTickets(order.Items, names),
+		SpecialInstructions: order.SpecialInstructions,
+		CreatedAt:           order.CreatedAt,
+		AgeSeconds:          int(now.Sub(order.CreatedAt).Seconds()),
+	}
+	if kds.AgeSeconds < 0 {
+		kds.AgeSeconds = 0
+	}
+	return kds
+}
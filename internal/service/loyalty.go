@@ -0,0 +1,297 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"frappuccino/models"
+)
+
+// applyLoyaltyRedemptions spends a customer's loyalty balances as discounts on an order's lines, after
+// the pricing rules. A points redemption takes points × point_value off the qualifying lines, spending only
+// the points needed when that is more than they have left; a stamp reward frees the most expensive
+// qualifying item, up to the rule's max_reward_value. The balance is checked here and spent atomically
+// when the order is stored.
+func (s *OrderService) applyLoyaltyRedemptions(customerID string, lines []models.OrderItem, redemptions []models.LoyaltyRedemption) ([]models.OrderDiscount, float64, error) {
+	if len(redemptions) == 0 {
+		return nil, 0, nil
+	}
+	if customerID == "" {
+		return nil, 0, fmt.Errorf("loyalty redemption requires a customer")
+	}
+
+	menu, err := s.lineMenu(lines)
+	if err != nil {
+		return nil, 0, err
+	}
+	remaining := remainingLineValues(lines)
+
+	var discounts []models.OrderDiscount
+	var total float64
+	seen := make(map[string]bool, len(redemptions))
+	for _, redemption := range redemptions {
+		if seen[redemption.RuleID] {
+			return nil, 0, fmt.Errorf("loyalty rule %s is redeemed more than once", redemption.RuleID)
+		}
+		seen[redemption.RuleID] = true
+
+		rule, err := s.loyaltyRepo.GetByID(redemption.RuleID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if !rule.Active {
+			return nil, 0, fmt.Errorf("loyalty rule '%s' is not active", rule.Name)
+		}
+
+		switch rule.Type {
+		case models.LoyaltyPoints:
+			if redemption.Points <= 0 {
+				return nil, 0, fmt.Errorf("points to redeem under '%s' must be positive", rule.Name)
+			}
+		case models.LoyaltyStamps:
+			if redemption.Rewards < 0 {
+				return nil, 0, fmt.Errorf("rewards to redeem under '%s' cannot be negative", rule.Name)
+			}
+			if redemption.Rewards == 0 {
+				redemption.Rewards = 1
+			}
+		}
+
+		amounts, spent, complete := loyaltyDiscounts(rule, redemption, lines, remaining, menu)
+		if !complete {
+			if rule.Type == models.LoyaltyStamps {
+				return nil, 0, fmt.Errorf("order has fewer qualifying items than the %d rewards redeemed under '%s'", redemption.Rewards, rule.Name)
+			}
+			return nil, 0, fmt.Errorf("loyalty rule '%s' does not apply to this order", rule.Name)
+		}
+
+		balance, err := s.loyaltyRepo.GetBalance(customerID, rule.ID)
+		if err != nil {
+			return nil, 0, err
+		}
+		if balance < spent {
+			return nil, 0, fmt.Errorf("insufficient loyalty balance for '%s': %d available, %d needed", rule.Name, balance, spent)
+		}
+
+		amount := applyLoyaltyAmounts(lines, remaining, amounts)
+		discounts = append(discounts, models.OrderDiscount{
+			Name:          rule.Name,
+			Amount:        amount,
+			LoyaltyRuleID: rule.ID,
+			LoyaltyPoints: spent,
+		})
+		total += amount
+	}
+
+	return discounts, roundMoney(total), nil
+}
+
+// reapplyLoyaltyRedemptions prices an order's new lines with the loyalty it already redeemed. The points
+// stay spent even if the new lines are worth less, and are given back if the order is cancelled.
+func (s *OrderService) reapplyLoyaltyRedemptions(order *models.Order, lines []models.OrderItem) ([]models.OrderDiscount, float64, error) {
+	var redeemed []models.OrderDiscount
+	for _, discount := range order.Discounts {
+		if discount.LoyaltyRuleID != "" {
+			redeemed = append(redeemed, discount)
+		}
+	}
+	if len(redeemed) == 0 {
+		return nil, 0, nil
+	}
+
+	menu, err := s.lineMenu(lines)
+	if err != nil {
+		return nil, 0, err
+	}
+	remaining := remainingLineValues(lines)
+
+	var discounts []models.OrderDiscount
+	var total float64
+	for _, discount := range redeemed {
+		rule, err := s.loyaltyRepo.GetByID(discount.LoyaltyRuleID)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		redemption := models.LoyaltyRedemption{RuleID: rule.ID, Points: discount.LoyaltyPoints}
+		if rule.Type == models.LoyaltyStamps {
+			redemption.Rewards = discount.LoyaltyPoints / rule.StampsRequired
+			if redemption.Rewards < 1 {
+				redemption.Rewards = 1
+			}
+		}
+		amounts, _, _ := loyaltyDiscounts(rule, redemption, lines, remaining, menu)
+
+		discount.ID = ""
+		discount.Amount = applyLoyaltyAmounts(lines, remaining, amounts)
+		discounts = append(discounts, discount)
+		total += discount.Amount
+	}
+
+	return discounts, roundMoney(total), nil
+}
+
+// loyaltyDiscounts is what a redemption takes off each line, unrounded, and the points or stamps it spends.
+// complete reports whether the order had enough to redeem against.
+func loyaltyDiscounts(rule *models.LoyaltyRule, redemption models.LoyaltyRedemption, lines []models.OrderItem, remaining []float64, menu map[string]*models.MenuItem) ([]float64, int, bool) {
+	targets := loyaltyTargets(rule, lines, menu)
+	amounts := make([]float64, len(lines))
+
+	switch rule.Type {
+	case models.LoyaltyPoints:
+		var base float64
+		for i := range lines {
+			if targets[i] {
+				base += remaining[i]
+			}
+		}
+		if base <= 0 {
+			return amounts, redemption.Points, false
+		}
+
+		spent := redemption.Points
+		off := float64(spent) * rule.PointValue
+		if off > base {
+			off = base
+			spent = int(math.Ceil(base/rule.PointValue - 1e-9))
+		}
+		for i := range lines {
+			if targets[i] {
+				amounts[i] = off * remaining[i] / base
+			}
+		}
+		return amounts, spent, true
+
+	case models.LoyaltyStamps:
+		type unit struct {
+			line  int
+			price float64
+		}
+		var units []unit
+		for i, line := range lines {
+			if !targets[i] || line.Quantity <= 0 || remaining[i] <= 0 {
+				continue
+			}
+			price := remaining[i] / float64(line.Quantity)
+			for n := 0; n < line.Quantity; n++ {
+				units = append(units, unit{line: i, price: price})
+			}
+		}
+		sort.SliceStable(units, func(a, b int) bool { return units[a].price > units[b].price })
+
+		free := redemption.Rewards
+		if free > len(units) {
+			free = len(units)
+		}
+		for _, u := range units[:free] {
+			price := u.price
+			if rule.MaxRewardValue != nil {
+				price = math.Min(price, *rule.MaxRewardValue)
+			}
+			amounts[u.line] += price
+		}
+		return amounts, redemption.Rewards * rule.StampsRequired, free == redemption.Rewards
+	}
+
+	return amounts, 0, false
+}
+
+// applyLoyaltyAmounts rounds a redemption's discounts and adds each line's share to it, returning the total
+func applyLoyaltyAmounts(lines []models.OrderItem, remaining, amounts []float64) float64 {
+	allocated := allocateMoney(roundMoney(sum(amounts)), amounts)
+
+	var amount float64
+	for i, share := range allocated {
+		lines[i].DiscountAmount = roundMoney(lines[i].DiscountAmount + share)
+		remaining[i] = roundMoney(remaining[i] - share)
+		amount += share
+	}
+	return roundMoney(amount)
+}
+
+// loyaltyTargets reports which lines count towards a rule. Rules for a category leave bundle components
+// alone, as pricing rules do; rules for everything count every line.
+func loyaltyTargets(rule *models.LoyaltyRule, lines []models.OrderItem, menu map[string]*models.MenuItem) []bool {
+	targets := make([]bool, len(lines))
+	for i, line := range lines {
+		if rule.Category == "" {
+			targets[i] = true
+			continue
+		}
+		targets[i] = line.BundleID == "" && menu[line.ProductID].Category == rule.Category
+	}
+	return targets
+}
+
+// accrueLoyalty credits a closed order's customer under every active rule: points for what they paid
+// for qualifying items after discounts, or a stamp per qualifying item not given free with stamps.
+// Accrual is idempotent, so closing an order twice doesn't earn twice.
+func (s *OrderService) accrueLoyalty(order *models.Order) error {
+	if order.CustomerID == "" {
+		return nil
+	}
+
+	rules, err := s.loyaltyRepo.GetActive()
+	if err != nil {
+		return err
+	}
+	if len(rules) == 0 {
+		return nil
+	}
+
+	menu, err := s.lineMenu(order.Items)
+	if err != nil {
+		return err
+	}
+	remaining := remainingLineValues(order.Items)
+
+	var entries []*models.LoyaltyEntry
+	for _, rule := range rules {
+		targets := loyaltyTargets(rule, order.Items, menu)
+
+		var earned int
+		switch rule.Type {
+		case models.LoyaltyPoints:
+			var spent float64
+			for i := range order.Items {
+				if targets[i] {
+					spent += remaining[i]
+				}
+			}
+			earned = int(math.Floor(spent*rule.PointsPerUnit + 1e-9))
+		case models.LoyaltyStamps:
+			for i, line := range order.Items {
+				if targets[i] {
+					earned += line.Quantity
+				}
+			}
+			for _, discount := range order.Discounts {
+				if discount.LoyaltyRuleID == rule.ID {
+					earned -= discount.LoyaltyPoints / rule.StampsRequired
+				}
+			}
+		}
+
+		if earned > 0 {
+			entries = append(entries, &models.LoyaltyEntry{
+				CustomerID: order.CustomerID,
+				RuleID:     rule.ID,
+				OrderID:    order.ID,
+				Points:     earned,
+				Reason:     "order closed",
+			})
+		}
+	}
+
+	return s.loyaltyRepo.Accrue(entries)
+}
+
+func hasLoyaltyRedemptions(order *models.Order) bool {
+	for _, discount := range order.Discounts {
+		if discount.LoyaltyRuleID != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"frappuccino/internal/repositories"
+	"frappuccino/models"
+	"frappuccino/pkg/logger"
+)
+
+type LoyaltyRuleRequest struct {
+	Name           string              `json:"name"`
+	Type           string              `json:"type"`
+	Category       models.MenuCategory `json:"category"`
+	PointsPerUnit  float64             `json:"points_per_unit"`
+	PointValue     float64             `json:"point_value"`
+	StampsRequired int                 `json:"stamps_required"`
+	MaxRewardValue *float64            `json:"max_reward_value"`
+	Active         *bool               `json:"active"` // Defaults to true
+}
+
+type LoyaltyServiceInterface interface {
+	GetLoyaltyRules() ([]*models.LoyaltyRule, error)
+	GetLoyaltyRule(id string) (*models.LoyaltyRule, error)
+	CreateLoyaltyRule(req LoyaltyRuleRequest) (*models.LoyaltyRule, error)
+	UpdateLoyaltyRule(id string, req LoyaltyRuleRequest) (*models.LoyaltyRule, error)
+	DeleteLoyaltyRule(id string) error
+	GetCustomerLoyalty(customerID string) (*models.CustomerLoyalty, error)
+	GetLiabilityReport() (*models.LoyaltyLiabilityReport, error)
+}
+
+type LoyaltyService struct {
+	loyaltyRepo  repositories.LoyaltyRepositoryInterface
+	customerRepo repositories.CustomerRepositoryInterface
+	logger       *logger.Logger
+}
+
+func NewLoyaltyService(loyaltyRepo repositories.LoyaltyRepositoryInterface, customerRepo repositories.CustomerRepositoryInterface, log *logger.Logger) *LoyaltyService {
+	return &LoyaltyService{
+		loyaltyRepo:  loyaltyRepo,
+		customerRepo: customerRepo,
+		logger:       log.WithComponent("loyalty_service"),
+	}
+}
+
+// GetLoyaltyRules returns every loyalty rule
+func (s *LoyaltyService) GetLoyaltyRules() ([]*models.LoyaltyRule, error) {
+	s.logger.Info("Fetching loyalty rules")
+
+	rules, err := s.loyaltyRepo.GetAll()
+	if err != nil {
+		s.logger.Error("Failed to fetch loyalty rules", "error", err)
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetLoyaltyRule returns a single loyalty rule
+func (s *LoyaltyService) GetLoyaltyRule(id string) (*models.LoyaltyRule, error) {
+	s.logger.Info("Fetching loyalty rule", "rule_id", id)
+
+	rule, err := s.loyaltyRepo.GetByID(id)
+	if err != nil {
+		s.logger.Warn("Loyalty rule not found", "rule_id", id, "error", err)
+		return nil, err
+	}
+	return rule, nil
+}
+
+// CreateLoyaltyRule validates and stores a new loyalty rule
+func (s *LoyaltyService) CreateLoyaltyRule(req LoyaltyRuleRequest) (*models.LoyaltyRule, error) {
+	s.logger.Info("Creating loyalty rule", "name", req.Name, "type", req.Type)
+
+	rule, err := loyaltyRuleFromRequest(req)
+	if err != nil {
+		s.logger.Warn("Create failed: invalid loyalty rule", "name", req.Name, "error", err)
+		return nil, err
+	}
+
+	if err := s.loyaltyRepo.Create(rule); err != nil {
+		s.logger.Error("Failed to create loyalty rule", "name", req.Name, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Loyalty rule created", "rule_id", rule.ID, "name", rule.Name)
+	return rule, nil
+}
+
+// UpdateLoyaltyRule replaces a rule's settings. Its type is fixed, since balances already held are counted in it.
+func (s *LoyaltyService) UpdateLoyaltyRule(id string, req LoyaltyRuleRequest) (*models.LoyaltyRule, error) {
+	s.logger.Info("Updating loyalty rule", "rule_id", id)
+
+	existing, err := s.loyaltyRepo.GetByID(id)
+	if err != nil {
+		s.logger.Warn("Loyalty rule not found for update", "rule_id", id, "error", err)
+		return nil, err
+	}
+	if req.Type == "" {
+		req.Type = existing.Type
+	}
+	if req.Type != existing.Type {
+		return nil, fmt.Errorf("type of loyalty rule '%s' cannot be changed from %s", existing.Name, existing.Type)
+	}
+
+	rule, err := loyaltyRuleFromRequest(req)
+	if err != nil {
+		s.logger.Warn("Update failed: invalid loyalty rule", "rule_id", id, "error", err)
+		return nil, err
+	}
+	rule.ID = id
+
+	if err := s.loyaltyRepo.Update(rule); err != nil {
+		s.logger.Warn("Failed to update loyalty rule", "rule_id", id, "error", err)
+		return nil, err
+	}
+	return rule, nil
+}
+
+// DeleteLoyaltyRule removes a loyalty rule that has no ledger entries
+func (s *LoyaltyService) DeleteLoyaltyRule(id string) error {
+	s.logger.Info("Deleting loyalty rule", "rule_id", id)
+
+	if err := s.loyaltyRepo.Delete(id); err != nil {
+		s.logger.Warn("Failed to delete loyalty rule", "rule_id", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+// GetCustomerLoyalty returns a customer's loyalty balances and ledger
+func (s *LoyaltyService) GetCustomerLoyalty(customerID string) (*models.CustomerLoyalty, error) {
+	s.logger.Info("Fetching customer loyalty", "customer_id", customerID)
+
+	if _, err := s.customerRepo.GetByID(customerID); err != nil {
+		s.logger.Warn("Customer not found", "customer_id", customerID, "error", err)
+		return nil, err
+	}
+
+	loyalty, err := s.loyaltyRepo.GetCustomerLoyalty(customerID)
+	if err != nil {
+		s.logger.Error("Failed to fetch customer loyalty", "customer_id", customerID, "error", err)
+		return nil, err
+	}
+	return loyalty, nil
+}
+
+// GetLiabilityReport returns what the loyalty balances customers hold are worth
+func (s *LoyaltyService) GetLiabilityReport() (*models.LoyaltyLiabilityReport, error) {
+	s.logger.Info("Generating loyalty liability report")
+
+	report, err := s.loyaltyRepo.GetLiability()
+	if err != nil {
+		s.logger.Error("Failed to generate loyalty liability report", "error", err)
+		return nil, err
+	}
+	return report, nil
+}
+
+func loyaltyRuleFromRequest(req LoyaltyRuleRequest) (*models.LoyaltyRule, error) {
+	rule := &models.LoyaltyRule{
+		Name:           strings.TrimSpace(req.Name),
+		Type:           req.Type,
+		Category:       req.Category,
+		PointsPerUnit:  req.PointsPerUnit,
+		PointValue:     req.PointValue,
+		StampsRequired: req.StampsRequired,
+		MaxRewardValue: req.MaxRewardValue,
+		Active:         req.Active == nil || *req.Active,
+	}
+
+	if rule.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	switch rule.Type {
+	case models.LoyaltyPoints:
+		if rule.PointsPerUnit <= 0 {
+			return nil, fmt.Errorf("points_per_unit must be positive")
+		}
+		if rule.PointValue <= 0 {
+			return nil, fmt.Errorf("point_value must be positive")
+		}
+		if rule.StampsRequired != 0 || rule.MaxRewardValue != nil {
+			return nil, fmt.Errorf("stamps_required and max_reward_value are only allowed for %s rules", models.LoyaltyStamps)
+		}
+	case models.LoyaltyStamps:
+		if rule.StampsRequired < 2 {
+			return nil, fmt.Errorf("stamps_required must be at least 2")
+		}
+		if rule.MaxRewardValue != nil && *rule.MaxRewardValue <= 0 {
+			return nil, fmt.Errorf("max_reward_value must be positive")
+		}
+		if rule.PointsPerUnit != 0 || rule.PointValue != 0 {
+			return nil, fmt.Errorf("points_per_unit and point_value are only allowed for %s rules", models.LoyaltyPoints)
+		}
+	default:
+		return nil, fmt.Errorf("invalid rule type '%s': must be %s or %s", rule.Type, models.LoyaltyPoints, models.LoyaltyStamps)
+	}
+
+	if rule.Category != "" {
+		if err := validateMenuCategory(rule.Category); err != nil {
+			return nil, err
+		}
+	}
+	return rule, nil
+}
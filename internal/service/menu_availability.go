@@ -0,0 +1,122 @@
+package service
+
+import (
+	"math"
+
+	"frappuccino/models"
+)
+
+// menuItemMaxServings is how many servings of item the available-to-promise stock allows.
+// It is nil when the item has no recipe, so stock doesn't limit it.
+func menuItemMaxServings(item *models.MenuItem, inventory map[string]*models.InventoryItem) (*int, error) {
+	if len(item.Ingredients) == 0 {
+		return nil, nil
+	}
+
+	servings := math.MaxInt32
+	for _, ingredient := range item.Ingredients {
+		inventoryItem, ok := inventory[ingredient.IngredientID]
+		if !ok {
+			servings = 0
+			break
+		}
+
+		perServing, err := recipeQuantity(ingredient, inventoryItem)
+		if err != nil {
+			return nil, err
+		}
+		if perServing <= 0 {
+			continue
+		}
+
+		// Absorb DECIMAL(10,3) rounding so exactly enough stock still counts as a serving
+		possible := int(math.Floor((inventoryItem.Available + 0.0005) / perServing))
+		if possible < servings {
+			servings = possible
+		}
+	}
+
+	if servings < 0 {
+		servings = 0
+	}
+	return &servings, nil
+}
+
+// setMaxServings fills in MaxServings on every item; items whose recipe can't be evaluated are left without it.
+// Bundles follow their components, so items must be the whole menu.
+func setMaxServings(items []*models.MenuItem, inventory map[string]*models.InventoryItem) []error {
+	var errs []error
+	for _, item := range items {
+		servings, err := menuItemMaxServings(item, inventory)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		item.MaxServings = servings
+	}
+	for _, item := range items {
+		if item.Bundle != nil {
+			item.MaxServings = bundleMaxServings(item, items)
+		}
+	}
+	return errs
+}
+
+// MenuAvailabilitySyncer switches menu items on and off as the stock of their ingredients moves
+type MenuAvailabilitySyncer interface {
+	SyncMenuAvailability(ingredientIDs []string)
+}
+
+// SyncMenuAvailability flips menu items using the given ingredients off when they can no longer be made
+// and back on when they can again. Only those items and their ingredients are loaded. Failures are logged,
+// like the stock level checks that call it.
+func (s *MenuService) SyncMenuAvailability(ingredientIDs []string) {
+	if len(ingredientIDs) == 0 {
+		return
+	}
+
+	menuItems, err := s.menuRepo.GetStockDependents(ingredientIDs)
+	if err != nil {
+		s.logger.Error("Failed to load menu items for availability check", "error", err)
+		return
+	}
+
+	inventory := make(map[string]*models.InventoryItem)
+	for _, item := range menuItems {
+		for _, ingredient := range item.Ingredients {
+			if _, loaded := inventory[ingredient.IngredientID]; loaded {
+				continue
+			}
+			inventoryItem, err := s.inventoryRepo.GetByID(ingredient.IngredientID)
+			if err != nil {
+				s.logger.Warn("Failed to load inventory item for availability check", "ingredient_id", ingredient.IngredientID, "error", err)
+				continue
+			}
+			inventory[ingredient.IngredientID] = inventoryItem
+		}
+	}
+
+	for _, item := range menuItems {
+		servings, err := menuItemMaxServings(item, inventory)
+		if err != nil || servings == nil {
+			if err != nil {
+				s.logger.Warn("Failed to evaluate menu item stock", "menu_item_id", item.ID, "error", err)
+			}
+			continue
+		}
+
+		inStock := *servings > 0
+		if (inStock && !item.OutOfStock) || (!inStock && !item.Available) {
+			continue
+		}
+
+		flipped, err := s.menuRepo.SetStockAvailability(item.ID, inStock)
+		if err != nil {
+			s.logger.Error("Failed to update menu item availability", "menu_item_id", item.ID, "error", err)
+			continue
+		}
+		if flipped {
+			s.logger.Info("Menu item availability follows stock", "menu_item_id", item.ID, "name", item.Name, "available", inStock)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"testing"
+
+	"frappuccino/models"
+)
+
+// availabilityFixture is a latte made from milk and espresso and a muffin made from flour
+func availabilityFixture(milk float64, latteAvailable, latteOutOfStock bool) (*fakeMenuRepo, *fakeInventoryRepo) {
+	menu := &fakeMenuRepo{items: map[string]*models.MenuItem{
+		"latte": {ID: "latte", Name: "Latte", Available: latteAvailable, OutOfStock: latteOutOfStock, Ingredients: []models.MenuItemIngredient{
+			{IngredientID: "milk", Quantity: 200, Unit: "ml"},
+			{IngredientID: "espresso", Quantity: 18, Unit: "grams"},
+		}},
+		"muffin": {ID: "muffin", Name: "Muffin", Available: true, Ingredients: []models.MenuItemIngredient{
+			{IngredientID: "flour", Quantity: 80, Unit: "grams"},
+		}},
+	}}
+	inventory := &fakeInventoryRepo{items: map[string]*models.InventoryItem{
+		"milk":     {IngredientID: "milk", Name: "Milk", Unit: "ml", Quantity: milk, Available: milk},
+		"espresso": {IngredientID: "espresso", Name: "Espresso", Unit: "grams", Quantity: 1000, Available: 1000},
+		"flour":    {IngredientID: "flour", Name: "Flour", Unit: "grams", Quantity: 0, Available: 0},
+	}}
+	return menu, inventory
+}
+
+func TestSyncMenuAvailability(t *testing.T) {
+	tests := []struct {
+		name           string
+		milk           float64
+		available      bool
+		outOfStock     bool
+		wantAvailable  bool
+		wantOutOfStock bool
+	}{
+		{name: "runs out", milk: 150, available: true, wantAvailable: false, wantOutOfStock: true},
+		{name: "restocked", milk: 1000, available: false, outOfStock: true, wantAvailable: true, wantOutOfStock: false},
+		{name: "still in stock", milk: 1000, available: true, wantAvailable: true, wantOutOfStock: false},
+		{name: "switched off by hand stays off", milk: 1000, available: false, wantAvailable: false, wantOutOfStock: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			menu, inventory := availabilityFixture(tt.milk, tt.available, tt.outOfStock)
+			s := &MenuService{menuRepo: menu, inventoryRepo: inventory, logger: testLogger()}
+
+			s.SyncMenuAvailability([]string{"milk"})
+
+			latte := menu.items["latte"]
+			if latte.Available != tt.wantAvailable || latte.OutOfStock != tt.wantOutOfStock {
+				t.Errorf("latte available=%v out_of_stock=%v, want %v %v", latte.Available, latte.OutOfStock, tt.wantAvailable, tt.wantOutOfStock)
+			}
+			// The muffin doesn't use milk, so it is neither loaded nor switched off despite having no flour
+			if !menu.items["muffin"].Available {
+				t.Error("muffin was switched off")
+			}
+			if len(menu.loaded) != 1 || menu.loaded[0] != "latte" {
+				t.Errorf("loaded %v, want only [latte]", menu.loaded)
+			}
+		})
+	}
+}
+
+func TestWriteOffExpiredLotsSyncsMenu(t *testing.T) {
+	menu, inventory := availabilityFixture(150, true, false)
+	menuService := &MenuService{menuRepo: menu, inventoryRepo: inventory, logger: testLogger()}
+	alertService := NewAlertService(&fakeAlertRepo{}, inventory, menuService, nil, testLogger())
+	lots := &fakeLotRepo{expired: []*models.InventoryTransaction{
+		{IngredientID: "milk", QuantityChange: -500, UnitCost: 0.002},
+	}}
+	s := NewInventoryService(inventory, lots, nil, menu, alertService, testLogger())
+
+	result, err := s.WriteOffExpiredLots()
+	if err != nil {
+		t.Fatalf("WriteOffExpiredLots() error = %v", err)
+	}
+	if result.LotsWrittenOff != 1 {
+		t.Errorf("LotsWrittenOff = %d, want 1", result.LotsWrittenOff)
+	}
+
+	if latte := menu.items["latte"]; latte.Available || !latte.OutOfStock {
+		t.Errorf("latte available=%v out_of_stock=%v, want switched off for stock", latte.Available, latte.OutOfStock)
+	}
+	if len(menu.loaded) != 1 || menu.loaded[0] != "latte" {
+		t.Errorf("loaded %v, want only [latte]", menu.loaded)
+	}
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"frappuccino/models"
+)
+
+// normalizeBundle validates a bundle's slots against the menu and fills in defaults.
+// It returns nil for an empty bundle, which makes the item a regular one.
+func (s *MenuService) normalizeBundle(bundleID string, bundle *models.MenuBundle) (*models.MenuBundle, error) {
+	if bundle == nil || len(bundle.Slots) == 0 {
+		return nil, nil
+	}
+
+	normalized := &models.MenuBundle{Slots: make([]models.BundleSlot, len(bundle.Slots))}
+	names := make(map[string]bool, len(bundle.Slots))
+
+	for i, slot := range bundle.Slots {
+		slot.Name = strings.TrimSpace(slot.Name)
+		if slot.Name == "" {
+			return nil, fmt.Errorf("bundle slot %d: name is required", i+1)
+		}
+		if names[slot.Name] {
+			return nil, fmt.Errorf("bundle slot %d: duplicate name '%s'", i+1, slot.Name)
+		}
+		names[slot.Name] = true
+
+		if slot.Quantity == 0 {
+			slot.Quantity = 1
+		} else if slot.Quantity < 0 {
+			return nil, fmt.Errorf("bundle slot '%s': quantity must be positive", slot.Name)
+		}
+
+		if slot.Category != "" {
+			if err := validateMenuCategory(slot.Category); err != nil {
+				return nil, fmt.Errorf("bundle slot '%s': %v", slot.Name, err)
+			}
+		} else if len(slot.Options) == 0 {
+			return nil, fmt.Errorf("bundle slot '%s': options or a category are required", slot.Name)
+		}
+
+		options := make([]string, 0, len(slot.Options))
+		seen := make(map[string]bool, len(slot.Options))
+		for _, option := range slot.Options {
+			if seen[option] {
+				continue
+			}
+			seen[option] = true
+
+			if option == bundleID {
+				return nil, fmt.Errorf("bundle slot '%s': a bundle cannot contain itself", slot.Name)
+			}
+			item, err := s.menuRepo.GetByID(option)
+			if err != nil {
+				return nil, fmt.Errorf("bundle slot '%s': menu item %s not found", slot.Name, option)
+			}
+			if item.Bundle != nil {
+				return nil, fmt.Errorf("bundle slot '%s': bundles cannot be nested ('%s' is a bundle)", slot.Name, item.Name)
+			}
+			options = append(options, option)
+		}
+		slot.Options = options
+
+		normalized.Slots[i] = slot
+	}
+
+	return normalized, nil
+}
+
+// checkMenuItemUsageInBundles rejects deleting an item that a bundle offers
+func (s *MenuService) checkMenuItemUsageInBundles(menuItemID string) error {
+	items, err := s.menuRepo.GetAll()
+	if err != nil {
+		return fmt.Errorf("failed to check bundles: %v", err)
+	}
+
+	for _, item := range items {
+		if item.Bundle == nil {
+			continue
+		}
+		for _, slot := range item.Bundle.Slots {
+			for _, option := range slot.Options {
+				if option == menuItemID {
+					return fmt.Errorf("menu item '%s' is used in bundle '%s'", menuItemID, item.Name)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// bundleMaxServings is how many of bundle the stock allows, each slot limited by its best-stocked option.
+// Components share the stock independently, so it's an upper bound when they use the same ingredients.
+// It is nil when no slot is limited by stock; menu must already have MaxServings set.
+func bundleMaxServings(bundle *models.MenuItem, menu []*models.MenuItem) *int {
+	servings := math.MaxInt32
+	limited := false
+
+	for _, slot := range bundle.Bundle.Slots {
+		best, unlimited := 0, false
+		for _, item := range menu {
+			if item.Bundle != nil || !slot.Allows(item) {
+				continue
+			}
+			if item.MaxServings == nil {
+				unlimited = true
+				break
+			}
+			if *item.MaxServings > best {
+				best = *item.MaxServings
+			}
+		}
+		if unlimited {
+			continue
+		}
+
+		limited = true
+		if possible := best / slot.Quantity; possible < servings {
+			servings = possible
+		}
+	}
+
+	if !limited {
+		return nil
+	}
+	return &servings
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"frappuccino/models"
+)
+
+// normalizeModifiers validates a menu item's modifiers against inventory, defaulting ingredient units to the
+// unit each is stocked in
+func (s *MenuService) normalizeModifiers(modifiers []models.MenuModifier) ([]models.MenuModifier, error) {
+	normalized := make([]models.MenuModifier, len(modifiers))
+	names := make(map[string]bool, len(modifiers))
+
+	for i, modifier := range modifiers {
+		modifier.Name = strings.TrimSpace(modifier.Name)
+		if modifier.Name == "" {
+			return nil, fmt.Errorf("modifier %d: name is required", i+1)
+		}
+		if names[modifier.Name] {
+			return nil, fmt.Errorf("modifier %d: duplicate name '%s'", i+1, modifier.Name)
+		}
+		names[modifier.Name] = true
+
+		if len(modifier.Ingredients) == 0 {
+			return nil, fmt.Errorf("modifier '%s': at least 1 ingredient is required", modifier.Name)
+		}
+		ingredients := make([]models.MenuItemIngredient, len(modifier.Ingredients))
+		seen := make(map[string]bool, len(modifier.Ingredients))
+		for j, ingredient := range modifier.Ingredients {
+			if ingredient.IngredientID == "" {
+				return nil, fmt.Errorf("modifier '%s' ingredient %d: ID is required", modifier.Name, j+1)
+			}
+			if ingredient.Quantity <= 0 {
+				return nil, fmt.Errorf("modifier '%s' ingredient %d: quantity must be positive", modifier.Name, j+1)
+			}
+			if seen[ingredient.IngredientID] {
+				return nil, fmt.Errorf("modifier '%s': duplicate ingredient %s", modifier.Name, ingredient.IngredientID)
+			}
+			seen[ingredient.IngredientID] = true
+			ingredients[j] = ingredient
+		}
+		if err := s.validateIngredients(ingredients); err != nil {
+			return nil, fmt.Errorf("modifier '%s': %v", modifier.Name, err)
+		}
+
+		modifier.Ingredients = ingredients
+		normalized[i] = modifier
+	}
+	return normalized, nil
+}
+
+// appliedModifier is a modifier an order item's customizations ask for, count times
+type appliedModifier struct {
+	models.MenuModifier
+	count float64
+}
+
+// appliedModifiers returns the item's modifiers that customizations apply: those set to true, or to a
+// positive count. Other customizations, like {"size": "large"}, add no ingredients.
+func appliedModifiers(item *models.MenuItem, customizations json.RawMessage) []appliedModifier {
+	if len(item.Modifiers) == 0 || len(customizations) == 0 {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(customizations, &fields); err != nil {
+		return nil
+	}
+
+	var applied []appliedModifier
+	for _, modifier := range item.Modifiers {
+		raw, ok := fields[modifier.Name]
+		if !ok {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			continue
+		}
+		switch v := value.(type) {
+		case bool:
+			if v {
+				applied = append(applied, appliedModifier{MenuModifier: modifier, count: 1})
+			}
+		case float64:
+			if v > 0 {
+				applied = append(applied, appliedModifier{MenuModifier: modifier, count: v})
+			}
+		}
+	}
+	return applied
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"fmt"
+	"time"
+
+	"frappuccino/models"
+)
+
+// SchedulePriceChangeRequest plans a menu item's price for a future time
+type SchedulePriceChangeRequest struct {
+	NewPrice    *float64  `json:"new_price"`
+	EffectiveAt time.Time `json:"effective_at"`
+	ChangedBy   string    `json:"changed_by"`
+	Reason      string    `json:"reason"`
+}
+
+// GetPriceHistory returns a menu item's recorded price changes, newest first
+func (s *MenuService) GetPriceHistory(id string) ([]*models.PriceHistoryEntry, error) {
+	if _, err := s.menuRepo.GetByID(id); err != nil {
+		s.logger.Warn("Menu item not found for price history", "id", id, "error", err)
+		return nil, err
+	}
+
+	history, err := s.priceRepo.GetHistory(id)
+	if err != nil {
+		s.logger.Error("Failed to get price history", "id", id, "error", err)
+		return nil, err
+	}
+	return history, nil
+}
+
+// GetScheduledPriceChanges returns a menu item's planned, applied and cancelled price changes
+func (s *MenuService) GetScheduledPriceChanges(id string) ([]*models.ScheduledPriceChange, error) {
+	if _, err := s.menuRepo.GetByID(id); err != nil {
+		s.logger.Warn("Menu item not found for scheduled price changes", "id", id, "error", err)
+		return nil, err
+	}
+
+	changes, err := s.priceRepo.GetScheduled(id)
+	if err != nil {
+		s.logger.Error("Failed to get scheduled price changes", "id", id, "error", err)
+		return nil, err
+	}
+	return changes, nil
+}
+
+// SchedulePriceChange plans a new price for a menu item, applied by the background job once it is due
+func (s *MenuService) SchedulePriceChange(id string, req SchedulePriceChangeRequest) (*models.ScheduledPriceChange, error) {
+	s.logger.Info("Scheduling price change", "id", id, "effective_at", req.EffectiveAt)
+
+	if req.NewPrice == nil {
+		return nil, fmt.Errorf("new_price is required")
+	}
+	if *req.NewPrice < 0 {
+		return nil, fmt.Errorf("price must be non-negative")
+	}
+	if req.EffectiveAt.IsZero() {
+		return nil, fmt.Errorf("effective_at is required")
+	}
+	if !req.EffectiveAt.After(time.Now()) {
+		return nil, fmt.Errorf("effective_at must be in the future")
+	}
+
+	if _, err := s.menuRepo.GetByID(id); err != nil {
+		s.logger.Warn("Menu item not found for price change", "id", id, "error", err)
+		return nil, err
+	}
+
+	change := &models.ScheduledPriceChange{
+		MenuItemID:  id,
+		NewPrice:    roundMoney(*req.NewPrice),
+		EffectiveAt: req.EffectiveAt,
+		ChangedBy:   req.ChangedBy,
+		Reason:      req.Reason,
+	}
+	if change.ChangedBy == "" {
+		change.ChangedBy = "system"
+	}
+
+	if err := s.priceRepo.Schedule(change); err != nil {
+		s.logger.Error("Failed to schedule price change", "id", id, "error", err)
+		return nil, err
+	}
+	return change, nil
+}
+
+// CancelPriceChange withdraws a price change that hasn't been applied yet
+func (s *MenuService) CancelPriceChange(id, changeID string) error {
+	s.logger.Info("Cancelling price change", "id", id, "change_id", changeID)
+
+	if err := s.priceRepo.Cancel(id, changeID); err != nil {
+		s.logger.Warn("Failed to cancel price change", "id", id, "change_id", changeID, "error", err)
+		return err
+	}
+	return nil
+}
+
+// ApplyScheduledPriceChanges applies every price change that has come due
+func (s *MenuService) ApplyScheduledPriceChanges() ([]*models.ScheduledPriceChange, error) {
+	applied, err := s.priceRepo.ApplyDue(time.Now())
+	if err != nil {
+		s.logger.Error("Failed to apply scheduled price changes", "error", err)
+		return nil, err
+	}
+
+	for _, change := range applied {
+		s.logger.Info("Applied scheduled price change", "id", change.ID, "menu_item_id", change.MenuItemID, "new_price", change.NewPrice)
+	}
+	return applied, nil
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"frappuccino/models"
+)
+
+// normalizeSchedule validates a menu schedule and brings its days, dates and times to canonical form.
+// An empty schedule becomes nil, meaning the item is always sold.
+func normalizeSchedule(schedule *models.MenuSchedule) (*models.MenuSchedule, error) {
+	if schedule.Empty() {
+		return nil, nil
+	}
+
+	normalized := &models.MenuSchedule{Windows: make([]models.MenuScheduleWindow, 0, len(schedule.Windows))}
+
+	var start, end time.Time
+	var err error
+	if schedule.StartDate != "" {
+		if start, err = time.Parse("2006-01-02", schedule.StartDate); err != nil {
+			return nil, fmt.Errorf("schedule: invalid start_date '%s' (expected YYYY-MM-DD)", schedule.StartDate)
+		}
+		normalized.StartDate = start.Format("2006-01-02")
+	}
+	if schedule.EndDate != "" {
+		if end, err = time.Parse("2006-01-02", schedule.EndDate); err != nil {
+			return nil, fmt.Errorf("schedule: invalid end_date '%s' (expected YYYY-MM-DD)", schedule.EndDate)
+		}
+		normalized.EndDate = end.Format("2006-01-02")
+	}
+	if !start.IsZero() && !end.IsZero() && end.Before(start) {
+		return nil, fmt.Errorf("schedule: end_date must not be before start_date")
+	}
+
+	for i, window := range schedule.Windows {
+		from, err := time.Parse("15:04", window.Start)
+		if err != nil {
+			return nil, fmt.Errorf("schedule window %d: invalid start '%s' (expected HH:MM)", i+1, window.Start)
+		}
+		// 24:00 closes a window at midnight
+		endClock := "24:00"
+		if window.End != endClock {
+			to, err := time.Parse("15:04", window.End)
+			if err != nil {
+				return nil, fmt.Errorf("schedule window %d: invalid end '%s' (expected HH:MM)", i+1, window.End)
+			}
+			if !from.Before(to) {
+				return nil, fmt.Errorf("schedule window %d: start must be before end", i+1)
+			}
+			endClock = to.Format("15:04")
+		}
+
+		weekdays := make([]string, 0, len(window.Weekdays))
+		seen := make(map[string]bool, len(window.Weekdays))
+		for _, day := range window.Weekdays {
+			day = strings.ToLower(strings.TrimSpace(day))
+			if !isWeekday(day) {
+				return nil, fmt.Errorf("schedule window %d: invalid weekday '%s' (expected one of %s)", i+1, day, strings.Join(models.Weekdays, ", "))
+			}
+			if !seen[day] {
+				seen[day] = true
+				weekdays = append(weekdays, day)
+			}
+		}
+
+		normalized.Windows = append(normalized.Windows, models.MenuScheduleWindow{
+			Weekdays: weekdays,
+			Start:    from.Format("15:04"),
+			End:      endClock,
+		})
+	}
+
+	return normalized, nil
+}
+
+func isWeekday(day string) bool {
+	for _, weekday := range models.Weekdays {
+		if day == weekday {
+			return true
+		}
+	}
+	return false
+}
@@ -10,11 +10,14 @@ package service
 
 import (
 	"fmt"
+	"reflect"
 	"strings"
+	"time"
 
 	"frappuccino/internal/repositories"
 	"frappuccino/models"
 	"frappuccino/pkg/logger"
+	"frappuccino/pkg/units"
 )
 
 type CreateMenuItemRequest struct {
@@ -24,6 +27,14 @@ type CreateMenuItemRequest struct {
 	Price       float64                     `json:"price"`
 	Available   bool                        `json:"available"`
 	Ingredients []models.MenuItemIngredient `json:"ingredients"`
+	Modifiers   []models.MenuModifier       `json:"modifiers"`
+	Schedule    *models.MenuSchedule        `json:"schedule"`
+	Bundle      *models.MenuBundle          `json:"bundle"`
+
+	Sizes              []string                  `json:"sizes"` // Defaults to medium
+	AllergenOverrides  models.AllergenOverrides  `json:"allergen_overrides"`
+	NutritionOverrides models.NutritionOverrides `json:"nutrition_overrides"`
+	StationOverride    models.Station            `json:"station_override"` // Defaults to the category's station
 }
 
 type UpdateMenuItemRequest struct {
@@ -33,35 +44,64 @@ type UpdateMenuItemRequest struct {
 	Price       *float64                     `json:"price"`
 	Available   *bool                        `json:"available"`
 	Ingredients *[]models.MenuItemIngredient `json:"ingredients"`
+	Modifiers   *[]models.MenuModifier       `json:"modifiers"`  // Replaces the modifiers, [] removes them
+	Schedule    *models.MenuSchedule         `json:"schedule"`   // Replaces the schedule, {} clears it
+	Bundle      *models.MenuBundle           `json:"bundle"`     // Replaces the slots, {} makes it a regular item
+	ChangedBy   string                       `json:"changed_by"` // Recorded in the price history when the price changes
+	Reason      string                       `json:"reason"`
+
+	Sizes              *[]string                  `json:"sizes"`
+	AllergenOverrides  *models.AllergenOverrides  `json:"allergen_overrides"`  // Replaces both lists
+	NutritionOverrides *models.NutritionOverrides `json:"nutrition_overrides"` // Replaces the overrides, {} derives every value
+	StationOverride    *models.Station            `json:"station_override"`    // "" sends the item to its category's station
+}
+
+// MenuFilter narrows the menu listing
+type MenuFilter struct {
+	AvailableOnly bool       // Only items switched on, on sale and with stock for at least one serving
+	At            *time.Time // Only items on sale at this time, the menu as it would appear then
 }
 
 type MenuServiceInterface interface {
-	GetAllMenuItems() ([]*models.MenuItem, error)
+	GetAllMenuItems(filter MenuFilter) ([]*models.MenuItem, error)
 	GetMenuItem(id string) (*models.MenuItem, error)
 	CreateMenuItem(req CreateMenuItemRequest) (*models.MenuItem, error)
 	UpdateMenuItem(id string, req UpdateMenuItemRequest) error
 	DeleteMenuItem(id string) error
+	GetPriceHistory(id string) ([]*models.PriceHistoryEntry, error)
+	GetScheduledPriceChanges(id string) ([]*models.ScheduledPriceChange, error)
+	SchedulePriceChange(id string, req SchedulePriceChangeRequest) (*models.ScheduledPriceChange, error)
+	CancelPriceChange(id, changeID string) error
+	ApplyScheduledPriceChanges() ([]*models.ScheduledPriceChange, error)
+	GetStationRoutes() ([]models.StationRoute, error)
+	SetStationRoute(category models.MenuCategory, req SetStationRouteRequest) (*models.StationRoute, error)
 }
 
 type MenuService struct {
 	menuRepo      repositories.MenuRepositoryInterface
 	inventoryRepo repositories.InventoryRepositoryInterface
 	orderRepo     repositories.OrderRepositoryInterface
+	priceRepo     repositories.PriceRepositoryInterface
+	stationRepo   repositories.StationRepositoryInterface
+	location      *time.Location // Shop timezone schedules are evaluated in
 	logger        *logger.Logger
 }
 
-func NewMenuService(inventoryRepo repositories.InventoryRepositoryInterface, menuRepo repositories.MenuRepositoryInterface, orderRepo repositories.OrderRepositoryInterface, logger *logger.Logger) *MenuService {
+func NewMenuService(inventoryRepo repositories.InventoryRepositoryInterface, menuRepo repositories.MenuRepositoryInterface, orderRepo repositories.OrderRepositoryInterface, priceRepo repositories.PriceRepositoryInterface, stationRepo repositories.StationRepositoryInterface, location *time.Location, logger *logger.Logger) *MenuService {
 	return &MenuService{
 		menuRepo:      menuRepo,
 		inventoryRepo: inventoryRepo,
 		orderRepo:     orderRepo,
+		priceRepo:     priceRepo,
+		stationRepo:   stationRepo,
+		location:      location,
 		logger:        logger.WithComponent("menu_service"),
 	}
 }
 
-// GetAllMenuItems retrieves all menu items
-func (s *MenuService) GetAllMenuItems() ([]*models.MenuItem, error) {
-	s.logger.Info("Fetching all menu items from repository")
+// GetAllMenuItems retrieves all menu items with the servings current stock allows
+func (s *MenuService) GetAllMenuItems(filter MenuFilter) ([]*models.MenuItem, error) {
+	s.logger.Info("Fetching all menu items from repository", "available_only", filter.AvailableOnly, "at", filter.At)
 
 	items, err := s.menuRepo.GetAll()
 	if err != nil {
@@ -69,6 +109,36 @@ func (s *MenuService) GetAllMenuItems() ([]*models.MenuItem, error) {
 		return nil, err
 	}
 
+	inventoryItems, err := s.inventoryRepo.GetAll()
+	if err != nil {
+		s.logger.Error("Failed to get inventory for menu makeability", "error", err)
+		return nil, err
+	}
+	for _, err := range setMaxServings(items, inventoryByID(inventoryItems)) {
+		// Makeability is informational, the items are still returned
+		s.logger.Warn("Failed to calculate menu item servings", "error", err)
+	}
+
+	if filter.AvailableOnly || filter.At != nil {
+		at := time.Now()
+		if filter.At != nil {
+			at = *filter.At
+		}
+		at = at.In(s.location)
+
+		shown := make([]*models.MenuItem, 0, len(items))
+		for _, item := range items {
+			if !item.Schedule.ActiveAt(at) {
+				continue
+			}
+			if filter.AvailableOnly && (!item.Available || (item.MaxServings != nil && *item.MaxServings == 0)) {
+				continue
+			}
+			shown = append(shown, item)
+		}
+		items = shown
+	}
+
 	s.logger.Info("Fetched menu items", "count", len(items))
 	return items, nil
 }
@@ -85,9 +155,36 @@ func (s *MenuService) CreateMenuItem(req CreateMenuItemRequest) (*models.MenuIte
 		s.logger.Warn("")
 		return nil, err
 	}
+	modifiers, err := s.normalizeModifiers(req.Modifiers)
+	if err != nil {
+		s.logger.Warn("Create failed: invalid modifiers", "error", err)
+		return nil, err
+	}
+	schedule, err := normalizeSchedule(req.Schedule)
+	if err != nil {
+		s.logger.Warn("Create failed: invalid schedule", "error", err)
+		return nil, err
+	}
+
+	sizes, err := normalizeSizes(req.Sizes)
+	if err != nil {
+		s.logger.Warn("Create failed: invalid sizes", "error", err)
+		return nil, err
+	}
+	allergenOverrides, err := normalizeAllergenOverrides(req.AllergenOverrides)
+	if err != nil {
+		s.logger.Warn("Create failed: invalid allergen overrides", "error", err)
+		return nil, err
+	}
 
 	newID := s.generateMenuItemID(req.Name)
 
+	bundle, err := s.normalizeBundle(newID, req.Bundle)
+	if err != nil {
+		s.logger.Warn("Create failed: invalid bundle", "error", err)
+		return nil, err
+	}
+
 	item := &models.MenuItem{
 		ID:          newID,
 		Name:        req.Name,
@@ -96,6 +193,14 @@ func (s *MenuService) CreateMenuItem(req CreateMenuItemRequest) (*models.MenuIte
 		Price:       req.Price,
 		Available:   req.Available,
 		Ingredients: req.Ingredients,
+		Modifiers:   modifiers,
+		Schedule:    schedule,
+		Bundle:      bundle,
+
+		Sizes:              sizes,
+		AllergenOverrides:  allergenOverrides,
+		NutritionOverrides: req.NutritionOverrides,
+		StationOverride:    req.StationOverride,
 	}
 
 	if err := s.menuRepo.Create(item); err != nil {
@@ -103,6 +208,13 @@ func (s *MenuService) CreateMenuItem(req CreateMenuItemRequest) (*models.MenuIte
 		return nil, err
 	}
 
+	// Allergens are derived from the recipe when the item is read back
+	if created, err := s.menuRepo.GetByID(newID); err != nil {
+		s.logger.Warn("Failed to read back created menu item", "id", newID, "error", err)
+	} else {
+		item = created
+	}
+
 	s.logger.Info("Menu item created successfully", "id", newID, "name", req.Name)
 	return item, nil
 }
@@ -144,6 +256,14 @@ func (s *MenuService) UpdateMenuItem(id string, req UpdateMenuItemRequest) error
 		Price:       existingItem.Price,
 		Available:   existingItem.Available,
 		Ingredients: existingItem.Ingredients,
+		Modifiers:   existingItem.Modifiers,
+		Schedule:    existingItem.Schedule,
+		Bundle:      existingItem.Bundle,
+
+		Sizes:              existingItem.Sizes,
+		AllergenOverrides:  existingItem.AllergenOverrides,
+		NutritionOverrides: existingItem.NutritionOverrides,
+		StationOverride:    existingItem.StationOverride,
 	}
 
 	if req.Name != nil {
@@ -158,15 +278,68 @@ func (s *MenuService) UpdateMenuItem(id string, req UpdateMenuItemRequest) error
 	if req.Price != nil {
 		updatedItem.Price = *req.Price
 	}
+	if req.StationOverride != nil {
+		updatedItem.StationOverride = *req.StationOverride
+	}
 	if req.Available != nil {
 		updatedItem.Available = *req.Available
 	}
 	if req.Ingredients != nil {
 		updatedItem.Ingredients = *req.Ingredients
 	}
+	if req.Modifiers != nil {
+		modifiers, err := s.normalizeModifiers(*req.Modifiers)
+		if err != nil {
+			s.logger.Warn("Update failed: invalid modifiers", "id", id, "error", err)
+			return err
+		}
+		updatedItem.Modifiers = modifiers
+	}
+	if req.Schedule != nil {
+		schedule, err := normalizeSchedule(req.Schedule)
+		if err != nil {
+			s.logger.Warn("Update failed: invalid schedule", "id", id, "error", err)
+			return err
+		}
+		updatedItem.Schedule = schedule
+	}
+	if req.Bundle != nil {
+		bundle, err := s.normalizeBundle(id, req.Bundle)
+		if err != nil {
+			s.logger.Warn("Update failed: invalid bundle", "id", id, "error", err)
+			return err
+		}
+		updatedItem.Bundle = bundle
+	}
+	if req.Sizes != nil {
+		sizes, err := normalizeSizes(*req.Sizes)
+		if err != nil {
+			s.logger.Warn("Update failed: invalid sizes", "id", id, "error", err)
+			return err
+		}
+		updatedItem.Sizes = sizes
+	}
+	if req.AllergenOverrides != nil {
+		overrides, err := normalizeAllergenOverrides(*req.AllergenOverrides)
+		if err != nil {
+			s.logger.Warn("Update failed: invalid allergen overrides", "id", id, "error", err)
+			return err
+		}
+		updatedItem.AllergenOverrides = overrides
+	}
+	if req.NutritionOverrides != nil {
+		updatedItem.NutritionOverrides = *req.NutritionOverrides
+	}
+	if updatedItem.Bundle != nil && len(updatedItem.Ingredients) > 0 {
+		return fmt.Errorf("a bundle cannot have ingredients of its own")
+	}
+	if updatedItem.Bundle != nil && len(updatedItem.Modifiers) > 0 {
+		return fmt.Errorf("a bundle cannot have modifiers of its own")
+	}
 
 	if s.hasMenuItemChanged(existingItem, updatedItem) {
-		if err := s.menuRepo.Update(id, updatedItem); err != nil {
+		note := models.PriceChangeNote{ChangedBy: req.ChangedBy, Reason: req.Reason}
+		if err := s.menuRepo.Update(id, updatedItem, note); err != nil {
 			s.logger.Error("Failed to update menu item", "id", id, "error", err)
 			return err
 		}
@@ -194,6 +367,11 @@ func (s *MenuService) DeleteMenuItem(id string) error {
 		return err
 	}
 
+	if err := s.checkMenuItemUsageInBundles(id); err != nil {
+		s.logger.Warn("Cannot delete menu item: used in bundles", "id", id, "error", err)
+		return err
+	}
+
 	if err := s.menuRepo.Delete(id); err != nil {
 		s.logger.Error("Failed to delete menu item from repository", "id", id, "error", err)
 		return err
@@ -211,6 +389,40 @@ func (s *MenuService) GetMenuItem(id string) (*models.MenuItem, error) {
 		return nil, err
 	}
 
+	// A bundle's cost and nutrition depend on the components chosen, so only regular items are costed
+	if item.Bundle == nil {
+		if inventory, err := s.recipeInventory(item); err != nil {
+			// Costing and nutrition are informational, the item itself is still returned
+			s.logger.Warn("Failed to get menu item recipe inventory", "id", id, "error", err)
+		} else {
+			if costing, err := calculateMenuItemCosting(item, inventory); err != nil {
+				s.logger.Warn("Failed to calculate menu item costing", "id", id, "error", err)
+			} else {
+				item.Costing = costing
+			}
+			if nutrition, err := calculateNutritionPanel(item, inventory); err != nil {
+				s.logger.Warn("Failed to calculate menu item nutrition", "id", id, "error", err)
+			} else {
+				item.Nutrition = nutrition
+			}
+		}
+	}
+
+	if inventoryItems, err := s.inventoryRepo.GetAll(); err != nil {
+		s.logger.Warn("Failed to get inventory for menu makeability", "id", id, "error", err)
+	} else if item.Bundle != nil {
+		if menu, err := s.menuRepo.GetAll(); err != nil {
+			s.logger.Warn("Failed to get menu for bundle makeability", "id", id, "error", err)
+		} else {
+			setMaxServings(menu, inventoryByID(inventoryItems))
+			item.MaxServings = bundleMaxServings(item, menu)
+		}
+	} else if servings, err := menuItemMaxServings(item, inventoryByID(inventoryItems)); err != nil {
+		s.logger.Warn("Failed to calculate menu item servings", "id", id, "error", err)
+	} else {
+		item.MaxServings = servings
+	}
+
 	s.logger.Info("Fetched menu item successfully", "id", id, "name", item.Name)
 	return item, nil
 }
@@ -223,10 +435,28 @@ func (s *MenuService) validateCreateMenuItemData(req CreateMenuItemRequest) erro
 	if req.Price < 0 {
 		return fmt.Errorf("price must be non-negative")
 	}
-	if len(req.Ingredients) == 0 {
+	// Bundles are made of other menu items and take their stock from them
+	if req.Bundle != nil && len(req.Bundle.Slots) > 0 {
+		if len(req.Ingredients) > 0 {
+			return fmt.Errorf("a bundle cannot have ingredients of its own")
+		}
+		if len(req.Modifiers) > 0 {
+			return fmt.Errorf("a bundle cannot have modifiers of its own")
+		}
+	} else if len(req.Ingredients) == 0 {
 		return fmt.Errorf("menu item must have at least 1 ingredient")
 	}
 
+	overrides := req.NutritionOverrides
+	if err := validateNutrition(overrides.Kcal, overrides.SugarG, overrides.FatG, overrides.CaffeineMg); err != nil {
+		return err
+	}
+	if req.StationOverride != "" {
+		if err := validateStation(req.StationOverride); err != nil {
+			return err
+		}
+	}
+
 	for i, ingredient := range req.Ingredients {
 		if ingredient.IngredientID == "" {
 			return fmt.Errorf("ingredient %d: ID is required", i+1)
@@ -248,22 +478,32 @@ func (s *MenuService) validateUpdateMenuItemData(req UpdateMenuItemRequest) erro
 		return fmt.Errorf("price must be non-negative")
 	}
 	if req.Category != nil {
-		if err := s.validateMenuCategory(*req.Category); err != nil {
+		if err := validateMenuCategory(*req.Category); err != nil {
+			return err
+		}
+	}
+	if overrides := req.NutritionOverrides; overrides != nil {
+		if err := validateNutrition(overrides.Kcal, overrides.SugarG, overrides.FatG, overrides.CaffeineMg); err != nil {
+			return err
+		}
+	}
+	if req.StationOverride != nil && *req.StationOverride != "" {
+		if err := validateStation(*req.StationOverride); err != nil {
 			return err
 		}
 	}
 	if req.Ingredients != nil {
-		if len(*req.Ingredients) == 0 {
+		if len(*req.Ingredients) == 0 && (req.Bundle == nil || len(req.Bundle.Slots) == 0) {
 			return fmt.Errorf("menu item must have at least 1 ingredient")
 		}
-	}
 
-	for i, ingredient := range *req.Ingredients {
-		if ingredient.IngredientID == "" {
-			return fmt.Errorf("ingredient %d: ID is required", i+1)
-		}
-		if ingredient.Quantity <= 0 {
-			return fmt.Errorf("ingredient %d: quantity must be positive", i+1)
+		for i, ingredient := range *req.Ingredients {
+			if ingredient.IngredientID == "" {
+				return fmt.Errorf("ingredient %d: ID is required", i+1)
+			}
+			if ingredient.Quantity <= 0 {
+				return fmt.Errorf("ingredient %d: quantity must be positive", i+1)
+			}
 		}
 	}
 
@@ -271,7 +511,7 @@ func (s *MenuService) validateUpdateMenuItemData(req UpdateMenuItemRequest) erro
 }
 
 // validateMenuCategory checks if the category is valid
-func (s *MenuService) validateMenuCategory(category models.MenuCategory) error {
+func validateMenuCategory(category models.MenuCategory) error {
 	switch category {
 	case models.CategoryCoffee, models.CategoryDrink, models.CategoryPastry, models.CategorySandwich, models.CategoryTea:
 		return nil
@@ -291,7 +531,7 @@ func (s *MenuService) checkMenuItemUsageInOrders(menuItemID string) error {
 		// Only check open orders (not closed orders)
 		if order.Status != "closed" {
 			for _, orderItem := range order.Items {
-				if orderItem.ProductID == menuItemID {
+				if orderItem.ProductID == menuItemID || orderItem.BundleID == menuItemID {
 					return fmt.Errorf("menu item '%s' is used in open order '%s'",
 						menuItemID, order.ID)
 				}
@@ -302,13 +542,21 @@ func (s *MenuService) checkMenuItemUsageInOrders(menuItemID string) error {
 }
 
 func (s *MenuService) validateIngredients(ingredients []models.MenuItemIngredient) error {
-	for _, requiredIng := range ingredients {
-		_, err := s.inventoryRepo.GetByID(requiredIng.IngredientID)
+	for i, requiredIng := range ingredients {
+		inventoryItem, err := s.inventoryRepo.GetByID(requiredIng.IngredientID)
 		if err != nil {
 			s.logger.Warn("Validation failed: ingredient not found in inventory", "ingredient_id", requiredIng.IngredientID)
 			return fmt.Errorf("ingredoent with ID %s not found", requiredIng.IngredientID)
 		}
 
+		// Recipe lines default to the unit the ingredient is stocked in
+		if requiredIng.Unit == "" {
+			ingredients[i].Unit = inventoryItem.Unit
+		} else if !units.Compatible(requiredIng.Unit, inventoryItem.Unit) {
+			s.logger.Warn("Validation failed: incompatible ingredient unit", "ingredient_id", requiredIng.IngredientID, "unit", requiredIng.Unit, "inventory_unit", inventoryItem.Unit)
+			return fmt.Errorf("unit %s is not compatible with %s for ingredient %s", requiredIng.Unit, inventoryItem.Unit, inventoryItem.Name)
+		}
+
 		// if inventoryItem.Quantity < requiredIng.Quantity {
 		// 	s.logger.Warn("Validation failed: lack of ingredient quantity", "ingredient_id", requiredIng.IngredientID, "required", requiredIng.Quantity, "available", inventoryItem.Quantity)
 		// 	return fmt.Errorf("insufficient quantity for ingredient %s", inventoryItem.Name)
@@ -324,6 +572,16 @@ func (s *MenuService) hasMenuItemChanged(existing, updated *models.MenuItem) boo
 		return true
 	}
 
+	if !reflect.DeepEqual(existing.Schedule, updated.Schedule) || !reflect.DeepEqual(existing.Bundle, updated.Bundle) ||
+		!reflect.DeepEqual(existing.Modifiers, updated.Modifiers) {
+		return true
+	}
+
+	if !reflect.DeepEqual(existing.Sizes, updated.Sizes) || !reflect.DeepEqual(existing.AllergenOverrides, updated.AllergenOverrides) ||
+		!reflect.DeepEqual(existing.NutritionOverrides, updated.NutritionOverrides) || existing.StationOverride != updated.StationOverride {
+		return true
+	}
+
 	if len(existing.Ingredients) != len(updated.Ingredients) {
 		return true
 	}
@@ -333,15 +591,44 @@ func (s *MenuService) hasMenuItemChanged(existing, updated *models.MenuItem) boo
 		existingIngredients[ing.IngredientID] = ing.Quantity
 	}
 
+	existingUnits := make(map[string]string)
+	for _, ing := range existing.Ingredients {
+		existingUnits[ing.IngredientID] = ing.Unit
+	}
+
 	for _, ing := range updated.Ingredients {
 		if qty, exists := existingIngredients[ing.IngredientID]; !exists || qty != ing.Quantity {
 			return true
 		}
+		if existingUnits[ing.IngredientID] != ing.Unit {
+			return true
+		}
 	}
 
 	return false
 }
 
+// recipeInventory loads the inventory items a menu item's recipe and modifiers use
+func (s *MenuService) recipeInventory(item *models.MenuItem) (map[string]*models.InventoryItem, error) {
+	ingredients := append([]models.MenuItemIngredient(nil), item.Ingredients...)
+	for _, modifier := range item.Modifiers {
+		ingredients = append(ingredients, modifier.Ingredients...)
+	}
+
+	inventory := make(map[string]*models.InventoryItem, len(ingredients))
+	for _, ingredient := range ingredients {
+		if _, ok := inventory[ingredient.IngredientID]; ok {
+			continue
+		}
+		inventoryItem, err := s.inventoryRepo.GetByID(ingredient.IngredientID)
+		if err != nil {
+			return nil, err
+		}
+		inventory[ingredient.IngredientID] = inventoryItem
+	}
+	return inventory, nil
+}
+
 // generateMenuItemID generates menu item ID based on the name
 func (s *MenuService) generateMenuItemID(name string) string {
 	cleaned := strings.ToLower(strings.TrimSpace(name))
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"frappuccino/models"
+)
+
+// SetStationRouteRequest sends a menu category's items to a station
+type SetStationRouteRequest struct {
+	Station models.Station `json:"station"`
+}
+
+// GetStationRoutes returns the station of every routed menu category; other categories go to the kitchen
+func (s *MenuService) GetStationRoutes() ([]models.StationRoute, error) {
+	routes, err := s.stationRepo.GetRoutes()
+	if err != nil {
+		s.logger.Error("Failed to get station routes", "error", err)
+		return nil, err
+	}
+	return routes, nil
+}
+
+// SetStationRoute sends a menu category's items to a station. Items with a station of their own keep it,
+// and orders already placed keep the stations they were routed to.
+func (s *MenuService) SetStationRoute(category models.MenuCategory, req SetStationRouteRequest) (*models.StationRoute, error) {
+	s.logger.Info("Setting station route", "category", category, "station", req.Station)
+
+	if err := validateMenuCategory(category); err != nil {
+		return nil, err
+	}
+	if err := validateStation(req.Station); err != nil {
+		return nil, err
+	}
+
+	route := models.StationRoute{Category: category, Station: req.Station}
+	if err := s.stationRepo.SetRoute(route); err != nil {
+		return nil, err
+	}
+	return &route, nil
+}
@@ -0,0 +1,189 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"frappuccino/models"
+)
+
+// sizeScale is how much of the recipe each item_size serves. Recipes are written for a medium.
+var sizeScale = map[string]float64{
+	"small":       0.75,
+	"medium":      1,
+	"large":       1.25,
+	"extra_large": 1.5,
+}
+
+// normalizeSizes validates the sizes an item is sold in and orders them smallest first, defaulting to medium
+func normalizeSizes(sizes []string) ([]string, error) {
+	if len(sizes) == 0 {
+		return []string{"medium"}, nil
+	}
+
+	seen := make(map[string]bool, len(sizes))
+	normalized := make([]string, 0, len(sizes))
+	for _, size := range sizes {
+		if _, ok := sizeScale[size]; !ok {
+			return nil, fmt.Errorf("invalid size '%s': must be small, medium, large or extra_large", size)
+		}
+		if !seen[size] {
+			seen[size] = true
+			normalized = append(normalized, size)
+		}
+	}
+	sort.Slice(normalized, func(i, j int) bool { return sizeScale[normalized[i]] < sizeScale[normalized[j]] })
+	return normalized, nil
+}
+
+// normalizeAllergenOverrides cleans up the allergens added and removed by hand
+func normalizeAllergenOverrides(overrides models.AllergenOverrides) (models.AllergenOverrides, error) {
+	added, err := normalizeAllergens(overrides.Added)
+	if err != nil {
+		return models.AllergenOverrides{}, err
+	}
+	removed, err := normalizeAllergens(overrides.Removed)
+	if err != nil {
+		return models.AllergenOverrides{}, err
+	}
+	for _, allergen := range added {
+		for _, other := range removed {
+			if allergen == other {
+				return models.AllergenOverrides{}, fmt.Errorf("allergen '%s' cannot be both added and removed", allergen)
+			}
+		}
+	}
+	return models.AllergenOverrides{Added: added, Removed: removed}, nil
+}
+
+// validateNutrition checks nutrition values can't be negative, whether per unit or set by hand
+func validateNutrition(kcal, sugar, fat, caffeine *float64) error {
+	names := []string{"kcal", "sugar_g", "fat_g", "caffeine_mg"}
+	for i, value := range []*float64{kcal, sugar, fat, caffeine} {
+		if value != nil && *value < 0 {
+			return fmt.Errorf("nutrition %s must be non-negative", names[i])
+		}
+	}
+	return nil
+}
+
+// calculateNutritionPanel adds up a recipe's nutrition and allergens from its ingredients, applies the item's
+// overrides and scales the serving to each size it is sold in. The item's allergens already have the overrides applied.
+// Each modifier's ingredients are added up on their own, as an amount on top of any size that doesn't scale with it.
+func calculateNutritionPanel(item *models.MenuItem, inventory map[string]*models.InventoryItem) (*models.NutritionPanel, error) {
+	panel := &models.NutritionPanel{
+		Allergens:   item.Allergens,
+		Ingredients: make([]models.IngredientNutrition, 0, len(item.Ingredients)),
+	}
+
+	for _, ingredient := range item.Ingredients {
+		inventoryItem, ok := inventory[ingredient.IngredientID]
+		if !ok {
+			return nil, fmt.Errorf("ingredient '%s' not found in inventory", ingredient.IngredientID)
+		}
+
+		quantity, err := recipeQuantity(ingredient, inventoryItem)
+		if err != nil {
+			return nil, err
+		}
+
+		nutrition := scaleNutrition(inventoryItem.Nutrition, quantity)
+		panel.Derived = addNutrition(panel.Derived, nutrition)
+		panel.Ingredients = append(panel.Ingredients, models.IngredientNutrition{
+			IngredientID: ingredient.IngredientID,
+			Name:         inventoryItem.Name,
+			Quantity:     quantity,
+			Unit:         inventoryItem.Unit,
+			Allergens:    inventoryItem.Allergens,
+			Nutrition:    roundNutrition(nutrition),
+		})
+	}
+
+	panel.Serving = panel.Derived
+	overrides := item.NutritionOverrides
+	for _, field := range []struct {
+		name     string
+		override *float64
+		value    *float64
+	}{
+		{"kcal", overrides.Kcal, &panel.Serving.Kcal},
+		{"sugar_g", overrides.SugarG, &panel.Serving.SugarG},
+		{"fat_g", overrides.FatG, &panel.Serving.FatG},
+		{"caffeine_mg", overrides.CaffeineMg, &panel.Serving.CaffeineMg},
+	} {
+		if field.override != nil {
+			*field.value = *field.override
+			panel.Overridden = append(panel.Overridden, field.name)
+		}
+	}
+
+	sizes := item.Sizes
+	if len(sizes) == 0 {
+		sizes = []string{"medium"}
+	}
+	for _, size := range sizes {
+		scale, ok := sizeScale[size]
+		if !ok {
+			continue
+		}
+		panel.Sizes = append(panel.Sizes, models.SizeNutrition{
+			Size:      size,
+			Scale:     scale,
+			Nutrition: roundNutrition(scaleNutrition(panel.Serving, scale)),
+		})
+	}
+
+	for _, modifier := range item.Modifiers {
+		added := models.ModifierNutrition{Name: modifier.Name, Allergens: []string{}}
+		var nutrition models.Nutrition
+		for _, ingredient := range modifier.Ingredients {
+			inventoryItem, ok := inventory[ingredient.IngredientID]
+			if !ok {
+				return nil, fmt.Errorf("ingredient '%s' of modifier '%s' not found in inventory", ingredient.IngredientID, modifier.Name)
+			}
+
+			quantity, err := recipeQuantity(ingredient, inventoryItem)
+			if err != nil {
+				return nil, err
+			}
+			nutrition = addNutrition(nutrition, scaleNutrition(inventoryItem.Nutrition, quantity))
+			added.Allergens = mergeAllergens(added.Allergens, inventoryItem.Allergens)
+		}
+		added.Nutrition = roundNutrition(nutrition)
+		panel.Modifiers = append(panel.Modifiers, added)
+	}
+
+	panel.Derived = roundNutrition(panel.Derived)
+	panel.Serving = roundNutrition(panel.Serving)
+	return panel, nil
+}
+
+func scaleNutrition(n models.Nutrition, factor float64) models.Nutrition {
+	return models.Nutrition{
+		Kcal:       n.Kcal * factor,
+		SugarG:     n.SugarG * factor,
+		FatG:       n.FatG * factor,
+		CaffeineMg: n.CaffeineMg * factor,
+	}
+}
+
+func addNutrition(a, b models.Nutrition) models.Nutrition {
+	return models.Nutrition{
+		Kcal:       a.Kcal + b.Kcal,
+		SugarG:     a.SugarG + b.SugarG,
+		FatG:       a.FatG + b.FatG,
+		CaffeineMg: a.CaffeineMg + b.CaffeineMg,
+	}
+}
+
+// roundNutrition rounds nutrition values to one decimal, as labels show them
+func roundNutrition(n models.Nutrition) models.Nutrition {
+	round := func(v float64) float64 { return math.Round(v*10) / 10 }
+	return models.Nutrition{
+		Kcal:       round(n.Kcal),
+		SugarG:     round(n.SugarG),
+		FatG:       round(n.FatG),
+		CaffeineMg: round(n.CaffeineMg),
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"frappuccino/models"
+)
+
+func TestCalculateNutritionPanel(t *testing.T) {
+	inventory := map[string]*models.InventoryItem{
+		"beans": {IngredientID: "beans", Name: "Beans", Unit: "kg", Nutrition: models.Nutrition{CaffeineMg: 12000}},
+		"milk":  {IngredientID: "milk", Name: "Milk", Unit: "liters", Allergens: []string{"milk"}, Nutrition: models.Nutrition{Kcal: 640, SugarG: 48, FatG: 35}},
+		"cream": {IngredientID: "cream", Name: "Cream", Unit: "liters", Allergens: []string{"milk"}, Nutrition: models.Nutrition{Kcal: 2570, SugarG: 120, FatG: 250}},
+		"syrup": {IngredientID: "syrup", Name: "Syrup", Unit: "liters", Nutrition: models.Nutrition{Kcal: 2700, SugarG: 650}},
+	}
+	item := &models.MenuItem{
+		Allergens: []string{"milk"},
+		Sizes:     []string{"small", "large"},
+		Ingredients: []models.MenuItemIngredient{
+			{IngredientID: "beans", Quantity: 18, Unit: "grams"},
+			{IngredientID: "milk", Quantity: 200, Unit: "ml"},
+		},
+		Modifiers: []models.MenuModifier{
+			{Name: "extra_shot", Ingredients: []models.MenuItemIngredient{{IngredientID: "beans", Quantity: 9, Unit: "grams"}}},
+			{Name: "whipped_cream", Ingredients: []models.MenuItemIngredient{
+				{IngredientID: "cream", Quantity: 30, Unit: "ml"},
+				{IngredientID: "syrup", Quantity: 10, Unit: "ml"},
+			}},
+		},
+	}
+
+	panel, err := calculateNutritionPanel(item, inventory)
+	if err != nil {
+		t.Fatalf("calculateNutritionPanel() error = %v", err)
+	}
+
+	wantServing := models.Nutrition{Kcal: 128, SugarG: 9.6, FatG: 7, CaffeineMg: 216}
+	if panel.Serving != wantServing {
+		t.Errorf("Serving = %+v, want %+v", panel.Serving, wantServing)
+	}
+
+	wantSizes := []models.SizeNutrition{
+		{Size: "small", Scale: 0.75, Nutrition: models.Nutrition{Kcal: 96, SugarG: 7.2, FatG: 5.3, CaffeineMg: 162}},
+		{Size: "large", Scale: 1.25, Nutrition: models.Nutrition{Kcal: 160, SugarG: 12, FatG: 8.8, CaffeineMg: 270}},
+	}
+	if !reflect.DeepEqual(panel.Sizes, wantSizes) {
+		t.Errorf("Sizes = %+v, want %+v", panel.Sizes, wantSizes)
+	}
+
+	// Modifiers add a fixed amount whatever the size
+	wantModifiers := []models.ModifierNutrition{
+		{Name: "extra_shot", Allergens: []string{}, Nutrition: models.Nutrition{CaffeineMg: 108}},
+		{Name: "whipped_cream", Allergens: []string{"milk"}, Nutrition: models.Nutrition{Kcal: 104.1, SugarG: 10.1, FatG: 7.5}},
+	}
+	if !reflect.DeepEqual(panel.Modifiers, wantModifiers) {
+		t.Errorf("Modifiers = %+v, want %+v", panel.Modifiers, wantModifiers)
+	}
+}
+
+func TestCalculateNutritionPanelMissingModifierIngredient(t *testing.T) {
+	item := &models.MenuItem{
+		Modifiers: []models.MenuModifier{
+			{Name: "extra_shot", Ingredients: []models.MenuItemIngredient{{IngredientID: "beans", Quantity: 9, Unit: "grams"}}},
+		},
+	}
+	if _, err := calculateNutritionPanel(item, map[string]*models.InventoryItem{}); err == nil {
+		t.Error("calculateNutritionPanel() error = nil, want an error for the unknown ingredient")
+	}
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"frappuccino/models"
+)
+
+// AllergenConflictError rejects an order containing allergens the customer declared, until the warnings
+// are explicitly overridden
+type AllergenConflictError struct {
+	Declared []string
+	Warnings []models.AllergenWarning
+}
+
+func (e *AllergenConflictError) Error() string {
+	items := make([]string, len(e.Warnings))
+	for i, warning := range e.Warnings {
+		items[i] = fmt.Sprintf("%s (%s)", warning.Name, strings.Join(warning.Allergens, ", "))
+	}
+	return fmt.Sprintf("order contains declared allergens: %s; set allergen_override to accept", strings.Join(items, "; "))
+}
+
+// checkAllergens cross-checks every order line, bundle choices and the ingredients of the modifiers its
+// customizations apply included, against the allergens declared on the customer's profile and in the order's
+// special instructions. Matches fail with an AllergenConflictError unless overridden, in which case the
+// warnings are returned to be recorded with who accepted them.
+func (s *OrderService) checkAllergens(customerID string, instructions json.RawMessage, lines []models.OrderItem, override bool, overrideBy string) ([]models.AllergenWarning, error) {
+	declared, err := s.declaredAllergens(customerID, instructions)
+	if err != nil {
+		return nil, err
+	}
+	if len(declared) == 0 {
+		return nil, nil
+	}
+
+	menu, err := s.lineMenu(lines)
+	if err != nil {
+		return nil, err
+	}
+
+	var warnings []models.AllergenWarning
+	seen := make(map[string]bool)
+	ingredientAllergens := make(map[string][]string)
+	for _, line := range lines {
+		key := allergenLineKey(line)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		menuItem := menu[line.ProductID]
+		matched := matchAllergens(declared, menuItem.Allergens)
+		var modifiers []string
+		for _, modifier := range appliedModifiers(menuItem, line.Customizations) {
+			var contained []string
+			for _, ingredient := range modifier.Ingredients {
+				allergens, ok := ingredientAllergens[ingredient.IngredientID]
+				if !ok {
+					inventoryItem, err := s.inventoryRepo.GetByID(ingredient.IngredientID)
+					if err != nil {
+						return nil, fmt.Errorf("modifier '%s' of '%s': ingredient '%s' not found in inventory", modifier.Name, menuItem.Name, ingredient.IngredientID)
+					}
+					allergens = inventoryItem.Allergens
+					ingredientAllergens[ingredient.IngredientID] = allergens
+				}
+				contained = append(contained, allergens...)
+			}
+			if modifierMatched := matchAllergens(declared, contained); len(modifierMatched) > 0 {
+				modifiers = append(modifiers, modifier.Name)
+				matched = mergeAllergens(matched, modifierMatched)
+			}
+		}
+
+		if len(matched) > 0 {
+			warnings = append(warnings, models.AllergenWarning{
+				MenuItemID: line.ProductID,
+				Name:       menuItem.Name,
+				BundleID:   line.BundleID,
+				Allergens:  matched,
+				Modifiers:  modifiers,
+			})
+		}
+	}
+
+	if len(warnings) == 0 {
+		return nil, nil
+	}
+	if !override {
+		return nil, &AllergenConflictError{Declared: declared, Warnings: warnings}
+	}
+	if strings.TrimSpace(overrideBy) == "" {
+		return nil, fmt.Errorf("allergen_override_by is required to override allergen warnings")
+	}
+	return warnings, nil
+}
+
+// addedOrderItems returns the lines whose item, by bundle, product and customizations, is not among the existing lines
+func addedOrderItems(existing, lines []models.OrderItem) []models.OrderItem {
+	had := make(map[string]bool, len(existing))
+	for _, line := range existing {
+		had[allergenLineKey(line)] = true
+	}
+
+	var added []models.OrderItem
+	for _, line := range lines {
+		if !had[allergenLineKey(line)] {
+			added = append(added, line)
+		}
+	}
+	return added
+}
+
+// allergenLineKey identifies what a line puts in front of the customer: the product, the bundle it came in
+// and its customizations, which can add modifier ingredients. Customizations are compared with keys sorted.
+func allergenLineKey(line models.OrderItem) string {
+	customizations := string(line.Customizations)
+	var fields map[string]interface{}
+	if err := json.Unmarshal(line.Customizations, &fields); err == nil && len(fields) > 0 {
+		if canonical, err := json.Marshal(fields); err == nil {
+			customizations = string(canonical)
+		}
+	} else if err == nil {
+		customizations = ""
+	}
+	return line.BundleID + "/" + line.ProductID + "/" + customizations
+}
+
+// mergeAllergens adds the allergens of b missing from a, sorted
+func mergeAllergens(a, b []string) []string {
+	merged := append(make([]string, 0, len(a)+len(b)), a...)
+	for _, allergen := range b {
+		found := false
+		for _, existing := range a {
+			if existing == allergen {
+				found = true
+				break
+			}
+		}
+		if !found {
+			merged = append(merged, allergen)
+		}
+	}
+	sort.Strings(merged)
+	return merged
+}
+
+// declaredAllergens merges the customer's profile allergens with the "allergies" listed in special instructions
+func (s *OrderService) declaredAllergens(customerID string, instructions json.RawMessage) ([]string, error) {
+	allergies, err := instructionAllergies(instructions)
+	if err != nil {
+		return nil, err
+	}
+
+	if customerID != "" {
+		customer, err := s.customerRepo.GetByID(customerID)
+		if err != nil {
+			return nil, err
+		}
+		allergies = append(allergies, customer.Allergens...)
+	}
+	return normalizeAllergens(allergies)
+}
+
+// instructionAllergies reads the allergies declared in an order's special instructions, which must be a JSON object
+func instructionAllergies(instructions json.RawMessage) ([]string, error) {
+	if len(instructions) == 0 || string(instructions) == "null" {
+		return nil, nil
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(instructions, &fields); err != nil {
+		return nil, fmt.Errorf("special_instructions must be a JSON object")
+	}
+	raw, ok := fields["allergies"]
+	if !ok {
+		return nil, nil
+	}
+
+	var allergies []string
+	if err := json.Unmarshal(raw, &allergies); err != nil {
+		return nil, fmt.Errorf("special_instructions allergies must be a list of allergen names")
+	}
+	return allergies, nil
+}
+
+// matchAllergens returns the declared allergens an item contains. Names match whole words either way,
+// so a declared "nuts" catches "tree nuts" and a declared "cow milk" catches "milk".
+func matchAllergens(declared, contained []string) []string {
+	var matched []string
+	for _, allergen := range declared {
+		for _, item := range contained {
+			item = strings.ToLower(strings.Join(strings.Fields(item), " "))
+			if containsWords(item, allergen) || containsWords(allergen, item) {
+				matched = append(matched, allergen)
+				break
+			}
+		}
+	}
+	sort.Strings(matched)
+	return matched
+}
+
+func containsWords(text, words string) bool {
+	return words != "" && strings.Contains(" "+text+" ", " "+words+" ")
+}
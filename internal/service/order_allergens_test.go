@@ -0,0 +1,178 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"frappuccino/models"
+)
+
+func TestAppliedModifiers(t *testing.T) {
+	item := &models.MenuItem{
+		Modifiers: []models.MenuModifier{
+			{Name: "almond_milk", Ingredients: []models.MenuItemIngredient{{IngredientID: "almond", Quantity: 200}}},
+			{Name: "extra_shot", Ingredients: []models.MenuItemIngredient{{IngredientID: "beans", Quantity: 18}}},
+		},
+	}
+
+	tests := []struct {
+		name           string
+		customizations string
+		want           map[string]float64 // Modifier name to count
+	}{
+		{name: "no customizations", customizations: ``, want: map[string]float64{}},
+		{name: "set to true", customizations: `{"almond_milk": true}`, want: map[string]float64{"almond_milk": 1}},
+		{name: "set to false", customizations: `{"almond_milk": false}`, want: map[string]float64{}},
+		{name: "count", customizations: `{"extra_shot": 2, "almond_milk": true}`, want: map[string]float64{"almond_milk": 1, "extra_shot": 2}},
+		{name: "zero count", customizations: `{"extra_shot": 0}`, want: map[string]float64{}},
+		{name: "other values add nothing", customizations: `{"size": "large", "almond_milk": "yes"}`, want: map[string]float64{}},
+		{name: "not an object", customizations: `["almond_milk"]`, want: map[string]float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := make(map[string]float64)
+			for _, modifier := range appliedModifiers(item, json.RawMessage(tt.customizations)) {
+				got[modifier.Name] = modifier.count
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("appliedModifiers() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAddedOrderItems(t *testing.T) {
+	existing := []models.OrderItem{
+		{ProductID: "latte", Customizations: json.RawMessage(`{"size": "large", "almond_milk": true}`)},
+		{ProductID: "muffin"},
+	}
+
+	tests := []struct {
+		name  string
+		lines []models.OrderItem
+		want  []string // Products of the added lines
+	}{
+		{
+			name: "same lines, keys reordered",
+			lines: []models.OrderItem{
+				{ProductID: "latte", Customizations: json.RawMessage(`{"almond_milk":true,"size":"large"}`)},
+				{ProductID: "muffin", Customizations: json.RawMessage(`{}`)},
+			},
+		},
+		{
+			name: "changed customizations",
+			lines: []models.OrderItem{
+				{ProductID: "latte", Customizations: json.RawMessage(`{"size": "large", "almond_milk": true, "extra_shot": 1}`)},
+				{ProductID: "muffin"},
+			},
+			want: []string{"latte"},
+		},
+		{
+			name: "new product",
+			lines: []models.OrderItem{
+				{ProductID: "muffin"},
+				{ProductID: "croissant"},
+			},
+			want: []string{"croissant"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, line := range addedOrderItems(existing, tt.lines) {
+				got = append(got, line.ProductID)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("addedOrderItems() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckAllergensModifiers(t *testing.T) {
+	s := &OrderService{
+		menuRepo: &fakeMenuRepo{items: map[string]*models.MenuItem{
+			"latte": {
+				ID:        "latte",
+				Name:      "Latte",
+				Allergens: []string{"milk"},
+				Modifiers: []models.MenuModifier{
+					{Name: "almond_milk", Ingredients: []models.MenuItemIngredient{{IngredientID: "almond", Quantity: 200}}},
+					{Name: "vanilla", Ingredients: []models.MenuItemIngredient{{IngredientID: "syrup", Quantity: 15}}},
+				},
+			},
+		}},
+		inventoryRepo: &fakeInventoryRepo{items: map[string]*models.InventoryItem{
+			"almond": {IngredientID: "almond", Name: "Almond Milk", Allergens: []string{"tree nuts"}},
+			"syrup":  {IngredientID: "syrup", Name: "Vanilla Syrup"},
+		}},
+		logger: testLogger(),
+	}
+
+	tests := []struct {
+		name           string
+		allergies      string
+		customizations string
+		override       bool
+		want           []models.AllergenWarning
+		wantConflict   bool
+	}{
+		{
+			name:           "modifier not applied",
+			allergies:      `{"allergies": ["nuts"]}`,
+			customizations: `{"vanilla": true}`,
+		},
+		{
+			name:           "modifier ingredient contains a declared allergen",
+			allergies:      `{"allergies": ["nuts"]}`,
+			customizations: `{"almond_milk": true, "vanilla": true}`,
+			wantConflict:   true,
+		},
+		{
+			name:           "overridden with the recipe's allergen",
+			allergies:      `{"allergies": ["nuts", "milk"]}`,
+			customizations: `{"almond_milk": true}`,
+			override:       true,
+			want: []models.AllergenWarning{
+				{MenuItemID: "latte", Name: "Latte", Allergens: []string{"milk", "nuts"}, Modifiers: []string{"almond_milk"}},
+			},
+		},
+		{
+			name:           "recipe allergen only",
+			allergies:      `{"allergies": ["milk"]}`,
+			customizations: `{"almond_milk": true}`,
+			override:       true,
+			want: []models.AllergenWarning{
+				{MenuItemID: "latte", Name: "Latte", Allergens: []string{"milk"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lines := []models.OrderItem{{ProductID: "latte", Quantity: 1, Customizations: json.RawMessage(tt.customizations)}}
+			got, err := s.checkAllergens("", json.RawMessage(tt.allergies), lines, tt.override, "barista")
+
+			var conflict *AllergenConflictError
+			if tt.wantConflict {
+				if !errors.As(err, &conflict) {
+					t.Fatalf("checkAllergens() error = %v, want an AllergenConflictError", err)
+				}
+				if len(conflict.Warnings) != 1 || !reflect.DeepEqual(conflict.Warnings[0].Modifiers, []string{"almond_milk"}) {
+					t.Errorf("conflict warnings = %+v, want one naming almond_milk", conflict.Warnings)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("checkAllergens() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("checkAllergens() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
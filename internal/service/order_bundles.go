@@ -0,0 +1,207 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"frappuccino/models"
+)
+
+// buildOrderItems prices the requested items at the current menu and totals the order.
+// Bundles are expanded into their components, which is what gets stored, reserved and reported;
+// each component carries its share of the bundle price.
+func (s *OrderService) buildOrderItems(items []CreateOrderItemRequest) ([]models.OrderItem, float64, error) {
+	var lines []models.OrderItem
+	var total float64
+
+	for i, item := range items {
+		menuItem, err := s.menuRepo.GetByID(item.ProductID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("item %d: product '%s' not found in menu", i+1, item.ProductID)
+		}
+		if string(item.Customizations) == "null" {
+			item.Customizations = nil
+		}
+		if !validCustomizations(item.Customizations) {
+			return nil, 0, fmt.Errorf("item %d: customizations must be a JSON object", i+1)
+		}
+
+		if menuItem.Bundle == nil {
+			if len(item.Choices) > 0 {
+				return nil, 0, fmt.Errorf("item %d: choices are only allowed for bundles", i+1)
+			}
+			lines = append(lines, models.OrderItem{
+				MenuItemID:  menuItem.ID,
+				ProductID:   menuItem.ID,
+				Quantity:    item.Quantity,
+				PriceAtTime: menuItem.Price,
+				Station:     menuItem.Station,
+				PrepStatus:  models.PrepQueued,
+
+				Customizations: item.Customizations,
+			})
+			total += menuItem.Price * float64(item.Quantity)
+			continue
+		}
+
+		components, err := s.expandBundle(i+1, menuItem, item)
+		if err != nil {
+			return nil, 0, err
+		}
+		lines = append(lines, components...)
+		total += menuItem.Price * float64(item.Quantity)
+	}
+
+	return lines, roundMoney(total), nil
+}
+
+// validCustomizations reports whether an order item's customizations are absent or a JSON object
+func validCustomizations(customizations json.RawMessage) bool {
+	if len(customizations) == 0 {
+		return true
+	}
+	var fields map[string]json.RawMessage
+	return json.Unmarshal(customizations, &fields) == nil
+}
+
+// expandBundle resolves the bundle's slots with the customer's choices into order items for line
+func (s *OrderService) expandBundle(line int, bundle *models.MenuItem, item CreateOrderItemRequest) ([]models.OrderItem, error) {
+	slotNames := make(map[string]bool, len(bundle.Bundle.Slots))
+	for _, slot := range bundle.Bundle.Slots {
+		slotNames[slot.Name] = true
+	}
+	for name := range item.Choices {
+		if !slotNames[name] {
+			return nil, fmt.Errorf("item %d: bundle '%s' has no slot '%s'", line, bundle.Name, name)
+		}
+	}
+
+	components := make([]*models.MenuItem, len(bundle.Bundle.Slots))
+	regular := make([]float64, len(bundle.Bundle.Slots))
+	quantities := make([]int, len(bundle.Bundle.Slots))
+
+	for i, slot := range bundle.Bundle.Slots {
+		choice, chosen := item.Choices[slot.Name]
+		if !chosen {
+			if !slot.Fixed() {
+				return nil, fmt.Errorf("item %d: choose an item for '%s' in bundle '%s'", line, slot.Name, bundle.Name)
+			}
+			choice = slot.Options[0]
+		}
+
+		component, err := s.menuRepo.GetByID(choice)
+		if err != nil {
+			return nil, fmt.Errorf("item %d: product '%s' not found in menu", line, choice)
+		}
+		if component.Bundle != nil || !slot.Allows(component) {
+			return nil, fmt.Errorf("item %d: '%s' cannot be chosen for '%s' in bundle '%s'", line, component.Name, slot.Name, bundle.Name)
+		}
+
+		components[i] = component
+		regular[i] = component.Price
+		quantities[i] = slot.Quantity
+	}
+
+	parts := allocateBundlePrice(bundle.Price, regular, quantities)
+
+	items := make([]models.OrderItem, len(parts))
+	for i, part := range parts {
+		component := components[part.slot]
+		items[i] = models.OrderItem{
+			MenuItemID:  component.ID,
+			ProductID:   component.ID,
+			Quantity:    part.quantity * item.Quantity,
+			PriceAtTime: part.unitPrice,
+			BundleID:    bundle.ID,
+			BundleLine:  line,
+			Station:     component.Station,
+			PrepStatus:  models.PrepQueued,
+
+			Customizations: item.Customizations,
+		}
+	}
+	return items, nil
+}
+
+// bundlePart is a quantity of a bundle slot's component priced at one unit price
+type bundlePart struct {
+	slot      int
+	quantity  int
+	unitPrice float64
+}
+
+// allocateBundlePrice splits a bundle's price across its components in proportion to their regular
+// price times quantity. Unit prices are rounded to cents and the rounding remainder is spread a cent per
+// unit, so the parts always add up to the bundle price. A component whose units end up at two prices is
+// returned as two parts; components whose whole quantity can take the remainder are adjusted first.
+func allocateBundlePrice(price float64, regular []float64, quantities []int) []bundlePart {
+	var weight float64
+	for i := range regular {
+		weight += regular[i] * float64(quantities[i])
+	}
+
+	unitPrices := make([]float64, len(regular))
+	var allocated float64
+	for i := range regular {
+		if weight > 0 {
+			unitPrices[i] = roundMoney(price * regular[i] / weight)
+		} else {
+			// Free components split the price evenly per slot
+			unitPrices[i] = roundMoney(price / float64(len(regular)) / float64(quantities[i]))
+		}
+		allocated += unitPrices[i] * float64(quantities[i])
+	}
+
+	// Units taking a cent more, or a cent less when the rounding overshot
+	cents := int(math.Round((price - allocated) * 100))
+	step := 1
+	if cents < 0 {
+		cents, step = -cents, -1
+	}
+	adjusted := make([]int, len(regular))
+	canAdjust := func(i int) bool { return step > 0 || unitPrices[i] >= 0.01 }
+	for i := range regular {
+		if cents > 0 && quantities[i] <= cents && canAdjust(i) {
+			adjusted[i] = quantities[i]
+			cents -= quantities[i]
+		}
+	}
+	for i := range regular {
+		if cents > 0 && adjusted[i] < quantities[i] && canAdjust(i) {
+			units := min(cents, quantities[i]-adjusted[i])
+			adjusted[i] += units
+			cents -= units
+		}
+	}
+
+	parts := make([]bundlePart, 0, len(regular))
+	for i := range regular {
+		if adjusted[i] > 0 {
+			parts = append(parts, bundlePart{slot: i, quantity: adjusted[i], unitPrice: roundMoney(unitPrices[i] + float64(step)*0.01)})
+		}
+		if rest := quantities[i] - adjusted[i]; rest > 0 {
+			parts = append(parts, bundlePart{slot: i, quantity: rest, unitPrice: unitPrices[i]})
+		}
+	}
+	return parts
+}
+
+// checkBundleSchedules rejects bundles whose chosen components are not on sale at t in the shop's timezone
+func (s *OrderService) checkBundleSchedules(lines []models.OrderItem, t time.Time) error {
+	local := t.In(s.location)
+	for _, line := range lines {
+		if line.BundleID == "" {
+			continue
+		}
+		component, err := s.menuRepo.GetByID(line.ProductID)
+		if err != nil {
+			return fmt.Errorf("item %d: product '%s' not found in menu", line.BundleLine, line.ProductID)
+		}
+		if !component.Schedule.ActiveAt(local) {
+			return fmt.Errorf("item %d: '%s' is not on sale at %s", line.BundleLine, component.Name, local.Format("Mon 2006-01-02 15:04 MST"))
+		}
+	}
+	return nil
+}
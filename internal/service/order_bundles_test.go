@@ -0,0 +1,164 @@
+package service
+
+import (
+	"math"
+	"reflect"
+	"testing"
+
+	"frappuccino/models"
+)
+
+func TestAllocateBundlePrice(t *testing.T) {
+	tests := []struct {
+		name       string
+		price      float64
+		regular    []float64
+		quantities []int
+		want       []bundlePart
+	}{
+		{
+			name:       "proportional split",
+			price:      5,
+			regular:    []float64{3, 2.5},
+			quantities: []int{1, 1},
+			want:       []bundlePart{{slot: 0, quantity: 1, unitPrice: 2.73}, {slot: 1, quantity: 1, unitPrice: 2.27}},
+		},
+		{
+			name:       "uneven split gives the cent to one unit",
+			price:      10,
+			regular:    []float64{1, 1, 1},
+			quantities: []int{1, 1, 1},
+			want:       []bundlePart{{slot: 0, quantity: 1, unitPrice: 3.34}, {slot: 1, quantity: 1, unitPrice: 3.33}, {slot: 2, quantity: 1, unitPrice: 3.33}},
+		},
+		{
+			name:       "single unit takes the remainder before multi-quantity components",
+			price:      6.99,
+			regular:    []float64{2, 1},
+			quantities: []int{2, 1},
+			want:       []bundlePart{{slot: 0, quantity: 2, unitPrice: 2.8}, {slot: 1, quantity: 1, unitPrice: 1.39}},
+		},
+		{
+			name:       "multi-quantity components split into two prices",
+			price:      10,
+			regular:    []float64{1, 1},
+			quantities: []int{3, 3},
+			want: []bundlePart{
+				{slot: 0, quantity: 2, unitPrice: 1.66},
+				{slot: 0, quantity: 1, unitPrice: 1.67},
+				{slot: 1, quantity: 3, unitPrice: 1.67},
+			},
+		},
+		{
+			name:       "remainder spread across a whole component",
+			price:      1,
+			regular:    []float64{1},
+			quantities: []int{3},
+			want:       []bundlePart{{slot: 0, quantity: 1, unitPrice: 0.34}, {slot: 0, quantity: 2, unitPrice: 0.33}},
+		},
+		{
+			name:       "free components split evenly per slot",
+			price:      3,
+			regular:    []float64{0, 0},
+			quantities: []int{1, 2},
+			want:       []bundlePart{{slot: 0, quantity: 1, unitPrice: 1.5}, {slot: 1, quantity: 2, unitPrice: 0.75}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allocateBundlePrice(tt.price, tt.regular, tt.quantities)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("allocateBundlePrice(%v, %v, %v) = %+v, want %+v", tt.price, tt.regular, tt.quantities, got, tt.want)
+			}
+
+			cents := 0
+			units := make([]int, len(tt.quantities))
+			for _, part := range got {
+				cents += int(math.Round(part.unitPrice*100)) * part.quantity
+				units[part.slot] += part.quantity
+			}
+			if cents != int(math.Round(tt.price*100)) {
+				t.Errorf("parts add up to %d cents, want %v", cents, tt.price)
+			}
+			if !reflect.DeepEqual(units, tt.quantities) {
+				t.Errorf("parts hold %v units per slot, want %v", units, tt.quantities)
+			}
+		})
+	}
+}
+
+func TestExpandBundle(t *testing.T) {
+	menu := &fakeMenuRepo{items: map[string]*models.MenuItem{
+		"latte":     {ID: "latte", Name: "Latte", Category: "coffee", Price: 4},
+		"tea":       {ID: "tea", Name: "Tea", Category: "tea", Price: 3},
+		"croissant": {ID: "croissant", Name: "Croissant", Category: "pastry", Price: 3},
+	}}
+	bundle := &models.MenuItem{ID: "combo", Name: "Breakfast", Price: 6, Bundle: &models.MenuBundle{Slots: []models.BundleSlot{
+		{Name: "drink", Quantity: 1, Options: []string{"latte", "tea"}},
+		{Name: "pastry", Quantity: 2, Options: []string{"croissant"}},
+	}}}
+	s := &OrderService{menuRepo: menu}
+
+	type line struct {
+		product  string
+		quantity int
+		price    float64
+	}
+	tests := []struct {
+		name     string
+		choices  map[string]string
+		quantity int
+		want     []line
+		wantErr  bool
+	}{
+		{
+			name:     "chosen drink prices the split",
+			choices:  map[string]string{"drink": "latte"},
+			quantity: 1,
+			want:     []line{{"latte", 1, 2.4}, {"croissant", 2, 1.8}},
+		},
+		{
+			name:     "cheaper choice takes a smaller share",
+			choices:  map[string]string{"drink": "tea"},
+			quantity: 1,
+			want:     []line{{"tea", 1, 2}, {"croissant", 2, 2}},
+		},
+		{
+			name:     "bundle quantity multiplies components",
+			choices:  map[string]string{"drink": "latte"},
+			quantity: 2,
+			want:     []line{{"latte", 2, 2.4}, {"croissant", 4, 1.8}},
+		},
+		{name: "choice is required", choices: nil, quantity: 1, wantErr: true},
+		{name: "choice must be an option", choices: map[string]string{"drink": "croissant"}, quantity: 1, wantErr: true},
+		{name: "unknown slot", choices: map[string]string{"drink": "latte", "side": "tea"}, quantity: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := s.expandBundle(1, bundle, CreateOrderItemRequest{ProductID: bundle.ID, Quantity: tt.quantity, Choices: tt.choices})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("expandBundle() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			var got []line
+			var total float64
+			for _, item := range items {
+				got = append(got, line{item.ProductID, item.Quantity, item.PriceAtTime})
+				total += item.PriceAtTime * float64(item.Quantity)
+				if item.BundleID != bundle.ID || item.BundleLine != 1 {
+					t.Errorf("item %s bundle = %s line %d, want %s line 1", item.ProductID, item.BundleID, item.BundleLine, bundle.ID)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expandBundle() = %+v, want %+v", got, tt.want)
+			}
+			if roundMoney(total) != roundMoney(bundle.Price*float64(tt.quantity)) {
+				t.Errorf("components add up to %v, want %v", total, bundle.Price*float64(tt.quantity))
+			}
+		})
+	}
+}
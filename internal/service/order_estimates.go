@@ -0,0 +1,173 @@
+package service
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"frappuccino/internal/repositories"
+	"frappuccino/models"
+)
+
+const (
+	// prepHistoryDays is how far back prep times are measured for ready time estimates
+	prepHistoryDays = 30
+	// defaultPrepSeconds is the prep time per unit assumed before there is any history
+	defaultPrepSeconds = 120
+	// prepTimesTTL is how long measured prep times are reused before they are measured again
+	prepTimesTTL = time.Minute
+)
+
+// prepTimes are the prep times per unit by menu item. Items without history take the average of
+// all measured orders, or defaultPrepSeconds when nothing has been measured yet.
+type prepTimes struct {
+	perUnit  map[string]time.Duration
+	fallback time.Duration
+}
+
+// loadPrepTimes measures the prep times of the last prepHistoryDays days
+func loadPrepTimes(orderRepo repositories.OrderRepositoryInterface, now time.Time) (prepTimes, error) {
+	measured, err := orderRepo.GetItemPrepTimes(now.AddDate(0, 0, -prepHistoryDays))
+	if err != nil {
+		return prepTimes{}, err
+	}
+
+	times := prepTimes{perUnit: make(map[string]time.Duration, len(measured))}
+	var weighted float64
+	var orders int
+	for _, prep := range measured {
+		times.perUnit[prep.MenuItemID] = time.Duration(prep.SecondsPerUnit * float64(time.Second))
+		weighted += prep.SecondsPerUnit * float64(prep.Orders)
+		orders += prep.Orders
+	}
+	if orders > 0 {
+		times.fallback = time.Duration(weighted / float64(orders) * float64(time.Second))
+	}
+	return times, nil
+}
+
+// prepTimeCache keeps the measured prep times for prepTimesTTL, so estimates made on every read and
+// event don't aggregate the status history each time
+type prepTimeCache struct {
+	orderRepo repositories.OrderRepositoryInterface
+	mu        sync.Mutex
+	times     prepTimes
+	loadedAt  time.Time
+}
+
+func newPrepTimeCache(orderRepo repositories.OrderRepositoryInterface) *prepTimeCache {
+	return &prepTimeCache{orderRepo: orderRepo}
+}
+
+// get returns the cached prep times, measuring them again once they are older than prepTimesTTL.
+// On failure the previous times are returned with the error.
+func (c *prepTimeCache) get(now time.Time) (prepTimes, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.loadedAt.IsZero() && now.Sub(c.loadedAt) < prepTimesTTL {
+		return c.times, nil
+	}
+	times, err := loadPrepTimes(c.orderRepo, now)
+	if err != nil {
+		return c.times, err
+	}
+	c.times, c.loadedAt = times, now
+	return times, nil
+}
+
+func (p prepTimes) unit(menuItemID string) time.Duration {
+	if prep, ok := p.perUnit[menuItemID]; ok {
+		return prep
+	}
+	if p.fallback > 0 {
+		return p.fallback
+	}
+	return defaultPrepSeconds * time.Second
+}
+
+// estimateReadyTimes estimates when the open orders will be ready. Each station makes its items one after
+// the other, oldest order first, and an order is ready once its last station is done with it.
+// Items in progress count only the time they have left.
+func estimateReadyTimes(orders []*models.Order, prep prepTimes, now time.Time) map[string]time.Time {
+	queue := make([]*models.Order, 0, len(orders))
+	for _, order := range orders {
+		if openOrder(order.Status) {
+			queue = append(queue, order)
+		}
+	}
+	sort.SliceStable(queue, func(i, j int) bool { return queue[i].CreatedAt.Before(queue[j].CreatedAt) })
+
+	busyUntil := make(map[models.Station]time.Time)
+	estimates := make(map[string]time.Time, len(queue))
+	for _, order := range queue {
+		ready := now
+		for _, item := range order.Items {
+			if item.PrepStatus == models.PrepDone {
+				continue
+			}
+
+			work := prep.unit(item.MenuItemID) * time.Duration(item.Quantity)
+			if item.PrepStatus == models.PrepInProgress && item.PrepStartedAt != nil {
+				work -= now.Sub(*item.PrepStartedAt)
+				if work < 0 {
+					work = 0
+				}
+			}
+
+			start := busyUntil[item.Station]
+			if start.Before(now) {
+				start = now
+			}
+			busyUntil[item.Station] = start.Add(work)
+			if busyUntil[item.Station].After(ready) {
+				ready = busyUntil[item.Station]
+			}
+		}
+		estimates[order.ID] = ready.Truncate(time.Second)
+	}
+	return estimates
+}
+
+// openOrder reports whether an order in status is still to be made
+func openOrder(status string) bool {
+	return status == models.OrderPending || status == models.OrderPreparing
+}
+
+// setEstimatedReadyAt estimates when the given orders will be ready from the queue of open orders.
+// Estimates are best effort: when they can't be made the orders are returned without one.
+func (s *OrderService) setEstimatedReadyAt(orders ...*models.Order) {
+	var open bool
+	for _, order := range orders {
+		order.EstimatedReadyAt = nil
+		open = open || openOrder(order.Status)
+	}
+	if !open {
+		return
+	}
+
+	queue, err := s.orderRepo.GetByStatus(models.OrderPending, models.OrderPreparing)
+	if err != nil {
+		s.logger.Warn("Failed to fetch order queue for ready estimate", "error", err)
+		return
+	}
+	s.applyReadyEstimates(queue, orders)
+}
+
+// applyReadyEstimates sets the estimated ready time of the open orders among orders from a snapshot of the
+// queue, which must hold every open order
+func (s *OrderService) applyReadyEstimates(queue, orders []*models.Order) {
+	now := time.Now()
+	prep, err := s.prepTimes.get(now)
+	if err != nil {
+		s.logger.Warn("Failed to load prep times, using previous or default times", "error", err)
+	}
+
+	estimates := estimateReadyTimes(queue, prep, now)
+	for _, order := range orders {
+		order.EstimatedReadyAt = nil
+		if estimate, ok := estimates[order.ID]; ok && openOrder(order.Status) {
+			order.EstimatedReadyAt = &estimate
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"frappuccino/models"
+)
+
+func TestPrepTimesUnit(t *testing.T) {
+	tests := []struct {
+		name   string
+		times  prepTimes
+		itemID string
+		want   time.Duration
+	}{
+		{name: "measured item", times: prepTimes{perUnit: map[string]time.Duration{"latte": time.Minute}, fallback: 2 * time.Minute}, itemID: "latte", want: time.Minute},
+		{name: "unmeasured item takes fallback", times: prepTimes{perUnit: map[string]time.Duration{"latte": time.Minute}, fallback: 2 * time.Minute}, itemID: "mocha", want: 2 * time.Minute},
+		{name: "no history takes default", times: prepTimes{}, itemID: "mocha", want: defaultPrepSeconds * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.times.unit(tt.itemID); got != tt.want {
+				t.Errorf("unit(%q) = %v, want %v", tt.itemID, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEstimateReadyTimes(t *testing.T) {
+	now := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	prep := prepTimes{perUnit: map[string]time.Duration{"latte": time.Minute, "croissant": 30 * time.Second}}
+
+	item := func(menuItemID string, station models.Station, quantity int, status string, startedAgo time.Duration) models.OrderItem {
+		line := models.OrderItem{MenuItemID: menuItemID, Quantity: quantity, Station: station, PrepStatus: status}
+		if startedAgo > 0 {
+			started := now.Add(-startedAgo)
+			line.PrepStartedAt = &started
+		}
+		return line
+	}
+	order := func(id, status string, placedAgo time.Duration, items ...models.OrderItem) *models.Order {
+		return &models.Order{ID: id, Status: status, CreatedAt: now.Add(-placedAgo), Items: items}
+	}
+	latte := item("latte", models.StationBar, 1, models.PrepQueued, 0)
+	croissant := item("croissant", models.StationPastryCase, 1, models.PrepQueued, 0)
+
+	tests := []struct {
+		name   string
+		orders []*models.Order
+		want   map[string]time.Duration // From now
+	}{
+		{
+			name:   "slowest station decides",
+			orders: []*models.Order{order("a", models.OrderPending, 0, item("latte", models.StationBar, 2, models.PrepQueued, 0), croissant)},
+			want:   map[string]time.Duration{"a": 2 * time.Minute},
+		},
+		{
+			name:   "station works oldest order first",
+			orders: []*models.Order{order("newer", models.OrderPending, time.Minute, latte), order("older", models.OrderPending, 2*time.Minute, latte)},
+			want:   map[string]time.Duration{"older": time.Minute, "newer": 2 * time.Minute},
+		},
+		{
+			name:   "stations work in parallel",
+			orders: []*models.Order{order("a", models.OrderPending, 2*time.Minute, latte), order("b", models.OrderPending, time.Minute, croissant)},
+			want:   map[string]time.Duration{"a": time.Minute, "b": 30 * time.Second},
+		},
+		{
+			name:   "done items take no time",
+			orders: []*models.Order{order("a", models.OrderPreparing, 0, item("latte", models.StationBar, 1, models.PrepDone, 0))},
+			want:   map[string]time.Duration{"a": 0},
+		},
+		{
+			name:   "items in progress count their remaining time",
+			orders: []*models.Order{order("a", models.OrderPreparing, 0, item("latte", models.StationBar, 1, models.PrepInProgress, 20*time.Second))},
+			want:   map[string]time.Duration{"a": 40 * time.Second},
+		},
+		{
+			name:   "overdue items count no time",
+			orders: []*models.Order{order("a", models.OrderPreparing, 0, item("latte", models.StationBar, 1, models.PrepInProgress, 5*time.Minute))},
+			want:   map[string]time.Duration{"a": 0},
+		},
+		{
+			name: "closed orders are not queued",
+			orders: []*models.Order{
+				order("ready", models.OrderReady, 3*time.Minute, latte),
+				order("closed", models.OrderClosed, 2*time.Minute, latte),
+				order("a", models.OrderPending, time.Minute, latte),
+			},
+			want: map[string]time.Duration{"a": time.Minute},
+		},
+		{
+			name:   "unmeasured items take the default",
+			orders: []*models.Order{order("a", models.OrderPending, 0, item("mocha", models.StationBar, 1, models.PrepQueued, 0))},
+			want:   map[string]time.Duration{"a": defaultPrepSeconds * time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := estimateReadyTimes(tt.orders, prep, now)
+			if len(got) != len(tt.want) {
+				t.Fatalf("estimateReadyTimes() = %v, want estimates for %v", got, tt.want)
+			}
+			for id, after := range tt.want {
+				if estimate, ok := got[id]; !ok || !estimate.Equal(now.Add(after)) {
+					t.Errorf("order %s ready at %v, want %v", id, estimate, now.Add(after))
+				}
+			}
+		})
+	}
+}
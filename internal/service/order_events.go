@@ -0,0 +1,59 @@
+package service
+
+import (
+	"time"
+
+	"frappuccino/models"
+)
+
+// publishOrderEvent tells subscribers what happened to an order, with its ready time estimated as of the change.
+// Deleted orders are sent without their details.
+func (s *OrderService) publishOrderEvent(eventType string, order *models.Order, previousStatus string) {
+	if s.events == nil {
+		return
+	}
+
+	event := models.OrderEvent{OrderID: order.ID, Status: order.Status, PreviousStatus: previousStatus}
+	if eventType != models.OrderEventDeleted {
+		if order.EstimatedReadyAt == nil {
+			s.setEstimatedReadyAt(order)
+		}
+		kds := kdsOrder(order, s.itemNames(order.Items), time.Now())
+		kds.EstimatedReadyAt = order.EstimatedReadyAt
+		event.Order = &kds
+	}
+	s.events.Publish(eventType, event)
+}
+
+// orderUpdateEvent is what an update amounts to: a cancellation, a status change or an edit of the order
+func orderUpdateEvent(previousStatus, status string) string {
+	switch {
+	case status == previousStatus:
+		return models.OrderEventUpdated
+	case status == models.OrderCancelled:
+		return models.OrderEventCancelled
+	default:
+		return models.OrderEventStatusChanged
+	}
+}
+
+// itemNames names the menu items and bundles of order lines. Items that can't be read are left out
+// and shown by their ID, since events are sent after the change has been made.
+func (s *OrderService) itemNames(lines []models.OrderItem) map[string]string {
+	names := make(map[string]string)
+	for _, line := range lines {
+		for _, id := range []string{line.MenuItemID, line.BundleID} {
+			if _, ok := names[id]; ok || id == "" {
+				continue
+			}
+			menuItem, err := s.menuRepo.GetByID(id)
+			if err != nil {
+				s.logger.Warn("Failed to name order item for event", "menu_item_id", id, "error", err)
+				names[id] = id
+				continue
+			}
+			names[id] = menuItem.Name
+		}
+	}
+	return names
+}
@@ -9,29 +9,48 @@ package service
 // 5. Implement database-based inventory tracking and order fulfillment
 
 import (
+	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"frappuccino/internal/repositories"
 	"frappuccino/models"
+	"frappuccino/pkg/events"
 	"frappuccino/pkg/logger"
 )
 
 // Define request/response structs
 type CreateOrderRequest struct {
-	CustomerName string                   `json:"customer_name"`
-	Items        []CreateOrderItemRequest `json:"items"`
+	CustomerName string                     `json:"customer_name"` // Defaults to the customer's name when customer_id is set
+	CustomerID   string                     `json:"customer_id"`
+	OrderType    string                     `json:"order_type"` // dine_in (default) or takeaway
+	Items        []CreateOrderItemRequest   `json:"items"`
+	PromoCodes   []string                   `json:"promo_codes,omitempty"`
+	Redeem       []models.LoyaltyRedemption `json:"redeem,omitempty"` // Loyalty to spend, requires customer_id
+
+	SpecialInstructions json.RawMessage `json:"special_instructions,omitempty"` // JSON object; "allergies" are checked against the items
+	AllergenOverride    bool            `json:"allergen_override,omitempty"`    // Accept items containing declared allergens
+	AllergenOverrideBy  string          `json:"allergen_override_by,omitempty"` // Who accepted them, required with allergen_override
 }
 
 type CreateOrderItemRequest struct {
-	ProductID string `json:"product_id"`
-	Quantity  int    `json:"quantity"`
+	ProductID string            `json:"product_id"`
+	Quantity  int               `json:"quantity"`
+	Choices   map[string]string `json:"choices,omitempty"` // Bundle slot name to chosen menu item ID
+
+	Customizations json.RawMessage `json:"customizations,omitempty"` // JSON object; a bundle's apply to each of its components
 }
 
 type UpdateOrderRequest struct {
 	CustomerName string                   `json:"customer_name"`
+	CustomerID   string                   `json:"customer_id"` // Unchanged when empty
+	OrderType    string                   `json:"order_type"`  // Unchanged when empty
 	Items        []CreateOrderItemRequest `json:"items"`
 	Status       string                   `json:"status"`
+
+	AllergenOverride   bool   `json:"allergen_override,omitempty"`    // Accept added items containing declared allergens
+	AllergenOverrideBy string `json:"allergen_override_by,omitempty"` // Who accepted them, required with allergen_override
 }
 
 // OrderService interface
@@ -42,25 +61,49 @@ type OrderServiceInterface interface {
 	UpdateOrder(id string, req UpdateOrderRequest) error
 	DeleteOrder(id string) error
 	CloseOrder(id string) error
+	GetOrderTickets(id string) (*models.OrderTickets, error)
+	UpdateItemStatus(orderID, itemID, status string) (*models.OrderTickets, error)
 	GetNumberOfOrderedItems(startDate, endDate string) (map[string]int, error)
 	BatchProcessOrders(req models.BatchOrderRequest) (*models.BatchProcessResponse, error)
 }
 
 // OrderService struct
 type OrderService struct {
-	orderRepo     repositories.OrderRepositoryInterface
-	menuRepo      repositories.MenuRepositoryInterface
-	inventoryRepo repositories.InventoryRepositoryInterface
-	logger        *logger.Logger
+	orderRepo       repositories.OrderRepositoryInterface
+	menuRepo        repositories.MenuRepositoryInterface
+	inventoryRepo   repositories.InventoryRepositoryInterface
+	reservationRepo repositories.ReservationRepositoryInterface
+	pricingRepo     repositories.PricingRuleRepositoryInterface
+	customerRepo    repositories.CustomerRepositoryInterface
+	taxRepo         repositories.TaxRateRepositoryInterface
+	paymentRepo     repositories.PaymentRepositoryInterface
+	loyaltyRepo     repositories.LoyaltyRepositoryInterface
+	alertService    AlertServiceInterface
+	events          *events.Bus    // Order events are published here for the kitchen display and other subscribers
+	prepTimes       *prepTimeCache // Measured prep times for ready time estimates
+	location        *time.Location // Shop timezone menu schedules are evaluated in
+	taxInclusive    bool           // Whether menu prices include tax
+	logger          *logger.Logger
 }
 
 // NewOrderService creates a new OrderService with the given repositories and logger
-func NewOrderService(orderRepo repositories.OrderRepositoryInterface, menuRepo repositories.MenuRepositoryInterface, inventoryRepo repositories.InventoryRepositoryInterface, logger *logger.Logger) *OrderService {
+func NewOrderService(orderRepo repositories.OrderRepositoryInterface, menuRepo repositories.MenuRepositoryInterface, inventoryRepo repositories.InventoryRepositoryInterface, reservationRepo repositories.ReservationRepositoryInterface, pricingRepo repositories.PricingRuleRepositoryInterface, customerRepo repositories.CustomerRepositoryInterface, taxRepo repositories.TaxRateRepositoryInterface, paymentRepo repositories.PaymentRepositoryInterface, loyaltyRepo repositories.LoyaltyRepositoryInterface, alertService AlertServiceInterface, eventBus *events.Bus, location *time.Location, taxInclusive bool, logger *logger.Logger) *OrderService {
 	return &OrderService{
-		orderRepo:     orderRepo,
-		menuRepo:      menuRepo,
-		inventoryRepo: inventoryRepo,
-		logger:        logger.WithComponent("order_service"),
+		orderRepo:       orderRepo,
+		menuRepo:        menuRepo,
+		inventoryRepo:   inventoryRepo,
+		reservationRepo: reservationRepo,
+		pricingRepo:     pricingRepo,
+		customerRepo:    customerRepo,
+		taxRepo:         taxRepo,
+		paymentRepo:     paymentRepo,
+		loyaltyRepo:     loyaltyRepo,
+		alertService:    alertService,
+		events:          eventBus,
+		prepTimes:       newPrepTimeCache(orderRepo),
+		location:        location,
+		taxInclusive:    taxInclusive,
+		logger:          logger.WithComponent("order_service"),
 	}
 }
 
@@ -68,60 +111,114 @@ func NewOrderService(orderRepo repositories.OrderRepositoryInterface, menuRepo r
 func (s *OrderService) CreateOrder(req CreateOrderRequest) (*models.Order, error) {
 	s.logger.Info("Creating new order", "customer", req.CustomerName)
 
+	customerName, err := s.resolveCustomer(req.CustomerID, req.CustomerName)
+	if err != nil {
+		s.logger.Warn("Create failed: invalid customer", "customer_id", req.CustomerID, "error", err)
+		return nil, err
+	}
+	req.CustomerName = customerName
+
 	if err := s.validateOrderData(req); err != nil {
 		s.logger.Warn("Create failed: invalid data", "error", err)
 		return nil, err
 	}
+	orderType, err := validateOrderType(req.OrderType)
+	if err != nil {
+		s.logger.Warn("Create failed: invalid order type", "error", err)
+		return nil, err
+	}
 
-	// Check inventory availability before creating order
-	if err := s.checkInventoryAvailability(req.Items); err != nil {
-		s.logger.Warn("Create failed: insufficient inventory", "error", err)
+	lines, subtotal, err := s.buildOrderItems(req.Items)
+	if err != nil {
+		s.logger.Warn("Create failed: invalid items", "error", err)
 		return nil, err
 	}
+	// Stock and sales follow what is actually made, the bundle components rather than the bundles
+	items := orderItemRequests(lines)
 
-	totalAmount, err := s.calculateOrderTotal(req.Items)
+	allergenWarnings, err := s.checkAllergens(req.CustomerID, req.SpecialInstructions, lines, req.AllergenOverride, req.AllergenOverrideBy)
 	if err != nil {
-		s.logger.Error("Failed to calculate order total", "error", err)
-		return nil, fmt.Errorf("failed to calculate order total: %v", err)
+		s.logger.Warn("Create failed: allergen check", "customer_id", req.CustomerID, "error", err)
+		return nil, err
+	}
+	if len(allergenWarnings) > 0 {
+		s.logger.Warn("Allergen warnings overridden", "customer", req.CustomerName, "override_by", req.AllergenOverrideBy, "items", len(allergenWarnings))
 	}
 
-	order := &models.Order{
-		CustomerName: req.CustomerName,
-		Status:       "pending",
-		TotalAmount:  totalAmount,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
-		Items:        make([]models.OrderItem, len(req.Items)),
+	now := time.Now()
+	if err := s.checkMenuSchedules(req.Items, now); err != nil {
+		s.logger.Warn("Create failed: item not on sale", "error", err)
+		return nil, err
+	}
+	if err := s.checkBundleSchedules(lines, now); err != nil {
+		s.logger.Warn("Create failed: bundle item not on sale", "error", err)
+		return nil, err
 	}
 
-	for i, item := range req.Items {
-		menuItem, err := s.menuRepo.GetByID(item.ProductID)
-		if err != nil {
-			s.logger.Error("Failed to get menu item", "product_id", item.ProductID, "error", err)
-			return nil, fmt.Errorf("menu item %s not found", item.ProductID)
-		}
+	discounts, discountAmount, err := s.applyPricingRules(lines, req.PromoCodes, now, nil)
+	if err != nil {
+		s.logger.Warn("Create failed: pricing rules", "error", err)
+		return nil, err
+	}
+	redemptions, redeemedAmount, err := s.applyLoyaltyRedemptions(req.CustomerID, lines, req.Redeem)
+	if err != nil {
+		s.logger.Warn("Create failed: loyalty redemption", "customer_id", req.CustomerID, "error", err)
+		return nil, err
+	}
+	discounts = append(discounts, redemptions...)
+	discountAmount = roundMoney(discountAmount + redeemedAmount)
+	subtotal = roundMoney(subtotal - discountAmount)
 
-		order.Items[i] = models.OrderItem{
-			MenuItemID:  item.ProductID,
-			ProductID:   item.ProductID,
-			Quantity:    item.Quantity,
-			PriceAtTime: menuItem.Price,
-		}
+	taxes, taxAmount, err := s.calculateTaxes(lines, orderType, s.taxInclusive)
+	if err != nil {
+		s.logger.Error("Create failed: taxes", "error", err)
+		return nil, err
 	}
+	totalAmount := orderTotal(subtotal, taxAmount, s.taxInclusive)
 
-	// Consume inventory for the order
-	if err := s.consumeInventory(req.Items); err != nil {
-		s.logger.Error("Failed to consume inventory", "error", err)
+	// Check inventory availability before creating order
+	if err := s.checkInventoryAvailability(items); err != nil {
+		s.logger.Warn("Create failed: insufficient inventory", "error", err)
 		return nil, err
 	}
 
+	order := &models.Order{
+		CustomerName:        req.CustomerName,
+		CustomerID:          req.CustomerID,
+		Status:              models.OrderPending,
+		OrderType:           orderType,
+		Subtotal:            subtotal,
+		DiscountAmount:      discountAmount,
+		TaxAmount:           taxAmount,
+		TaxInclusive:        s.taxInclusive,
+		TotalAmount:         totalAmount,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+		Items:               lines,
+		Discounts:           discounts,
+		Taxes:               taxes,
+		SpecialInstructions: req.SpecialInstructions,
+		AllergenWarnings:    allergenWarnings,
+		AllergenOverrideBy:  strings.TrimSpace(req.AllergenOverrideBy),
+	}
+
 	if err := s.orderRepo.Add(order); err != nil {
-		// Rollback inventory consumption if order creation fails
-		s.logger.Error("Failed to add order, rolling back inventory", "error", err)
-		s.restoreInventory(req.Items)
+		s.logger.Error("Failed to add order", "error", err)
+		return nil, err
+	}
+
+	// Reserve stock once the order exists; it is consumed when the order moves to preparing or closed
+	if err := s.reserveInventory(order.ID, items); err != nil {
+		s.logger.Warn("Failed to reserve inventory, removing order", "order_id", order.ID, "error", err)
+		if delErr := s.orderRepo.Delete(order.ID); delErr != nil {
+			s.logger.Error("Failed to remove order after inventory failure", "order_id", order.ID, "error", delErr)
+		}
 		return nil, err
 	}
 
+	s.setEstimatedReadyAt(order)
+	s.publishOrderEvent(models.OrderEventCreated, order, "")
+
 	s.logger.Info("Order created", "order_id", order.ID, "total_amount", totalAmount)
 	return order, nil
 }
@@ -135,6 +232,8 @@ func (s *OrderService) GetAllOrders() ([]*models.Order, error) {
 		s.logger.Error("Failed to fetch orders from repository", "error", err)
 		return nil, err
 	}
+	// Every open order is in the list, so it is its own queue
+	s.applyReadyEstimates(orders, orders)
 
 	s.logger.Info("Fetched orders", "count", len(orders))
 	return orders, nil
@@ -154,12 +253,15 @@ func (s *OrderService) GetOrderByID(id string) (*models.Order, error) {
 		s.logger.Warn("Order not found", "order_id", id, "error", err)
 		return nil, err
 	}
+	s.setEstimatedReadyAt(order)
 
 	s.logger.Info("Fetched order", "order_id", id)
 	return order, nil
 }
 
-// UpdateOrder updates an existing order
+// UpdateOrder updates an existing order.
+// A pending order keeps a reservation for its items; moving it to preparing, ready or closed consumes
+// the reservation and cancelling releases it. Orders past pending have consumed stock already.
 func (s *OrderService) UpdateOrder(id string, req UpdateOrderRequest) error {
 	s.logger.Info("Updating order", "order_id", id, "customer", req.CustomerName)
 
@@ -168,79 +270,200 @@ func (s *OrderService) UpdateOrder(id string, req UpdateOrderRequest) error {
 		return fmt.Errorf("order ID is required")
 	}
 
+	customerName, err := s.resolveCustomer(req.CustomerID, req.CustomerName)
+	if err != nil {
+		s.logger.Warn("Update failed: invalid customer", "order_id", id, "customer_id", req.CustomerID, "error", err)
+		return err
+	}
+	req.CustomerName = customerName
+
 	if err := s.validateUpdateOrderData(req); err != nil {
 		s.logger.Warn("Update failed: invalid data", "order_id", id, "error", err)
 		return err
 	}
 
-	// Get the existing order to restore its inventory first
 	existingOrder, err := s.orderRepo.GetByID(id)
 	if err != nil {
 		s.logger.Warn("Order not found for update", "order_id", id, "error", err)
 		return err
 	}
 
-	if existingOrder.Status == "closed" {
-		s.logger.Warn("Attempted to update a closed order", "order_id", id)
-		return fmt.Errorf("cannot update closed order")
+	if err := validateStatusTransition(existingOrder.Status, req.Status); err != nil {
+		s.logger.Warn("Attempted invalid order status change", "order_id", id, "from", existingOrder.Status, "to", req.Status)
+		return err
 	}
 
-	// Convert existing order items to request format for inventory restoration
-	existingItems := make([]CreateOrderItemRequest, len(existingOrder.Items))
-	for i, item := range existingOrder.Items {
-		existingItems[i] = CreateOrderItemRequest{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
-		}
+	customerID := req.CustomerID
+	if customerID == "" {
+		customerID = existingOrder.CustomerID
 	}
+	if customerID != existingOrder.CustomerID && hasLoyaltyRedemptions(existingOrder) {
+		s.logger.Warn("Attempted to change the customer of an order with loyalty redemptions", "order_id", id)
+		return fmt.Errorf("cannot change the customer of an order with loyalty redemptions")
+	}
+
+	reserved := existingOrder.Status == models.OrderPending
 
-	// Restore inventory from the existing order
-	if err := s.restoreInventory(existingItems); err != nil {
-		s.logger.Error("Failed to restore inventory from existing order", "order_id", id, "error", err)
+	lines, subtotal, err := s.buildOrderItems(req.Items)
+	if err != nil {
+		s.logger.Warn("Update failed: invalid items", "order_id", id, "error", err)
 		return err
 	}
+	carryPrepStatus(existingOrder.Items, lines)
+	items := orderItemRequests(lines)
 
-	// Check inventory availability for the new order items
-	if err := s.checkInventoryAvailability(req.Items); err != nil {
-		s.logger.Warn("Update failed: insufficient inventory", "order_id", id, "error", err)
-		// Re-consume the original inventory since update failed
-		s.consumeInventory(existingItems)
+	// Items the order already had were checked when they were added, unless the order changes customer
+	checked := lines
+	if customerID == existingOrder.CustomerID {
+		checked = addedOrderItems(existingOrder.Items, lines)
+	}
+	allergenWarnings, err := s.checkAllergens(customerID, existingOrder.SpecialInstructions, checked, req.AllergenOverride, req.AllergenOverrideBy)
+	if err != nil {
+		s.logger.Warn("Update failed: allergen check", "order_id", id, "customer_id", customerID, "error", err)
 		return err
 	}
+	if len(allergenWarnings) > 0 {
+		s.logger.Warn("Allergen warnings overridden", "order_id", id, "override_by", req.AllergenOverrideBy, "items", len(allergenWarnings))
+	}
 
-	// Consume inventory for the new order items
-	if err := s.consumeInventory(req.Items); err != nil {
-		s.logger.Error("Failed to consume inventory for updated order", "order_id", id, "error", err)
-		// Re-consume the original inventory since update failed
-		s.consumeInventory(existingItems)
+	// The order keeps the deals it was placed with, re-evaluated against the new items
+	discounts, discountAmount, err := s.reapplyPricingRules(existingOrder, lines)
+	if err != nil {
+		s.logger.Warn("Update failed: pricing rules", "order_id", id, "error", err)
+		return err
+	}
+	redemptions, redeemedAmount, err := s.reapplyLoyaltyRedemptions(existingOrder, lines)
+	if err != nil {
+		s.logger.Warn("Update failed: loyalty redemption", "order_id", id, "error", err)
+		return err
+	}
+	discounts = append(discounts, redemptions...)
+	discountAmount = roundMoney(discountAmount + redeemedAmount)
+	subtotal = roundMoney(subtotal - discountAmount)
+
+	// The order keeps the pricing mode it was placed under
+	orderType := existingOrder.OrderType
+	if req.OrderType != "" {
+		if orderType, err = validateOrderType(req.OrderType); err != nil {
+			s.logger.Warn("Update failed: invalid order type", "order_id", id, "error", err)
+			return err
+		}
+	}
+	taxes, taxAmount, err := s.calculateTaxes(lines, orderType, existingOrder.TaxInclusive)
+	if err != nil {
+		s.logger.Error("Update failed: taxes", "order_id", id, "error", err)
 		return err
 	}
+	totalAmount := orderTotal(subtotal, taxAmount, existingOrder.TaxInclusive)
 
-	order := &models.Order{
-		ID:           id,
-		CustomerName: req.CustomerName,
-		Items:        make([]models.OrderItem, len(req.Items)),
-		Status:       req.Status,
-		CreatedAt:    existingOrder.CreatedAt, // Preserve original creation time
+	if err := s.checkPayments(existingOrder, req.Status, totalAmount); err != nil {
+		s.logger.Warn("Update failed: payments", "order_id", id, "error", err)
+		return err
+	}
+
+	existingItems := orderItemRequests(existingOrder.Items)
+
+	// rollback puts the order's stock back the way it was before the update
+	var consumed []*models.InventoryTransaction
+	rollback := func() {
+		if len(consumed) > 0 {
+			if err := s.returnConsumedInventory(id, consumed); err != nil {
+				s.logger.Error("Failed to return consumed inventory", "order_id", id, "error", err)
+			}
+		}
+		if reserved {
+			if err := s.reserveInventory(id, existingItems); err != nil {
+				s.logger.Error("Failed to restore reservation", "order_id", id, "error", err)
+			}
+		} else if req.Status != models.OrderCancelled {
+			s.restoreInventory(id, items)
+			s.consumeInventory(id, existingItems)
+		}
 	}
 
-	// Convert request items to order items
-	for i, item := range req.Items {
-		order.Items[i] = models.OrderItem{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
+	switch {
+	case reserved && req.Status == models.OrderCancelled:
+		if err := s.reservationRepo.Release(id); err != nil {
+			s.logger.Error("Failed to release reservation", "order_id", id, "error", err)
+			return err
+		}
+	case reserved:
+		// Replacing the reservation only needs stock for the difference
+		if err := s.reserveInventory(id, items); err != nil {
+			s.logger.Warn("Update failed: insufficient inventory", "order_id", id, "error", err)
+			return err
+		}
+		if consumesReservation(req.Status) {
+			consumed, err = s.reservationRepo.Consume(id)
+			if err != nil {
+				s.logger.Warn("Failed to consume reservation", "order_id", id, "error", err)
+				consumed = nil
+				rollback()
+				return fmt.Errorf("failed to consume inventory: %v", err)
+			}
+		}
+	case req.Status != models.OrderCancelled:
+		// Stock was consumed when the order left pending; swap the old items for the new ones
+		if err := s.restoreInventory(id, existingItems); err != nil {
+			s.logger.Error("Failed to restore inventory from existing order", "order_id", id, "error", err)
+			return err
+		}
+
+		if err := s.checkInventoryAvailability(items); err != nil {
+			s.logger.Warn("Update failed: insufficient inventory", "order_id", id, "error", err)
+			s.consumeInventory(id, existingItems)
+			return err
+		}
+
+		if err := s.consumeInventory(id, items); err != nil {
+			s.logger.Error("Failed to consume inventory for updated order", "order_id", id, "error", err)
+			s.consumeInventory(id, existingItems)
+			return err
 		}
 	}
 
+	order := &models.Order{
+		ID:             id,
+		CustomerName:   req.CustomerName,
+		CustomerID:     customerID,
+		Items:          lines, // Priced at the current menu
+		Status:         req.Status,
+		OrderType:      orderType,
+		Subtotal:       subtotal,
+		DiscountAmount: discountAmount,
+		TaxAmount:      taxAmount,
+		TaxInclusive:   existingOrder.TaxInclusive,
+		TotalAmount:    totalAmount,
+		Discounts:      discounts,
+		Taxes:          taxes,
+		CreatedAt:      existingOrder.CreatedAt, // Preserve original creation time
+
+		SpecialInstructions: existingOrder.SpecialInstructions,
+		AllergenWarnings:    allergenWarnings,
+		AllergenOverrideBy:  strings.TrimSpace(req.AllergenOverrideBy),
+	}
+
 	if err := s.orderRepo.Update(id, order); err != nil {
 		s.logger.Error("Failed to update order in repository", "order_id", id, "error", err)
-		// Rollback inventory changes
-		s.restoreInventory(req.Items)
-		s.consumeInventory(existingItems)
+		rollback()
 		return err
 	}
 
-	s.logger.Info("Order updated with inventory management", "order_id", id)
+	if len(consumed) > 0 {
+		s.alertService.CheckStockLevels(transactionIngredientIDs(consumed))
+	}
+	if reserved {
+		// Ingredients dropped from the reservation are available again
+		s.checkOrderStock(existingItems)
+	}
+	if req.Status == models.OrderClosed {
+		if err := s.accrueLoyalty(order); err != nil {
+			s.logger.Error("Failed to accrue loyalty", "order_id", id, "error", err)
+		}
+	}
+	s.publishOrderEvent(orderUpdateEvent(existingOrder.Status, req.Status), order, existingOrder.Status)
+
+	s.logger.Info("Order updated with inventory management", "order_id", id, "status", req.Status)
 	return nil
 }
 
@@ -260,17 +483,35 @@ func (s *OrderService) DeleteOrder(id string) error {
 		return err
 	}
 
-	// Convert order items to request format for inventory restoration
-	items := make([]CreateOrderItemRequest, len(order.Items))
-	for i, item := range order.Items {
-		items[i] = CreateOrderItemRequest{
-			ProductID: item.ProductID,
-			Quantity:  item.Quantity,
+	// Payments and refunds are kept for the books, so a paid order can only be cancelled
+	payments, _, err := s.paymentRepo.GetByOrder(id)
+	if err != nil {
+		s.logger.Error("Failed to check order payments", "order_id", id, "error", err)
+		return err
+	}
+	if len(payments) > 0 {
+		s.logger.Warn("Attempted to delete an order with payments", "order_id", id)
+		return fmt.Errorf("cannot delete order with payments")
+	}
+
+	// A pending order's reservation is removed with it and a cancelled order holds no stock
+	if order.Status == models.OrderPending || order.Status == models.OrderCancelled {
+		if err := s.orderRepo.Delete(id); err != nil {
+			s.logger.Warn("Failed to delete order", "order_id", id, "error", err)
+			return err
 		}
+		if order.Status == models.OrderPending {
+			s.checkOrderStock(orderItemRequests(order.Items))
+		}
+		s.publishOrderEvent(models.OrderEventDeleted, order, "")
+		s.logger.Info("Order deleted", "order_id", id)
+		return nil
 	}
 
-	// Restore inventory before deleting order
-	if err := s.restoreInventory(items); err != nil {
+	items := orderItemRequests(order.Items)
+
+	// Restore consumed inventory before deleting order
+	if err := s.restoreInventory(id, items); err != nil {
 		s.logger.Error("Failed to restore inventory", "order_id", id, "error", err)
 		return err
 	}
@@ -278,15 +519,16 @@ func (s *OrderService) DeleteOrder(id string) error {
 	if err := s.orderRepo.Delete(id); err != nil {
 		s.logger.Warn("Failed to delete order", "order_id", id, "error", err)
 		// Try to re-consume inventory if delete fails
-		s.consumeInventory(items)
+		s.consumeInventory(id, items)
 		return err
 	}
 
+	s.publishOrderEvent(models.OrderEventDeleted, order, "")
 	s.logger.Info("Order deleted and inventory restored", "order_id", id)
 	return nil
 }
 
-// CloseOrder closes an order by setting status to closed
+// CloseOrder closes an order by setting status to closed, consuming its reservation if it is still pending
 func (s *OrderService) CloseOrder(id string) error {
 	s.logger.Info("Closing order", "order_id", id)
 
@@ -295,12 +537,56 @@ func (s *OrderService) CloseOrder(id string) error {
 		return fmt.Errorf("order ID is required")
 	}
 
+	order, err := s.orderRepo.GetByID(id)
+	if err != nil {
+		s.logger.Warn("Order not found for closing", "order_id", id, "error", err)
+		return err
+	}
+
+	if order.Status == models.OrderCancelled {
+		s.logger.Warn("Attempted to close a cancelled order", "order_id", id)
+		return fmt.Errorf("cannot close cancelled order")
+	}
+
+	if err := s.checkPayments(order, models.OrderClosed, order.TotalAmount); err != nil {
+		s.logger.Warn("Attempted to close an unpaid order", "order_id", id, "error", err)
+		return err
+	}
+
+	var consumed []*models.InventoryTransaction
+	if order.Status == models.OrderPending {
+		consumed, err = s.reservationRepo.Consume(id)
+		if err != nil {
+			s.logger.Warn("Failed to consume reservation", "order_id", id, "error", err)
+			return fmt.Errorf("failed to consume inventory: %v", err)
+		}
+	}
+
 	if err := s.orderRepo.CloseOrder(id); err != nil {
 		s.logger.Warn("Failed to close order", "order_id", id, "error", err)
+		if len(consumed) > 0 {
+			if err := s.returnConsumedInventory(id, consumed); err != nil {
+				s.logger.Error("Failed to return consumed inventory", "order_id", id, "error", err)
+			} else if err := s.reserveInventory(id, orderItemRequests(order.Items)); err != nil {
+				s.logger.Error("Failed to restore reservation", "order_id", id, "error", err)
+			}
+		}
 		return err
 	}
 
-	s.logger.Info("Order closed", "order_id", id)
+	if len(consumed) > 0 {
+		s.alertService.CheckStockLevels(transactionIngredientIDs(consumed))
+	}
+	// The order is closed either way; accrual can be retried since it only ever earns once
+	if err := s.accrueLoyalty(order); err != nil {
+		s.logger.Error("Failed to accrue loyalty", "order_id", id, "error", err)
+	}
+
+	previousStatus := order.Status
+	order.Status = models.OrderClosed
+	s.publishOrderEvent(models.OrderEventStatusChanged, order, previousStatus)
+
+	s.logger.Info("Order closed", "order_id", id, "consumed_ingredients", len(consumed))
 	return nil
 }
 
@@ -359,79 +645,115 @@ func (s *OrderService) BatchProcessOrders(req models.BatchOrderRequest) (*models
 	totalRevenue := 0.0
 
 	for i, orderReq := range req.Orders {
+		customerName, err := s.resolveCustomer(orderReq.CustomerID, orderReq.CustomerName)
+		if err != nil {
+			s.logger.Warn("Batch order customer invalid", "order_index", i, "error", err)
+			return nil, fmt.Errorf("order %d validation failed: %v", i, err)
+		}
+		orderReq.CustomerName = customerName
+
 		if err := s.validateBatchOrderRequest(orderReq, i); err != nil {
 			s.logger.Warn("Batch order validation failed", "order_index", i, "error", err)
 			return nil, fmt.Errorf("order %d validation failed: %v", i, err)
 		}
 
-		// Calculate order total and create Order struct
-		orderTotal, err := s.calculateBatchOrderTotal(orderReq.Items)
+		items := make([]CreateOrderItemRequest, len(orderReq.Items))
+		for j, item := range orderReq.Items {
+			items[j] = CreateOrderItemRequest{ProductID: item.MenuItemID, Quantity: item.Quantity, Choices: item.Choices, Customizations: item.Customizations}
+		}
+
+		lines, subtotal, err := s.buildOrderItems(items)
 		if err != nil {
-			s.logger.Error("Failed to calculate order total in batch", "order_index", i, "error", err)
-			return nil, fmt.Errorf("order %d total calculation failed: %v", i, err)
+			s.logger.Warn("Batch order items invalid", "order_index", i, "error", err)
+			return nil, fmt.Errorf("order %d validation failed: %v", i, err)
 		}
 
-		order := &models.Order{
-			CustomerName: orderReq.CustomerName,
-			Status:       "pending",
-			TotalAmount:  orderTotal,
-			Items:        make([]models.OrderItem, len(orderReq.Items)),
+		allergenWarnings, err := s.checkAllergens(orderReq.CustomerID, orderReq.SpecialInstructions, lines, orderReq.AllergenOverride, orderReq.AllergenOverrideBy)
+		if err != nil {
+			s.logger.Warn("Batch order allergen check failed", "order_index", i, "error", err)
+			return nil, fmt.Errorf("order %d validation failed: %v", i, err)
 		}
 
-		// Convert items
-		for j, item := range orderReq.Items {
-			menuItem, err := s.menuRepo.GetByID(item.MenuItemID)
-			if err != nil {
-				s.logger.Error("Menu item not found in batch processing", "menu_item_id", item.MenuItemID, "error", err)
-				return nil, fmt.Errorf("order %d: menu item %s not found", i, item.MenuItemID)
-			}
+		now := time.Now()
+		if err := s.checkMenuSchedules(items, now); err != nil {
+			s.logger.Warn("Batch order item not on sale", "order_index", i, "error", err)
+			return nil, fmt.Errorf("order %d validation failed: %v", i, err)
+		}
+		if err := s.checkBundleSchedules(lines, now); err != nil {
+			s.logger.Warn("Batch order bundle item not on sale", "order_index", i, "error", err)
+			return nil, fmt.Errorf("order %d validation failed: %v", i, err)
+		}
 
-			order.Items[j] = models.OrderItem{
-				MenuItemID:  item.MenuItemID,
-				ProductID:   item.MenuItemID,
-				Quantity:    item.Quantity,
-				PriceAtTime: menuItem.Price,
-			}
+		discounts, discountAmount, err := s.applyPricingRules(lines, orderReq.PromoCodes, now, nil)
+		if err != nil {
+			s.logger.Warn("Batch order pricing rules failed", "order_index", i, "error", err)
+			return nil, fmt.Errorf("order %d validation failed: %v", i, err)
+		}
+		redemptions, redeemedAmount, err := s.applyLoyaltyRedemptions(orderReq.CustomerID, lines, orderReq.Redeem)
+		if err != nil {
+			s.logger.Warn("Batch order loyalty redemption failed", "order_index", i, "error", err)
+			return nil, fmt.Errorf("order %d validation failed: %v", i, err)
+		}
+		discounts = append(discounts, redemptions...)
+		discountAmount = roundMoney(discountAmount + redeemedAmount)
+		subtotal = roundMoney(subtotal - discountAmount)
+
+		orderType, err := validateOrderType(orderReq.OrderType)
+		if err != nil {
+			s.logger.Warn("Batch order type invalid", "order_index", i, "error", err)
+			return nil, fmt.Errorf("order %d validation failed: %v", i, err)
+		}
+		taxes, taxAmount, err := s.calculateTaxes(lines, orderType, s.taxInclusive)
+		if err != nil {
+			s.logger.Error("Batch order taxes failed", "order_index", i, "error", err)
+			return nil, fmt.Errorf("order %d: %v", i, err)
+		}
+		total := orderTotal(subtotal, taxAmount, s.taxInclusive)
+
+		order := &models.Order{
+			CustomerName:   orderReq.CustomerName,
+			CustomerID:     orderReq.CustomerID,
+			Status:         models.OrderPending,
+			OrderType:      orderType,
+			Subtotal:       subtotal,
+			DiscountAmount: discountAmount,
+			TaxAmount:      taxAmount,
+			TaxInclusive:   s.taxInclusive,
+			TotalAmount:    total,
+			Items:          lines,
+			Discounts:      discounts,
+			Taxes:          taxes,
+
+			SpecialInstructions: orderReq.SpecialInstructions,
+			AllergenWarnings:    allergenWarnings,
+			AllergenOverrideBy:  strings.TrimSpace(orderReq.AllergenOverrideBy),
 		}
 
 		orders = append(orders, order)
-		totalRevenue += orderTotal
+		totalRevenue += total
 	}
 
 	// Check inventory availability for all orders
-	inventoryRequirements, err := s.orderRepo.GetInventoryRequirements(orders)
-	if err != nil {
-		s.logger.Error("Failed to calculate inventory requirements", "error", err)
-		return nil, fmt.Errorf("failed to calculate inventory requirements: %v", err)
+	inventoryRequirements := make(map[string]float64)
+	orderRequirements := make([]map[string]float64, len(orders))
+	for i, order := range orders {
+		quantities, _, err := s.orderIngredientQuantities(orderItemRequests(order.Items))
+		if err != nil {
+			s.logger.Error("Failed to calculate inventory requirements", "error", err)
+			return nil, fmt.Errorf("failed to calculate inventory requirements: %v", err)
+		}
+		orderRequirements[i] = quantities
+		for ingredientID, quantity := range quantities {
+			inventoryRequirements[ingredientID] += quantity
+		}
 	}
 
 	// Check if we have sufficient inventory
-	_, err = s.inventoryRepo.CheckInventoryAvailability(inventoryRequirements)
+	_, err := s.inventoryRepo.CheckInventoryAvailability(inventoryRequirements)
 	if err != nil {
 		s.logger.Warn("Insufficient inventory for batch orders", "error", err)
 
-		// Return response with all orders rejected due to insufficient inventory
-		response := &models.BatchProcessResponse{
-			ProcessedOrders: make([]models.BatchProcessResult, len(orders)),
-			Summary: models.BatchProcessSummary{
-				TotalOrders:      len(orders),
-				Accepted:         0,
-				Rejected:         len(orders),
-				TotalRevenue:     0,
-				InventoryUpdates: []models.InventoryUpdateResult{},
-			},
-		}
-
-		for i, order := range orders {
-			response.ProcessedOrders[i] = models.BatchProcessResult{
-				OrderID:      "",
-				CustomerName: order.CustomerName,
-				Status:       "rejected",
-				Reason:       "insufficient_inventory",
-			}
-		}
-
-		return response, nil
+		return rejectedBatchResponse(orders, "insufficient_inventory"), nil
 	}
 
 	// Process orders in the repository (with transaction)
@@ -439,37 +761,33 @@ func (s *OrderService) BatchProcessOrders(req models.BatchOrderRequest) (*models
 	if err != nil {
 		s.logger.Error("Failed to batch process orders in repository", "error", err)
 
-		// Return response with all orders rejected due to processing failure
-		response := &models.BatchProcessResponse{
-			ProcessedOrders: make([]models.BatchProcessResult, len(orders)),
-			Summary: models.BatchProcessSummary{
-				TotalOrders:      len(orders),
-				Accepted:         0,
-				Rejected:         len(orders),
-				TotalRevenue:     0,
-				InventoryUpdates: []models.InventoryUpdateResult{},
-			},
-		}
-
-		for i, order := range orders {
-			response.ProcessedOrders[i] = models.BatchProcessResult{
-				OrderID:      "",
-				CustomerName: order.CustomerName,
-				Status:       "rejected",
-				Reason:       "processing_error",
-			}
-		}
+		return rejectedBatchResponse(orders, "processing_error"), nil
+	}
+
+	// Reserve stock for every order at once; if another order took it since the check, undo the batch
+	reservations := make(map[string]map[string]float64, len(processedOrders))
+	for i, order := range processedOrders {
+		reservations[order.ID] = orderRequirements[i]
+	}
 
-		return response, nil
+	if err := s.reservationRepo.ReserveOrders(reservations); err != nil {
+		s.logger.Warn("Failed to reserve inventory for batch orders", "error", err)
+		s.deleteBatchOrders(processedOrders)
+		return rejectedBatchResponse(orders, "insufficient_inventory"), nil
 	}
 
-	// Update inventory
-	inventoryUpdates, err := s.inventoryRepo.BatchUpdateInventory(inventoryRequirements)
-	if err != nil {
-		s.logger.Error("Failed to update inventory in batch", "error", err)
-		// Note: Orders were already created, this is a partial failure scenario
-		// In production, you might want to implement compensation logic here
+	inventoryUpdates := make([]models.InventoryUpdateResult, 0, len(inventoryRequirements))
+	ingredientIDs := make([]string, 0, len(inventoryRequirements))
+	for ingredientID, quantity := range inventoryRequirements {
+		update := models.InventoryUpdateResult{IngredientID: ingredientID, QuantityUsed: quantity}
+		if item, err := s.inventoryRepo.GetByID(ingredientID); err == nil {
+			update.Name = item.Name
+			update.Remaining = item.Available
+		}
+		inventoryUpdates = append(inventoryUpdates, update)
+		ingredientIDs = append(ingredientIDs, ingredientID)
 	}
+	s.alertService.CheckStockLevels(ingredientIDs)
 
 	// Build response
 	response := &models.BatchProcessResponse{
@@ -483,6 +801,8 @@ func (s *OrderService) BatchProcessOrders(req models.BatchOrderRequest) (*models
 		},
 	}
 
+	// One snapshot of the queue estimates the whole batch before its events are sent
+	s.setEstimatedReadyAt(processedOrders...)
 	for i, order := range processedOrders {
 		response.ProcessedOrders[i] = models.BatchProcessResult{
 			OrderID:      order.ID,
@@ -490,6 +810,7 @@ func (s *OrderService) BatchProcessOrders(req models.BatchOrderRequest) (*models
 			Status:       "accepted",
 			Total:        order.TotalAmount,
 		}
+		s.publishOrderEvent(models.OrderEventCreated, order, "")
 	}
 
 	s.logger.Info("Completed batch order processing",
@@ -513,6 +834,22 @@ func (s *OrderService) validateOrderData(req CreateOrderRequest) error {
 	return s.validateOrderItems(req.Items)
 }
 
+// resolveCustomer checks that a linked customer exists and returns the name the order is placed under,
+// the given one or else the customer's
+func (s *OrderService) resolveCustomer(customerID, customerName string) (string, error) {
+	if customerID == "" {
+		return customerName, nil
+	}
+	customer, err := s.customerRepo.GetByID(customerID)
+	if err != nil {
+		return "", err
+	}
+	if customerName == "" {
+		return customer.Name, nil
+	}
+	return customerName, nil
+}
+
 // validateUpdateOrderData validates the data for order updates
 func (s *OrderService) validateUpdateOrderData(req UpdateOrderRequest) error {
 	if req.CustomerName == "" {
@@ -526,7 +863,7 @@ func (s *OrderService) validateUpdateOrderData(req UpdateOrderRequest) error {
 	}
 
 	// Validate status values
-	validStatuses := []string{"open", "closed"}
+	validStatuses := []string{models.OrderPending, models.OrderPreparing, models.OrderReady, models.OrderClosed, models.OrderCancelled}
 	statusValid := false
 	for _, status := range validStatuses {
 		if req.Status == status {
@@ -558,117 +895,256 @@ func (s *OrderService) validateOrderItems(items []CreateOrderItemRequest) error
 	return nil
 }
 
-// calculateOrderTotal calculates the total amount for an order
-func (s *OrderService) calculateOrderTotal(items []CreateOrderItemRequest) (float64, error) {
-	var total float64
+// checkMenuSchedules rejects items that are not on sale at t in the shop's timezone
+func (s *OrderService) checkMenuSchedules(items []CreateOrderItemRequest, t time.Time) error {
+	local := t.In(s.location)
 	for i, item := range items {
 		menuItem, err := s.menuRepo.GetByID(item.ProductID)
 		if err != nil {
-			return 0, fmt.Errorf("item %d: product '%s' not found in menu", i+1, item.ProductID)
+			return fmt.Errorf("item %d: product '%s' not found in menu", i+1, item.ProductID)
+		}
+		if !menuItem.Schedule.ActiveAt(local) {
+			return fmt.Errorf("item %d: '%s' is not on sale at %s", i+1, menuItem.Name, local.Format("Mon 2006-01-02 15:04 MST"))
 		}
-		total += menuItem.Price * float64(item.Quantity)
 	}
-	return total, nil
+	return nil
 }
 
-// checkInventoryAvailability checks if there's enough inventory for all order items
+// checkInventoryAvailability checks the order items against available-to-promise stock,
+// which leaves out expired lots and stock reserved by open orders
 func (s *OrderService) checkInventoryAvailability(items []CreateOrderItemRequest) error {
-	for i, item := range items {
-		// Get the menu item to find its ingredients
-		menuItem, err := s.menuRepo.GetByID(item.ProductID)
-		if err != nil {
-			return fmt.Errorf("item %d: product '%s' not found in menu", i+1, item.ProductID)
-		}
+	quantities, _, err := s.orderIngredientQuantities(items)
+	if err != nil {
+		return err
+	}
 
-		// Check each ingredient's availability
-		for _, ingredient := range menuItem.Ingredients {
-			inventoryItem, err := s.inventoryRepo.GetByID(ingredient.IngredientID)
-			if err != nil {
-				return fmt.Errorf("item %d: ingredient '%s' not found in inventory", i+1, ingredient.IngredientID)
-			}
+	if _, err := s.inventoryRepo.CheckInventoryAvailability(quantities); err != nil {
+		return err
+	}
+	return nil
+}
 
-			// Calculate total needed quantity for this ingredient
-			totalNeeded := ingredient.Quantity * float64(item.Quantity)
+// reserveInventory replaces the stock held by an order with what its items need
+func (s *OrderService) reserveInventory(orderID string, items []CreateOrderItemRequest) error {
+	quantities, ingredientIDs, err := s.orderIngredientQuantities(items)
+	if err != nil {
+		return err
+	}
 
-			if inventoryItem.Quantity < totalNeeded {
-				return fmt.Errorf("item %d: insufficient inventory for ingredient '%s' (need %.2f, have %.2f)",
-					i+1, ingredient.IngredientID, totalNeeded, inventoryItem.Quantity)
-			}
+	if err := s.reservationRepo.Reserve(orderID, quantities); err != nil {
+		return err
+	}
+
+	s.logger.Info("Reserved inventory", "order_id", orderID, "ingredients", len(quantities))
+	// Reserving lowers available-to-promise, which can leave menu items unmakeable
+	s.alertService.CheckStockLevels(ingredientIDs)
+	return nil
+}
+
+// checkOrderStock re-evaluates stock levels for the ingredients of items after their reservation was released
+func (s *OrderService) checkOrderStock(items []CreateOrderItemRequest) {
+	_, ingredientIDs, err := s.orderIngredientQuantities(items)
+	if err != nil {
+		s.logger.Warn("Failed to resolve order ingredients for stock check", "error", err)
+		return
+	}
+	s.alertService.CheckStockLevels(ingredientIDs)
+}
+
+// returnConsumedInventory puts back stock consumed from a reservation, when the status change that consumed it fails
+func (s *OrderService) returnConsumedInventory(orderID string, consumed []*models.InventoryTransaction) error {
+	transactions := make([]*models.InventoryTransaction, len(consumed))
+	for i, transaction := range consumed {
+		transactions[i] = &models.InventoryTransaction{
+			IngredientID:    transaction.IngredientID,
+			TransactionType: models.TransactionReturn,
+			QuantityChange:  -transaction.QuantityChange,
+			UnitCost:        transaction.UnitCost,
+			ReferenceType:   models.ReferenceTypeOrder,
+			ReferenceID:     orderID,
 		}
 	}
+
+	if err := s.inventoryRepo.ApplyTransactions(transactions); err != nil {
+		return fmt.Errorf("failed to return inventory: %v", err)
+	}
 	return nil
 }
 
-// consumeInventory reduces inventory quantities based on order items
-func (s *OrderService) consumeInventory(items []CreateOrderItemRequest) error {
+// consumesReservation reports whether moving an order to status turns its reservation into usage
+func consumesReservation(status string) bool {
+	return status == models.OrderPreparing || status == models.OrderReady || status == models.OrderClosed
+}
+
+// orderItemRequests converts stored order items to request items
+func orderItemRequests(items []models.OrderItem) []CreateOrderItemRequest {
+	requests := make([]CreateOrderItemRequest, len(items))
 	for i, item := range items {
-		// Get the menu item to find its ingredients
-		menuItem, err := s.menuRepo.GetByID(item.ProductID)
-		if err != nil {
-			return fmt.Errorf("item %d: product '%s' not found in menu", i+1, item.ProductID)
+		requests[i] = CreateOrderItemRequest{
+			ProductID:      item.ProductID,
+			Quantity:       item.Quantity,
+			Customizations: item.Customizations,
 		}
+	}
+	return requests
+}
 
-		// Consume each ingredient
-		for _, ingredient := range menuItem.Ingredients {
-			inventoryItem, err := s.inventoryRepo.GetByID(ingredient.IngredientID)
-			if err != nil {
-				return fmt.Errorf("item %d: ingredient '%s' not found in inventory", i+1, ingredient.IngredientID)
-			}
+// rejectedBatchResponse rejects every order of a batch for the same reason
+func rejectedBatchResponse(orders []*models.Order, reason string) *models.BatchProcessResponse {
+	response := &models.BatchProcessResponse{
+		ProcessedOrders: make([]models.BatchProcessResult, len(orders)),
+		Summary: models.BatchProcessSummary{
+			TotalOrders:      len(orders),
+			Accepted:         0,
+			Rejected:         len(orders),
+			TotalRevenue:     0,
+			InventoryUpdates: []models.InventoryUpdateResult{},
+		},
+	}
 
-			// Calculate consumption amount
-			consumeAmount := ingredient.Quantity * float64(item.Quantity)
+	for i, order := range orders {
+		response.ProcessedOrders[i] = models.BatchProcessResult{
+			OrderID:      "",
+			CustomerName: order.CustomerName,
+			Status:       "rejected",
+			Reason:       reason,
+		}
+	}
+	return response
+}
 
-			// Update inventory item
-			inventoryItem.Quantity -= consumeAmount
+// deleteBatchOrders removes orders created by a batch that could not reserve its stock
+func (s *OrderService) deleteBatchOrders(orders []*models.Order) {
+	for _, order := range orders {
+		if err := s.orderRepo.Delete(order.ID); err != nil {
+			s.logger.Error("Failed to remove batch order", "order_id", order.ID, "error", err)
+		}
+	}
+}
 
-			if err := s.inventoryRepo.Update(ingredient.IngredientID, inventoryItem); err != nil {
-				return fmt.Errorf("item %d: failed to update inventory for ingredient '%s': %v",
-					i+1, ingredient.IngredientID, err)
-			}
+// consumeInventory reduces inventory quantities based on order items and records usage in the ledger
+func (s *OrderService) consumeInventory(orderID string, items []CreateOrderItemRequest) error {
+	transactions, err := s.orderInventoryTransactions(orderID, items, models.TransactionUsage, -1)
+	if err != nil {
+		return err
+	}
 
-			s.logger.Info("Consumed inventory",
-				"ingredient_id", ingredient.IngredientID,
-				"amount", consumeAmount,
-				"remaining", inventoryItem.Quantity)
-		}
+	if err := s.inventoryRepo.ApplyTransactions(transactions); err != nil {
+		return fmt.Errorf("failed to consume inventory: %v", err)
+	}
+
+	for _, transaction := range transactions {
+		s.logger.Info("Consumed inventory",
+			"order_id", orderID,
+			"ingredient_id", transaction.IngredientID,
+			"amount", -transaction.QuantityChange,
+			"remaining", transaction.QuantityAfter)
+	}
+
+	s.alertService.CheckStockLevels(transactionIngredientIDs(transactions))
+	return nil
+}
+
+// restoreInventory adds back inventory quantities when order is deleted and records returns in the ledger
+func (s *OrderService) restoreInventory(orderID string, items []CreateOrderItemRequest) error {
+	transactions, err := s.orderInventoryTransactions(orderID, items, models.TransactionReturn, 1)
+	if err != nil {
+		return err
+	}
+
+	if err := s.inventoryRepo.ApplyTransactions(transactions); err != nil {
+		return fmt.Errorf("failed to restore inventory: %v", err)
 	}
+
+	for _, transaction := range transactions {
+		s.logger.Info("Restored inventory",
+			"order_id", orderID,
+			"ingredient_id", transaction.IngredientID,
+			"amount", transaction.QuantityChange,
+			"new_total", transaction.QuantityAfter)
+	}
+
+	s.alertService.CheckStockLevels(transactionIngredientIDs(transactions))
 	return nil
 }
 
-// restoreInventory adds back inventory quantities when order is deleted
-func (s *OrderService) restoreInventory(items []CreateOrderItemRequest) error {
+// transactionIngredientIDs lists the ingredients touched by a set of ledger movements
+func transactionIngredientIDs(transactions []*models.InventoryTransaction) []string {
+	ids := make([]string, len(transactions))
+	for i, transaction := range transactions {
+		ids[i] = transaction.IngredientID
+	}
+	return ids
+}
+
+// orderInventoryTransactions builds one ledger movement per ingredient used by the order items.
+// sign is -1 for stock leaving inventory and 1 for stock coming back.
+func (s *OrderService) orderInventoryTransactions(orderID string, items []CreateOrderItemRequest, transactionType string, sign float64) ([]*models.InventoryTransaction, error) {
+	quantities, ingredientIDs, err := s.orderIngredientQuantities(items)
+	if err != nil {
+		return nil, err
+	}
+
+	transactions := make([]*models.InventoryTransaction, len(ingredientIDs))
+	for i, ingredientID := range ingredientIDs {
+		transactions[i] = &models.InventoryTransaction{
+			IngredientID:    ingredientID,
+			TransactionType: transactionType,
+			QuantityChange:  sign * quantities[ingredientID],
+			ReferenceType:   models.ReferenceTypeOrder,
+			ReferenceID:     orderID,
+		}
+	}
+	return transactions, nil
+}
+
+// orderIngredientQuantities totals the stock the order items need per ingredient, in inventory units, including
+// the ingredients of the modifiers their customizations apply. The ingredient IDs are returned in recipe order;
+// the same ingredient across several lines is counted once.
+func (s *OrderService) orderIngredientQuantities(items []CreateOrderItemRequest) (map[string]float64, []string, error) {
+	quantities := make(map[string]float64)
+	var ingredientIDs []string
+
 	for i, item := range items {
 		// Get the menu item to find its ingredients
 		menuItem, err := s.menuRepo.GetByID(item.ProductID)
 		if err != nil {
-			return fmt.Errorf("item %d: product '%s' not found in menu", i+1, item.ProductID)
+			return nil, nil, fmt.Errorf("item %d: product '%s' not found in menu", i+1, item.ProductID)
 		}
 
-		// Restore each ingredient
-		for _, ingredient := range menuItem.Ingredients {
+		add := func(ingredient models.MenuItemIngredient, servings float64) error {
 			inventoryItem, err := s.inventoryRepo.GetByID(ingredient.IngredientID)
 			if err != nil {
 				return fmt.Errorf("item %d: ingredient '%s' not found in inventory", i+1, ingredient.IngredientID)
 			}
 
-			// Calculate restoration amount
-			restoreAmount := ingredient.Quantity * float64(item.Quantity)
-
-			// Update inventory item
-			inventoryItem.Quantity += restoreAmount
+			perServing, err := recipeQuantity(ingredient, inventoryItem)
+			if err != nil {
+				return fmt.Errorf("item %d: %v", i+1, err)
+			}
 
-			if err := s.inventoryRepo.Update(ingredient.IngredientID, inventoryItem); err != nil {
-				return fmt.Errorf("item %d: failed to update inventory for ingredient '%s': %v",
-					i+1, ingredient.IngredientID, err)
+			if _, ok := quantities[ingredient.IngredientID]; !ok {
+				ingredientIDs = append(ingredientIDs, ingredient.IngredientID)
 			}
+			quantities[ingredient.IngredientID] += perServing * servings
+			return nil
+		}
 
-			s.logger.Info("Restored inventory",
-				"ingredient_id", ingredient.IngredientID,
-				"amount", restoreAmount,
-				"new_total", inventoryItem.Quantity)
+		for _, ingredient := range menuItem.Ingredients {
+			if err := add(ingredient, float64(item.Quantity)); err != nil {
+				return nil, nil, err
+			}
+		}
+		for _, modifier := range appliedModifiers(menuItem, item.Customizations) {
+			for _, ingredient := range modifier.Ingredients {
+				if err := add(ingredient, modifier.count*float64(item.Quantity)); err != nil {
+					return nil, nil, err
+				}
+			}
 		}
 	}
-	return nil
+
+	return quantities, ingredientIDs, nil
 }
 
 // parseDate parses date string in multiple formats
@@ -716,16 +1192,24 @@ func (s *OrderService) validateBatchOrderRequest(orderReq models.BatchOrderItem,
 	return nil
 }
 
-func (s *OrderService) calculateBatchOrderTotal(items []models.BatchOrderItemDetail) (float64, error) {
-	var total float64
-
-	for i, item := range items {
-		menuItem, err := s.menuRepo.GetByID(item.MenuItemID)
-		if err != nil {
-			return 0, fmt.Errorf("item %d: menu item '%s' not found", i+1, item.MenuItemID)
-		}
-		total += menuItem.Price * float64(item.Quantity)
+// checkPayments makes sure an order moving to status with the given total is consistent with what
+// has been paid: it can only close once fully paid, can only be cancelled once every payment is
+// refunded, and its total cannot drop below the amount already paid
+func (s *OrderService) checkPayments(order *models.Order, status string, totalAmount float64) error {
+	summary, err := orderPaymentSummary(s.paymentRepo, order)
+	if err != nil {
+		return err
 	}
 
-	return total, nil
+	switch {
+	case status == models.OrderCancelled && summary.Paid > 0:
+		return fmt.Errorf("cannot cancel order with %.2f paid: refund the payments first", summary.Paid)
+	case status == models.OrderCancelled:
+		return nil
+	case summary.Paid > totalAmount:
+		return fmt.Errorf("cannot reduce order total to %.2f below the %.2f already paid", totalAmount, summary.Paid)
+	case status == models.OrderClosed && summary.Paid < totalAmount:
+		return fmt.Errorf("cannot close order: %.2f is still unpaid", roundMoney(totalAmount-summary.Paid))
+	}
+	return nil
 }
@@ -0,0 +1,172 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"frappuccino/models"
+)
+
+// stockedOrder is an order for quantity lattes of 200 ml milk each, from 1000 ml of milk. A pending order holds
+// its milk as a reservation; any other status has already used it.
+type stockedOrder struct {
+	service      *OrderService
+	orders       *fakeOrderRepo
+	inventory    *fakeInventoryRepo
+	reservations *fakeReservationRepo
+	alerts       *fakeAlertService
+}
+
+func newStockedOrder(status string, quantity int) *stockedOrder {
+	milk := 1000.0
+	reservations := &fakeReservationRepo{reserved: map[string]map[string]float64{}}
+	if status == models.OrderPending {
+		reservations.reserved["order-1"] = map[string]float64{"milk": 200 * float64(quantity)}
+	} else {
+		milk -= 200 * float64(quantity)
+	}
+
+	inventory := &fakeInventoryRepo{items: map[string]*models.InventoryItem{
+		"milk": {IngredientID: "milk", Name: "Milk", Unit: "ml", Quantity: milk},
+	}}
+	reservations.inventory = inventory
+	menu := &fakeMenuRepo{items: map[string]*models.MenuItem{
+		"latte": {ID: "latte", Name: "Latte", Category: "coffee", Price: 4, Ingredients: []models.MenuItemIngredient{
+			{IngredientID: "milk", Quantity: 200, Unit: "ml"},
+		}},
+	}}
+	orders := &fakeOrderRepo{orders: map[string]*models.Order{
+		"order-1": {ID: "order-1", CustomerName: "Ana", Status: status, Items: []models.OrderItem{
+			{MenuItemID: "latte", ProductID: "latte", Quantity: quantity, PriceAtTime: 4},
+		}},
+	}}
+	alerts := &fakeAlertService{}
+
+	return &stockedOrder{
+		service: &OrderService{
+			orderRepo:       orders,
+			menuRepo:        menu,
+			inventoryRepo:   inventory,
+			reservationRepo: reservations,
+			pricingRepo:     &fakePricingRepo{},
+			taxRepo:         &fakeTaxRepo{},
+			paymentRepo:     &fakePaymentRepo{},
+			alertService:    alerts,
+			location:        time.UTC,
+			logger:          testLogger(),
+		},
+		orders:       orders,
+		inventory:    inventory,
+		reservations: reservations,
+		alerts:       alerts,
+	}
+}
+
+// check compares the milk on hand and reserved, and the saved order, with what the test wants
+func (o *stockedOrder) check(t *testing.T, wantMilk, wantReserved float64, wantStatus string, wantQuantity int) {
+	t.Helper()
+	if got := o.inventory.items["milk"].Quantity; got != wantMilk {
+		t.Errorf("milk on hand = %.0f, want %.0f", got, wantMilk)
+	}
+	if got := o.reservations.reserved["order-1"]["milk"]; got != wantReserved {
+		t.Errorf("milk reserved = %.0f, want %.0f", got, wantReserved)
+	}
+	order := o.orders.orders["order-1"]
+	if order.Status != wantStatus {
+		t.Errorf("saved status = %s, want %s", order.Status, wantStatus)
+	}
+	if order.Items[0].Quantity != wantQuantity {
+		t.Errorf("saved quantity = %d, want %d", order.Items[0].Quantity, wantQuantity)
+	}
+}
+
+func TestUpdateOrderStock(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       string
+		quantity     int
+		newStatus    string
+		newQuantity  int
+		saveFails    bool
+		consumeFails bool
+		wantErr      bool
+		wantMilk     float64
+		wantReserved float64
+		wantStatus   string
+		wantQuantity int
+	}{
+		{
+			name:   "pending edit replaces the reservation",
+			status: models.OrderPending, quantity: 1, newStatus: models.OrderPending, newQuantity: 2,
+			wantMilk: 1000, wantReserved: 400, wantStatus: models.OrderPending, wantQuantity: 2,
+		},
+		{
+			name:   "leaving pending uses the changed lines",
+			status: models.OrderPending, quantity: 1, newStatus: models.OrderPreparing, newQuantity: 2,
+			wantMilk: 600, wantReserved: 0, wantStatus: models.OrderPreparing, wantQuantity: 2,
+		},
+		{
+			name:   "preparing edit swaps the used stock",
+			status: models.OrderPreparing, quantity: 1, newStatus: models.OrderPreparing, newQuantity: 3,
+			wantMilk: 400, wantReserved: 0, wantStatus: models.OrderPreparing, wantQuantity: 3,
+		},
+		{
+			name:   "preparing edit beyond stock uses the old lines again",
+			status: models.OrderPreparing, quantity: 1, newStatus: models.OrderPreparing, newQuantity: 6,
+			wantErr: true, wantMilk: 800, wantReserved: 0, wantStatus: models.OrderPreparing, wantQuantity: 1,
+		},
+		{
+			name:   "cancelling releases the reservation",
+			status: models.OrderPending, quantity: 1, newStatus: models.OrderCancelled, newQuantity: 1,
+			wantMilk: 1000, wantReserved: 0, wantStatus: models.OrderCancelled, wantQuantity: 1,
+		},
+		{
+			name:   "consume failure keeps the old reservation",
+			status: models.OrderPending, quantity: 1, newStatus: models.OrderPreparing, newQuantity: 2, consumeFails: true,
+			wantErr: true, wantMilk: 1000, wantReserved: 200, wantStatus: models.OrderPending, wantQuantity: 1,
+		},
+		{
+			name:   "save failure after consuming returns the stock and reservation",
+			status: models.OrderPending, quantity: 1, newStatus: models.OrderPreparing, newQuantity: 2, saveFails: true,
+			wantErr: true, wantMilk: 1000, wantReserved: 200, wantStatus: models.OrderPending, wantQuantity: 1,
+		},
+		{
+			name:   "save failure after a pending edit restores the reservation",
+			status: models.OrderPending, quantity: 1, newStatus: models.OrderPending, newQuantity: 2, saveFails: true,
+			wantErr: true, wantMilk: 1000, wantReserved: 200, wantStatus: models.OrderPending, wantQuantity: 1,
+		},
+		{
+			name:   "save failure after cancelling reserves the stock again",
+			status: models.OrderPending, quantity: 1, newStatus: models.OrderCancelled, newQuantity: 1, saveFails: true,
+			wantErr: true, wantMilk: 1000, wantReserved: 200, wantStatus: models.OrderPending, wantQuantity: 1,
+		},
+		{
+			name:   "save failure after a preparing edit swaps the stock back",
+			status: models.OrderPreparing, quantity: 1, newStatus: models.OrderPreparing, newQuantity: 3, saveFails: true,
+			wantErr: true, wantMilk: 800, wantReserved: 0, wantStatus: models.OrderPreparing, wantQuantity: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newStockedOrder(tt.status, tt.quantity)
+			if tt.saveFails {
+				o.orders.saveError = fmt.Errorf("connection lost")
+			}
+			if tt.consumeFails {
+				o.reservations.consumeError = fmt.Errorf("insufficient inventory for ingredient milk")
+			}
+
+			err := o.service.UpdateOrder("order-1", UpdateOrderRequest{
+				CustomerName: "Ana",
+				Status:       tt.newStatus,
+				Items:        []CreateOrderItemRequest{{ProductID: "latte", Quantity: tt.newQuantity}},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("UpdateOrder() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			o.check(t, tt.wantMilk, tt.wantReserved, tt.wantStatus, tt.wantQuantity)
+		})
+	}
+}
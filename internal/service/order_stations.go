@@ -0,0 +1,279 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"frappuccino/models"
+)
+
+// UpdateItemStatusRequest is a station's progress on an order item: queued, in_progress or done
+type UpdateItemStatusRequest struct {
+	Status string `json:"status"`
+}
+
+// orderTransitions lists the statuses an order can move to from each status; closed and cancelled orders are final
+var orderTransitions = map[string][]string{
+	models.OrderPending:   {models.OrderPreparing, models.OrderReady, models.OrderClosed, models.OrderCancelled},
+	models.OrderPreparing: {models.OrderReady, models.OrderClosed, models.OrderCancelled},
+	models.OrderReady:     {models.OrderPreparing, models.OrderClosed, models.OrderCancelled},
+}
+
+// validateStatusTransition checks an order may move from one status to another. An open order can keep its status.
+func validateStatusTransition(from, to string) error {
+	next, open := orderTransitions[from]
+	if !open {
+		return fmt.Errorf("cannot update %s order", from)
+	}
+	if from == to {
+		return nil
+	}
+	for _, status := range next {
+		if status == to {
+			return nil
+		}
+	}
+	if to == models.OrderPending {
+		return fmt.Errorf("cannot move %s order back to pending", from)
+	}
+	return fmt.Errorf("cannot move %s order to %s", from, to)
+}
+
+// advanceOrderStatus moves an order to a new status without changing its items, turning its reservation
+// into usage when it leaves pending
+func (s *OrderService) advanceOrderStatus(order *models.Order, status string) error {
+	if err := validateStatusTransition(order.Status, status); err != nil {
+		return err
+	}
+
+	var consumed []*models.InventoryTransaction
+	if order.Status == models.OrderPending && consumesReservation(status) {
+		var err error
+		consumed, err = s.reservationRepo.Consume(order.ID)
+		if err != nil {
+			return fmt.Errorf("failed to consume inventory: %v", err)
+		}
+	}
+
+	if err := s.orderRepo.UpdateStatus(order.ID, order.Status, status); err != nil {
+		if len(consumed) > 0 {
+			if err := s.returnConsumedInventory(order.ID, consumed); err != nil {
+				s.logger.Error("Failed to return consumed inventory", "order_id", order.ID, "error", err)
+			} else if err := s.reserveInventory(order.ID, orderItemRequests(order.Items)); err != nil {
+				s.logger.Error("Failed to restore reservation", "order_id", order.ID, "error", err)
+			}
+		}
+		return err
+	}
+
+	if len(consumed) > 0 {
+		s.alertService.CheckStockLevels(transactionIngredientIDs(consumed))
+	}
+
+	previousStatus := order.Status
+	order.Status = status
+	s.publishOrderEvent(models.OrderEventStatusChanged, order, previousStatus)
+
+	s.logger.Info("Order status changed", "order_id", order.ID, "from", previousStatus, "to", status)
+	return nil
+}
+
+// GetOrderTickets returns an order split into its station tickets
+func (s *OrderService) GetOrderTickets(id string) (*models.OrderTickets, error) {
+	order, err := s.orderRepo.GetByID(id)
+	if err != nil {
+		s.logger.Warn("Order not found for tickets", "order_id", id, "error", err)
+		return nil, err
+	}
+	return orderTickets(order, s.itemNames(order.Items)), nil
+}
+
+// UpdateItemStatus records how far a station has made an order item, then moves the order along with its
+// tickets: to preparing once an item is started, and to ready once every ticket is done
+func (s *OrderService) UpdateItemStatus(orderID, itemID, status string) (*models.OrderTickets, error) {
+	s.logger.Info("Updating order item prep status", "order_id", orderID, "item_id", itemID, "status", status)
+
+	if err := validatePrepStatus(status); err != nil {
+		return nil, err
+	}
+
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		s.logger.Warn("Order not found for item status update", "order_id", orderID, "error", err)
+		return nil, err
+	}
+	if order.Status == models.OrderClosed || order.Status == models.OrderCancelled {
+		return nil, fmt.Errorf("cannot update items of %s order", order.Status)
+	}
+
+	if err := s.orderRepo.UpdateItemPrepStatus(orderID, itemID, status); err != nil {
+		s.logger.Warn("Failed to update order item prep status", "order_id", orderID, "item_id", itemID, "error", err)
+		return nil, err
+	}
+	if order, err = s.orderRepo.GetByID(orderID); err != nil {
+		return nil, err
+	}
+
+	moved := false
+	if next := ticketsOrderStatus(order); next != "" {
+		// The item's progress is kept either way; an order that couldn't move can still be moved by hand
+		if err := s.advanceOrderStatus(order, next); err != nil {
+			s.logger.Error("Failed to move order along with its tickets", "order_id", orderID, "status", next, "error", err)
+		} else {
+			moved = true
+		}
+	}
+	if !moved {
+		s.publishOrderEvent(models.OrderEventUpdated, order, order.Status)
+	}
+
+	return orderTickets(order, s.itemNames(order.Items)), nil
+}
+
+// ticketsOrderStatus is the status an order's tickets move it to, or "" when they leave it where it is
+func ticketsOrderStatus(order *models.Order) string {
+	started, done := 0, 0
+	for _, item := range order.Items {
+		if item.PrepStatus != models.PrepQueued {
+			started++
+		}
+		if item.PrepStatus == models.PrepDone {
+			done++
+		}
+	}
+
+	switch {
+	case len(order.Items) > 0 && done == len(order.Items) &&
+		(order.Status == models.OrderPending || order.Status == models.OrderPreparing):
+		return models.OrderReady
+	case started > 0 && order.Status == models.OrderPending:
+		return models.OrderPreparing
+	}
+	return ""
+}
+
+func validatePrepStatus(status string) error {
+	switch status {
+	case models.PrepQueued, models.PrepInProgress, models.PrepDone:
+		return nil
+	default:
+		return fmt.Errorf("invalid prep status: %s", status)
+	}
+}
+
+func validateStation(station models.Station) error {
+	for _, known := range models.Stations {
+		if station == known {
+			return nil
+		}
+	}
+	return fmt.Errorf("invalid station: %s", station)
+}
+
+// carryPrepStatus keeps the station and progress of the items an update leaves unchanged; changed and new items
+// are routed afresh and queued
+func carryPrepStatus(existing, lines []models.OrderItem) {
+	used := make([]bool, len(existing))
+	for i := range lines {
+		for j, item := range existing {
+			if used[j] || item.MenuItemID != lines[i].MenuItemID || item.BundleID != lines[i].BundleID ||
+				item.Quantity != lines[i].Quantity || !sameCustomizations(item.Customizations, lines[i].Customizations) {
+				continue
+			}
+			used[j] = true
+			lines[i].Station = item.Station
+			lines[i].PrepStatus = item.PrepStatus
+			lines[i].PrepStartedAt = item.PrepStartedAt
+			lines[i].PrepDoneAt = item.PrepDoneAt
+			break
+		}
+	}
+}
+
+// sameCustomizations compares customizations as JSON, since stored ones come back reformatted
+func sameCustomizations(a, b json.RawMessage) bool {
+	var x, y map[string]interface{}
+	if len(a) > 0 {
+		if err := json.Unmarshal(a, &x); err != nil {
+			return false
+		}
+	}
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &y); err != nil {
+			return false
+		}
+	}
+	return len(x) == 0 && len(y) == 0 || reflect.DeepEqual(x, y)
+}
+
+func orderTickets(order *models.Order, names map[string]string) *models.OrderTickets {
+	return &models.OrderTickets{
+		OrderID: order.ID,
+		Status:  order.Status,
+		Tickets: stationTickets(order.Items, names),
+	}
+}
+
+// stationTickets splits order items into a ticket per station, naming them from names by menu item ID
+func stationTickets(items []models.OrderItem, names map[string]string) []models.StationTicket {
+	byStation := make(map[models.Station][]models.KDSItem)
+	for _, item := range items {
+		byStation[item.Station] = append(byStation[item.Station], kdsItem(item, names))
+	}
+
+	tickets := []models.StationTicket{}
+	for _, station := range models.Stations {
+		if ticketItems, ok := byStation[station]; ok {
+			tickets = append(tickets, models.StationTicket{Station: station, Status: ticketStatus(ticketItems), Items: ticketItems})
+		}
+	}
+	return tickets
+}
+
+// ticketStatus is queued until an item is started and done once every item is
+func ticketStatus(items []models.KDSItem) string {
+	queued, done := 0, 0
+	for _, item := range items {
+		switch item.Status {
+		case models.PrepQueued:
+			queued++
+		case models.PrepDone:
+			done++
+		}
+	}
+
+	switch len(items) {
+	case queued:
+		return models.PrepQueued
+	case done:
+		return models.PrepDone
+	default:
+		return models.PrepInProgress
+	}
+}
+
+func kdsItem(item models.OrderItem, names map[string]string) models.KDSItem {
+	name := func(id string) string {
+		if name, ok := names[id]; ok {
+			return name
+		}
+		return id
+	}
+
+	kds := models.KDSItem{
+		OrderItemID:    item.ID,
+		MenuItemID:     item.MenuItemID,
+		Name:           name(item.MenuItemID),
+		Quantity:       item.Quantity,
+		Customizations: item.Customizations,
+		BundleID:       item.BundleID,
+		Status:         item.PrepStatus,
+		StartedAt:      item.PrepStartedAt,
+		DoneAt:         item.PrepDoneAt,
+	}
+	if item.BundleID != "" {
+		kds.BundleName = name(item.BundleID)
+	}
+	return kds
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+
+	"frappuccino/models"
+)
+
+func TestAdvanceOrderStatus(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       string
+		newStatus    string
+		saveFails    bool
+		consumeFails bool
+		wantErr      bool
+		wantMilk     float64
+		wantReserved float64
+		wantStatus   string
+		wantChecked  bool
+	}{
+		{
+			name:   "leaving pending uses the reservation",
+			status: models.OrderPending, newStatus: models.OrderPreparing,
+			wantMilk: 800, wantReserved: 0, wantStatus: models.OrderPreparing, wantChecked: true,
+		},
+		{
+			name:   "moving on from preparing uses nothing more",
+			status: models.OrderPreparing, newStatus: models.OrderReady,
+			wantMilk: 800, wantReserved: 0, wantStatus: models.OrderReady,
+		},
+		{
+			name:   "consume failure leaves the order pending",
+			status: models.OrderPending, newStatus: models.OrderReady, consumeFails: true,
+			wantErr: true, wantMilk: 1000, wantReserved: 200, wantStatus: models.OrderPending,
+		},
+		{
+			name:   "save failure returns the stock and reservation",
+			status: models.OrderPending, newStatus: models.OrderPreparing, saveFails: true,
+			wantErr: true, wantMilk: 1000, wantReserved: 200, wantStatus: models.OrderPending,
+		},
+		{
+			name:   "final orders can't move",
+			status: models.OrderClosed, newStatus: models.OrderReady,
+			wantErr: true, wantMilk: 800, wantReserved: 0, wantStatus: models.OrderClosed,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o := newStockedOrder(tt.status, 1)
+			if tt.saveFails {
+				o.orders.saveError = fmt.Errorf("connection lost")
+			}
+			if tt.consumeFails {
+				o.reservations.consumeError = fmt.Errorf("insufficient inventory for ingredient milk")
+			}
+
+			order, _ := o.orders.GetByID("order-1")
+			err := o.service.advanceOrderStatus(order, tt.newStatus)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("advanceOrderStatus() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if order.Status != tt.wantStatus {
+				t.Errorf("order status = %s, want %s", order.Status, tt.wantStatus)
+			}
+			o.check(t, tt.wantMilk, tt.wantReserved, tt.wantStatus, 1)
+
+			checked := false
+			for _, id := range o.alerts.checked {
+				checked = checked || id == "milk"
+			}
+			if tt.wantChecked && !checked {
+				t.Error("used stock was not checked against its threshold")
+			}
+		})
+	}
+}
@@ -0,0 +1,106 @@
+package service
+
+import (
+	"fmt"
+
+	"frappuccino/models"
+)
+
+// calculateTaxes works out the tax an order's lines owe for its order type, grouping the lines by the
+// tax rate that applies to each. Line amounts are after discounts; with inclusive pricing they contain the
+// tax, which is backed out of them, otherwise it is charged on top. Lines no rate applies to are untaxed.
+func (s *OrderService) calculateTaxes(lines []models.OrderItem, orderType string, inclusive bool) ([]models.OrderTax, float64, error) {
+	rates, err := s.taxRepo.GetAll()
+	if err != nil {
+		return nil, 0, err
+	}
+	if len(rates) == 0 {
+		return nil, 0, nil
+	}
+
+	var taxes []models.OrderTax
+	byRate := make(map[string]int)
+	for _, line := range lines {
+		menuItem, err := s.menuRepo.GetByID(line.ProductID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("product '%s' not found in menu", line.ProductID)
+		}
+		rate := matchTaxRate(rates, menuItem.Category, orderType)
+		if rate == nil {
+			continue
+		}
+
+		i, ok := byRate[rate.ID]
+		if !ok {
+			i = len(taxes)
+			byRate[rate.ID] = i
+			taxes = append(taxes, models.OrderTax{TaxRateID: rate.ID, Name: rate.Name, Rate: rate.Rate})
+		}
+		taxes[i].NetAmount += line.PriceAtTime*float64(line.Quantity) - line.DiscountAmount
+	}
+
+	var total float64
+	for i := range taxes {
+		taxes[i].NetAmount, taxes[i].TaxAmount = splitTax(taxes[i].NetAmount, taxes[i].Rate, inclusive)
+		total += taxes[i].TaxAmount
+	}
+
+	return taxes, roundMoney(total), nil
+}
+
+// splitTax splits the amount of the lines taxed at rate percent into their net amount and tax, in cents.
+// With inclusive pricing the amount contains the tax, otherwise the tax comes on top of it.
+func splitTax(amount, rate float64, inclusive bool) (net, tax float64) {
+	amount = roundMoney(amount)
+	if inclusive {
+		tax = roundMoney(amount * rate / (100 + rate))
+		return roundMoney(amount - tax), tax
+	}
+	return amount, roundMoney(amount * rate / 100)
+}
+
+// matchTaxRate picks the most specific rate for a category and order type: one naming both beats one
+// naming the category, which beats one naming the order type, which beats the general rate
+func matchTaxRate(rates []*models.TaxRate, category models.MenuCategory, orderType string) *models.TaxRate {
+	var best *models.TaxRate
+	bestScore := -1
+	for _, rate := range rates {
+		score := 0
+		if rate.Category != "" {
+			if rate.Category != category {
+				continue
+			}
+			score += 2
+		}
+		if rate.OrderType != "" {
+			if rate.OrderType != orderType {
+				continue
+			}
+			score++
+		}
+		if score > bestScore {
+			best, bestScore = rate, score
+		}
+	}
+	return best
+}
+
+// orderTotal is what the customer pays for a subtotal: the subtotal itself when it includes the tax
+func orderTotal(subtotal, tax float64, inclusive bool) float64 {
+	if inclusive {
+		return subtotal
+	}
+	return roundMoney(subtotal + tax)
+}
+
+// validateOrderType defaults an empty order type to dine-in
+func validateOrderType(orderType string) (string, error) {
+	switch orderType {
+	case "":
+		return models.OrderDineIn, nil
+	case models.OrderDineIn, models.OrderTakeaway:
+		return orderType, nil
+	default:
+		return "", fmt.Errorf("invalid order type '%s': must be %s or %s", orderType, models.OrderDineIn, models.OrderTakeaway)
+	}
+}
@@ -0,0 +1,57 @@
+package service
+
+import "testing"
+
+func TestSplitTax(t *testing.T) {
+	tests := []struct {
+		name      string
+		amount    float64
+		rate      float64
+		inclusive bool
+		wantNet   float64
+		wantTax   float64
+	}{
+		{name: "exclusive adds tax on top", amount: 10, rate: 20, wantNet: 10, wantTax: 2},
+		{name: "exclusive rounds tax to cents", amount: 10.99, rate: 7, wantNet: 10.99, wantTax: 0.77},
+		{name: "exclusive fractional rate", amount: 4.5, rate: 8.875, wantNet: 4.5, wantTax: 0.4},
+		{name: "inclusive backs tax out", amount: 12, rate: 20, inclusive: true, wantNet: 10, wantTax: 2},
+		{name: "inclusive net and tax add up", amount: 10.99, rate: 7, inclusive: true, wantNet: 10.27, wantTax: 0.72},
+		{name: "amount is rounded first", amount: 3.333, rate: 10, wantNet: 3.33, wantTax: 0.33},
+		{name: "zero rate", amount: 5, rate: 0, inclusive: true, wantNet: 5, wantTax: 0},
+		{name: "zero amount", amount: 0, rate: 20, wantNet: 0, wantTax: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			net, tax := splitTax(tt.amount, tt.rate, tt.inclusive)
+			if net != tt.wantNet || tax != tt.wantTax {
+				t.Errorf("splitTax(%v, %v, %v) = (%v, %v), want (%v, %v)", tt.amount, tt.rate, tt.inclusive, net, tax, tt.wantNet, tt.wantTax)
+			}
+			if tt.inclusive && roundMoney(net+tax) != roundMoney(tt.amount) {
+				t.Errorf("inclusive split %v + %v does not add up to %v", net, tax, tt.amount)
+			}
+		})
+	}
+}
+
+func TestOrderTotal(t *testing.T) {
+	tests := []struct {
+		name      string
+		subtotal  float64
+		tax       float64
+		inclusive bool
+		want      float64
+	}{
+		{name: "exclusive adds tax", subtotal: 10.99, tax: 0.77, want: 11.76},
+		{name: "inclusive is the subtotal", subtotal: 12, tax: 2, inclusive: true, want: 12},
+		{name: "exclusive rounds float error", subtotal: 0.1, tax: 0.2, want: 0.3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := orderTotal(tt.subtotal, tt.tax, tt.inclusive); got != tt.want {
+				t.Errorf("orderTotal(%v, %v, %v) = %v, want %v", tt.subtotal, tt.tax, tt.inclusive, got, tt.want)
+			}
+		})
+	}
+}
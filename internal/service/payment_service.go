@@ -0,0 +1,301 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"frappuccino/internal/repositories"
+	"frappuccino/models"
+	"frappuccino/pkg/logger"
+	"frappuccino/pkg/payments"
+)
+
+type PaymentRequest struct {
+	Tender      string   `json:"tender"`
+	Amount      *float64 `json:"amount"`   // Defaults to the order's balance
+	Tip         float64  `json:"tip"`      // On top of the amount
+	Tendered    *float64 `json:"tendered"` // Cash handed over, defaults to amount plus tip
+	Guest       string   `json:"guest"`
+	CardToken   string   `json:"card_token"`
+	VoucherCode string   `json:"voucher_code"`
+}
+
+type RefundRequest struct {
+	PaymentID string   `json:"payment_id"`
+	Amount    *float64 `json:"amount"` // Defaults to what is left to refund on the payment
+	Reason    string   `json:"reason"`
+}
+
+type PaymentServiceInterface interface {
+	GetOrderPayments(orderID string, split int) (*models.OrderPayments, error)
+	AddPayment(orderID string, req PaymentRequest) (*models.Payment, error)
+	RefundPayment(orderID string, req RefundRequest) (*models.Refund, error)
+}
+
+type PaymentService struct {
+	paymentRepo repositories.PaymentRepositoryInterface
+	orderRepo   repositories.OrderRepositoryInterface
+	loyaltyRepo repositories.LoyaltyRepositoryInterface
+	gateway     payments.Gateway
+	logger      *logger.Logger
+}
+
+func NewPaymentService(paymentRepo repositories.PaymentRepositoryInterface, orderRepo repositories.OrderRepositoryInterface, loyaltyRepo repositories.LoyaltyRepositoryInterface, gateway payments.Gateway, log *logger.Logger) *PaymentService {
+	return &PaymentService{
+		paymentRepo: paymentRepo,
+		orderRepo:   orderRepo,
+		loyaltyRepo: loyaltyRepo,
+		gateway:     gateway,
+		logger:      log.WithComponent("payment_service"),
+	}
+}
+
+// GetOrderPayments returns an order's payments, refunds and balance; with split > 1 the balance
+// is also divided into that many equal shares
+func (s *PaymentService) GetOrderPayments(orderID string, split int) (*models.OrderPayments, error) {
+	s.logger.Info("Fetching order payments", "order_id", orderID)
+
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		s.logger.Warn("Order not found for payments", "order_id", orderID, "error", err)
+		return nil, fmt.Errorf("order with id %s not found", orderID)
+	}
+
+	summary, err := orderPaymentSummary(s.paymentRepo, order)
+	if err != nil {
+		s.logger.Error("Failed to load order payments", "order_id", orderID, "error", err)
+		return nil, err
+	}
+
+	if split > 1 && summary.Balance > 0 {
+		weights := make([]float64, split)
+		for i := range weights {
+			weights[i] = 1
+		}
+		summary.Split = allocateMoney(summary.Balance, weights)
+	}
+	return summary, nil
+}
+
+// AddPayment takes a payment for an open order. Cards are charged through the gateway before the
+// payment is recorded, and the charge is refunded again if recording it fails.
+func (s *PaymentService) AddPayment(orderID string, req PaymentRequest) (*models.Payment, error) {
+	s.logger.Info("Adding payment", "order_id", orderID, "tender", req.Tender)
+
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		s.logger.Warn("Order not found for payment", "order_id", orderID, "error", err)
+		return nil, fmt.Errorf("order with id %s not found", orderID)
+	}
+	if order.Status == models.OrderClosed || order.Status == models.OrderCancelled {
+		return nil, fmt.Errorf("cannot pay %s order", order.Status)
+	}
+
+	summary, err := orderPaymentSummary(s.paymentRepo, order)
+	if err != nil {
+		s.logger.Error("Failed to load order payments", "order_id", orderID, "error", err)
+		return nil, err
+	}
+
+	payment, err := newPayment(order.ID, req, summary.Balance)
+	if err != nil {
+		s.logger.Warn("Invalid payment", "order_id", orderID, "error", err)
+		return nil, err
+	}
+
+	if payment.Tender == models.TenderCard {
+		reference, err := s.gateway.Charge(payments.ChargeRequest{
+			OrderID: order.ID,
+			Amount:  roundMoney(payment.Amount + payment.Tip),
+			Token:   req.CardToken,
+		})
+		if err != nil {
+			s.logger.Warn("Card charge failed", "order_id", orderID, "error", err)
+			if errors.Is(err, payments.ErrDeclined) {
+				return nil, fmt.Errorf("payment declined: %v", err)
+			}
+			return nil, fmt.Errorf("failed to charge card: %v", err)
+		}
+		payment.Reference = reference
+	}
+
+	if err := s.paymentRepo.Create(payment); err != nil {
+		s.logger.Warn("Failed to record payment", "order_id", orderID, "error", err)
+		if payment.Tender == models.TenderCard {
+			if _, voidErr := s.gateway.Refund(payment.Reference, roundMoney(payment.Amount+payment.Tip)); voidErr != nil {
+				s.logger.Error("Failed to refund unrecorded card charge", "order_id", orderID, "reference", payment.Reference, "error", voidErr)
+			}
+		}
+		return nil, err
+	}
+
+	s.logger.Info("Payment added", "payment_id", payment.ID, "order_id", orderID, "amount", payment.Amount, "tip", payment.Tip)
+	return payment, nil
+}
+
+// RefundPayment gives back part or all of a payment's amount; tips are not refunded.
+// Card refunds go back through the gateway.
+func (s *PaymentService) RefundPayment(orderID string, req RefundRequest) (*models.Refund, error) {
+	s.logger.Info("Refunding payment", "order_id", orderID, "payment_id", req.PaymentID)
+
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.PaymentID == "" {
+		return nil, fmt.Errorf("payment_id is required")
+	}
+	if req.Reason == "" {
+		return nil, fmt.Errorf("reason is required")
+	}
+
+	payment, err := s.paymentRepo.GetByID(req.PaymentID)
+	if err != nil {
+		s.logger.Warn("Payment not found for refund", "payment_id", req.PaymentID, "error", err)
+		return nil, err
+	}
+	if payment.OrderID != orderID {
+		return nil, fmt.Errorf("payment with id %s not found", req.PaymentID)
+	}
+
+	refundable := roundMoney(payment.Amount - payment.RefundedAmount)
+	amount := refundable
+	if req.Amount != nil {
+		amount = roundMoney(*req.Amount)
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("refund amount must be positive")
+	}
+	if amount > refundable {
+		return nil, fmt.Errorf("refund of %.2f exceeds the %.2f left to refund on payment %s", amount, refundable, payment.ID)
+	}
+
+	refund := &models.Refund{
+		PaymentID: payment.ID,
+		OrderID:   orderID,
+		Tender:    payment.Tender,
+		Amount:    amount,
+		Reason:    req.Reason,
+	}
+
+	if payment.Tender == models.TenderCard {
+		reference, err := s.gateway.Refund(payment.Reference, amount)
+		if err != nil {
+			s.logger.Error("Card refund failed", "payment_id", payment.ID, "error", err)
+			return nil, fmt.Errorf("failed to refund card: %v", err)
+		}
+		refund.Reference = reference
+	}
+
+	if err := s.paymentRepo.Refund(refund); err != nil {
+		s.logger.Error("Failed to record refund", "payment_id", payment.ID, "reference", refund.Reference, "error", err)
+		return nil, err
+	}
+
+	// The refund stands either way; a failed reversal is logged and taken up by the next refund
+	if err := s.reverseRefundedLoyalty(orderID); err != nil {
+		s.logger.Error("Failed to reverse refunded loyalty", "order_id", orderID, "error", err)
+	}
+
+	s.logger.Info("Payment refunded", "refund_id", refund.ID, "payment_id", payment.ID, "amount", amount)
+	return refund, nil
+}
+
+// reverseRefundedLoyalty takes back the loyalty an order earned in proportion to how much of it has been refunded
+func (s *PaymentService) reverseRefundedLoyalty(orderID string) error {
+	order, err := s.orderRepo.GetByID(orderID)
+	if err != nil {
+		return err
+	}
+	if order.CustomerID == "" || order.TotalAmount <= 0 {
+		return nil
+	}
+
+	summary, err := orderPaymentSummary(s.paymentRepo, order)
+	if err != nil {
+		return err
+	}
+	return s.loyaltyRepo.ReverseRefund(orderID, summary.Refunded/order.TotalAmount)
+}
+
+// newPayment validates a payment request against the order's balance and works out the change
+func newPayment(orderID string, req PaymentRequest, balance float64) (*models.Payment, error) {
+	payment := &models.Payment{
+		OrderID: orderID,
+		Tender:  req.Tender,
+		Tip:     roundMoney(req.Tip),
+		Guest:   strings.TrimSpace(req.Guest),
+	}
+
+	switch req.Tender {
+	case models.TenderCash:
+	case models.TenderCard:
+		if req.CardToken == "" {
+			return nil, fmt.Errorf("card_token is required for card payments")
+		}
+	case models.TenderVoucher:
+		payment.Reference = strings.TrimSpace(req.VoucherCode)
+		if payment.Reference == "" {
+			return nil, fmt.Errorf("voucher_code is required for voucher payments")
+		}
+	default:
+		return nil, fmt.Errorf("invalid tender '%s': must be %s, %s or %s", req.Tender, models.TenderCash, models.TenderCard, models.TenderVoucher)
+	}
+
+	if balance <= 0 {
+		return nil, fmt.Errorf("order is already fully paid")
+	}
+	payment.Amount = balance
+	if req.Amount != nil {
+		payment.Amount = roundMoney(*req.Amount)
+	}
+	if payment.Amount <= 0 {
+		return nil, fmt.Errorf("payment amount must be positive")
+	}
+	if payment.Amount > balance {
+		return nil, fmt.Errorf("payment of %.2f exceeds the balance of %.2f", payment.Amount, balance)
+	}
+	if payment.Tip < 0 {
+		return nil, fmt.Errorf("tip cannot be negative")
+	}
+
+	if req.Tendered != nil {
+		if req.Tender != models.TenderCash {
+			return nil, fmt.Errorf("tendered is only allowed for cash payments")
+		}
+		due := roundMoney(payment.Amount + payment.Tip)
+		payment.Tendered = roundMoney(*req.Tendered)
+		if payment.Tendered < due {
+			return nil, fmt.Errorf("tendered %.2f is less than the %.2f due", payment.Tendered, due)
+		}
+		payment.Change = roundMoney(payment.Tendered - due)
+	} else if req.Tender == models.TenderCash {
+		payment.Tendered = roundMoney(payment.Amount + payment.Tip)
+	}
+
+	return payment, nil
+}
+
+// orderPaymentSummary totals an order's payments and refunds against its total
+func orderPaymentSummary(paymentRepo repositories.PaymentRepositoryInterface, order *models.Order) (*models.OrderPayments, error) {
+	paid, refunds, err := paymentRepo.GetByOrder(order.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.OrderPayments{
+		OrderID:     order.ID,
+		TotalAmount: order.TotalAmount,
+		Payments:    paid,
+		Refunds:     refunds,
+	}
+	for _, payment := range paid {
+		summary.Paid += payment.Amount - payment.RefundedAmount
+		summary.Refunded += payment.RefundedAmount
+		summary.Tips += payment.Tip
+	}
+	summary.Paid = roundMoney(summary.Paid)
+	summary.Refunded = roundMoney(summary.Refunded)
+	summary.Tips = roundMoney(summary.Tips)
+	summary.Balance = roundMoney(order.TotalAmount - summary.Paid)
+	summary.FullyPaid = summary.Balance <= 0
+	return summary, nil
+}
@@ -0,0 +1,267 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"frappuccino/models"
+)
+
+// applyPricingRules discounts an order's lines at t: first the automatic rules active then, then the promo codes.
+// Each rule discounts what the earlier ones left, so a line is never discounted below zero. Every line's share
+// is added to its DiscountAmount; the discounts applied are returned with their total.
+// Promo codes in redeemed were already redeemed by the order, so expiry and use limits don't apply to them again.
+func (s *OrderService) applyPricingRules(lines []models.OrderItem, promoCodes []string, t time.Time, redeemed map[string]bool) ([]models.OrderDiscount, float64, error) {
+	local := t.In(s.location)
+
+	automatic, err := s.pricingRepo.GetAutomatic()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var rules []*models.PricingRule
+	for _, rule := range automatic {
+		if rule.Schedule.ActiveAt(local) {
+			rules = append(rules, rule)
+		}
+	}
+
+	seen := make(map[string]bool, len(promoCodes))
+	for _, code := range promoCodes {
+		code = strings.ToUpper(strings.TrimSpace(code))
+		if code == "" || seen[code] {
+			continue
+		}
+		seen[code] = true
+
+		rule, err := s.pricingRepo.GetByPromoCode(code)
+		if err != nil {
+			return nil, 0, err
+		}
+		if err := checkPromoCode(rule, t, redeemed[code]); err != nil {
+			return nil, 0, err
+		}
+		if !rule.Schedule.ActiveAt(local) {
+			return nil, 0, fmt.Errorf("promo code '%s' is not valid at this time", code)
+		}
+		rules = append(rules, rule)
+	}
+
+	menu, err := s.lineMenu(lines)
+	if err != nil {
+		return nil, 0, err
+	}
+	remaining := remainingLineValues(lines)
+
+	var discounts []models.OrderDiscount
+	var total float64
+	for _, rule := range rules {
+		amounts := ruleDiscounts(rule, lines, remaining, menu)
+		allocated := allocateMoney(roundMoney(sum(amounts)), amounts)
+
+		var amount float64
+		for i, share := range allocated {
+			lines[i].DiscountAmount = roundMoney(lines[i].DiscountAmount + share)
+			remaining[i] = roundMoney(remaining[i] - share)
+			amount += share
+		}
+		amount = roundMoney(amount)
+
+		if amount <= 0 {
+			if rule.PromoCode != "" {
+				return nil, 0, fmt.Errorf("promo code '%s' does not apply to this order", rule.PromoCode)
+			}
+			continue
+		}
+		discounts = append(discounts, models.OrderDiscount{
+			RuleID:    rule.ID,
+			Name:      rule.Name,
+			PromoCode: rule.PromoCode,
+			Amount:    amount,
+		})
+		total += amount
+	}
+
+	return discounts, roundMoney(total), nil
+}
+
+// lineMenu loads the menu items of an order's lines, keyed by product ID
+func (s *OrderService) lineMenu(lines []models.OrderItem) (map[string]*models.MenuItem, error) {
+	menu := make(map[string]*models.MenuItem)
+	for _, line := range lines {
+		if _, ok := menu[line.ProductID]; ok {
+			continue
+		}
+		menuItem, err := s.menuRepo.GetByID(line.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		menu[line.ProductID] = menuItem
+	}
+	return menu, nil
+}
+
+// remainingLineValues is what each line is worth after the discounts already applied to it
+func remainingLineValues(lines []models.OrderItem) []float64 {
+	remaining := make([]float64, len(lines))
+	for i, line := range lines {
+		remaining[i] = roundMoney(line.PriceAtTime*float64(line.Quantity) - line.DiscountAmount)
+	}
+	return remaining
+}
+
+// checkPromoCode rejects a promo code that can't be redeemed at t
+func checkPromoCode(rule *models.PricingRule, t time.Time, redeemed bool) error {
+	if redeemed {
+		return nil
+	}
+	if !rule.Active {
+		return fmt.Errorf("promo code '%s' is not active", rule.PromoCode)
+	}
+	if rule.ExpiresAt != nil && !t.Before(*rule.ExpiresAt) {
+		return fmt.Errorf("promo code '%s' has expired", rule.PromoCode)
+	}
+	if rule.MaxUses != nil && rule.Uses >= *rule.MaxUses {
+		return fmt.Errorf("promo code '%s' has been used up", rule.PromoCode)
+	}
+	return nil
+}
+
+// ruleTargets reports which lines a rule applies to. Rules aimed at items or a category leave bundle
+// components alone, since the bundle price is already a deal; order-wide rules apply to every line.
+func ruleTargets(rule *models.PricingRule, lines []models.OrderItem, menu map[string]*models.MenuItem) []bool {
+	targets := make([]bool, len(lines))
+	orderWide := len(rule.MenuItemIDs) == 0 && rule.Category == ""
+
+	for i, line := range lines {
+		if orderWide {
+			targets[i] = true
+			continue
+		}
+		if line.BundleID != "" {
+			continue
+		}
+		if rule.Category != "" && menu[line.ProductID].Category == rule.Category {
+			targets[i] = true
+			continue
+		}
+		for _, id := range rule.MenuItemIDs {
+			if id == line.ProductID {
+				targets[i] = true
+				break
+			}
+		}
+	}
+	return targets
+}
+
+// ruleDiscounts is what a rule takes off each line, unrounded, given what is left of each line's value
+func ruleDiscounts(rule *models.PricingRule, lines []models.OrderItem, remaining []float64, menu map[string]*models.MenuItem) []float64 {
+	targets := ruleTargets(rule, lines, menu)
+	amounts := make([]float64, len(lines))
+
+	switch rule.Type {
+	case models.RulePercentage:
+		percent := math.Min(rule.Value, 100)
+		for i := range lines {
+			if targets[i] {
+				amounts[i] = remaining[i] * percent / 100
+			}
+		}
+
+	case models.RuleFixed:
+		var base float64
+		for i := range lines {
+			if targets[i] {
+				base += remaining[i]
+			}
+		}
+		if base <= 0 {
+			break
+		}
+		off := math.Min(rule.Value, base)
+		for i := range lines {
+			if targets[i] {
+				amounts[i] = off * remaining[i] / base
+			}
+		}
+
+	case models.RuleBuyXGetY:
+		// The cheapest units go free, GetQuantity of them for every BuyQuantity+GetQuantity bought
+		type unit struct {
+			line  int
+			price float64
+		}
+		var units []unit
+		for i, line := range lines {
+			if !targets[i] || line.Quantity <= 0 {
+				continue
+			}
+			price := remaining[i] / float64(line.Quantity)
+			for n := 0; n < line.Quantity; n++ {
+				units = append(units, unit{line: i, price: price})
+			}
+		}
+		group := rule.BuyQuantity + rule.GetQuantity
+		if group <= 0 {
+			break
+		}
+		sort.SliceStable(units, func(a, b int) bool { return units[a].price > units[b].price })
+
+		free := len(units) / group * rule.GetQuantity
+		percent := math.Min(rule.Value, 100)
+		for _, u := range units[len(units)-free:] {
+			amounts[u.line] += u.price * percent / 100
+		}
+	}
+
+	return amounts
+}
+
+// allocateMoney splits a rounded amount across lines in proportion to weights, in cents.
+// The rounding remainder goes to the heaviest line so the shares add up to amount exactly.
+func allocateMoney(amount float64, weights []float64) []float64 {
+	shares := make([]float64, len(weights))
+	total := sum(weights)
+	if amount <= 0 || total <= 0 {
+		return shares
+	}
+
+	heaviest := 0
+	var allocated float64
+	for i, weight := range weights {
+		shares[i] = roundMoney(amount * weight / total)
+		allocated += shares[i]
+		if weight > weights[heaviest] {
+			heaviest = i
+		}
+	}
+	shares[heaviest] = roundMoney(shares[heaviest] + amount - allocated)
+	return shares
+}
+
+func sum(values []float64) float64 {
+	var total float64
+	for _, value := range values {
+		total += value
+	}
+	return total
+}
+
+// reapplyPricingRules prices an order's new lines with the rules in effect when it was placed and the
+// promo codes it already redeemed, so editing an order neither loses nor redeems a code again.
+// Codes whose rule has since been deleted are dropped.
+func (s *OrderService) reapplyPricingRules(order *models.Order, lines []models.OrderItem) ([]models.OrderDiscount, float64, error) {
+	var promoCodes []string
+	redeemed := make(map[string]bool)
+	for _, discount := range order.Discounts {
+		if discount.PromoCode != "" && discount.RuleID != "" {
+			promoCodes = append(promoCodes, discount.PromoCode)
+			redeemed[strings.ToUpper(discount.PromoCode)] = true
+		}
+	}
+	return s.applyPricingRules(lines, promoCodes, order.CreatedAt, redeemed)
+}
@@ -0,0 +1,196 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"frappuccino/internal/repositories"
+	"frappuccino/models"
+	"frappuccino/pkg/logger"
+)
+
+type CreatePricingRuleRequest struct {
+	Name        string               `json:"name"`
+	Type        string               `json:"type"`
+	Value       float64              `json:"value"`
+	BuyQuantity int                  `json:"buy_quantity"`
+	GetQuantity int                  `json:"get_quantity"`
+	MenuItemIDs []string             `json:"menu_item_ids"`
+	Category    models.MenuCategory  `json:"category"`
+	Schedule    *models.MenuSchedule `json:"schedule"`
+	PromoCode   string               `json:"promo_code"`
+	MaxUses     *int                 `json:"max_uses"`
+	ExpiresAt   *time.Time           `json:"expires_at"`
+	Active      *bool                `json:"active"` // Defaults to true
+}
+
+type PricingRuleServiceInterface interface {
+	GetAllPricingRules() ([]*models.PricingRule, error)
+	GetPricingRule(id string) (*models.PricingRule, error)
+	CreatePricingRule(req CreatePricingRuleRequest) (*models.PricingRule, error)
+	SetPricingRuleActive(id string, active bool) (*models.PricingRule, error)
+	DeletePricingRule(id string) error
+}
+
+type PricingRuleService struct {
+	pricingRepo repositories.PricingRuleRepositoryInterface
+	menuRepo    repositories.MenuRepositoryInterface
+	logger      *logger.Logger
+}
+
+func NewPricingRuleService(pricingRepo repositories.PricingRuleRepositoryInterface, menuRepo repositories.MenuRepositoryInterface, log *logger.Logger) *PricingRuleService {
+	return &PricingRuleService{
+		pricingRepo: pricingRepo,
+		menuRepo:    menuRepo,
+		logger:      log.WithComponent("pricing_rule_service"),
+	}
+}
+
+// GetAllPricingRules returns every pricing rule
+func (s *PricingRuleService) GetAllPricingRules() ([]*models.PricingRule, error) {
+	s.logger.Info("Fetching all pricing rules")
+
+	rules, err := s.pricingRepo.GetAll()
+	if err != nil {
+		s.logger.Error("Failed to fetch pricing rules", "error", err)
+		return nil, err
+	}
+	return rules, nil
+}
+
+// GetPricingRule returns a single pricing rule
+func (s *PricingRuleService) GetPricingRule(id string) (*models.PricingRule, error) {
+	s.logger.Info("Fetching pricing rule", "rule_id", id)
+
+	rule, err := s.pricingRepo.GetByID(id)
+	if err != nil {
+		s.logger.Warn("Pricing rule not found", "rule_id", id, "error", err)
+		return nil, err
+	}
+	return rule, nil
+}
+
+// CreatePricingRule validates and stores a new pricing rule
+func (s *PricingRuleService) CreatePricingRule(req CreatePricingRuleRequest) (*models.PricingRule, error) {
+	s.logger.Info("Creating pricing rule", "name", req.Name, "type", req.Type)
+
+	rule, err := s.pricingRuleFromRequest(req)
+	if err != nil {
+		s.logger.Warn("Create failed: invalid pricing rule", "name", req.Name, "error", err)
+		return nil, err
+	}
+
+	if err := s.pricingRepo.Create(rule); err != nil {
+		s.logger.Error("Failed to create pricing rule", "name", req.Name, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Pricing rule created", "rule_id", rule.ID, "name", rule.Name)
+	return rule, nil
+}
+
+// SetPricingRuleActive switches a rule on or off; orders that already used it keep their discount
+func (s *PricingRuleService) SetPricingRuleActive(id string, active bool) (*models.PricingRule, error) {
+	s.logger.Info("Updating pricing rule", "rule_id", id, "active", active)
+
+	if err := s.pricingRepo.SetActive(id, active); err != nil {
+		s.logger.Warn("Failed to update pricing rule", "rule_id", id, "error", err)
+		return nil, err
+	}
+	return s.pricingRepo.GetByID(id)
+}
+
+// DeletePricingRule removes a pricing rule
+func (s *PricingRuleService) DeletePricingRule(id string) error {
+	s.logger.Info("Deleting pricing rule", "rule_id", id)
+
+	if err := s.pricingRepo.Delete(id); err != nil {
+		s.logger.Warn("Failed to delete pricing rule", "rule_id", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+func (s *PricingRuleService) pricingRuleFromRequest(req CreatePricingRuleRequest) (*models.PricingRule, error) {
+	rule := &models.PricingRule{
+		Name:        strings.TrimSpace(req.Name),
+		Type:        req.Type,
+		Value:       req.Value,
+		BuyQuantity: req.BuyQuantity,
+		GetQuantity: req.GetQuantity,
+		Category:    req.Category,
+		PromoCode:   strings.ToUpper(strings.TrimSpace(req.PromoCode)),
+		MaxUses:     req.MaxUses,
+		ExpiresAt:   req.ExpiresAt,
+		Active:      req.Active == nil || *req.Active,
+	}
+
+	if rule.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	switch rule.Type {
+	case models.RulePercentage:
+		if rule.Value <= 0 || rule.Value > 100 {
+			return nil, fmt.Errorf("percentage value must be between 0 and 100")
+		}
+	case models.RuleFixed:
+		if rule.Value <= 0 {
+			return nil, fmt.Errorf("fixed value must be positive")
+		}
+	case models.RuleBuyXGetY:
+		if rule.BuyQuantity < 1 || rule.GetQuantity < 1 {
+			return nil, fmt.Errorf("buy_quantity and get_quantity must be at least 1")
+		}
+		if rule.Value == 0 {
+			rule.Value = 100
+		}
+		if rule.Value < 0 || rule.Value > 100 {
+			return nil, fmt.Errorf("buy_x_get_y value must be between 0 and 100")
+		}
+	default:
+		return nil, fmt.Errorf("invalid rule type '%s': must be one of %s, %s, %s", rule.Type, models.RulePercentage, models.RuleFixed, models.RuleBuyXGetY)
+	}
+	if rule.Type != models.RuleBuyXGetY && (rule.BuyQuantity != 0 || rule.GetQuantity != 0) {
+		return nil, fmt.Errorf("buy_quantity and get_quantity are only allowed for %s rules", models.RuleBuyXGetY)
+	}
+
+	seen := make(map[string]bool, len(req.MenuItemIDs))
+	for _, id := range req.MenuItemIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		if _, err := s.menuRepo.GetByID(id); err != nil {
+			return nil, fmt.Errorf("menu item %s not found", id)
+		}
+		rule.MenuItemIDs = append(rule.MenuItemIDs, id)
+	}
+
+	if rule.Category != "" {
+		if err := validateMenuCategory(rule.Category); err != nil {
+			return nil, err
+		}
+	}
+
+	schedule, err := normalizeSchedule(req.Schedule)
+	if err != nil {
+		return nil, err
+	}
+	rule.Schedule = schedule
+
+	if rule.MaxUses != nil {
+		if rule.PromoCode == "" {
+			return nil, fmt.Errorf("max_uses requires a promo code")
+		}
+		if *rule.MaxUses <= 0 {
+			return nil, fmt.Errorf("max_uses must be positive")
+		}
+	}
+	if rule.ExpiresAt != nil && !rule.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("expires_at must be in the future")
+	}
+
+	return rule, nil
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAllocateMoney(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  float64
+		weights []float64
+		want    []float64
+	}{
+		{name: "even split", amount: 6, weights: []float64{1, 1, 1}, want: []float64{2, 2, 2}},
+		{name: "proportional", amount: 5, weights: []float64{3, 0, 7}, want: []float64{1.5, 0, 3.5}},
+		{name: "remainder up to first heaviest", amount: 10, weights: []float64{1, 1, 1}, want: []float64{3.34, 3.33, 3.33}},
+		{name: "remainder to heaviest line", amount: 1, weights: []float64{1, 4, 1}, want: []float64{0.17, 0.66, 0.17}},
+		{name: "over-rounding taken back", amount: 0.05, weights: []float64{1, 1, 1}, want: []float64{0.01, 0.02, 0.02}},
+		{name: "zero amount", amount: 0, weights: []float64{1, 2}, want: []float64{0, 0}},
+		{name: "negative amount", amount: -1, weights: []float64{1, 2}, want: []float64{0, 0}},
+		{name: "zero weights", amount: 3, weights: []float64{0, 0}, want: []float64{0, 0}},
+		{name: "no lines", amount: 3, weights: []float64{}, want: []float64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := allocateMoney(tt.amount, tt.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("allocateMoney(%v, %v) = %v, want %v", tt.amount, tt.weights, got, tt.want)
+			}
+			if tt.amount > 0 && sum(tt.weights) > 0 && roundMoney(sum(got)) != tt.amount {
+				t.Errorf("shares %v add up to %v, want %v", got, sum(got), tt.amount)
+			}
+		})
+	}
+}
@@ -0,0 +1,406 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"frappuccino/internal/repositories"
+	"frappuccino/models"
+	"frappuccino/pkg/logger"
+)
+
+// DefaultReorderCoverDays is how many days of usage a reorder should cover beyond the reorder point
+const DefaultReorderCoverDays = 7
+
+type CreatePurchaseOrderRequest struct {
+	SupplierID string                           `json:"supplier_id"`
+	Notes      string                           `json:"notes"`
+	Items      []CreatePurchaseOrderItemRequest `json:"items"`
+}
+
+type CreatePurchaseOrderItemRequest struct {
+	IngredientID string `json:"ingredient_id"`
+	Packs        int    `json:"packs"`
+}
+
+// ReorderLine is the engine's proposal for one ingredient
+type ReorderLine struct {
+	IngredientID string  `json:"ingredient_id"`
+	Name         string  `json:"name"`
+	Unit         string  `json:"unit"`
+	Quantity     float64 `json:"quantity"`
+	OnOrder      float64 `json:"on_order"` // Already on draft or sent purchase orders
+	MinThreshold float64 `json:"min_threshold"`
+	DailyUsage   float64 `json:"daily_usage"`
+	ReorderPoint float64 `json:"reorder_point"` // min_threshold + usage during the supplier lead time
+	TargetLevel  float64 `json:"target_level"`  // reorder point + usage over the cover period
+	Needed       float64 `json:"needed"`
+	Packs        int     `json:"packs,omitempty"`
+	PackSize     float64 `json:"pack_size,omitempty"`
+	PackPrice    float64 `json:"pack_price,omitempty"`
+	LineTotal    float64 `json:"line_total,omitempty"`
+}
+
+// ProposedPurchaseOrder groups reorder lines by their cheapest supplier
+type ProposedPurchaseOrder struct {
+	SupplierID   string        `json:"supplier_id"`
+	SupplierName string        `json:"supplier_name"`
+	LeadTimeDays int           `json:"lead_time_days"`
+	TotalAmount  float64       `json:"total_amount"`
+	Items        []ReorderLine `json:"items"`
+}
+
+type ReorderProposal struct {
+	CoverDays       int                     `json:"cover_days"`
+	UsageWindowDays int                     `json:"usage_window_days"`
+	Orders          []ProposedPurchaseOrder `json:"orders"`
+	Unsourced       []ReorderLine           `json:"unsourced"` // Items to reorder that no supplier sells
+}
+
+type PurchaseOrderServiceInterface interface {
+	GetPurchaseOrders(status string) ([]*models.PurchaseOrder, error)
+	GetPurchaseOrder(id string) (*models.PurchaseOrder, error)
+	CreatePurchaseOrder(req CreatePurchaseOrderRequest) (*models.PurchaseOrder, error)
+	DeletePurchaseOrder(id string) error
+	SendPurchaseOrder(id string) (*models.PurchaseOrder, error)
+	ReceivePurchaseOrder(id string) (*models.PurchaseOrder, error)
+	CancelPurchaseOrder(id string) (*models.PurchaseOrder, error)
+	GetReorderProposal(coverDays int) (*ReorderProposal, error)
+	GenerateDraftOrders(coverDays int) ([]*models.PurchaseOrder, error)
+}
+
+type PurchaseOrderService struct {
+	purchaseOrderRepo repositories.PurchaseOrderRepositoryInterface
+	supplierRepo      repositories.SupplierRepositoryInterface
+	inventoryRepo     repositories.InventoryRepositoryInterface
+	alertService      AlertServiceInterface
+	logger            *logger.Logger
+}
+
+func NewPurchaseOrderService(purchaseOrderRepo repositories.PurchaseOrderRepositoryInterface, supplierRepo repositories.SupplierRepositoryInterface, inventoryRepo repositories.InventoryRepositoryInterface, alertService AlertServiceInterface, log *logger.Logger) *PurchaseOrderService {
+	return &PurchaseOrderService{
+		purchaseOrderRepo: purchaseOrderRepo,
+		supplierRepo:      supplierRepo,
+		inventoryRepo:     inventoryRepo,
+		alertService:      alertService,
+		logger:            log.WithComponent("purchase_order_service"),
+	}
+}
+
+// GetPurchaseOrders lists purchase orders, optionally filtered by status
+func (s *PurchaseOrderService) GetPurchaseOrders(status string) ([]*models.PurchaseOrder, error) {
+	s.logger.Info("Fetching purchase orders", "status", status)
+
+	if status != "" && !isPurchaseOrderStatus(status) {
+		return nil, fmt.Errorf("invalid status filter (allowed: draft, sent, received, cancelled)")
+	}
+
+	orders, err := s.purchaseOrderRepo.GetAll(status)
+	if err != nil {
+		s.logger.Error("Failed to fetch purchase orders", "error", err)
+		return nil, err
+	}
+	return orders, nil
+}
+
+// GetPurchaseOrder returns a single purchase order
+func (s *PurchaseOrderService) GetPurchaseOrder(id string) (*models.PurchaseOrder, error) {
+	s.logger.Info("Fetching purchase order", "purchase_order_id", id)
+	return s.purchaseOrderRepo.GetByID(id)
+}
+
+// CreatePurchaseOrder creates a draft, pricing each line from the supplier's catalog
+func (s *PurchaseOrderService) CreatePurchaseOrder(req CreatePurchaseOrderRequest) (*models.PurchaseOrder, error) {
+	s.logger.Info("Creating purchase order", "supplier_id", req.SupplierID, "items", len(req.Items))
+
+	if req.SupplierID == "" {
+		return nil, fmt.Errorf("supplier_id is required")
+	}
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("purchase order must have at least one item")
+	}
+
+	supplier, err := s.supplierRepo.GetByID(req.SupplierID)
+	if err != nil {
+		s.logger.Warn("Create failed: supplier not found", "supplier_id", req.SupplierID, "error", err)
+		return nil, err
+	}
+
+	catalog := make(map[string]models.SupplierItem, len(supplier.Items))
+	for _, item := range supplier.Items {
+		catalog[item.IngredientID] = item
+	}
+
+	order := &models.PurchaseOrder{
+		SupplierID:   supplier.ID,
+		SupplierName: supplier.Name,
+		Status:       models.PurchaseOrderDraft,
+		Notes:        req.Notes,
+		Items:        make([]models.PurchaseOrderItem, 0, len(req.Items)),
+	}
+
+	seen := make(map[string]bool, len(req.Items))
+	for i, item := range req.Items {
+		if item.Packs <= 0 {
+			return nil, fmt.Errorf("item %d: packs must be positive", i+1)
+		}
+		if seen[item.IngredientID] {
+			return nil, fmt.Errorf("item %d: duplicate ingredient '%s'", i+1, item.IngredientID)
+		}
+		seen[item.IngredientID] = true
+
+		offer, ok := catalog[item.IngredientID]
+		if !ok {
+			return nil, fmt.Errorf("item %d: supplier '%s' does not sell ingredient '%s'", i+1, supplier.Name, item.IngredientID)
+		}
+
+		order.Items = append(order.Items, models.PurchaseOrderItem{
+			IngredientID: item.IngredientID,
+			Packs:        item.Packs,
+			PackSize:     offer.PackSize,
+			PackPrice:    offer.PackPrice,
+		})
+	}
+
+	if err := s.purchaseOrderRepo.Create(order); err != nil {
+		s.logger.Error("Failed to create purchase order", "supplier_id", req.SupplierID, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Purchase order created", "purchase_order_id", order.ID, "total_amount", order.TotalAmount)
+	return s.purchaseOrderRepo.GetByID(order.ID)
+}
+
+// DeletePurchaseOrder removes a draft purchase order
+func (s *PurchaseOrderService) DeletePurchaseOrder(id string) error {
+	s.logger.Info("Deleting purchase order", "purchase_order_id", id)
+	return s.purchaseOrderRepo.Delete(id)
+}
+
+// SendPurchaseOrder marks a draft as sent to the supplier
+func (s *PurchaseOrderService) SendPurchaseOrder(id string) (*models.PurchaseOrder, error) {
+	s.logger.Info("Sending purchase order", "purchase_order_id", id)
+
+	if err := s.purchaseOrderRepo.UpdateStatus(id, models.PurchaseOrderDraft, models.PurchaseOrderSent); err != nil {
+		s.logger.Warn("Failed to send purchase order", "purchase_order_id", id, "error", err)
+		return nil, err
+	}
+	return s.purchaseOrderRepo.GetByID(id)
+}
+
+// ReceivePurchaseOrder books a sent purchase order into stock
+func (s *PurchaseOrderService) ReceivePurchaseOrder(id string) (*models.PurchaseOrder, error) {
+	s.logger.Info("Receiving purchase order", "purchase_order_id", id)
+
+	transactions, err := s.purchaseOrderRepo.Receive(id)
+	if err != nil {
+		s.logger.Warn("Failed to receive purchase order", "purchase_order_id", id, "error", err)
+		return nil, err
+	}
+
+	// Received stock may clear open low-stock alerts
+	s.alertService.CheckStockLevels(transactionIngredientIDs(transactions))
+
+	s.logger.Info("Purchase order received", "purchase_order_id", id, "lines", len(transactions))
+	return s.purchaseOrderRepo.GetByID(id)
+}
+
+// CancelPurchaseOrder cancels a draft or sent purchase order
+func (s *PurchaseOrderService) CancelPurchaseOrder(id string) (*models.PurchaseOrder, error) {
+	s.logger.Info("Cancelling purchase order", "purchase_order_id", id)
+
+	order, err := s.purchaseOrderRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if order.Status != models.PurchaseOrderDraft && order.Status != models.PurchaseOrderSent {
+		return nil, fmt.Errorf("purchase order is %s, only draft or sent orders can be cancelled", order.Status)
+	}
+
+	if err := s.purchaseOrderRepo.UpdateStatus(id, order.Status, models.PurchaseOrderCancelled); err != nil {
+		s.logger.Warn("Failed to cancel purchase order", "purchase_order_id", id, "error", err)
+		return nil, err
+	}
+	return s.purchaseOrderRepo.GetByID(id)
+}
+
+// GetReorderProposal works out what to buy: an item is reordered when stock plus stock already on order
+// is at or below min_threshold plus the usage expected during the supplier's lead time. The order tops it
+// up to that reorder point plus coverDays of usage, rounded up to whole packs of the cheapest supplier.
+func (s *PurchaseOrderService) GetReorderProposal(coverDays int) (*ReorderProposal, error) {
+	s.logger.Info("Calculating reorder proposal", "cover_days", coverDays)
+
+	if coverDays <= 0 {
+		coverDays = DefaultReorderCoverDays
+	}
+
+	inventoryItems, err := s.inventoryRepo.GetAll()
+	if err != nil {
+		s.logger.Error("Failed to get inventory for reorder", "error", err)
+		return nil, err
+	}
+
+	usageRates, err := s.inventoryRepo.GetUsageRates(DefaultUsageWindowDays)
+	if err != nil {
+		s.logger.Error("Failed to get usage rates for reorder", "error", err)
+		return nil, err
+	}
+
+	onOrder, err := s.purchaseOrderRepo.GetOnOrderQuantities()
+	if err != nil {
+		s.logger.Error("Failed to get on-order quantities for reorder", "error", err)
+		return nil, err
+	}
+
+	suppliers, err := s.supplierRepo.GetAll()
+	if err != nil {
+		s.logger.Error("Failed to get suppliers for reorder", "error", err)
+		return nil, err
+	}
+
+	proposal := &ReorderProposal{
+		CoverDays:       coverDays,
+		UsageWindowDays: DefaultUsageWindowDays,
+		Orders:          make([]ProposedPurchaseOrder, 0),
+		Unsourced:       make([]ReorderLine, 0),
+	}
+	orders := make(map[string]*ProposedPurchaseOrder)
+
+	for _, item := range inventoryItems {
+		supplier, offer := cheapestOffer(suppliers, item.IngredientID)
+
+		leadTime := 0
+		if supplier != nil {
+			leadTime = supplier.LeadTimeDays
+		}
+
+		dailyUsage := usageRates[item.IngredientID]
+		line := ReorderLine{
+			IngredientID: item.IngredientID,
+			Name:         item.Name,
+			Unit:         item.Unit,
+			Quantity:     item.Quantity,
+			OnOrder:      onOrder[item.IngredientID],
+			MinThreshold: item.MinThreshold,
+			DailyUsage:   roundQuantity(dailyUsage),
+			ReorderPoint: item.MinThreshold + dailyUsage*float64(leadTime),
+		}
+		line.TargetLevel = line.ReorderPoint + dailyUsage*float64(coverDays)
+
+		position := line.Quantity + line.OnOrder
+		if position > line.ReorderPoint {
+			continue
+		}
+		line.Needed = line.TargetLevel - position
+		if line.Needed <= 0 {
+			continue
+		}
+
+		line.ReorderPoint = roundQuantity(line.ReorderPoint)
+		line.TargetLevel = roundQuantity(line.TargetLevel)
+		line.Needed = roundQuantity(line.Needed)
+
+		if supplier == nil {
+			proposal.Unsourced = append(proposal.Unsourced, line)
+			continue
+		}
+
+		line.PackSize = offer.PackSize
+		line.PackPrice = offer.PackPrice
+		line.Packs = int(math.Ceil(line.Needed / offer.PackSize))
+		line.LineTotal = roundMoney(float64(line.Packs) * offer.PackPrice)
+
+		order, ok := orders[supplier.ID]
+		if !ok {
+			order = &ProposedPurchaseOrder{
+				SupplierID:   supplier.ID,
+				SupplierName: supplier.Name,
+				LeadTimeDays: supplier.LeadTimeDays,
+			}
+			orders[supplier.ID] = order
+		}
+		order.Items = append(order.Items, line)
+		order.TotalAmount = roundMoney(order.TotalAmount + line.LineTotal)
+	}
+
+	for _, order := range orders {
+		proposal.Orders = append(proposal.Orders, *order)
+	}
+	sort.Slice(proposal.Orders, func(i, j int) bool {
+		return proposal.Orders[i].SupplierName < proposal.Orders[j].SupplierName
+	})
+
+	s.logger.Info("Reorder proposal calculated", "orders", len(proposal.Orders), "unsourced", len(proposal.Unsourced))
+	return proposal, nil
+}
+
+// GenerateDraftOrders turns the current reorder proposal into draft purchase orders
+func (s *PurchaseOrderService) GenerateDraftOrders(coverDays int) ([]*models.PurchaseOrder, error) {
+	proposal, err := s.GetReorderProposal(coverDays)
+	if err != nil {
+		return nil, err
+	}
+
+	created := make([]*models.PurchaseOrder, 0, len(proposal.Orders))
+	for _, proposed := range proposal.Orders {
+		order := &models.PurchaseOrder{
+			SupplierID: proposed.SupplierID,
+			Status:     models.PurchaseOrderDraft,
+			Notes:      "Generated by reorder engine",
+			Items:      make([]models.PurchaseOrderItem, 0, len(proposed.Items)),
+		}
+		for _, line := range proposed.Items {
+			order.Items = append(order.Items, models.PurchaseOrderItem{
+				IngredientID: line.IngredientID,
+				Packs:        line.Packs,
+				PackSize:     line.PackSize,
+				PackPrice:    line.PackPrice,
+			})
+		}
+
+		if err := s.purchaseOrderRepo.Create(order); err != nil {
+			s.logger.Error("Failed to create draft purchase order", "supplier_id", proposed.SupplierID, "error", err)
+			return created, err
+		}
+
+		saved, err := s.purchaseOrderRepo.GetByID(order.ID)
+		if err != nil {
+			return created, err
+		}
+		created = append(created, saved)
+	}
+
+	s.logger.Info("Generated draft purchase orders", "count", len(created))
+	return created, nil
+}
+
+// cheapestOffer picks the supplier with the lowest unit cost for an ingredient, preferring shorter lead times on ties
+func cheapestOffer(suppliers []*models.Supplier, ingredientID string) (*models.Supplier, models.SupplierItem) {
+	var best *models.Supplier
+	var bestOffer models.SupplierItem
+
+	for _, supplier := range suppliers {
+		for _, offer := range supplier.Items {
+			if offer.IngredientID != ingredientID {
+				continue
+			}
+			if best == nil ||
+				offer.UnitCost() < bestOffer.UnitCost() ||
+				(offer.UnitCost() == bestOffer.UnitCost() && supplier.LeadTimeDays < best.LeadTimeDays) {
+				best = supplier
+				bestOffer = offer
+			}
+		}
+	}
+
+	return best, bestOffer
+}
+
+func isPurchaseOrderStatus(status string) bool {
+	switch status {
+	case models.PurchaseOrderDraft, models.PurchaseOrderSent, models.PurchaseOrderReceived, models.PurchaseOrderCancelled:
+		return true
+	}
+	return false
+}
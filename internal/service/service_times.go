@@ -0,0 +1,265 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"frappuccino/internal/repositories"
+)
+
+// maxServiceTimeOutliers caps the outlier orders listed in the service times report
+const maxServiceTimeOutliers = 50
+
+// ServiceTimesReport is how long orders created in a period took between statuses, overall and grouped by the
+// hour and weekday they were placed in the shop's timezone and by the categories they contained
+type ServiceTimesReport struct {
+	From      string               `json:"from,omitempty"`
+	To        string               `json:"to,omitempty"`
+	Timezone  string               `json:"timezone"`
+	Orders    int                  `json:"orders"`
+	Overall   ServiceTimes         `json:"overall"`
+	ByHour    []ServiceTimeGroup   `json:"by_hour"`     // Keyed 00 to 23
+	ByWeekday []ServiceTimeGroup   `json:"by_weekday"`  // Keyed mon to sun
+	ByItemMix []ServiceTimeGroup   `json:"by_item_mix"` // Keyed by the order's categories, e.g. coffee+pastry
+	Outliers  []ServiceTimeOutlier `json:"outliers"`
+}
+
+// ServiceTimes are the durations between order statuses. An order only counts towards the stages it went through.
+type ServiceTimes struct {
+	PendingToPreparing StageTime `json:"pending_to_preparing"`
+	PreparingToReady   StageTime `json:"preparing_to_ready"`
+	ReadyToClosed      StageTime `json:"ready_to_closed"`
+	PendingToReady     StageTime `json:"pending_to_ready"` // The wait customers see
+	PendingToClosed    StageTime `json:"pending_to_closed"`
+}
+
+// StageTime summarises the duration of one stage in seconds
+type StageTime struct {
+	Orders     int     `json:"orders"`
+	AvgSeconds float64 `json:"avg_seconds"`
+	P50Seconds float64 `json:"p50_seconds"`
+	P90Seconds float64 `json:"p90_seconds"`
+}
+
+type ServiceTimeGroup struct {
+	Key    string `json:"key"`
+	Orders int    `json:"orders"`
+	ServiceTimes
+}
+
+// ServiceTimeOutlier is an order that took unusually long to be ready
+type ServiceTimeOutlier struct {
+	OrderID               string    `json:"order_id"`
+	CustomerName          string    `json:"customer_name"`
+	CreatedAt             time.Time `json:"created_at"`
+	ItemMix               string    `json:"item_mix"`
+	Items                 int       `json:"items"`
+	PendingToReadySeconds float64   `json:"pending_to_ready_seconds"`
+	TimesMedian           float64   `json:"times_median"` // How many times the median wait it took
+}
+
+// serviceTimeSamples collects stage durations in seconds
+type serviceTimeSamples struct {
+	orders                                              int
+	pendingToPreparing, preparingToReady, readyToClosed []float64
+	pendingToReady, pendingToClosed                     []float64
+}
+
+var weekdayKeys = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// GetServiceTimesReport computes the service times of orders created in [from, to) from their status history.
+// Orders whose wait to ready lies above the upper Tukey fence of all waits are listed as outliers, longest first.
+func (s *AggregationService) GetServiceTimesReport(from, to *time.Time) (*ServiceTimesReport, error) {
+	s.logger.Info("Calculating service times report", "from", from, "to", to)
+
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, errors.New("invalid date range: 'from' must be before 'to'")
+	}
+
+	timings, err := s.aggregationRepo.GetOrderServiceTimes(from, to)
+	if err != nil {
+		s.logger.Error("Failed to get order service times", "error", err)
+		return nil, err
+	}
+
+	report := &ServiceTimesReport{
+		Timezone:  s.location.String(),
+		Orders:    len(timings),
+		ByHour:    make([]ServiceTimeGroup, 0),
+		ByWeekday: make([]ServiceTimeGroup, 0),
+		ByItemMix: make([]ServiceTimeGroup, 0),
+		Outliers:  make([]ServiceTimeOutlier, 0),
+	}
+	if from != nil {
+		report.From = from.Format("2006-01-02")
+	}
+	if to != nil {
+		report.To = to.AddDate(0, 0, -1).Format("2006-01-02")
+	}
+
+	overall := &serviceTimeSamples{}
+	byHour := make(map[int]*serviceTimeSamples)
+	byWeekday := make(map[time.Weekday]*serviceTimeSamples)
+	byItemMix := make(map[string]*serviceTimeSamples)
+
+	for _, timing := range timings {
+		placed := timing.CreatedAt.In(s.location)
+		mix := itemMix(timing.Categories)
+
+		if byHour[placed.Hour()] == nil {
+			byHour[placed.Hour()] = &serviceTimeSamples{}
+		}
+		if byWeekday[placed.Weekday()] == nil {
+			byWeekday[placed.Weekday()] = &serviceTimeSamples{}
+		}
+		if byItemMix[mix] == nil {
+			byItemMix[mix] = &serviceTimeSamples{}
+		}
+		for _, samples := range []*serviceTimeSamples{overall, byHour[placed.Hour()], byWeekday[placed.Weekday()], byItemMix[mix]} {
+			samples.add(timing)
+		}
+	}
+
+	report.Overall = overall.summarise()
+	for hour := 0; hour < 24; hour++ {
+		if samples, ok := byHour[hour]; ok {
+			report.ByHour = append(report.ByHour, ServiceTimeGroup{Key: fmt.Sprintf("%02d", hour), Orders: samples.orders, ServiceTimes: samples.summarise()})
+		}
+	}
+	// Weeks start on Monday
+	for day := 1; day <= 7; day++ {
+		weekday := time.Weekday(day % 7)
+		if samples, ok := byWeekday[weekday]; ok {
+			report.ByWeekday = append(report.ByWeekday, ServiceTimeGroup{Key: weekdayKeys[weekday], Orders: samples.orders, ServiceTimes: samples.summarise()})
+		}
+	}
+	for mix, samples := range byItemMix {
+		report.ByItemMix = append(report.ByItemMix, ServiceTimeGroup{Key: mix, Orders: samples.orders, ServiceTimes: samples.summarise()})
+	}
+	sort.Slice(report.ByItemMix, func(i, j int) bool {
+		if report.ByItemMix[i].Orders != report.ByItemMix[j].Orders {
+			return report.ByItemMix[i].Orders > report.ByItemMix[j].Orders
+		}
+		return report.ByItemMix[i].Key < report.ByItemMix[j].Key
+	})
+
+	report.Outliers = serviceTimeOutliers(timings, overall.pendingToReady)
+
+	s.logger.Info("Service times report calculated successfully", "orders", report.Orders, "outliers", len(report.Outliers))
+	return report, nil
+}
+
+func (samples *serviceTimeSamples) add(timing repositories.OrderServiceTime) {
+	samples.orders++
+	appendStage(&samples.pendingToPreparing, &timing.CreatedAt, timing.PreparingAt)
+	appendStage(&samples.preparingToReady, timing.PreparingAt, timing.ReadyAt)
+	appendStage(&samples.readyToClosed, timing.ReadyAt, timing.ClosedAt)
+	appendStage(&samples.pendingToReady, &timing.CreatedAt, timing.ReadyAt)
+	appendStage(&samples.pendingToClosed, &timing.CreatedAt, timing.ClosedAt)
+}
+
+// appendStage records the seconds between two status changes, if the order went through both in order
+func appendStage(durations *[]float64, start, end *time.Time) {
+	if start == nil || end == nil || end.Before(*start) {
+		return
+	}
+	*durations = append(*durations, end.Sub(*start).Seconds())
+}
+
+func (samples *serviceTimeSamples) summarise() ServiceTimes {
+	return ServiceTimes{
+		PendingToPreparing: stageTime(samples.pendingToPreparing),
+		PreparingToReady:   stageTime(samples.preparingToReady),
+		ReadyToClosed:      stageTime(samples.readyToClosed),
+		PendingToReady:     stageTime(samples.pendingToReady),
+		PendingToClosed:    stageTime(samples.pendingToClosed),
+	}
+}
+
+func stageTime(durations []float64) StageTime {
+	if len(durations) == 0 {
+		return StageTime{}
+	}
+
+	sorted := append([]float64(nil), durations...)
+	sort.Float64s(sorted)
+
+	var total float64
+	for _, duration := range sorted {
+		total += duration
+	}
+	return StageTime{
+		Orders:     len(sorted),
+		AvgSeconds: math.Round(total / float64(len(sorted))),
+		P50Seconds: math.Round(percentile(sorted, 50)),
+		P90Seconds: math.Round(percentile(sorted, 90)),
+	}
+}
+
+// percentile interpolates between the closest ranks of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+// serviceTimeOutliers lists the orders whose wait to ready lies above q3 + 1.5 * IQR of all waits
+func serviceTimeOutliers(timings []repositories.OrderServiceTime, waits []float64) []ServiceTimeOutlier {
+	outliers := make([]ServiceTimeOutlier, 0)
+	// Too few orders to tell what is unusual
+	if len(waits) < 4 {
+		return outliers
+	}
+
+	sorted := append([]float64(nil), waits...)
+	sort.Float64s(sorted)
+	q1, median, q3 := percentile(sorted, 25), percentile(sorted, 50), percentile(sorted, 75)
+	fence := q3 + 1.5*(q3-q1)
+
+	for _, timing := range timings {
+		if timing.ReadyAt == nil || timing.ReadyAt.Before(timing.CreatedAt) {
+			continue
+		}
+		wait := timing.ReadyAt.Sub(timing.CreatedAt).Seconds()
+		if wait <= fence {
+			continue
+		}
+
+		outlier := ServiceTimeOutlier{
+			OrderID:               timing.OrderID,
+			CustomerName:          timing.CustomerName,
+			CreatedAt:             timing.CreatedAt,
+			ItemMix:               itemMix(timing.Categories),
+			Items:                 timing.Items,
+			PendingToReadySeconds: math.Round(wait),
+		}
+		if median > 0 {
+			outlier.TimesMedian = roundMoney(wait / median)
+		}
+		outliers = append(outliers, outlier)
+	}
+
+	sort.Slice(outliers, func(i, j int) bool {
+		return outliers[i].PendingToReadySeconds > outliers[j].PendingToReadySeconds
+	})
+	if len(outliers) > maxServiceTimeOutliers {
+		outliers = outliers[:maxServiceTimeOutliers]
+	}
+	return outliers
+}
+
+// itemMix names an order's mix of items by its sorted categories, e.g. coffee+pastry
+func itemMix(categories []string) string {
+	if len(categories) == 0 {
+		return "none"
+	}
+	return strings.Join(categories, "+")
+}
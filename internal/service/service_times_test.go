@@ -0,0 +1,172 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"frappuccino/internal/repositories"
+)
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		name   string
+		sorted []float64
+		p      float64
+		want   float64
+	}{
+		{name: "empty", sorted: nil, p: 50, want: 0},
+		{name: "single value", sorted: []float64{5}, p: 90, want: 5},
+		{name: "median of odd count", sorted: []float64{1, 2, 3}, p: 50, want: 2},
+		{name: "median interpolates", sorted: []float64{10, 20, 30, 40}, p: 50, want: 25},
+		{name: "p90 interpolates", sorted: []float64{10, 20, 30, 40}, p: 90, want: 37},
+		{name: "p0 is minimum", sorted: []float64{10, 20, 30, 40}, p: 0, want: 10},
+		{name: "p100 is maximum", sorted: []float64{10, 20, 30, 40}, p: 100, want: 40},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := percentile(tt.sorted, tt.p); got != tt.want {
+				t.Errorf("percentile(%v, %v) = %v, want %v", tt.sorted, tt.p, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStageTime(t *testing.T) {
+	tests := []struct {
+		name      string
+		durations []float64
+		want      StageTime
+	}{
+		{name: "no orders", durations: nil, want: StageTime{}},
+		{name: "unsorted input", durations: []float64{40, 10, 30, 20}, want: StageTime{Orders: 4, AvgSeconds: 25, P50Seconds: 25, P90Seconds: 37}},
+		{name: "rounds seconds", durations: []float64{1, 2}, want: StageTime{Orders: 2, AvgSeconds: 2, P50Seconds: 2, P90Seconds: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stageTime(tt.durations); got != tt.want {
+				t.Errorf("stageTime(%v) = %+v, want %+v", tt.durations, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAppendStage(t *testing.T) {
+	start := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	later := start.Add(90 * time.Second)
+	earlier := start.Add(-time.Minute)
+
+	var durations []float64
+	appendStage(&durations, &start, &later)
+	appendStage(&durations, &start, nil)
+	appendStage(&durations, nil, &later)
+	appendStage(&durations, &start, &earlier)
+
+	if len(durations) != 1 || durations[0] != 90 {
+		t.Errorf("durations = %v, want [90]", durations)
+	}
+}
+
+func TestServiceTimeOutliers(t *testing.T) {
+	created := time.Date(2024, 1, 1, 9, 0, 0, 0, time.UTC)
+	timing := func(id string, wait time.Duration) repositories.OrderServiceTime {
+		ready := created.Add(wait)
+		return repositories.OrderServiceTime{OrderID: id, CreatedAt: created, ReadyAt: &ready, Categories: []string{"coffee"}, Items: 1}
+	}
+	waits := func(timings []repositories.OrderServiceTime) []float64 {
+		var seconds []float64
+		for _, timing := range timings {
+			if timing.ReadyAt != nil {
+				seconds = append(seconds, timing.ReadyAt.Sub(timing.CreatedAt).Seconds())
+			}
+		}
+		return seconds
+	}
+
+	tests := []struct {
+		name      string
+		timings   []repositories.OrderServiceTime
+		wantIDs   []string
+		wantTimes []float64
+	}{
+		{
+			name:    "too few orders",
+			timings: []repositories.OrderServiceTime{timing("a", time.Minute), timing("b", time.Minute), timing("c", time.Hour)},
+		},
+		{
+			name: "above the fence",
+			timings: []repositories.OrderServiceTime{
+				timing("a", time.Minute), timing("b", time.Minute), timing("c", time.Minute), timing("d", time.Minute), timing("slow", 10*time.Minute),
+			},
+			wantIDs:   []string{"slow"},
+			wantTimes: []float64{10},
+		},
+		{
+			name: "longest first",
+			timings: []repositories.OrderServiceTime{
+				timing("a", 2*time.Minute), timing("b", 2*time.Minute), timing("c", 2*time.Minute), timing("d", 2*time.Minute),
+				timing("e", 2*time.Minute), timing("f", 2*time.Minute), timing("g", 2*time.Minute), timing("h", 2*time.Minute),
+				timing("slow", 10*time.Minute), timing("slowest", 20*time.Minute),
+			},
+			wantIDs:   []string{"slowest", "slow"},
+			wantTimes: []float64{10, 5},
+		},
+		{
+			name: "within the fence of a wide spread",
+			timings: []repositories.OrderServiceTime{
+				timing("a", 2*time.Minute), timing("b", 2*time.Minute), timing("c", 2*time.Minute), timing("d", 2*time.Minute),
+				timing("e", 2*time.Minute), timing("slow", 10*time.Minute), timing("slowest", 20*time.Minute),
+			},
+			wantIDs:   []string{"slowest"},
+			wantTimes: []float64{10},
+		},
+		{
+			name: "spread out waits have none",
+			timings: []repositories.OrderServiceTime{
+				timing("a", time.Minute), timing("b", 2*time.Minute), timing("c", 3*time.Minute), timing("d", 4*time.Minute),
+			},
+		},
+		{
+			name: "orders never ready are skipped",
+			timings: []repositories.OrderServiceTime{
+				timing("a", time.Minute), timing("b", time.Minute), timing("c", time.Minute), timing("d", time.Minute),
+				{OrderID: "open", CreatedAt: created},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serviceTimeOutliers(tt.timings, waits(tt.timings))
+			if len(got) != len(tt.wantIDs) {
+				t.Fatalf("serviceTimeOutliers() = %+v, want orders %v", got, tt.wantIDs)
+			}
+			for i, outlier := range got {
+				if outlier.OrderID != tt.wantIDs[i] || outlier.TimesMedian != tt.wantTimes[i] {
+					t.Errorf("outlier %d = %s at %vx median, want %s at %vx", i, outlier.OrderID, outlier.TimesMedian, tt.wantIDs[i], tt.wantTimes[i])
+				}
+				if outlier.ItemMix != "coffee" {
+					t.Errorf("outlier %d item mix = %q, want coffee", i, outlier.ItemMix)
+				}
+			}
+		})
+	}
+}
+
+func TestItemMix(t *testing.T) {
+	tests := []struct {
+		categories []string
+		want       string
+	}{
+		{nil, "none"},
+		{[]string{"coffee"}, "coffee"},
+		{[]string{"coffee", "pastry"}, "coffee+pastry"},
+	}
+
+	for _, tt := range tests {
+		if got := itemMix(tt.categories); got != tt.want {
+			t.Errorf("itemMix(%v) = %q, want %q", tt.categories, got, tt.want)
+		}
+	}
+}
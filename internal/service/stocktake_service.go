@@ -0,0 +1,208 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"frappuccino/internal/repositories"
+	"frappuccino/models"
+	"frappuccino/pkg/logger"
+)
+
+type CreateStocktakeRequest struct {
+	Notes string `json:"notes"`
+}
+
+type SubmitCountsRequest struct {
+	Counts []StocktakeCountRequest `json:"counts"`
+}
+
+type StocktakeCountRequest struct {
+	IngredientID    string  `json:"ingredient_id"`
+	CountedQuantity float64 `json:"counted_quantity"` // In the ingredient's inventory unit
+}
+
+type ApproveStocktakeRequest struct {
+	ApprovedBy string `json:"approved_by"`
+}
+
+// StocktakeVarianceReport shows counting differences over time; shrinkage is stock lost without a record
+type StocktakeVarianceReport struct {
+	From                *time.Time                        `json:"from,omitempty"`
+	To                  *time.Time                        `json:"to,omitempty"`
+	StocktakesCount     int                               `json:"stocktakes_count"`
+	TotalVarianceValue  float64                           `json:"total_variance_value"`
+	TotalShrinkageValue float64                           `json:"total_shrinkage_value"`
+	Sessions            []models.StocktakeSessionVariance `json:"sessions"`
+	Items               []models.IngredientVariance       `json:"items"`
+}
+
+type StocktakeServiceInterface interface {
+	GetStocktakes(status string) ([]*models.Stocktake, error)
+	GetStocktake(id string) (*models.Stocktake, error)
+	CreateStocktake(req CreateStocktakeRequest) (*models.Stocktake, error)
+	SubmitCounts(id string, req SubmitCountsRequest) (*models.Stocktake, error)
+	ApproveStocktake(id string, req ApproveStocktakeRequest) (*models.Stocktake, error)
+	CancelStocktake(id string) (*models.Stocktake, error)
+	GetVarianceReport(from, to *time.Time) (*StocktakeVarianceReport, error)
+}
+
+type StocktakeService struct {
+	stocktakeRepo repositories.StocktakeRepositoryInterface
+	alertService  AlertServiceInterface
+	logger        *logger.Logger
+}
+
+func NewStocktakeService(stocktakeRepo repositories.StocktakeRepositoryInterface, alertService AlertServiceInterface, log *logger.Logger) *StocktakeService {
+	return &StocktakeService{
+		stocktakeRepo: stocktakeRepo,
+		alertService:  alertService,
+		logger:        log.WithComponent("stocktake_service"),
+	}
+}
+
+// GetStocktakes lists stocktakes, optionally filtered by status
+func (s *StocktakeService) GetStocktakes(status string) ([]*models.Stocktake, error) {
+	if status != "" && !isStocktakeStatus(status) {
+		return nil, fmt.Errorf("invalid stocktake status '%s'", status)
+	}
+	return s.stocktakeRepo.GetAll(status)
+}
+
+// GetStocktake returns a stocktake with its counts and variances
+func (s *StocktakeService) GetStocktake(id string) (*models.Stocktake, error) {
+	return s.stocktakeRepo.GetByID(id)
+}
+
+// CreateStocktake opens a counting session
+func (s *StocktakeService) CreateStocktake(req CreateStocktakeRequest) (*models.Stocktake, error) {
+	s.logger.Info("Opening stocktake")
+
+	stocktake := &models.Stocktake{Notes: strings.TrimSpace(req.Notes)}
+	if err := s.stocktakeRepo.Create(stocktake); err != nil {
+		s.logger.Warn("Failed to open stocktake", "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Stocktake opened", "stocktake_id", stocktake.ID)
+	return stocktake, nil
+}
+
+// SubmitCounts records counted quantities; counting an ingredient again replaces its previous count
+func (s *StocktakeService) SubmitCounts(id string, req SubmitCountsRequest) (*models.Stocktake, error) {
+	s.logger.Info("Submitting stocktake counts", "stocktake_id", id, "counts", len(req.Counts))
+
+	if len(req.Counts) == 0 {
+		return nil, fmt.Errorf("at least one count is required")
+	}
+
+	seen := make(map[string]bool, len(req.Counts))
+	counts := make([]models.StocktakeCount, 0, len(req.Counts))
+	for i, count := range req.Counts {
+		if count.IngredientID == "" {
+			return nil, fmt.Errorf("count %d: ingredient_id is required", i+1)
+		}
+		if seen[count.IngredientID] {
+			return nil, fmt.Errorf("count %d: duplicate ingredient '%s'", i+1, count.IngredientID)
+		}
+		seen[count.IngredientID] = true
+
+		if count.CountedQuantity < 0 {
+			return nil, fmt.Errorf("count %d: counted quantity must be non-negative", i+1)
+		}
+		counts = append(counts, models.StocktakeCount{
+			IngredientID:    count.IngredientID,
+			CountedQuantity: roundQuantity(count.CountedQuantity),
+		})
+	}
+
+	if err := s.stocktakeRepo.SubmitCounts(id, counts); err != nil {
+		s.logger.Warn("Failed to submit stocktake counts", "stocktake_id", id, "error", err)
+		return nil, err
+	}
+
+	return s.stocktakeRepo.GetByID(id)
+}
+
+// ApproveStocktake books every variance as an adjustment transaction and closes the stocktake
+func (s *StocktakeService) ApproveStocktake(id string, req ApproveStocktakeRequest) (*models.Stocktake, error) {
+	s.logger.Info("Approving stocktake", "stocktake_id", id)
+
+	transactions, err := s.stocktakeRepo.Approve(id, strings.TrimSpace(req.ApprovedBy))
+	if err != nil {
+		s.logger.Warn("Failed to approve stocktake", "stocktake_id", id, "error", err)
+		return nil, err
+	}
+
+	if len(transactions) > 0 {
+		s.alertService.CheckStockLevels(transactionIngredientIDs(transactions))
+	}
+
+	s.logger.Info("Stocktake approved", "stocktake_id", id, "adjustments", len(transactions))
+	return s.stocktakeRepo.GetByID(id)
+}
+
+// CancelStocktake discards an open stocktake
+func (s *StocktakeService) CancelStocktake(id string) (*models.Stocktake, error) {
+	s.logger.Info("Cancelling stocktake", "stocktake_id", id)
+
+	if err := s.stocktakeRepo.Cancel(id); err != nil {
+		s.logger.Warn("Failed to cancel stocktake", "stocktake_id", id, "error", err)
+		return nil, err
+	}
+	return s.stocktakeRepo.GetByID(id)
+}
+
+// GetVarianceReport summarises approved stocktakes in [from, to) per session and per ingredient
+func (s *StocktakeService) GetVarianceReport(from, to *time.Time) (*StocktakeVarianceReport, error) {
+	s.logger.Info("Building stocktake variance report", "from", from, "to", to)
+
+	if from != nil && to != nil && !from.Before(*to) {
+		return nil, fmt.Errorf("invalid date range: from must be before to")
+	}
+
+	sessions, err := s.stocktakeRepo.GetSessionVariances(from, to)
+	if err != nil {
+		s.logger.Error("Failed to fetch stocktake variances", "error", err)
+		return nil, err
+	}
+	items, err := s.stocktakeRepo.GetIngredientVariances(from, to)
+	if err != nil {
+		s.logger.Error("Failed to fetch ingredient variances", "error", err)
+		return nil, err
+	}
+
+	report := &StocktakeVarianceReport{
+		From:            from,
+		To:              to,
+		StocktakesCount: len(sessions),
+		Sessions:        sessions,
+		Items:           items,
+	}
+	for i := range report.Sessions {
+		session := &report.Sessions[i]
+		session.VarianceValue = roundMoney(session.VarianceValue)
+		session.ShrinkageValue = roundMoney(session.ShrinkageValue)
+		report.TotalVarianceValue += session.VarianceValue
+		report.TotalShrinkageValue += session.ShrinkageValue
+	}
+	for i := range report.Items {
+		item := &report.Items[i]
+		item.Variance = roundQuantity(item.Variance)
+		item.VarianceValue = roundMoney(item.VarianceValue)
+		item.ShrinkageValue = roundMoney(item.ShrinkageValue)
+	}
+	report.TotalVarianceValue = roundMoney(report.TotalVarianceValue)
+	report.TotalShrinkageValue = roundMoney(report.TotalShrinkageValue)
+
+	return report, nil
+}
+
+func isStocktakeStatus(status string) bool {
+	switch status {
+	case models.StocktakeOpen, models.StocktakeApproved, models.StocktakeCancelled:
+		return true
+	}
+	return false
+}
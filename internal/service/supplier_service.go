@@ -0,0 +1,164 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"frappuccino/internal/repositories"
+	"frappuccino/models"
+	"frappuccino/pkg/logger"
+)
+
+type SupplierRequest struct {
+	Name         string                `json:"name"`
+	ContactName  string                `json:"contact_name"`
+	Email        string                `json:"email"`
+	Phone        string                `json:"phone"`
+	LeadTimeDays int                   `json:"lead_time_days"`
+	Items        []models.SupplierItem `json:"items"`
+}
+
+type SupplierServiceInterface interface {
+	GetAllSuppliers() ([]*models.Supplier, error)
+	GetSupplier(id string) (*models.Supplier, error)
+	CreateSupplier(req SupplierRequest) (*models.Supplier, error)
+	UpdateSupplier(id string, req SupplierRequest) (*models.Supplier, error)
+	DeleteSupplier(id string) error
+}
+
+type SupplierService struct {
+	supplierRepo  repositories.SupplierRepositoryInterface
+	inventoryRepo repositories.InventoryRepositoryInterface
+	logger        *logger.Logger
+}
+
+func NewSupplierService(supplierRepo repositories.SupplierRepositoryInterface, inventoryRepo repositories.InventoryRepositoryInterface, log *logger.Logger) *SupplierService {
+	return &SupplierService{
+		supplierRepo:  supplierRepo,
+		inventoryRepo: inventoryRepo,
+		logger:        log.WithComponent("supplier_service"),
+	}
+}
+
+// GetAllSuppliers returns every supplier with its catalog
+func (s *SupplierService) GetAllSuppliers() ([]*models.Supplier, error) {
+	s.logger.Info("Fetching all suppliers")
+
+	suppliers, err := s.supplierRepo.GetAll()
+	if err != nil {
+		s.logger.Error("Failed to fetch suppliers", "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Fetched suppliers", "count", len(suppliers))
+	return suppliers, nil
+}
+
+// GetSupplier returns a single supplier
+func (s *SupplierService) GetSupplier(id string) (*models.Supplier, error) {
+	s.logger.Info("Fetching supplier", "supplier_id", id)
+
+	supplier, err := s.supplierRepo.GetByID(id)
+	if err != nil {
+		s.logger.Warn("Supplier not found", "supplier_id", id, "error", err)
+		return nil, err
+	}
+	return supplier, nil
+}
+
+// CreateSupplier validates and stores a new supplier
+func (s *SupplierService) CreateSupplier(req SupplierRequest) (*models.Supplier, error) {
+	s.logger.Info("Creating supplier", "name", req.Name)
+
+	if err := s.validateSupplierRequest(req); err != nil {
+		s.logger.Warn("Create failed: invalid supplier data", "name", req.Name, "error", err)
+		return nil, err
+	}
+
+	supplier := supplierFromRequest(req)
+	if err := s.supplierRepo.Create(supplier); err != nil {
+		s.logger.Error("Failed to create supplier", "name", req.Name, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Supplier created", "supplier_id", supplier.ID, "name", supplier.Name)
+	return supplier, nil
+}
+
+// UpdateSupplier replaces a supplier's details and catalog
+func (s *SupplierService) UpdateSupplier(id string, req SupplierRequest) (*models.Supplier, error) {
+	s.logger.Info("Updating supplier", "supplier_id", id, "name", req.Name)
+
+	if err := s.validateSupplierRequest(req); err != nil {
+		s.logger.Warn("Update failed: invalid supplier data", "supplier_id", id, "error", err)
+		return nil, err
+	}
+
+	supplier := supplierFromRequest(req)
+	if err := s.supplierRepo.Update(id, supplier); err != nil {
+		s.logger.Error("Failed to update supplier", "supplier_id", id, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Supplier updated", "supplier_id", id)
+	return s.supplierRepo.GetByID(id)
+}
+
+// DeleteSupplier removes a supplier that has no purchase orders
+func (s *SupplierService) DeleteSupplier(id string) error {
+	s.logger.Info("Deleting supplier", "supplier_id", id)
+
+	if err := s.supplierRepo.Delete(id); err != nil {
+		s.logger.Warn("Failed to delete supplier", "supplier_id", id, "error", err)
+		return err
+	}
+
+	s.logger.Info("Supplier deleted", "supplier_id", id)
+	return nil
+}
+
+func supplierFromRequest(req SupplierRequest) *models.Supplier {
+	return &models.Supplier{
+		Name:         strings.TrimSpace(req.Name),
+		ContactName:  strings.TrimSpace(req.ContactName),
+		Email:        strings.TrimSpace(req.Email),
+		Phone:        strings.TrimSpace(req.Phone),
+		LeadTimeDays: req.LeadTimeDays,
+		Items:        req.Items,
+	}
+}
+
+func (s *SupplierService) validateSupplierRequest(req SupplierRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return fmt.Errorf("supplier name is required")
+	}
+	if req.Email != "" && !strings.Contains(req.Email, "@") {
+		return fmt.Errorf("invalid supplier email")
+	}
+	if req.LeadTimeDays < 0 {
+		return fmt.Errorf("lead time must be non-negative")
+	}
+
+	seen := make(map[string]bool, len(req.Items))
+	for i, item := range req.Items {
+		if item.IngredientID == "" {
+			return fmt.Errorf("item %d: ingredient_id is required", i+1)
+		}
+		if seen[item.IngredientID] {
+			return fmt.Errorf("item %d: duplicate ingredient '%s'", i+1, item.IngredientID)
+		}
+		seen[item.IngredientID] = true
+
+		if item.PackSize <= 0 {
+			return fmt.Errorf("item %d: pack size must be positive", i+1)
+		}
+		if item.PackPrice < 0 {
+			return fmt.Errorf("item %d: pack price must be non-negative", i+1)
+		}
+		if _, err := s.inventoryRepo.GetByID(item.IngredientID); err != nil {
+			return fmt.Errorf("item %d: ingredient '%s' not found in inventory", i+1, item.IngredientID)
+		}
+	}
+
+	return nil
+}
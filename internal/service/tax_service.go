@@ -0,0 +1,139 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"frappuccino/internal/repositories"
+	"frappuccino/models"
+	"frappuccino/pkg/logger"
+)
+
+type TaxRateRequest struct {
+	Name      string              `json:"name"`
+	Rate      *float64            `json:"rate"`
+	Category  models.MenuCategory `json:"category"`
+	OrderType string              `json:"order_type"`
+}
+
+type TaxServiceInterface interface {
+	GetAllTaxRates() ([]*models.TaxRate, error)
+	GetTaxRate(id string) (*models.TaxRate, error)
+	CreateTaxRate(req TaxRateRequest) (*models.TaxRate, error)
+	UpdateTaxRate(id string, req TaxRateRequest) (*models.TaxRate, error)
+	DeleteTaxRate(id string) error
+}
+
+type TaxService struct {
+	taxRepo repositories.TaxRateRepositoryInterface
+	logger  *logger.Logger
+}
+
+func NewTaxService(taxRepo repositories.TaxRateRepositoryInterface, log *logger.Logger) *TaxService {
+	return &TaxService{
+		taxRepo: taxRepo,
+		logger:  log.WithComponent("tax_service"),
+	}
+}
+
+// GetAllTaxRates returns every tax rate
+func (s *TaxService) GetAllTaxRates() ([]*models.TaxRate, error) {
+	s.logger.Info("Fetching all tax rates")
+
+	rates, err := s.taxRepo.GetAll()
+	if err != nil {
+		s.logger.Error("Failed to fetch tax rates", "error", err)
+		return nil, err
+	}
+	return rates, nil
+}
+
+// GetTaxRate returns a single tax rate
+func (s *TaxService) GetTaxRate(id string) (*models.TaxRate, error) {
+	s.logger.Info("Fetching tax rate", "tax_rate_id", id)
+
+	rate, err := s.taxRepo.GetByID(id)
+	if err != nil {
+		s.logger.Warn("Tax rate not found", "tax_rate_id", id, "error", err)
+		return nil, err
+	}
+	return rate, nil
+}
+
+// CreateTaxRate validates and stores a new tax rate
+func (s *TaxService) CreateTaxRate(req TaxRateRequest) (*models.TaxRate, error) {
+	s.logger.Info("Creating tax rate", "name", req.Name)
+
+	rate, err := taxRateFromRequest(req)
+	if err != nil {
+		s.logger.Warn("Create failed: invalid tax rate", "name", req.Name, "error", err)
+		return nil, err
+	}
+
+	if err := s.taxRepo.Create(rate); err != nil {
+		s.logger.Warn("Failed to create tax rate", "name", req.Name, "error", err)
+		return nil, err
+	}
+
+	s.logger.Info("Tax rate created", "tax_rate_id", rate.ID, "rate", rate.Rate)
+	return rate, nil
+}
+
+// UpdateTaxRate replaces a tax rate; it applies to orders placed or updated from now on
+func (s *TaxService) UpdateTaxRate(id string, req TaxRateRequest) (*models.TaxRate, error) {
+	s.logger.Info("Updating tax rate", "tax_rate_id", id)
+
+	rate, err := taxRateFromRequest(req)
+	if err != nil {
+		s.logger.Warn("Update failed: invalid tax rate", "tax_rate_id", id, "error", err)
+		return nil, err
+	}
+
+	if err := s.taxRepo.Update(id, rate); err != nil {
+		s.logger.Warn("Failed to update tax rate", "tax_rate_id", id, "error", err)
+		return nil, err
+	}
+	return rate, nil
+}
+
+// DeleteTaxRate removes a tax rate
+func (s *TaxService) DeleteTaxRate(id string) error {
+	s.logger.Info("Deleting tax rate", "tax_rate_id", id)
+
+	if err := s.taxRepo.Delete(id); err != nil {
+		s.logger.Warn("Failed to delete tax rate", "tax_rate_id", id, "error", err)
+		return err
+	}
+	return nil
+}
+
+func taxRateFromRequest(req TaxRateRequest) (*models.TaxRate, error) {
+	rate := &models.TaxRate{
+		Name:      strings.TrimSpace(req.Name),
+		Category:  req.Category,
+		OrderType: req.OrderType,
+	}
+
+	if rate.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if req.Rate == nil {
+		return nil, fmt.Errorf("rate is required")
+	}
+	if *req.Rate < 0 || *req.Rate > 100 {
+		return nil, fmt.Errorf("rate must be between 0 and 100")
+	}
+	rate.Rate = *req.Rate
+
+	if rate.Category != "" {
+		if err := validateMenuCategory(rate.Category); err != nil {
+			return nil, err
+		}
+	}
+	if rate.OrderType != "" {
+		if _, err := validateOrderType(rate.OrderType); err != nil {
+			return nil, err
+		}
+	}
+	return rate, nil
+}
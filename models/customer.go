@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Customer is a known guest. Orders link to it with customer_id; walk-ins only carry a customer_name.
+type Customer struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Phone       string            `json:"phone,omitempty"`
+	Email       string            `json:"email,omitempty"`
+	Preferences map[string]string `json:"preferences"` // e.g. {"milk": "oat"}
+	Allergens   []string          `json:"allergens"`   // Lowercase, e.g. "milk", "tree nuts"
+	CreatedAt   time.Time         `json:"created_at"`
+	UpdatedAt   time.Time         `json:"updated_at"`
+}
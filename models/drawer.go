@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// Drawer session statuses
+const (
+	DrawerOpen   = "open"
+	DrawerClosed = "closed"
+)
+
+// Drawer movement types
+const (
+	DrawerPaidIn  = "paid_in"
+	DrawerPaidOut = "paid_out"
+)
+
+// DrawerSession is a shift on the cash drawer, from the opening float to the closing count
+type DrawerSession struct {
+	ID           string           `json:"id"`
+	Status       string           `json:"status"`
+	OpenedBy     string           `json:"opened_by"`
+	OpeningFloat float64          `json:"opening_float"`
+	OpenedAt     time.Time        `json:"opened_at"`
+	ClosedBy     string           `json:"closed_by,omitempty"`
+	CountedCash  *float64         `json:"counted_cash,omitempty"`
+	ClosedAt     *time.Time       `json:"closed_at,omitempty"`
+	Movements    []DrawerMovement `json:"movements"`
+}
+
+// DrawerMovement is cash put into or taken out of the drawer outside of sales
+type DrawerMovement struct {
+	ID        string    `json:"id"`
+	SessionID string    `json:"session_id"`
+	Type      string    `json:"type"`
+	Amount    float64   `json:"amount"`
+	Reason    string    `json:"reason"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ZReport is the end-of-session report produced when a drawer session is closed. It is stored as
+// it was at close and never recalculated.
+type ZReport struct {
+	ID              string          `json:"id"`
+	SessionID       string          `json:"session_id"`
+	OpenedBy        string          `json:"opened_by"`
+	ClosedBy        string          `json:"closed_by"`
+	OpenedAt        time.Time       `json:"opened_at"`
+	ClosedAt        time.Time       `json:"closed_at"`
+	Tenders         []ZReportTender `json:"tenders"`
+	Sales           float64         `json:"sales"` // Payment amounts less refunds, excluding tips
+	Tips            float64         `json:"tips"`
+	Refunds         float64         `json:"refunds"`
+	RefundCount     int             `json:"refund_count"`
+	Discounts       float64         `json:"discounts"`
+	Taxes           []ZReportTax    `json:"taxes"`
+	TaxTotal        float64         `json:"tax_total"`
+	OrdersClosed    int             `json:"orders_closed"`
+	OrdersCancelled int             `json:"orders_cancelled"`
+	OpeningFloat    float64         `json:"opening_float"`
+	PaidIn          float64         `json:"paid_in"`
+	PaidOut         float64         `json:"paid_out"`
+	ExpectedCash    float64         `json:"expected_cash"` // Float + cash sales and tips - cash refunds + paid in - paid out
+	CountedCash     float64         `json:"counted_cash"`
+	CashVariance    float64         `json:"cash_variance"` // counted - expected, negative is a shortage
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+// ZReportTender is what one tender took during a drawer session
+type ZReportTender struct {
+	Tender   string  `json:"tender"`
+	Payments int     `json:"payments"`
+	Amount   float64 `json:"amount"`
+	Tips     float64 `json:"tips"`
+	Refunds  float64 `json:"refunds"`
+	Net      float64 `json:"net"` // amount + tips - refunds
+}
+
+// ZReportTax is the tax charged at one rate on the orders closed during a drawer session
+type ZReportTax struct {
+	Name      string  `json:"name"`
+	Rate      float64 `json:"rate"`
+	NetAmount float64 `json:"net_amount"`
+	TaxAmount float64 `json:"tax_amount"`
+}
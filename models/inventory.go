@@ -1,16 +1,47 @@
 package models
 
+import (
+	"encoding/json"
+	"time"
+)
+
 // TODO: Transition State: JSON → PostgreSQL
 // ✅ COMPLETED: Repository now uses PostgreSQL inventory table
 
 type InventoryItem struct {
-	IngredientID string  `json:"ingredient_id"` // Maps to inventory.id (UUID)
-	Name         string  `json:"name"`          // Maps to inventory.name (VARCHAR)
-	Quantity     float64 `json:"quantity"`      // Maps to inventory.quantity (DECIMAL)
-	Unit         string  `json:"unit"`          // Maps to inventory.unit (unit_type ENUM)
-	MinThreshold float64 `json:"min_threshold"` // Maps to inventory.min_threshold (DECIMAL)
+	IngredientID  string  `json:"ingredient_id"`        // Maps to inventory.id (UUID)
+	Name          string  `json:"name"`                 // Maps to inventory.name (VARCHAR)
+	Quantity      float64 `json:"quantity"`             // Maps to inventory.quantity (DECIMAL), the sum of its lots
+	Unit          string  `json:"unit"`                 // Maps to inventory.unit (unit_type ENUM)
+	MinThreshold  float64 `json:"min_threshold"`        // Maps to inventory.min_threshold (DECIMAL)
+	CostPerUnit   float64 `json:"cost_per_unit"`        // Maps to inventory.cost_per_unit (DECIMAL)
+	ShelfLifeDays int     `json:"shelf_life_days"`      // Days until a new lot expires, 0 if it does not
+	Reserved      float64 `json:"reserved"`             // Held by open orders, read-only
+	Available     float64 `json:"available_to_promise"` // Usable (non-expired) quantity minus reserved, read-only
+
+	Allergens []string  `json:"allergens"` // Maps to inventory.allergens (TEXT[])
+	Nutrition Nutrition `json:"nutrition"` // Per unit of Unit
+}
+
+// InventoryStockLevel is an inventory item with its stock value and recent consumption rate
+type InventoryStockLevel struct {
+	InventoryItem
+	StockValue  float64  `json:"stock_value"`   // quantity × cost_per_unit
+	DailyUsage  float64  `json:"daily_usage"`   // Average net usage per day over the usage window
+	DaysOfCover *float64 `json:"days_of_cover"` // nil when there was no usage in the window
+}
+
+// InventoryValuation summarises the value of stock on hand
+type InventoryValuation struct {
+	ItemsCount          int       `json:"items_count"`
+	TotalValue          float64   `json:"total_value"`
+	BelowThresholdCount int       `json:"below_threshold_count"`
+	BelowThresholdValue float64   `json:"below_threshold_value"`
+	UnpricedCount       int       `json:"unpriced_count"` // Items in stock with no cost_per_unit
+	ValuedAt            time.Time `json:"valued_at"`
 }
 
+// InventoryUpdateResult reports stock taken by a batch; batch orders reserve it, so remaining is available-to-promise
 type InventoryUpdateResult struct {
 	IngredientID string  `json:"ingredient_id"`
 	Name         string  `json:"name"`
@@ -24,12 +55,23 @@ type BatchOrderRequest struct {
 
 type BatchOrderItem struct {
 	CustomerName string                 `json:"customer_name"`
+	CustomerID   string                 `json:"customer_id,omitempty"`
+	OrderType    string                 `json:"order_type,omitempty"`
 	Items        []BatchOrderItemDetail `json:"items"`
+	PromoCodes   []string               `json:"promo_codes,omitempty"`
+	Redeem       []LoyaltyRedemption    `json:"redeem,omitempty"`
+
+	SpecialInstructions json.RawMessage `json:"special_instructions,omitempty"`
+	AllergenOverride    bool            `json:"allergen_override,omitempty"`
+	AllergenOverrideBy  string          `json:"allergen_override_by,omitempty"`
 }
 
 type BatchOrderItemDetail struct {
-	MenuItemID string `json:"menu_item_id"`
-	Quantity   int    `json:"quantity"`
+	MenuItemID string            `json:"menu_item_id"`
+	Quantity   int               `json:"quantity"`
+	Choices    map[string]string `json:"choices,omitempty"` // Bundle slot name to chosen menu item ID
+
+	Customizations json.RawMessage `json:"customizations,omitempty"`
 }
 
 type BatchProcessResult struct {
@@ -52,3 +94,78 @@ type BatchProcessResponse struct {
 	ProcessedOrders []BatchProcessResult `json:"processed_orders"`
 	Summary         BatchProcessSummary  `json:"summary"`
 }
+
+// Inventory transaction types, mirror the transaction_type ENUM
+const (
+	TransactionPurchase   = "purchase"
+	TransactionUsage      = "usage"
+	TransactionWaste      = "waste"
+	TransactionAdjustment = "adjustment"
+	TransactionReturn     = "return"
+)
+
+// Reference types for ledger rows and lots
+const (
+	ReferenceTypeOrder = "order"
+	ReferenceTypeLot   = "lot"
+)
+
+// InventoryTransaction is a single stock movement in the inventory_transactions ledger
+type InventoryTransaction struct {
+	ID              string    `json:"id"`
+	IngredientID    string    `json:"ingredient_id"`
+	TransactionType string    `json:"transaction_type"`
+	QuantityChange  float64   `json:"quantity_change"` // Negative for stock leaving inventory
+	QuantityBefore  float64   `json:"quantity_before"`
+	QuantityAfter   float64   `json:"quantity_after"`
+	UnitCost        float64   `json:"unit_cost"` // cost_per_unit at the time of the movement
+	TransactionDate time.Time `json:"transaction_date"`
+	ReferenceType   string    `json:"reference_type,omitempty"`
+	ReferenceID     string    `json:"reference_id,omitempty"`
+	Notes           string    `json:"notes,omitempty"`
+}
+
+// Inventory alert types and statuses
+const (
+	AlertTypeLowStock = "low_stock"
+
+	AlertStatusOpen     = "open"
+	AlertStatusResolved = "resolved"
+)
+
+// InventoryAlert is raised when an item drops below its min_threshold and stays open until restocked
+type InventoryAlert struct {
+	ID               string     `json:"id"`
+	IngredientID     string     `json:"ingredient_id"`
+	Name             string     `json:"name"`
+	Unit             string     `json:"unit"`
+	AlertType        string     `json:"alert_type"`
+	Status           string     `json:"status"`
+	Quantity         float64    `json:"quantity"`      // Stock level when the alert was raised
+	MinThreshold     float64    `json:"min_threshold"` // Threshold when the alert was raised
+	CreatedAt        time.Time  `json:"created_at"`
+	ResolvedAt       *time.Time `json:"resolved_at,omitempty"`
+	DeliveryAttempts int        `json:"delivery_attempts"`
+	DeliveredAt      *time.Time `json:"delivered_at,omitempty"`
+	LastError        string     `json:"last_error,omitempty"`
+}
+
+// InventoryLot is a quantity of an ingredient received together, consumed first-in first-out
+type InventoryLot struct {
+	ID              string     `json:"id"`
+	IngredientID    string     `json:"ingredient_id"`
+	Name            string     `json:"name"`
+	Unit            string     `json:"unit"`
+	Quantity        float64    `json:"quantity"` // Remaining in the lot
+	InitialQuantity float64    `json:"initial_quantity"`
+	UnitCost        float64    `json:"unit_cost"`
+	ReceivedAt      time.Time  `json:"received_at"`
+	ExpiresAt       *time.Time `json:"expires_at,omitempty"`
+	ReferenceType   string     `json:"reference_type,omitempty"`
+	ReferenceID     string     `json:"reference_id,omitempty"`
+}
+
+// Expired reports whether the lot is past its expiry date at t
+func (l *InventoryLot) Expired(t time.Time) bool {
+	return l.ExpiresAt != nil && !l.ExpiresAt.After(t)
+}
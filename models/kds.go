@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Order events published by the order service
+const (
+	OrderEventCreated       = "order.created"
+	OrderEventUpdated       = "order.updated"
+	OrderEventStatusChanged = "order.status_changed"
+	OrderEventCancelled     = "order.cancelled"
+	OrderEventDeleted       = "order.deleted"
+)
+
+// OrderEvent is the data of an order event
+type OrderEvent struct {
+	OrderID        string    `json:"order_id"`
+	Status         string    `json:"status"`
+	PreviousStatus string    `json:"previous_status,omitempty"`
+	Order          *KDSOrder `json:"order,omitempty"` // Omitted when the order was deleted
+}
+
+// KDSOrder is an order as the kitchen display shows it
+type KDSOrder struct {
+	OrderID             string          `json:"order_id"`
+	CustomerName        string          `json:"customer_name"`
+	OrderType           string          `json:"order_type"`
+	Status              string          `json:"status"`
+	Tickets             []StationTicket `json:"tickets"`
+	SpecialInstructions json.RawMessage `json:"special_instructions,omitempty"`
+	CreatedAt           time.Time       `json:"created_at"`
+	AgeSeconds          int             `json:"age_seconds"` // Since the order was placed
+	EstimatedReadyAt    *time.Time      `json:"estimated_ready_at,omitempty"`
+}
+
+// KDSItem is an item to make
+type KDSItem struct {
+	OrderItemID    string          `json:"order_item_id"`
+	MenuItemID     string          `json:"menu_item_id"`
+	Name           string          `json:"name"`
+	Quantity       int             `json:"quantity"`
+	Customizations json.RawMessage `json:"customizations,omitempty"`
+	BundleID       string          `json:"bundle_id,omitempty"`
+	BundleName     string          `json:"bundle_name,omitempty"`
+	Status         string          `json:"status"`
+	StartedAt      *time.Time      `json:"started_at,omitempty"`
+	DoneAt         *time.Time      `json:"done_at,omitempty"`
+}
+
+// KDSQueue is the orders waiting to be made, oldest first
+type KDSQueue struct {
+	Orders      []KDSOrder `json:"orders"`
+	GeneratedAt time.Time  `json:"generated_at"`
+}
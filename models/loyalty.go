@@ -0,0 +1,87 @@
+package models
+
+import "time"
+
+// Loyalty rule types
+const (
+	LoyaltyPoints = "points" // PointsPerUnit points per currency unit spent, redeemed at PointValue each
+	LoyaltyStamps = "stamps" // A stamp per item bought, StampsRequired stamps for a free item
+)
+
+// Loyalty ledger entry types
+const (
+	LoyaltyEarn     = "earn"     // Accrued when an order is closed
+	LoyaltyRedeem   = "redeem"   // Spent as a discount on an order
+	LoyaltyReversal = "reversal" // Given back or taken back when an order is cancelled, deleted or refunded
+)
+
+// LoyaltyRule is a loyalty program customers collect points or stamps under. Each rule keeps its own balance.
+type LoyaltyRule struct {
+	ID             string       `json:"id"`
+	Name           string       `json:"name"`
+	Type           string       `json:"type"`
+	Category       MenuCategory `json:"category,omitempty"`         // Items that count, empty for all
+	PointsPerUnit  float64      `json:"points_per_unit,omitempty"`  // Points rules
+	PointValue     float64      `json:"point_value,omitempty"`      // Points rules, money off per point
+	StampsRequired int          `json:"stamps_required,omitempty"`  // Stamp rules
+	MaxRewardValue *float64     `json:"max_reward_value,omitempty"` // Stamp rules, cap on the free item's price
+	Active         bool         `json:"active"`
+	CreatedAt      time.Time    `json:"created_at"`
+	UpdatedAt      time.Time    `json:"updated_at"`
+}
+
+// LoyaltyRedemption asks to spend a loyalty balance on an order: Points for points rules,
+// Rewards free items (default 1) for stamp rules
+type LoyaltyRedemption struct {
+	RuleID  string `json:"rule_id"`
+	Points  int    `json:"points,omitempty"`
+	Rewards int    `json:"rewards,omitempty"`
+}
+
+// LoyaltyEntry is one movement on a customer's loyalty ledger; Points are stamps for stamp rules
+type LoyaltyEntry struct {
+	ID         string    `json:"id"`
+	CustomerID string    `json:"customer_id"`
+	RuleID     string    `json:"rule_id"`
+	RuleName   string    `json:"rule_name,omitempty"`
+	OrderID    string    `json:"order_id,omitempty"`
+	Type       string    `json:"type"`
+	Points     int       `json:"points"` // Positive adds to the balance
+	Reason     string    `json:"reason,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// LoyaltyBalance is what a customer holds under one rule
+type LoyaltyBalance struct {
+	RuleID  string  `json:"rule_id"`
+	Name    string  `json:"name"`
+	Type    string  `json:"type"`
+	Points  int     `json:"points"`
+	Rewards int     `json:"rewards,omitempty"` // Stamp rules, free items available
+	Value   float64 `json:"value,omitempty"`   // Points rules, money the points are worth
+}
+
+// CustomerLoyalty is a customer's balances and ledger, newest entries first
+type CustomerLoyalty struct {
+	CustomerID string           `json:"customer_id"`
+	Balances   []LoyaltyBalance `json:"balances"`
+	Entries    []LoyaltyEntry   `json:"entries"`
+}
+
+// LoyaltyLiability is what is owed to customers under one rule
+type LoyaltyLiability struct {
+	RuleID      string  `json:"rule_id"`
+	Name        string  `json:"name"`
+	Type        string  `json:"type"`
+	Customers   int     `json:"customers"`              // Customers with a positive balance
+	Outstanding int     `json:"outstanding"`            // Points or stamps held
+	Rewards     int     `json:"rewards,omitempty"`      // Stamp rules, free items earned and not yet redeemed
+	RewardValue float64 `json:"reward_value,omitempty"` // Stamp rules, estimated value of one free item
+	Value       float64 `json:"value"`
+}
+
+// LoyaltyLiabilityReport is the outstanding loyalty liability across rules
+type LoyaltyLiabilityReport struct {
+	Rules      []LoyaltyLiability `json:"rules"`
+	TotalValue float64            `json:"total_value"`
+}
@@ -17,12 +17,24 @@ type MenuItem struct {
 	Category             MenuCategory         `json:"category" db:"category"`
 	Price                float64              `json:"price" db:"price"`
 	Available            bool                 `json:"available" db:"available"`
+	OutOfStock           bool                 `json:"out_of_stock" db:"out_of_stock"` // Switched off automatically, back on when restocked
+	MaxServings          *int                 `json:"max_servings"`                   // Servings the available stock allows, nil when no recipe limits it
+	Schedule             *MenuSchedule        `json:"schedule,omitempty"`             // When the item is sold, nil when always
+	Bundle               *MenuBundle          `json:"bundle,omitempty"`               // Set for combos made of other menu items
 	Tags                 []string             `json:"tags" db:"tags"`
-	Allergens            []string             `json:"allergens" db:"allergens"`
+	Sizes                []string             `json:"sizes" db:"available_sizes"`
+	Station              Station              `json:"station"`                                 // Where the item is made: its own station, else its category's, read-only
+	StationOverride      Station              `json:"station_override,omitempty" db:"station"` // Set when the item doesn't go to its category's station
+	Allergens            []string             `json:"allergens"`                               // The recipe's ingredient allergens with the overrides applied, read-only
+	AllergenOverrides    AllergenOverrides    `json:"allergen_overrides"`                      // Maps to menu_items.allergens_added and allergens_removed
+	NutritionOverrides   NutritionOverrides   `json:"nutrition_overrides" db:"nutrition_overrides"`
 	CustomizationOptions []byte               `json:"customization_options" db:"customization_options"`
 	Ingredients          []MenuItemIngredient `json:"ingredients"`
+	Modifiers            []MenuModifier       `json:"modifiers"` // Customizations that add ingredients
 	CreatedAt            time.Time            `json:"created_at" db:"created_at"`
 	UpdatedAt            time.Time            `json:"updated_at" db:"updated_at"`
+	Costing              *MenuItemCosting     `json:"costing,omitempty"`
+	Nutrition            *NutritionPanel      `json:"nutrition,omitempty"`
 }
 
 // TODO: Add additional fields based on README spec:
@@ -38,6 +50,32 @@ type MenuItem struct {
 type MenuItemIngredient struct {
 	IngredientID string  `json:"ingredient_id" db:"ingredient_id"`
 	Quantity     float64 `json:"quantity" db:"quantity"`
+	Unit         string  `json:"unit,omitempty" db:"unit"` // Defaults to the inventory item's unit
+}
+
+// MenuModifier is a customization that adds ingredients to a serving. It applies to an order item whose
+// customizations set its name to true, or to a count for that many, e.g. {"extra_shot": 2}.
+type MenuModifier struct {
+	Name        string               `json:"name"`
+	Ingredients []MenuItemIngredient `json:"ingredients"`
+}
+
+// MenuItemCosting is the recipe cost of a menu item at current ingredient prices
+type MenuItemCosting struct {
+	IngredientCost float64          `json:"ingredient_cost"`
+	GrossMargin    float64          `json:"gross_margin"`
+	MarginPercent  float64          `json:"margin_percent"`
+	Ingredients    []IngredientCost `json:"ingredients"`
+}
+
+// IngredientCost is the cost contribution of a single recipe line
+type IngredientCost struct {
+	IngredientID string  `json:"ingredient_id"`
+	Name         string  `json:"name"`
+	Quantity     float64 `json:"quantity"` // Recipe quantity converted to the inventory unit
+	Unit         string  `json:"unit"`
+	CostPerUnit  float64 `json:"cost_per_unit"`
+	Cost         float64 `json:"cost"`
 }
 
 // TODO: Add MenuCategory enum based on README spec
@@ -0,0 +1,33 @@
+package models
+
+// MenuBundle makes a menu item a combo of other menu items, sold at the bundle's price.
+// Ordering a bundle records its components as order items, each with its share of the price.
+type MenuBundle struct {
+	Slots []BundleSlot `json:"slots"`
+}
+
+// BundleSlot is one component of a bundle: a fixed item, or a choice among options or a whole category
+type BundleSlot struct {
+	Name     string       `json:"name"`
+	Quantity int          `json:"quantity"`
+	Options  []string     `json:"options,omitempty"`  // Menu item IDs that can fill the slot
+	Category MenuCategory `json:"category,omitempty"` // Any menu item of this category can fill the slot
+}
+
+// Fixed reports whether the slot always holds the same item, so the customer has nothing to choose
+func (s BundleSlot) Fixed() bool {
+	return len(s.Options) == 1 && s.Category == ""
+}
+
+// Allows reports whether item can fill the slot
+func (s BundleSlot) Allows(item *MenuItem) bool {
+	if s.Category != "" && item.Category == s.Category {
+		return true
+	}
+	for _, option := range s.Options {
+		if option == item.ID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// Scheduled price change statuses
+const (
+	PriceChangePending   = "pending"
+	PriceChangeApplied   = "applied"
+	PriceChangeCancelled = "cancelled"
+)
+
+// PriceChangeNote says who changed a menu item's price and why, for its price history
+type PriceChangeNote struct {
+	ChangedBy string `json:"changed_by,omitempty"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// PriceHistoryEntry is one recorded change of a menu item's price
+type PriceHistoryEntry struct {
+	ID         string    `json:"id"`
+	MenuItemID string    `json:"menu_item_id"`
+	OldPrice   *float64  `json:"old_price"` // nil for the first recorded price
+	NewPrice   float64   `json:"new_price"`
+	ChangedAt  time.Time `json:"changed_at"`
+	ChangedBy  string    `json:"changed_by"`
+	Reason     string    `json:"reason"`
+}
+
+// ScheduledPriceChange is a price change planned for a future time, applied by a background job
+type ScheduledPriceChange struct {
+	ID          string     `json:"id"`
+	MenuItemID  string     `json:"menu_item_id"`
+	NewPrice    float64    `json:"new_price"`
+	EffectiveAt time.Time  `json:"effective_at"`
+	ChangedBy   string     `json:"changed_by"`
+	Reason      string     `json:"reason"`
+	Status      string     `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	AppliedAt   *time.Time `json:"applied_at,omitempty"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Weekdays accepted in menu schedule windows, indexed by time.Weekday
+var Weekdays = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// MenuSchedule limits when a menu item is sold. Dates and times are in the shop's timezone.
+// An item without windows is sold all day on every date in its range.
+type MenuSchedule struct {
+	Windows   []MenuScheduleWindow `json:"windows,omitempty"`
+	StartDate string               `json:"start_date,omitempty"` // YYYY-MM-DD, inclusive
+	EndDate   string               `json:"end_date,omitempty"`   // YYYY-MM-DD, inclusive
+}
+
+// MenuScheduleWindow is a daily time range on some weekdays, every day when Weekdays is empty
+type MenuScheduleWindow struct {
+	Weekdays []string `json:"weekdays"`
+	Start    string   `json:"start"` // HH:MM, inclusive
+	End      string   `json:"end"`   // HH:MM, exclusive, 24:00 for midnight
+}
+
+// Empty reports whether the schedule places no limits
+func (s *MenuSchedule) Empty() bool {
+	return s == nil || (len(s.Windows) == 0 && s.StartDate == "" && s.EndDate == "")
+}
+
+// ActiveAt reports whether the item is on sale at t, which must already be in the shop's timezone
+func (s *MenuSchedule) ActiveAt(t time.Time) bool {
+	if s.Empty() {
+		return true
+	}
+
+	date := t.Format("2006-01-02")
+	if s.StartDate != "" && date < s.StartDate {
+		return false
+	}
+	if s.EndDate != "" && date > s.EndDate {
+		return false
+	}
+
+	if len(s.Windows) == 0 {
+		return true
+	}
+	clock := t.Format("15:04")
+	weekday := Weekdays[t.Weekday()]
+	for _, window := range s.Windows {
+		if clock < window.Start || clock >= window.End {
+			continue
+		}
+		if len(window.Weekdays) == 0 {
+			return true
+		}
+		for _, day := range window.Weekdays {
+			if day == weekday {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,59 @@
+package models
+
+// Nutrition is the nutritional content of an amount of food. On inventory items it is per unit of the item's unit.
+type Nutrition struct {
+	Kcal       float64 `json:"kcal"`
+	SugarG     float64 `json:"sugar_g"`
+	FatG       float64 `json:"fat_g"`
+	CaffeineMg float64 `json:"caffeine_mg"`
+}
+
+// NutritionOverrides sets a menu item's per-serving values by hand, fields left nil are derived from the recipe
+type NutritionOverrides struct {
+	Kcal       *float64 `json:"kcal,omitempty"`
+	SugarG     *float64 `json:"sugar_g,omitempty"`
+	FatG       *float64 `json:"fat_g,omitempty"`
+	CaffeineMg *float64 `json:"caffeine_mg,omitempty"`
+}
+
+// AllergenOverrides corrects the allergens a menu item's recipe declares: traces added by hand, or
+// ingredient allergens ruled out for the item
+type AllergenOverrides struct {
+	Added   []string `json:"added"`
+	Removed []string `json:"removed"`
+}
+
+// NutritionPanel is a menu item's nutrition and allergens, derived from its recipe's ingredients
+type NutritionPanel struct {
+	Serving     Nutrition             `json:"serving"`              // A standard (medium) serving, overrides applied
+	Derived     Nutrition             `json:"derived"`              // A standard serving as the recipe adds up
+	Overridden  []string              `json:"overridden,omitempty"` // Serving fields set by hand
+	Sizes       []SizeNutrition       `json:"sizes"`                // Serving scaled to each size the item is sold in
+	Modifiers   []ModifierNutrition   `json:"modifiers,omitempty"`  // What each modifier adds to a serving of any size
+	Allergens   []string              `json:"allergens"`
+	Ingredients []IngredientNutrition `json:"ingredients"`
+}
+
+// SizeNutrition is a serving scaled to one size
+type SizeNutrition struct {
+	Size  string  `json:"size"`
+	Scale float64 `json:"scale"`
+	Nutrition
+}
+
+// ModifierNutrition is what applying a modifier once adds to a serving
+type ModifierNutrition struct {
+	Name      string   `json:"name"`
+	Allergens []string `json:"allergens"`
+	Nutrition
+}
+
+// IngredientNutrition is what a single recipe line contributes to a serving
+type IngredientNutrition struct {
+	IngredientID string    `json:"ingredient_id"`
+	Name         string    `json:"name"`
+	Quantity     float64   `json:"quantity"` // Recipe quantity converted to the inventory unit
+	Unit         string    `json:"unit"`
+	Allergens    []string  `json:"allergens"`
+	Nutrition    Nutrition `json:"nutrition"`
+}
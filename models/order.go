@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"encoding/json"
+	"time"
+)
 
 // TODO: Transition State: JSON → PostgreSQL
 // DEPRECATED: Update models to support database relationships and constraints
@@ -10,23 +13,65 @@ import "time"
 // 4. Implement database constraints for order status validation
 // 5. Add foreign key relationships to customer and menu_item tables
 
+// Order statuses, mirror the order_status ENUM
+const (
+	OrderPending   = "pending"
+	OrderPreparing = "preparing"
+	OrderReady     = "ready"
+	OrderClosed    = "closed"
+	OrderCancelled = "cancelled"
+)
+
+// Order types
+const (
+	OrderDineIn   = "dine_in"
+	OrderTakeaway = "takeaway"
+)
+
 type Order struct {
-	ID                  string      `json:"order_id" db:"id"`
-	CustomerName        string      `json:"customer_name" db:"customer_name"`
-	Items               []OrderItem `json:"items"`
-	Status              string      `json:"status" db:"status"`
-	TotalAmount         float64     `json:"total_amount" db:"total_amount"`
-	SpecialInstructions string      `json:"special_instructions"`
-	CreatedAt           time.Time   `json:"created_at" db:"created_at"`
-	UpdatedAt           time.Time   `json:"updated_at" db:"updated_at"`
+	ID                  string            `json:"order_id" db:"id"`
+	CustomerName        string            `json:"customer_name" db:"customer_name"`
+	CustomerID          string            `json:"customer_id,omitempty" db:"customer_id"` // Empty for walk-ins
+	Items               []OrderItem       `json:"items"`
+	Status              string            `json:"status" db:"status"`
+	OrderType           string            `json:"order_type" db:"order_type"`
+	Subtotal            float64           `json:"subtotal" db:"subtotal"` // Item prices after discounts
+	DiscountAmount      float64           `json:"discount_amount" db:"discount_amount"`
+	TaxAmount           float64           `json:"tax_amount" db:"tax_amount"`
+	TaxInclusive        bool              `json:"tax_inclusive" db:"tax_inclusive"` // Whether the subtotal already contains the tax
+	TotalAmount         float64           `json:"total_amount" db:"total_amount"`   // What the customer pays
+	Discounts           []OrderDiscount   `json:"discounts,omitempty"`
+	Taxes               []OrderTax        `json:"taxes,omitempty"`
+	SpecialInstructions json.RawMessage   `json:"special_instructions,omitempty"` // JSON object, e.g. {"allergies": ["milk"], "notes": "extra hot"}
+	AllergenWarnings    []AllergenWarning `json:"allergen_warnings,omitempty"`    // Overridden on creation, returned by the create call only
+	AllergenOverrideBy  string            `json:"-"`                              // Who accepted the allergen warnings
+	EstimatedReadyAt    *time.Time        `json:"estimated_ready_at,omitempty"`   // Estimated from the queue while the order is pending or preparing
+	CreatedAt           time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at" db:"updated_at"`
+}
+
+// AllergenWarning is an order item containing allergens the customer declared
+type AllergenWarning struct {
+	MenuItemID string   `json:"menu_item_id"`
+	Name       string   `json:"name"`
+	BundleID   string   `json:"bundle_id,omitempty"` // Set when the item was chosen as part of a bundle
+	Allergens  []string `json:"allergens"`           // Declared allergens the item contains
+	Modifiers  []string `json:"modifiers,omitempty"` // Modifiers of the item that add declared allergens
 }
 
 type OrderItem struct {
-	ID             string  `json:"id" db:"id"`
-	OrderID        string  `json:"order_id" db:"order_id"`
-	MenuItemID     string  `json:"menu_item_id" db:"menu_item_id"`
-	ProductID      string  `json:"product_id" db:"product_id"`
-	Quantity       int     `json:"quantity" db:"quantity"`
-	PriceAtTime    float64 `json:"price_at_time" db:"price_at_time"`
-	Customizations string  `json:"customizations"`
+	ID             string          `json:"id" db:"id"`
+	OrderID        string          `json:"order_id" db:"order_id"`
+	MenuItemID     string          `json:"menu_item_id" db:"menu_item_id"`
+	ProductID      string          `json:"product_id" db:"product_id"`
+	Quantity       int             `json:"quantity" db:"quantity"`
+	PriceAtTime    float64         `json:"price_at_time" db:"price_at_time"`
+	Customizations json.RawMessage `json:"customizations,omitempty"`                       // JSON object, e.g. {"milk": "oat", "shots": 2}
+	BundleID       string          `json:"bundle_id,omitempty" db:"bundle_id"`             // Bundle this item was ordered as part of
+	BundleLine     int             `json:"bundle_line,omitempty" db:"bundle_line"`         // Requested order line the bundle came from, from 1
+	DiscountAmount float64         `json:"discount_amount,omitempty" db:"discount_amount"` // The line's share of the order's discounts
+	Station        Station         `json:"station" db:"station"`                           // Where the item is made
+	PrepStatus     string          `json:"prep_status" db:"prep_status"`
+	PrepStartedAt  *time.Time      `json:"prep_started_at,omitempty" db:"prep_started_at"`
+	PrepDoneAt     *time.Time      `json:"prep_done_at,omitempty" db:"prep_done_at"`
 }
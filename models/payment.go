@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// Payment tenders
+const (
+	TenderCash    = "cash"
+	TenderCard    = "card"
+	TenderVoucher = "voucher"
+)
+
+// Payment is money taken for an order with one tender. Amount counts towards the order total;
+// the tip is on top of it and goes to staff.
+type Payment struct {
+	ID             string    `json:"id"`
+	OrderID        string    `json:"order_id"`
+	Tender         string    `json:"tender"`
+	Amount         float64   `json:"amount"`
+	Tip            float64   `json:"tip"`
+	Tendered       float64   `json:"tendered,omitempty"`  // Cash handed over
+	Change         float64   `json:"change,omitempty"`    // Cash handed back
+	Guest          string    `json:"guest,omitempty"`     // Who paid, when the bill is split
+	Reference      string    `json:"reference,omitempty"` // Gateway transaction or voucher code
+	RefundedAmount float64   `json:"refunded_amount"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// Refund gives back part or all of a payment's amount
+type Refund struct {
+	ID        string    `json:"id"`
+	PaymentID string    `json:"payment_id"`
+	OrderID   string    `json:"order_id"`
+	Tender    string    `json:"tender"`
+	Amount    float64   `json:"amount"`
+	Reason    string    `json:"reason"`
+	Reference string    `json:"reference,omitempty"` // Gateway refund reference
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// OrderPayments is where an order stands with its payments
+type OrderPayments struct {
+	OrderID     string     `json:"order_id"`
+	TotalAmount float64    `json:"total_amount"`
+	Paid        float64    `json:"paid"` // Payment amounts less refunds
+	Tips        float64    `json:"tips"`
+	Refunded    float64    `json:"refunded"`
+	Balance     float64    `json:"balance"` // Still to pay
+	FullyPaid   bool       `json:"fully_paid"`
+	Split       []float64  `json:"split,omitempty"` // Equal shares of the balance, when asked for
+	Payments    []*Payment `json:"payments"`
+	Refunds     []*Refund  `json:"refunds"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// Pricing rule types
+const (
+	RulePercentage = "percentage"  // Value percent off the targeted lines
+	RuleFixed      = "fixed"       // Value off the targeted lines, spread across them
+	RuleBuyXGetY   = "buy_x_get_y" // For every BuyQuantity+GetQuantity units, the cheapest GetQuantity are Value percent off
+)
+
+// PricingRule is a discount applied to new orders. Rules with a promo code only apply when the
+// order names the code; the others apply automatically whenever they are active.
+type PricingRule struct {
+	ID          string        `json:"id"`
+	Name        string        `json:"name"`
+	Type        string        `json:"type"`
+	Value       float64       `json:"value"`
+	BuyQuantity int           `json:"buy_quantity,omitempty"`
+	GetQuantity int           `json:"get_quantity,omitempty"`
+	MenuItemIDs []string      `json:"menu_item_ids,omitempty"` // Items the rule applies to; none with no category means the whole order
+	Category    MenuCategory  `json:"category,omitempty"`      // Category the rule applies to, e.g. for happy hour
+	Schedule    *MenuSchedule `json:"schedule,omitempty"`      // When the rule applies, in the shop's timezone
+	PromoCode   string        `json:"promo_code,omitempty"`
+	MaxUses     *int          `json:"max_uses,omitempty"` // Orders that can redeem the promo code, nil for unlimited
+	Uses        int           `json:"uses"`
+	ExpiresAt   *time.Time    `json:"expires_at,omitempty"`
+	Active      bool          `json:"active"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+// OrderDiscount is a pricing rule applied to an order
+type OrderDiscount struct {
+	ID        string  `json:"id,omitempty"`
+	RuleID    string  `json:"rule_id"`
+	Name      string  `json:"name"`
+	PromoCode string  `json:"promo_code,omitempty"`
+	Amount    float64 `json:"amount"`
+
+	// Set on loyalty redemptions instead of RuleID
+	LoyaltyRuleID string `json:"loyalty_rule_id,omitempty"`
+	LoyaltyPoints int    `json:"loyalty_points,omitempty"` // Points or stamps spent
+}
@@ -0,0 +1,40 @@
+package models
+
+// Station is where in the shop an item is made, mirrors the station ENUM
+type Station string
+
+const (
+	StationBar        Station = "bar"
+	StationKitchen    Station = "kitchen"
+	StationPastryCase Station = "pastry_case"
+)
+
+// Stations lists the stations in the order their tickets are shown
+var Stations = []Station{StationBar, StationKitchen, StationPastryCase}
+
+// Item preparation statuses, mirror the prep_status ENUM
+const (
+	PrepQueued     = "queued"
+	PrepInProgress = "in_progress"
+	PrepDone       = "done"
+)
+
+// StationRoute sends a menu category's items to a station, unless an item sets its own
+type StationRoute struct {
+	Category MenuCategory `json:"category" db:"category"`
+	Station  Station      `json:"station" db:"station"`
+}
+
+// StationTicket is the part of an order one station makes
+type StationTicket struct {
+	Station Station   `json:"station"`
+	Status  string    `json:"status"` // queued until an item is started, done once every item is
+	Items   []KDSItem `json:"items"`
+}
+
+// OrderTickets is an order split into its station tickets
+type OrderTickets struct {
+	OrderID string          `json:"order_id"`
+	Status  string          `json:"status"`
+	Tickets []StationTicket `json:"tickets"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Stocktake statuses, mirror the stocktake_status ENUM
+const (
+	StocktakeOpen      = "open"
+	StocktakeApproved  = "approved"
+	StocktakeCancelled = "cancelled"
+)
+
+// ReferenceTypeStocktake marks ledger rows posted by approving a stocktake
+const ReferenceTypeStocktake = "stocktake"
+
+// Stocktake is a physical count session; approving it books every variance as an adjustment
+type Stocktake struct {
+	ID            string           `json:"id" db:"id"`
+	Status        string           `json:"status" db:"status"`
+	Notes         string           `json:"notes,omitempty" db:"notes"`
+	ApprovedBy    string           `json:"approved_by,omitempty" db:"approved_by"`
+	Counts        []StocktakeCount `json:"counts"`
+	VarianceValue float64          `json:"variance_value"` // Net cost impact of all counts
+	CreatedAt     time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time        `json:"updated_at" db:"updated_at"`
+	ApprovedAt    *time.Time       `json:"approved_at,omitempty" db:"approved_at"`
+	CancelledAt   *time.Time       `json:"cancelled_at,omitempty" db:"cancelled_at"`
+}
+
+// StocktakeCount is the counted quantity of one ingredient against the system quantity at count time
+type StocktakeCount struct {
+	IngredientID    string    `json:"ingredient_id" db:"ingredient_id"`
+	Name            string    `json:"name,omitempty"`
+	Unit            string    `json:"unit,omitempty"`
+	CountedQuantity float64   `json:"counted_quantity" db:"counted_quantity"`
+	SystemQuantity  float64   `json:"system_quantity" db:"system_quantity"`
+	Variance        float64   `json:"variance"`  // counted - system, negative is shrinkage
+	UnitCost        float64   `json:"unit_cost"` // cost_per_unit at count time
+	VarianceValue   float64   `json:"variance_value"`
+	CountedAt       time.Time `json:"counted_at" db:"counted_at"`
+}
+
+// StocktakeSessionVariance summarises one approved stocktake
+type StocktakeSessionVariance struct {
+	StocktakeID    string    `json:"stocktake_id"`
+	ApprovedAt     time.Time `json:"approved_at"`
+	ItemsCounted   int       `json:"items_counted"`
+	ItemsVarying   int       `json:"items_varying"`
+	VarianceValue  float64   `json:"variance_value"`
+	ShrinkageValue float64   `json:"shrinkage_value"` // Value of negative variances, as a positive amount
+}
+
+// IngredientVariance is the variance of one ingredient across approved stocktakes
+type IngredientVariance struct {
+	IngredientID   string  `json:"ingredient_id"`
+	Name           string  `json:"name"`
+	Unit           string  `json:"unit"`
+	Counts         int     `json:"counts"`
+	Variance       float64 `json:"variance"`
+	VarianceValue  float64 `json:"variance_value"`
+	ShrinkageValue float64 `json:"shrinkage_value"`
+}
@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+type Supplier struct {
+	ID           string         `json:"id" db:"id"`
+	Name         string         `json:"name" db:"name"`
+	ContactName  string         `json:"contact_name" db:"contact_name"`
+	Email        string         `json:"email" db:"email"`
+	Phone        string         `json:"phone" db:"phone"`
+	LeadTimeDays int            `json:"lead_time_days" db:"lead_time_days"`
+	Items        []SupplierItem `json:"items"`
+	CreatedAt    time.Time      `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at" db:"updated_at"`
+}
+
+// SupplierItem is an ingredient a supplier sells, in packs of PackSize inventory units
+type SupplierItem struct {
+	IngredientID string  `json:"ingredient_id" db:"ingredient_id"`
+	PackSize     float64 `json:"pack_size" db:"pack_size"`
+	PackPrice    float64 `json:"pack_price" db:"pack_price"`
+}
+
+// UnitCost is the price of one inventory unit when bought from this supplier
+func (i SupplierItem) UnitCost() float64 {
+	if i.PackSize <= 0 {
+		return 0
+	}
+	return i.PackPrice / i.PackSize
+}
+
+// Purchase order statuses, mirror the purchase_order_status ENUM
+const (
+	PurchaseOrderDraft     = "draft"
+	PurchaseOrderSent      = "sent"
+	PurchaseOrderReceived  = "received"
+	PurchaseOrderCancelled = "cancelled"
+)
+
+// ReferenceTypePurchaseOrder marks ledger rows caused by receiving a purchase order
+const ReferenceTypePurchaseOrder = "purchase_order"
+
+type PurchaseOrder struct {
+	ID           string              `json:"id" db:"id"`
+	SupplierID   string              `json:"supplier_id" db:"supplier_id"`
+	SupplierName string              `json:"supplier_name"`
+	Status       string              `json:"status" db:"status"`
+	TotalAmount  float64             `json:"total_amount" db:"total_amount"`
+	Notes        string              `json:"notes,omitempty" db:"notes"`
+	Items        []PurchaseOrderItem `json:"items"`
+	CreatedAt    time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time           `json:"updated_at" db:"updated_at"`
+	SentAt       *time.Time          `json:"sent_at,omitempty" db:"sent_at"`
+	ReceivedAt   *time.Time          `json:"received_at,omitempty" db:"received_at"`
+}
+
+type PurchaseOrderItem struct {
+	IngredientID string  `json:"ingredient_id" db:"ingredient_id"`
+	Name         string  `json:"name,omitempty"`
+	Unit         string  `json:"unit,omitempty"`
+	Packs        int     `json:"packs" db:"packs"`
+	PackSize     float64 `json:"pack_size" db:"pack_size"`
+	PackPrice    float64 `json:"pack_price" db:"pack_price"`
+}
+
+// Quantity is the number of inventory units the line adds to stock
+func (i PurchaseOrderItem) Quantity() float64 {
+	return float64(i.Packs) * i.PackSize
+}
+
+// Total is the line cost
+func (i PurchaseOrderItem) Total() float64 {
+	return float64(i.Packs) * i.PackPrice
+}
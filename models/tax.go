@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Tax pricing modes. With inclusive pricing menu prices already contain the tax; with exclusive
+// pricing the tax is added on top of them.
+const (
+	TaxExclusive = "exclusive"
+	TaxInclusive = "inclusive"
+)
+
+// TaxRate is the tax charged on menu items of a category for an order type. A rate without a
+// category or order type applies to all of them; the most specific matching rate wins.
+type TaxRate struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Rate      float64      `json:"rate"` // Percent
+	Category  MenuCategory `json:"category,omitempty"`
+	OrderType string       `json:"order_type,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// OrderTax is the tax an order owes at one rate
+type OrderTax struct {
+	ID        string  `json:"id,omitempty"`
+	TaxRateID string  `json:"tax_rate_id"`
+	Name      string  `json:"name"`
+	Rate      float64 `json:"rate"`
+	NetAmount float64 `json:"net_amount"` // Taxable amount, excluding the tax
+	TaxAmount float64 `json:"tax_amount"`
+}
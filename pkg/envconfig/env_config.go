@@ -67,3 +67,14 @@ func GetEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// GetList returns a comma-separated environment variable as a list, skipping empty entries
+func GetList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
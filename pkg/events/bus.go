@@ -0,0 +1,92 @@
+// Package events is an in-process publish/subscribe bus for application events
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Event is a single published event. IDs increase with every event published on a bus.
+type Event struct {
+	ID   uint64      `json:"id"`
+	Type string      `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// Bus fans published events out to every subscriber
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[chan Event]struct{}
+	buffer      int
+	lastID      uint64
+	closed      bool
+}
+
+// NewBus creates a bus whose subscribers can fall up to buffer events behind
+func NewBus(buffer int) *Bus {
+	return &Bus{
+		subscribers: make(map[chan Event]struct{}),
+		buffer:      buffer,
+	}
+}
+
+// Subscribe returns a channel receiving every event published from now on, and a function ending the
+// subscription. The channel is closed when the subscription ends, when the subscriber falls more than
+// the buffer behind, or when the bus is closed.
+func (b *Bus) Subscribe() (<-chan Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ch := make(chan Event, b.buffer)
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		b.remove(ch)
+	}
+}
+
+// Publish delivers an event to every subscriber without waiting on any of them
+func (b *Bus) Publish(eventType string, data interface{}) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.lastID++
+	event := Event{ID: b.lastID, Type: eventType, Time: time.Now(), Data: data}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			// A subscriber that fell behind has missed events, so it is dropped rather than left inconsistent
+			b.remove(ch)
+		}
+	}
+}
+
+// Close ends every subscription and discards events published afterwards
+func (b *Bus) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for ch := range b.subscribers {
+		b.remove(ch)
+	}
+}
+
+func (b *Bus) remove(ch chan Event) {
+	if _, ok := b.subscribers[ch]; ok {
+		delete(b.subscribers, ch)
+		close(ch)
+	}
+}
@@ -276,6 +276,18 @@ func (rw *responseWriter) Write(data []byte) (int, error) {
 	return size, err
 }
 
+// Flush forwards to the underlying writer so streamed responses are not buffered
+func (rw *responseWriter) Flush() {
+	if flusher, ok := rw.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap exposes the underlying writer to http.ResponseController
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
 // getClientIP extracts the real client IP from the request
 func getClientIP(r *http.Request) string {
 	// Check X-Forwarded-For header first
@@ -0,0 +1,79 @@
+package payments
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// ErrDeclined is returned when the card issuer refuses a charge
+var ErrDeclined = errors.New("card declined")
+
+// ChargeRequest is a card charge for an order
+type ChargeRequest struct {
+	OrderID string
+	Amount  float64 // Including any tip
+	Token   string  // Card token from the terminal or payment page
+}
+
+// Gateway processes card payments. Implementations return their own transaction reference,
+// which is stored on the payment and used to refund it.
+type Gateway interface {
+	Charge(req ChargeRequest) (reference string, err error)
+	Refund(reference string, amount float64) (refundReference string, err error)
+}
+
+// DeclineToken makes the fake gateway decline a charge
+const DeclineToken = "tok_declined"
+
+// FakeGateway approves every card charge except DeclineToken, in memory. It is meant for local
+// development and tests; references don't survive a restart, so refunds of earlier charges are accepted.
+type FakeGateway struct {
+	mu       sync.Mutex
+	sequence int
+	charges  map[string]float64 // Reference to the amount still refundable
+}
+
+// NewFakeGateway creates an empty FakeGateway
+func NewFakeGateway() *FakeGateway {
+	return &FakeGateway{charges: make(map[string]float64)}
+}
+
+// Charge approves the charge and records it for refunds
+func (g *FakeGateway) Charge(req ChargeRequest) (string, error) {
+	if req.Amount <= 0 {
+		return "", fmt.Errorf("charge amount must be positive")
+	}
+	if req.Token == DeclineToken {
+		return "", ErrDeclined
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.sequence++
+	reference := fmt.Sprintf("fake_ch_%06d", g.sequence)
+	g.charges[reference] = req.Amount
+	return reference, nil
+}
+
+// Refund returns up to the charged amount of a charge
+func (g *FakeGateway) Refund(reference string, amount float64) (string, error) {
+	if amount <= 0 {
+		return "", fmt.Errorf("refund amount must be positive")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if remaining, ok := g.charges[reference]; ok {
+		if amount > remaining+0.005 {
+			return "", fmt.Errorf("refund of %.2f exceeds the %.2f left on charge %s", amount, remaining, reference)
+		}
+		g.charges[reference] = math.Round((remaining-amount)*100) / 100
+	}
+
+	g.sequence++
+	return fmt.Sprintf("fake_re_%06d", g.sequence), nil
+}
@@ -0,0 +1,68 @@
+// Package units converts quantities between the unit_type values stored in the database
+package units
+
+import "fmt"
+
+// Supported unit_type enum values
+const (
+	Grams  = "grams"
+	Kg     = "kg"
+	Ml     = "ml"
+	Liters = "liters"
+	Pieces = "pieces"
+)
+
+// unitInfo describes a unit by its dimension and its factor relative to the dimension's base unit
+type unitInfo struct {
+	dimension string
+	factor    float64
+}
+
+var knownUnits = map[string]unitInfo{
+	Grams:  {dimension: "mass", factor: 1},
+	Kg:     {dimension: "mass", factor: 1000},
+	Ml:     {dimension: "volume", factor: 1},
+	Liters: {dimension: "volume", factor: 1000},
+	Pieces: {dimension: "count", factor: 1},
+}
+
+// IsValid reports whether unit is one of the unit_type enum values
+func IsValid(unit string) bool {
+	_, ok := knownUnits[unit]
+	return ok
+}
+
+// Compatible reports whether quantities in the two units can be converted into each other
+func Compatible(from, to string) bool {
+	fromInfo, ok := knownUnits[from]
+	if !ok {
+		return false
+	}
+	toInfo, ok := knownUnits[to]
+	if !ok {
+		return false
+	}
+	return fromInfo.dimension == toInfo.dimension
+}
+
+// Convert converts quantity from one unit into another of the same dimension.
+// An empty from unit is treated as already being in the target unit.
+func Convert(quantity float64, from, to string) (float64, error) {
+	if from == "" || from == to {
+		return quantity, nil
+	}
+
+	fromInfo, ok := knownUnits[from]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit: %s", from)
+	}
+	toInfo, ok := knownUnits[to]
+	if !ok {
+		return 0, fmt.Errorf("unknown unit: %s", to)
+	}
+	if fromInfo.dimension != toInfo.dimension {
+		return 0, fmt.Errorf("cannot convert %s to %s", from, to)
+	}
+
+	return quantity * fromInfo.factor / toInfo.factor, nil
+}
@@ -0,0 +1,62 @@
+package units
+
+import (
+	"math"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name     string
+		quantity float64
+		from     string
+		to       string
+		want     float64
+		wantErr  bool
+	}{
+		{name: "same unit", quantity: 18, from: Grams, to: Grams, want: 18},
+		{name: "empty from is already in target unit", quantity: 18, from: "", to: Kg, want: 18},
+		{name: "grams to kg", quantity: 18, from: Grams, to: Kg, want: 0.018},
+		{name: "kg to grams", quantity: 2.5, from: Kg, to: Grams, want: 2500},
+		{name: "ml to liters", quantity: 250, from: Ml, to: Liters, want: 0.25},
+		{name: "liters to ml", quantity: 0.03, from: Liters, to: Ml, want: 30},
+		{name: "pieces", quantity: 3, from: Pieces, to: Pieces, want: 3},
+		{name: "mass to volume", quantity: 1, from: Grams, to: Ml, wantErr: true},
+		{name: "volume to count", quantity: 1, from: Liters, to: Pieces, wantErr: true},
+		{name: "unknown from unit", quantity: 1, from: "cups", to: Ml, wantErr: true},
+		{name: "unknown to unit", quantity: 1, from: Ml, to: "cups", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Convert(tt.quantity, tt.from, tt.to)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Convert(%v, %q, %q) error = %v, wantErr %v", tt.quantity, tt.from, tt.to, err, tt.wantErr)
+			}
+			if !tt.wantErr && math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Convert(%v, %q, %q) = %v, want %v", tt.quantity, tt.from, tt.to, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompatible(t *testing.T) {
+	tests := []struct {
+		from, to string
+		want     bool
+	}{
+		{Grams, Kg, true},
+		{Liters, Ml, true},
+		{Pieces, Pieces, true},
+		{Grams, Ml, false},
+		{Kg, Pieces, false},
+		{"cups", Ml, false},
+		{Ml, "", false},
+	}
+
+	for _, tt := range tests {
+		if got := Compatible(tt.from, tt.to); got != tt.want {
+			t.Errorf("Compatible(%q, %q) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Header names sent with every delivery
+const (
+	HeaderID        = "X-Webhook-ID" // Same on every retry and redelivery of an event, for receivers to deduplicate on
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderSignature = "X-Webhook-Signature"
+)
+
+// Config holds webhook delivery settings
+type Config struct {
+	URLs           []string
+	Secret         string        // HMAC-SHA256 key; deliveries are unsigned when empty
+	MaxAttempts    int           // Attempts per URL, including the first one
+	InitialBackoff time.Duration // Doubled after every failed attempt
+	Timeout        time.Duration // Per request
+}
+
+// Sender delivers signed JSON events to the configured URLs
+type Sender struct {
+	config Config
+	client *http.Client
+}
+
+// NewSender creates a Sender, filling in defaults for unset retry and timeout settings
+func NewSender(config Config) *Sender {
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 3
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	return &Sender{
+		config: config,
+		client: &http.Client{Timeout: config.Timeout},
+	}
+}
+
+// Enabled reports whether any receiver is configured
+func (s *Sender) Enabled() bool {
+	return len(s.config.URLs) > 0
+}
+
+// URLs lists the configured receivers
+func (s *Sender) URLs() []string {
+	return s.config.URLs
+}
+
+// Delivery is the outcome of sending an event to one receiver
+type Delivery struct {
+	URL      string
+	Attempts int
+	Err      error // Last failure when the receiver never accepted the event
+}
+
+// Send posts the payload to each of urls, retrying failures with exponential backoff, and reports the outcome per URL.
+// id identifies the event and is sent with every delivery of it.
+func (s *Sender) Send(id, event string, payload interface{}, urls []string) ([]Delivery, error) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook payload: %v", err)
+	}
+
+	deliveries := make([]Delivery, len(urls))
+	for i, url := range urls {
+		attempts, err := s.deliver(url, id, event, body)
+		deliveries[i] = Delivery{URL: url, Attempts: attempts, Err: err}
+	}
+	return deliveries, nil
+}
+
+func (s *Sender) deliver(url, id, event string, body []byte) (int, error) {
+	backoff := s.config.InitialBackoff
+
+	var lastErr error
+	for attempt := 1; attempt <= s.config.MaxAttempts; attempt++ {
+		lastErr = s.post(url, id, event, body)
+		if lastErr == nil {
+			return attempt, nil
+		}
+		if attempt < s.config.MaxAttempts {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+	}
+
+	return s.config.MaxAttempts, fmt.Errorf("giving up after %d attempts: %v", s.config.MaxAttempts, lastErr)
+}
+
+func (s *Sender) post(url, id, event string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderID, id)
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderTimestamp, timestamp)
+	if s.config.Secret != "" {
+		req.Header.Set(HeaderSignature, "sha256="+Sign(s.config.Secret, timestamp, body))
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("receiver responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Sign computes the hex HMAC-SHA256 of "<timestamp>.<body>", which receivers recompute to verify a delivery
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
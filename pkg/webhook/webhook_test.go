@@ -0,0 +1,184 @@
+package webhook
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSign(t *testing.T) {
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp string
+		body      string
+		want      string
+	}{
+		{
+			name:      "alert payload",
+			secret:    "secret",
+			timestamp: "1700000000",
+			body:      `{"event":"inventory.low_stock"}`,
+			want:      "acaf00159632b629feab97070fcb8102d63d690f3eb733235cf079c4155d3407",
+		},
+		{
+			name:      "empty object",
+			secret:    "key",
+			timestamp: "1",
+			body:      `{}`,
+			want:      "1ba6b8171186efc613e8bcc0cbdab2748f24984d7c5a84faa2637afa0e40d224",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Sign(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+				t.Errorf("Sign() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSendSignsDelivery(t *testing.T) {
+	var gotID, gotEvent, gotTimestamp, gotSignature, gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		gotID = r.Header.Get(HeaderID)
+		gotEvent = r.Header.Get(HeaderEvent)
+		gotTimestamp = r.Header.Get(HeaderTimestamp)
+		gotSignature = r.Header.Get(HeaderSignature)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := NewSender(Config{URLs: []string{server.URL}, Secret: "secret"})
+	deliveries, err := sender.Send("alert-1", "inventory.low_stock", map[string]string{"event": "inventory.low_stock"}, sender.URLs())
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].Err != nil || deliveries[0].Attempts != 1 {
+		t.Errorf("Send() deliveries = %+v, want 1 successful attempt", deliveries)
+	}
+	if gotID != "alert-1" {
+		t.Errorf("%s = %q, want alert-1", HeaderID, gotID)
+	}
+	if gotEvent != "inventory.low_stock" {
+		t.Errorf("%s = %q, want inventory.low_stock", HeaderEvent, gotEvent)
+	}
+	if gotTimestamp == "" {
+		t.Fatalf("%s is missing", HeaderTimestamp)
+	}
+	if want := "sha256=" + Sign("secret", gotTimestamp, []byte(gotBody)); gotSignature != want {
+		t.Errorf("%s = %q, want %q", HeaderSignature, gotSignature, want)
+	}
+}
+
+func TestSendUnsignedWithoutSecret(t *testing.T) {
+	var gotSignature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotSignature = r.Header.Get(HeaderSignature)
+	}))
+	defer server.Close()
+
+	deliveries, err := NewSender(Config{URLs: []string{server.URL}}).Send("1", "test", struct{}{}, []string{server.URL})
+	if err != nil || deliveries[0].Err != nil {
+		t.Fatalf("Send() error = %v, deliveries = %+v", err, deliveries)
+	}
+	if gotSignature != "" {
+		t.Errorf("%s = %q, want none", HeaderSignature, gotSignature)
+	}
+}
+
+func TestSendRetries(t *testing.T) {
+	tests := []struct {
+		name         string
+		failures     int32 // Requests answered with 503 before succeeding
+		maxAttempts  int
+		wantAttempts int
+		wantRequests int32
+		wantErr      bool
+	}{
+		{name: "succeeds first time", failures: 0, maxAttempts: 3, wantAttempts: 1, wantRequests: 1},
+		{name: "retries after server error", failures: 2, maxAttempts: 3, wantAttempts: 3, wantRequests: 3},
+		{name: "gives up after max attempts", failures: 10, maxAttempts: 3, wantAttempts: 3, wantRequests: 3, wantErr: true},
+		{name: "single attempt", failures: 1, maxAttempts: 1, wantAttempts: 1, wantRequests: 1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&requests, 1) <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			sender := NewSender(Config{URLs: []string{server.URL}, MaxAttempts: tt.maxAttempts, InitialBackoff: time.Millisecond})
+			deliveries, err := sender.Send("1", "test", struct{}{}, sender.URLs())
+			if err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+			if (deliveries[0].Err != nil) != tt.wantErr {
+				t.Fatalf("Send() delivery error = %v, wantErr %v", deliveries[0].Err, tt.wantErr)
+			}
+			if deliveries[0].Attempts != tt.wantAttempts {
+				t.Errorf("Send() attempts = %d, want %d", deliveries[0].Attempts, tt.wantAttempts)
+			}
+			if got := atomic.LoadInt32(&requests); got != tt.wantRequests {
+				t.Errorf("server got %d requests, want %d", got, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestSendBacksOff(t *testing.T) {
+	var times []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		times = append(times, time.Now())
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	backoff := 20 * time.Millisecond
+	sender := NewSender(Config{URLs: []string{server.URL}, MaxAttempts: 3, InitialBackoff: backoff})
+	if deliveries, _ := sender.Send("1", "test", struct{}{}, sender.URLs()); deliveries[0].Err == nil {
+		t.Fatal("Send() delivery error = nil, want an error")
+	}
+
+	if len(times) != 3 {
+		t.Fatalf("server got %d requests, want 3", len(times))
+	}
+	if gap := times[1].Sub(times[0]); gap < backoff {
+		t.Errorf("first retry after %v, want at least %v", gap, backoff)
+	}
+	if gap := times[2].Sub(times[1]); gap < 2*backoff {
+		t.Errorf("second retry after %v, want at least %v", gap, 2*backoff)
+	}
+}
+
+func TestSendOnlyToGivenURLs(t *testing.T) {
+	var hitA, hitB int32
+	a := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { atomic.AddInt32(&hitA, 1) }))
+	defer a.Close()
+	b := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { atomic.AddInt32(&hitB, 1) }))
+	defer b.Close()
+
+	sender := NewSender(Config{URLs: []string{a.URL, b.URL}})
+	deliveries, err := sender.Send("1", "test", struct{}{}, []string{b.URL})
+	if err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(deliveries) != 1 || deliveries[0].URL != b.URL {
+		t.Errorf("Send() deliveries = %+v, want one to %s", deliveries, b.URL)
+	}
+	if hitA != 0 || hitB != 1 {
+		t.Errorf("receivers got %d and %d requests, want 0 and 1", hitA, hitB)
+	}
+}
@@ -1,7 +1,7 @@
 -- Sample Data for Frappuccino Coffee Shop Database (Expanded Version)
 
 -- First, let's check if we have menu items, if not create some basic ones
-INSERT INTO menu_items (name, description, price, category, available, metadata, tags, allergens, available_sizes)
+INSERT INTO menu_items (name, description, price, category, available, metadata, tags, allergens_added, available_sizes)
 SELECT * FROM (VALUES
     ('Classic Americano', 'Rich espresso with hot water', 3.50, 'hot_coffee', true, '{"prep_time": 120, "caffeine_level": "high"}'::jsonb, ARRAY['coffee', 'espresso'], ARRAY[]::text[], ARRAY['small', 'medium', 'large']::item_size[]),
     ('Vanilla Latte', 'Smooth espresso with steamed milk and vanilla', 4.75, 'hot_coffee', true, '{"prep_time": 180, "caffeine_level": "medium"}'::jsonb, ARRAY['coffee', 'latte', 'vanilla'], ARRAY['milk'], ARRAY['small', 'medium', 'large']::item_size[]),
@@ -18,9 +18,17 @@ SELECT * FROM (VALUES
     ('Croissant', 'Buttery, flaky French pastry', 3.25, 'pastry', true, '{"prep_time": 45, "contains_gluten": true}'::jsonb, ARRAY['bakery', 'buttery', 'breakfast'], ARRAY['gluten', 'butter'], ARRAY['medium']::item_size[]),
     ('Avocado Toast', 'Multigrain bread with fresh avocado spread', 6.50, 'food', true, '{"prep_time": 300, "healthy": true}'::jsonb, ARRAY['healthy', 'breakfast', 'vegetarian'], ARRAY['gluten'], ARRAY['medium']::item_size[]),
     ('Turkey Sandwich', 'Sliced turkey with lettuce, tomato on sourdough', 8.95, 'food', true, '{"prep_time": 360, "protein_rich": true}'::jsonb, ARRAY['lunch', 'protein', 'savory'], ARRAY['gluten'], ARRAY['medium']::item_size[])
-) as new_items(name, description, price, category, available, metadata, tags, allergens, available_sizes)
+) as new_items(name, description, price, category, available, metadata, tags, allergens_added, available_sizes)
 WHERE NOT EXISTS (SELECT 1 FROM menu_items WHERE menu_items.name = new_items.name);
 
+-- Stations for the sample menu's categories
+INSERT INTO category_stations (category, station) VALUES
+    ('hot_coffee', 'bar'),
+    ('cold_coffee', 'bar'),
+    ('non_coffee', 'bar'),
+    ('food', 'kitchen')
+ON CONFLICT (category) DO NOTHING;
+
 -- Add comprehensive inventory items
 INSERT INTO inventory (name, quantity, unit, min_threshold, cost_per_unit)
 SELECT * FROM (VALUES
@@ -47,6 +55,45 @@ SELECT * FROM (VALUES
 ) as new_inventory(name, quantity, unit, min_threshold, cost_per_unit)
 WHERE NOT EXISTS (SELECT 1 FROM inventory WHERE inventory.name = new_inventory.name);
 
+-- Shelf life for perishables, then one opening lot per item so quantity matches its lots
+UPDATE inventory SET shelf_life_days = v.days
+FROM (VALUES
+    ('Whole Milk', 7),
+    ('Oat Milk', 10),
+    ('Almond Milk', 10),
+    ('Whipped Cream', 5)
+) AS v(name, days)
+WHERE inventory.name = v.name AND inventory.shelf_life_days = 0;
+
+-- Allergens and nutrition per unit (per kg or liter here), which menu items derive theirs from
+UPDATE inventory SET allergens = v.allergens, kcal_per_unit = v.kcal, sugar_g_per_unit = v.sugar,
+                     fat_g_per_unit = v.fat, caffeine_mg_per_unit = v.caffeine
+FROM (VALUES
+    ('Coffee Beans - Arabica', ARRAY[]::text[], 0, 0, 0, 12000),
+    ('Coffee Beans - Robusta', ARRAY[]::text[], 0, 0, 0, 22000),
+    ('Whole Milk', ARRAY['milk'], 640, 48, 35, 0),
+    ('Oat Milk', ARRAY['gluten'], 460, 40, 15, 0),
+    ('Almond Milk', ARRAY['tree nuts'], 150, 1, 11, 0),
+    ('Vanilla Syrup', ARRAY[]::text[], 2670, 660, 0, 0),
+    ('Caramel Syrup', ARRAY['milk'], 2700, 650, 5, 0),
+    ('Chocolate Syrup', ARRAY['milk'], 2700, 600, 10, 50),
+    ('Matcha Powder', ARRAY[]::text[], 3240, 0, 53, 30000),
+    ('Chai Tea Blend', ARRAY[]::text[], 0, 0, 0, 20000),
+    ('Whipped Cream', ARRAY['milk'], 2570, 120, 250, 0),
+    ('Sugar', ARRAY[]::text[], 4000, 1000, 0, 0),
+    ('Cinnamon', ARRAY[]::text[], 2470, 22, 12, 0),
+    ('Cocoa Powder', ARRAY[]::text[], 2280, 18, 137, 2300)
+) AS v(name, allergens, kcal, sugar, fat, caffeine)
+WHERE inventory.name = v.name;
+
+INSERT INTO inventory_lots (ingredient_id, quantity, initial_quantity, unit_cost, expires_at, reference_type)
+SELECT i.id, i.quantity, i.quantity, i.cost_per_unit,
+       CASE WHEN i.shelf_life_days > 0 THEN CURRENT_TIMESTAMP + make_interval(days => i.shelf_life_days) END,
+       'opening'
+FROM inventory i
+WHERE i.quantity > 0
+AND NOT EXISTS (SELECT 1 FROM inventory_lots l WHERE l.ingredient_id = i.id);
+
 -- Insert expanded sample orders
 INSERT INTO orders (customer_name, status, total_amount, special_instructions) VALUES
 ('Alice Johnson', 'closed', 8.25, '{"notes": "Extra hot, no foam"}'),
@@ -148,6 +195,19 @@ FROM order_data
 INSERT INTO order_items (order_id, menu_item_id, quantity, price_at_time, customizations)
 SELECT order_id, menu_item_id, quantity, price_at_time, customizations FROM order_items_data;
 
+-- Route the sample items to their stations; orders past preparing have been made
+UPDATE order_items oi
+SET station = COALESCE(m.station, cs.station, 'kitchen'),
+    prep_status = CASE o.status
+        WHEN 'preparing' THEN 'in_progress'
+        WHEN 'ready' THEN 'done'
+        WHEN 'closed' THEN 'done'
+        ELSE 'queued'
+    END::prep_status
+FROM orders o, menu_items m
+LEFT JOIN category_stations cs ON cs.category = m.category
+WHERE o.id = oi.order_id AND m.id = oi.menu_item_id;
+
 -- Add menu item ingredients relationships
 INSERT INTO menu_item_ingredients (menu_item_id, ingredient_id, required_quantity, unit)
 SELECT m.id, i.id, 
@@ -198,6 +258,22 @@ AND NOT EXISTS (
     WHERE mii.menu_item_id = m.id AND mii.ingredient_id = i.id
 );
 
+-- Modifiers for the sample orders' customizations
+INSERT INTO menu_item_modifiers (menu_item_id, name, ingredient_id, quantity, unit)
+SELECT m.id, v.modifier, i.id, v.quantity, v.unit::unit_type
+FROM (VALUES
+    ('Caramel Macchiato', 'extra_caramel', 'Caramel Syrup', 0.010, 'liters'),
+    ('Mocha', 'whipped_cream', 'Whipped Cream', 0.030, 'liters'),
+    ('Frappuccino Classic', 'extra_whipped_cream', 'Whipped Cream', 0.040, 'liters'),
+    ('Hot Chocolate', 'extra_chocolate', 'Chocolate Syrup', 0.020, 'liters'),
+    ('Cappuccino', 'extra_shot', 'Coffee Beans - Arabica', 0.009, 'kg'),
+    ('Cafe Latte', 'extra_shot', 'Coffee Beans - Arabica', 0.009, 'kg'),
+    ('Vanilla Latte', 'extra_shot', 'Coffee Beans - Arabica', 0.009, 'kg')
+) AS v(menu_name, modifier, ingredient, quantity, unit)
+JOIN menu_items m ON m.name = v.menu_name
+JOIN inventory i ON i.name = v.ingredient
+ON CONFLICT (menu_item_id, name, ingredient_id) DO NOTHING;
+
 -- Insert comprehensive inventory transactions
 INSERT INTO inventory_transactions (ingredient_id, transaction_type, quantity_change, quantity_before, quantity_after, reference_type, reference_id, notes)
 SELECT 